@@ -0,0 +1,51 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: colors for text output
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"os"
+)
+
+// -- global variables
+
+// Colors for text output (empty strings after DisableColors)
+var COLOR_YELLOW = "\033[93m"
+var COLOR_RED    = "\033[91m"
+var COLOR_GREEN  = "\033[32m"
+var COLOR_NORMAL = "\033[39m"
+var COLOR_CYAN   = "\033[96m"
+var COLOR_BLUE   = "\033[94m"
+var COLOR_GREY   = "\033[90m"
+
+// -- functions
+
+// StdoutIsTerminal returns true if stdout is a terminal (not redirected to a file or a pipe)
+func StdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	if err != nil { return false }
+	return (fi.Mode() & os.ModeCharDevice) != 0
+}
+
+// DisableColors removes colors from the text output
+func DisableColors() {
+	COLOR_YELLOW = ""
+	COLOR_RED    = ""
+	COLOR_GREEN  = ""
+	COLOR_NORMAL = ""
+	COLOR_CYAN   = ""
+	COLOR_BLUE   = ""
+	COLOR_GREY   = ""
+}
+
+// SetupColors disables colors if --no-color is provided or if stdout is not a terminal
+func SetupColors(no_color bool) {
+	if no_color || !StdoutIsTerminal() { DisableColors() }
+}
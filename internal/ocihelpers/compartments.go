@@ -0,0 +1,231 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: list of compartments and tree of compartments
+// (full paths, sub-compartments, name and path resolution, tags)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Read the compartments from a local cache file (see compartments_cache.go)
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- types
+
+// CompartmentLister is the part of the identity client used to list compartments
+// (implemented by identity.IdentityClient, and by fake clients in unit tests)
+type CompartmentLister interface {
+	ListCompartments(ctx context.Context, request identity.ListCompartmentsRequest) (identity.ListCompartmentsResponse, error)
+}
+
+// CompartmentTree contains all the compartments and sub-compartments of a tenant
+type CompartmentTree struct {
+	TenancyOCID  string
+	Compartments []identity.Compartment
+	Visible      map[string]bool     // compartments returned by Children (nil = all compartments), see SetVisible
+	SortBy       string              // sort order of Children: "name" (default) or "created"
+	by_id        map[string]int      // index of compartments in Compartments by OCID
+}
+
+// -- functions
+
+// ListAllCompartments returns all the compartments and sub-compartments of a tenant (all pages of results)
+func ListAllCompartments(client CompartmentLister, tenancy_ocid string) ([]identity.Compartment, error) {
+	vrai := true
+	request := identity.ListCompartmentsRequest{
+		CompartmentId          : common.String(tenancy_ocid),
+		CompartmentIdInSubtree : &vrai,
+		RequestMetadata        : common.RequestMetadata{ RetryPolicy : RetryPolicy() },
+	}
+	cpts := make([]identity.Compartment, 0)
+	err := ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListCompartments(context.Background(), request)
+		if err != nil { return nil, err }
+		cpts = append(cpts, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	return cpts, err
+}
+
+// GetCompartmentTree gets all the compartments of the tenant of a configuration provider
+// (from the cache file if it is more recent than CompartmentCacheTTL, unless RefreshCompartmentCache is set)
+func GetCompartmentTree(config common.ConfigurationProvider) (*CompartmentTree, error) {
+	tenancy_ocid, err := config.TenancyOCID()
+	if err != nil { return nil, err }
+	if CompartmentCacheTTL > 0 && !RefreshCompartmentCache {
+		if cpts, found := ReadCompartmentCache(tenancy_ocid, CompartmentCacheTTL); found { return NewCompartmentTree(tenancy_ocid, cpts), nil }
+	}
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	cpts, err := ListAllCompartments(client, tenancy_ocid)
+	if err != nil { return nil, err }
+	// the cache file is only an optimization: errors are ignored
+	if CompartmentCacheTTL > 0 { WriteCompartmentCache(tenancy_ocid, cpts) }
+	return NewCompartmentTree(tenancy_ocid, cpts), nil
+}
+
+// NewCompartmentTree creates the tree of compartments from the list of all compartments of a tenant
+func NewCompartmentTree(tenancy_ocid string, cpts []identity.Compartment) *CompartmentTree {
+	t := &CompartmentTree{ TenancyOCID : tenancy_ocid, Compartments : cpts, SortBy : "name" }
+	t.by_id = make(map[string]int)
+	for i, c := range cpts {
+		t.by_id[*c.Id] = i
+	}
+	return t
+}
+
+// Get returns the compartment with the given OCID (false if not found)
+func (t *CompartmentTree) Get(cpt_id string) (identity.Compartment, bool) {
+	i, found := t.by_id[cpt_id]
+	if !found { return identity.Compartment{}, false }
+	return t.Compartments[i], true
+}
+
+// FullPath returns the full path of a compartment (root/parent/.../name) by walking the parent compartments
+func (t *CompartmentTree) FullPath(cpt_id string) string {
+	names := make([]string, 0)
+	for cpt_id != t.TenancyOCID {
+		c, found := t.Get(cpt_id)
+		if !found {
+			names = append([]string{"UNKNOWN"}, names...)
+			break
+		}
+		names = append([]string{*c.Name}, names...)
+		cpt_id = *c.CompartmentId
+	}
+	return strings.Join(append([]string{"root"}, names...), "/")
+}
+
+// FullPaths returns the full paths of all compartments (map compartment OCID -> full path)
+func (t *CompartmentTree) FullPaths() map[string]string {
+	paths := make(map[string]string)
+	for _, c := range t.Compartments {
+		paths[*c.Id] = t.FullPath(*c.Id)
+	}
+	return paths
+}
+
+// FindByPath returns the OCID of a compartment (not deleted) from its full path (root/parent/.../name),
+// walking the tree from root (false if not found)
+func (t *CompartmentTree) FindByPath(path string) (string, bool) {
+	names := strings.Split(strings.Trim(path, "/"), "/")
+	if names[0] == "root" { names = names[1:] }
+	cpt_id := t.TenancyOCID
+	for _, name := range names {
+		found := false
+		for _, c := range t.Compartments {
+			if *c.CompartmentId == cpt_id && *c.Name == name && c.LifecycleState != identity.CompartmentLifecycleStateDeleted {
+				cpt_id = *c.Id
+				found = true
+				break
+			}
+		}
+		if !found { return "", false }
+	}
+	return cpt_id, true
+}
+
+// FindByName returns the OCIDs of the compartments (not deleted) with a given name
+func (t *CompartmentTree) FindByName(name string) []string {
+	ocids := make([]string, 0)
+	for _, c := range t.Compartments {
+		if *c.Name == name && c.LifecycleState != identity.CompartmentLifecycleStateDeleted {
+			ocids = append(ocids, *c.Id)
+		}
+	}
+	return ocids
+}
+
+// ActiveCompartmentIds returns the OCIDs of the root compartment and of the active compartments, sorted by
+// full path, to list the resources of all the compartments of the tenancy
+func (t *CompartmentTree) ActiveCompartmentIds() []string {
+	paths := map[string]string{ t.TenancyOCID : "root" }
+	for _, c := range t.Compartments {
+		if c.LifecycleState == identity.CompartmentLifecycleStateActive { paths[*c.Id] = t.FullPath(*c.Id) }
+	}
+	ocids := make([]string, 0, len(paths))
+	for id := range paths { ocids = append(ocids, id) }
+	sort.Slice(ocids, func(i, j int) bool { return paths[ocids[i]] < paths[ocids[j]] })
+	return ocids
+}
+
+// Filter returns the compartments whose name or OCID matches the regular expression
+func (t *CompartmentTree) Filter(re *regexp.Regexp) []identity.Compartment {
+	filtered := make([]identity.Compartment, 0)
+	for _, c := range t.Compartments {
+		if re.MatchString(*c.Name) || re.MatchString(*c.Id) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// Children returns the direct sub-compartments of a compartment (only visible ones if Visible is set),
+// sorted by name or creation date
+func (t *CompartmentTree) Children(parent_id string) []identity.Compartment {
+	subcpts := make([]identity.Compartment, 0)
+	for _, c := range t.Compartments {
+		if *c.CompartmentId == parent_id && (t.Visible == nil || t.Visible[*c.Id]) {
+			subcpts = append(subcpts, c)
+		}
+	}
+	sort.SliceStable(subcpts, func(i, j int) bool {
+		if t.SortBy == "created" {
+			return subcpts[i].TimeCreated.Before(subcpts[j].TimeCreated.Time)
+		}
+		return strings.ToLower(*subcpts[i].Name) < strings.ToLower(*subcpts[j].Name)
+	})
+	return subcpts
+}
+
+// CountDescendants returns the number of sub-compartments (direct and indirect) of a compartment
+func (t *CompartmentTree) CountDescendants(parent_id string) int {
+	count := 0
+	for _, c := range t.Children(parent_id) {
+		count += 1 + t.CountDescendants(*c.Id)
+	}
+	return count
+}
+
+// SetVisible marks visible the compartments whose name or OCID matches the regular expression, and their
+// parent compartments, so that Children only returns the branches of the tree leading to them
+func (t *CompartmentTree) SetVisible(re *regexp.Regexp) {
+	t.Visible = make(map[string]bool)
+	for _, c := range t.Filter(re) {
+		for id := *c.Id; id != t.TenancyOCID && !t.Visible[id]; {
+			t.Visible[id] = true
+			parent, found := t.Get(id)
+			if !found { break }
+			id = *parent.CompartmentId
+		}
+	}
+}
+
+// FormatTags formats freeform tags and defined tags in a single string (key=value, namespace.key=value, ...)
+func FormatTags(freeform_tags map[string]string, defined_tags map[string]map[string]interface{}) string {
+	tags := make([]string, 0)
+	for k, v := range freeform_tags {
+		tags = append(tags, fmt.Sprintf("%s=%s", k, v))
+	}
+	for ns, keys := range defined_tags {
+		for k, v := range keys {
+			tags = append(tags, fmt.Sprintf("%s.%s=%v", ns, k, v))
+		}
+	}
+	sort.Strings(tags)
+	return strings.Join(tags, ", ")
+}
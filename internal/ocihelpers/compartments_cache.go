@@ -0,0 +1,94 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: local cache file of the compartments of a tenant
+// (~/.oci/compartments-cache-TENANCY_OCID.json) used by GetCompartmentTree to avoid listing all the
+// compartments at each execution in tenants with many compartments
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- constants
+
+// DefaultCompartmentCacheTTL is the default time to live of the compartments cache file
+const DefaultCompartmentCacheTTL = 60 * time.Minute
+
+// -- global variables
+
+// CompartmentCacheTTL is the time to live of the compartments cache file
+// (OCI_COMPARTMENTS_CACHE_TTL environment variable in minutes, 0 = no cache file)
+var CompartmentCacheTTL = compartment_cache_ttl()
+
+// RefreshCompartmentCache forces GetCompartmentTree to list the compartments instead of reading the cache file
+// (--refresh option)
+var RefreshCompartmentCache bool
+
+// directory of the cache files (changed by unit tests)
+var compartment_cache_dir = "~/.oci"
+
+// -- functions
+
+// time to live of the cache file from the OCI_COMPARTMENTS_CACHE_TTL environment variable (minutes)
+func compartment_cache_ttl() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("OCI_COMPARTMENTS_CACHE_TTL"))
+	if err != nil || minutes < 0 { return DefaultCompartmentCacheTTL }
+	return time.Duration(minutes) * time.Minute
+}
+
+// CompartmentCacheFile returns the name of the compartments cache file of a tenant
+func CompartmentCacheFile(tenancy_ocid string) string {
+	return filepath.Join(ExpandPath(compartment_cache_dir), "compartments-cache-"+tenancy_ocid+".json")
+}
+
+// ReadCompartmentCache returns the compartments of a tenant from the cache file
+// (false if there is no cache file, if it is older than the time to live or if it cannot be read)
+func ReadCompartmentCache(tenancy_ocid string, ttl time.Duration) ([]identity.Compartment, bool) {
+	file := CompartmentCacheFile(tenancy_ocid)
+	info, err := os.Stat(file)
+	if err != nil || time.Since(info.ModTime()) > ttl { return nil, false }
+	data, err := ioutil.ReadFile(file)
+	if err != nil { return nil, false }
+	cpts := make([]identity.Compartment, 0)
+	if err := json.Unmarshal(data, &cpts); err != nil { return nil, false }
+	return cpts, true
+}
+
+// WriteCompartmentCache writes the compartments of a tenant to the cache file
+// (temporary file renamed, so that scripts executed at the same time never read a partial file)
+func WriteCompartmentCache(tenancy_ocid string, cpts []identity.Compartment) error {
+	data, err := json.Marshal(cpts)
+	if err != nil { return err }
+	file := CompartmentCacheFile(tenancy_ocid)
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil { return err }
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil { return err }
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil { return err }
+	return os.Rename(tmp.Name(), file)
+}
+
+// ClearCompartmentCache removes the cache file of a tenant (after creating, renaming, moving or deleting compartments)
+func ClearCompartmentCache(tenancy_ocid string) error {
+	err := os.Remove(CompartmentCacheFile(tenancy_ocid))
+	if os.IsNotExist(err) { return nil }
+	return err
+}
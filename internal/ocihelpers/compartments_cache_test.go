@@ -0,0 +1,50 @@
+package ocihelpers
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+func TestCompartmentCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocihelpers")
+	if err != nil { t.Fatal(err) }
+	defer os.RemoveAll(dir)
+	saved_dir := compartment_cache_dir
+	compartment_cache_dir = dir
+	defer func() { compartment_cache_dir = saved_dir }()
+
+	// no cache file
+	if _, found := ReadCompartmentCache(test_tenancy, time.Hour); found { t.Errorf("cache found before being written") }
+
+	// cache file written then read
+	cpts := []identity.Compartment{
+		new_compartment("ocid1.compartment.oc1..prod", test_tenancy, "Prod", identity.CompartmentLifecycleStateActive, "2020-03-01"),
+		new_compartment("ocid1.compartment.oc1..old", test_tenancy, "Old", identity.CompartmentLifecycleStateDeleted, "2019-01-01"),
+	}
+	if err := WriteCompartmentCache(test_tenancy, cpts); err != nil { t.Fatal(err) }
+	cached, found := ReadCompartmentCache(test_tenancy, time.Hour)
+	if !found { t.Fatalf("cache not found after being written") }
+	tree := NewCompartmentTree(test_tenancy, cached)
+	if len(cached) != 2 || tree.FullPath("ocid1.compartment.oc1..prod") != "root/Prod" || cached[1].LifecycleState != identity.CompartmentLifecycleStateDeleted {
+		t.Errorf("cached compartments = %+v", cached)
+	}
+	if !cached[0].TimeCreated.Equal(cpts[0].TimeCreated.Time) { t.Errorf("time created = %s, want %s", cached[0].TimeCreated, cpts[0].TimeCreated) }
+
+	// expired cache file
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(CompartmentCacheFile(test_tenancy), old, old); err != nil { t.Fatal(err) }
+	if _, found := ReadCompartmentCache(test_tenancy, time.Hour); found { t.Errorf("expired cache found") }
+
+	// invalid cache file
+	if err := ioutil.WriteFile(CompartmentCacheFile(test_tenancy), []byte("{"), 0600); err != nil { t.Fatal(err) }
+	if _, found := ReadCompartmentCache(test_tenancy, time.Hour); found { t.Errorf("invalid cache found") }
+
+	// cache file removed (no error if it does not exist)
+	if err := ClearCompartmentCache(test_tenancy); err != nil { t.Errorf("clear: %s", err) }
+	if _, err := os.Stat(CompartmentCacheFile(test_tenancy)); !os.IsNotExist(err) { t.Errorf("cache file not removed") }
+	if err := ClearCompartmentCache(test_tenancy); err != nil { t.Errorf("clear without cache file: %s", err) }
+}
@@ -0,0 +1,163 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: display of the tree of compartments as text (colors and
+// indents), Graphviz DOT or HTML page (collapsible lists), used by OCI_compartments_list_formatted.go and
+// ocitools compartments tree
+// Note: with a maximum depth N, only the compartments of level N or less are displayed (0 = root only)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- functions
+
+// true if the sub-compartments of a compartment of this level are not displayed (max_depth < 0 for no limit)
+func depth_reached(level int, max_depth int) bool {
+	return max_depth >= 0 && level >= max_depth
+}
+
+// FprintText writes the tree of compartments with colors and indents to w (visible compartments only),
+// with the number of hidden sub-compartments of the compartments of level max_depth
+func (t *CompartmentTree) FprintText(w io.Writer, max_depth int, show_tags bool) {
+	line := COLOR_GREEN+"root"+COLOR_NORMAL+" "+t.TenancyOCID+COLOR_YELLOW+" ACTIVE"+COLOR_NORMAL
+	if hidden := t.CountDescendants(t.TenancyOCID); depth_reached(0, max_depth) && hidden > 0 {
+		line += fmt.Sprintf(COLOR_GREY+" (+%d hidden sub-compartments)"+COLOR_NORMAL, hidden)
+	}
+	fmt.Fprintln (w, line)
+	t.fprint_text_nodes(w, t.TenancyOCID, "", 0, max_depth, show_tags)
+}
+
+// write the sub-compartments of a compartment of level level (0 for root)
+// prefix contains the vertical lines of the parent levels
+func (t *CompartmentTree) fprint_text_nodes(w io.Writer, parent_id string, prefix string, level int, max_depth int, show_tags bool) {
+	if depth_reached(level, max_depth) { return }
+	subcpts := t.Children(parent_id)
+	for i, c := range subcpts {
+		branch, next_prefix := "├───── ", prefix+COLOR_CYAN+"│      "+COLOR_NORMAL
+		if i == len(subcpts)-1 {
+			branch, next_prefix = "└───── ", prefix+"       "
+		}
+		line := prefix+COLOR_CYAN+branch+COLOR_NORMAL
+		if c.LifecycleState == identity.CompartmentLifecycleStateActive {
+			line += COLOR_GREEN+*c.Name+COLOR_NORMAL+" "+*c.Id+COLOR_YELLOW+" ACTIVE"+COLOR_NORMAL
+		} else {
+			line += COLOR_BLUE+*c.Name+COLOR_GREY+" "+*c.Id+COLOR_RED+" DELETED"+COLOR_NORMAL
+		}
+		if show_tags {
+			line += COLOR_GREY+" ["+FormatTags(c.FreeformTags, c.DefinedTags)+"]"+COLOR_NORMAL
+		}
+		if hidden := t.CountDescendants(*c.Id); depth_reached(level+1, max_depth) && hidden > 0 {
+			line += fmt.Sprintf(COLOR_GREY+" (+%d hidden sub-compartments)"+COLOR_NORMAL, hidden)
+		}
+		fmt.Fprintln (w, line)
+		t.fprint_text_nodes(w, *c.Id, next_prefix, level+1, max_depth, show_tags)
+	}
+}
+
+// escape a string for a Graphviz DOT label
+func dot_escape(str string) string {
+	return strings.Replace(str, "\"", "\\\"", -1)
+}
+
+// FprintDot writes the tree of compartments as a Graphviz digraph to w (visible compartments only)
+// which can be rendered with: dot -Tpng -o compartments.png
+func (t *CompartmentTree) FprintDot(w io.Writer, max_depth int, show_tags bool) {
+	fmt.Fprintln (w, "digraph compartments {")
+	fmt.Fprintln (w, "  rankdir=LR;")
+	fmt.Fprintln (w, "  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];")
+	fmt.Fprintf  (w, "  \"%s\" [label=\"root\", fillcolor=\"palegreen\"];\n", t.TenancyOCID)
+	t.fprint_dot_nodes(w, t.TenancyOCID, 0, max_depth, show_tags)
+	fmt.Fprintln (w, "}")
+}
+
+// write the nodes and edges of the sub-compartments of a compartment of level level (0 for root)
+func (t *CompartmentTree) fprint_dot_nodes(w io.Writer, parent_id string, level int, max_depth int, show_tags bool) {
+	if depth_reached(level, max_depth) { return }
+	for _, c := range t.Children(parent_id) {
+		fillcolor := "palegreen"
+		if c.LifecycleState != identity.CompartmentLifecycleStateActive { fillcolor = "lightpink" }
+		label := dot_escape(*c.Name)
+		if show_tags {
+			label += "\\n" + dot_escape(FormatTags(c.FreeformTags, c.DefinedTags))
+		}
+		fmt.Fprintf (w, "  \"%s\" [label=\"%s\", fillcolor=\"%s\"];\n", *c.Id, label, fillcolor)
+		fmt.Fprintf (w, "  \"%s\" -> \"%s\";\n", parent_id, *c.Id)
+		t.fprint_dot_nodes(w, *c.Id, level+1, max_depth, show_tags)
+	}
+}
+
+// FprintHTML writes the tree of compartments as an HTML page with collapsible lists to w (visible compartments
+// only), with the number of hidden sub-compartments of the compartments of level max_depth
+func (t *CompartmentTree) FprintHTML(w io.Writer, max_depth int, show_tags bool) {
+	fmt.Fprintln (w, "<!DOCTYPE html>")
+	fmt.Fprintln (w, "<html>")
+	fmt.Fprintln (w, "<head>")
+	fmt.Fprintln (w, "<meta charset=\"utf-8\">")
+	fmt.Fprintln (w, "<title>OCI compartments</title>")
+	fmt.Fprintln (w, "<style>")
+	fmt.Fprintln (w, "  body          { font-family: Helvetica, Arial, sans-serif; }")
+	fmt.Fprintln (w, "  ul            { list-style-type: none; }")
+	fmt.Fprintln (w, "  summary       { cursor: pointer; }")
+	fmt.Fprintln (w, "  .active       { color: green; font-weight: bold; }")
+	fmt.Fprintln (w, "  .deleted      { color: blue; }")
+	fmt.Fprintln (w, "  .active-state { color: orange; }")
+	fmt.Fprintln (w, "  .deleted-state{ color: red; }")
+	fmt.Fprintln (w, "  .ocid, .tags  { color: grey; }")
+	fmt.Fprintln (w, "</style>")
+	fmt.Fprintln (w, "</head>")
+	fmt.Fprintln (w, "<body>")
+	fmt.Fprintln (w, "<ul>")
+	root := "<span class=\"active\">root</span> <span class=\"ocid\">"+t.TenancyOCID+"</span> <span class=\"active-state\">ACTIVE</span>"
+	if hidden := t.CountDescendants(t.TenancyOCID); depth_reached(0, max_depth) && hidden > 0 {
+		fmt.Fprintf (w, "  <li>%s <span class=\"ocid\">(+%d hidden sub-compartments)</span></li>\n", root, hidden)
+	} else {
+		fmt.Fprintln (w, "  <li><details open><summary>"+root+"</summary>")
+		t.fprint_html_nodes(w, t.TenancyOCID, 0, max_depth, show_tags)
+		fmt.Fprintln (w, "  </details></li>")
+	}
+	fmt.Fprintln (w, "</ul>")
+	fmt.Fprintln (w, "</body>")
+	fmt.Fprintln (w, "</html>")
+}
+
+// write the sub-compartments of a compartment of level level (0 for root) as nested HTML lists
+func (t *CompartmentTree) fprint_html_nodes(w io.Writer, parent_id string, level int, max_depth int, show_tags bool) {
+	subcpts := t.Children(parent_id)
+	if len(subcpts) == 0 || depth_reached(level, max_depth) { return }
+
+	indent := strings.Repeat("  ", level+2)
+	fmt.Fprintln (w, indent+"<ul>")
+	for _, c := range subcpts {
+		class := "active"
+		if c.LifecycleState != identity.CompartmentLifecycleStateActive { class = "deleted" }
+		item := fmt.Sprintf ("<span class=\"%s\">%s</span> <span class=\"ocid\">%s</span> <span class=\"%s-state\">%s</span>",
+			class, html.EscapeString(*c.Name), *c.Id, class, c.LifecycleState)
+		if show_tags {
+			item += " <span class=\"tags\">["+html.EscapeString(FormatTags(c.FreeformTags, c.DefinedTags))+"]</span>"
+		}
+		hidden := t.CountDescendants(*c.Id)
+		if hidden == 0 {
+			fmt.Fprintln (w, indent+"  <li>"+item+"</li>")
+		} else if depth_reached(level+1, max_depth) {
+			fmt.Fprintf (w, indent+"  <li>%s <span class=\"ocid\">(+%d hidden sub-compartments)</span></li>\n", item, hidden)
+		} else {
+			fmt.Fprintln (w, indent+"  <li><details open><summary>"+item+"</summary>")
+			t.fprint_html_nodes(w, *c.Id, level+1, max_depth, show_tags)
+			fmt.Fprintln (w, indent+"  </details></li>")
+		}
+	}
+	fmt.Fprintln (w, indent+"</ul>")
+}
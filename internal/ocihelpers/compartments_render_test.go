@@ -0,0 +1,82 @@
+package ocihelpers
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// OCIDs of the test compartments found in an output, in the order of test_compartments()
+func displayed_ids(output string) []string {
+	result := []string{}
+	for _, c := range test_compartments() {
+		if strings.Contains(output, "\""+*c.Id+"\"") || strings.Contains(output, " "+*c.Id+" ") || strings.Contains(output, ">"+*c.Id+"<") {
+			result = append(result, *c.Id)
+		}
+	}
+	return result
+}
+
+func TestFprintText(t *testing.T) {
+	saved := []string{ COLOR_YELLOW, COLOR_RED, COLOR_GREEN, COLOR_NORMAL, COLOR_CYAN, COLOR_BLUE, COLOR_GREY }
+	defer func() { COLOR_YELLOW, COLOR_RED, COLOR_GREEN, COLOR_NORMAL, COLOR_CYAN, COLOR_BLUE, COLOR_GREY = saved[0], saved[1], saved[2], saved[3], saved[4], saved[5], saved[6] }()
+	DisableColors()
+
+	tree := NewCompartmentTree(test_tenancy, test_compartments())
+	var buf bytes.Buffer
+	tree.FprintText(&buf, 1, false)
+	want := "root "+test_tenancy+" ACTIVE\n"+
+		"├───── Dev ocid.dev ACTIVE (+1 hidden sub-compartments)\n"+
+		"├───── old ocid.old DELETED\n"+
+		"└───── Prod ocid.prod ACTIVE (+2 hidden sub-compartments)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFprintMaxDepth(t *testing.T) {
+	saved := []string{ COLOR_YELLOW, COLOR_RED, COLOR_GREEN, COLOR_NORMAL, COLOR_CYAN, COLOR_BLUE, COLOR_GREY }
+	defer func() { COLOR_YELLOW, COLOR_RED, COLOR_GREEN, COLOR_NORMAL, COLOR_CYAN, COLOR_BLUE, COLOR_GREY = saved[0], saved[1], saved[2], saved[3], saved[4], saved[5], saved[6] }()
+	DisableColors()
+
+	tests := []struct {
+		max_depth int
+		want      []string
+		hidden    string
+	}{
+		{  0, []string{}, "(+6 hidden sub-compartments)" },
+		{  1, []string{ "ocid.prod", "ocid.dev", "ocid.old" }, "(+2 hidden sub-compartments)" },
+		{  2, []string{ "ocid.prod", "ocid.prod.net", "ocid.prod.app", "ocid.dev", "ocid.dev.net", "ocid.old" }, "" },
+		{ -1, []string{ "ocid.prod", "ocid.prod.net", "ocid.prod.app", "ocid.dev", "ocid.dev.net", "ocid.old" }, "" },
+	}
+	tree := NewCompartmentTree(test_tenancy, test_compartments())
+	for _, tt := range tests {
+		var text, dot, html bytes.Buffer
+		tree.FprintText(&text, tt.max_depth, false)
+		tree.FprintDot(&dot, tt.max_depth, false)
+		tree.FprintHTML(&html, tt.max_depth, false)
+		for format, output := range map[string]string{ "text": text.String(), "dot": dot.String(), "html": html.String() } {
+			if got := displayed_ids(output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("max_depth %d, %s: got %v, want %v", tt.max_depth, format, got, tt.want)
+			}
+			if format != "dot" && tt.hidden != "" && !strings.Contains(output, tt.hidden) {
+				t.Errorf("max_depth %d, %s: %q not found in\n%s", tt.max_depth, format, tt.hidden, output)
+			}
+			if format != "dot" && tt.hidden == "" && strings.Contains(output, "hidden") {
+				t.Errorf("max_depth %d, %s: unexpected hidden count in\n%s", tt.max_depth, format, output)
+			}
+		}
+	}
+}
+
+func TestFprintDotEscape(t *testing.T) {
+	cpts := test_compartments()
+	cpts[0].Name = &[]string{ "Prod \"main\"" }[0]
+	tree := NewCompartmentTree(test_tenancy, cpts)
+	var buf bytes.Buffer
+	tree.FprintDot(&buf, -1, false)
+	if want := "  \"ocid.prod\" [label=\"Prod \\\"main\\\"\", fillcolor=\"palegreen\"];\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("%q not found in\n%s", want, buf.String())
+	}
+}
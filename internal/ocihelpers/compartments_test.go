@@ -0,0 +1,252 @@
+package ocihelpers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+const test_tenancy = "ocid1.tenancy.oc1..test"
+
+// fake identity client returning the compartments in pages of page_size items
+type fake_compartment_lister struct {
+	cpts      []identity.Compartment
+	page_size int
+	err       error
+	requests  []identity.ListCompartmentsRequest
+}
+
+func (f *fake_compartment_lister) ListCompartments(ctx context.Context, request identity.ListCompartmentsRequest) (identity.ListCompartmentsResponse, error) {
+	f.requests = append(f.requests, request)
+	if f.err != nil { return identity.ListCompartmentsResponse{}, f.err }
+	start := 0
+	if request.Page != nil {
+		for i, c := range f.cpts {
+			if *c.Id == *request.Page { start = i }
+		}
+	}
+	end := start + f.page_size
+	response := identity.ListCompartmentsResponse{}
+	if end < len(f.cpts) {
+		response.OpcNextPage = f.cpts[end].Id
+	} else {
+		end = len(f.cpts)
+	}
+	response.Items = f.cpts[start:end]
+	return response, nil
+}
+
+func new_compartment(id string, parent_id string, name string, state identity.CompartmentLifecycleStateEnum, created string) identity.Compartment {
+	t, _ := time.Parse("2006-01-02", created)
+	return identity.Compartment{
+		Id             : common.String(id),
+		CompartmentId  : common.String(parent_id),
+		Name           : common.String(name),
+		LifecycleState : state,
+		TimeCreated    : &common.SDKTime{ Time : t },
+	}
+}
+
+// root
+// ├── Prod          (created 2020-03-01)
+// │   ├── Network
+// │   └── App
+// ├── Dev           (created 2020-01-01)
+// │   └── Network
+// └── old           (deleted, created 2019-01-01)
+func test_compartments() []identity.Compartment {
+	active  := identity.CompartmentLifecycleStateActive
+	deleted := identity.CompartmentLifecycleStateDeleted
+	return []identity.Compartment{
+		new_compartment("ocid.prod",     test_tenancy, "Prod",    active,  "2020-03-01"),
+		new_compartment("ocid.prod.net", "ocid.prod",  "Network", active,  "2020-03-02"),
+		new_compartment("ocid.prod.app", "ocid.prod",  "App",     active,  "2020-03-03"),
+		new_compartment("ocid.dev",      test_tenancy, "Dev",     active,  "2020-01-01"),
+		new_compartment("ocid.dev.net",  "ocid.dev",   "Network", active,  "2020-01-02"),
+		new_compartment("ocid.old",      test_tenancy, "old",     deleted, "2019-01-01"),
+	}
+}
+
+func ids(cpts []identity.Compartment) []string {
+	result := make([]string, 0, len(cpts))
+	for _, c := range cpts { result = append(result, *c.Id) }
+	return result
+}
+
+func TestListAllCompartments(t *testing.T) {
+	tests := []struct {
+		name          string
+		page_size     int
+		nb_requests   int
+	}{
+		{ "single page",       10, 1 },
+		{ "exact pages",        3, 2 },
+		{ "one item per page",  1, 6 },
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fake_compartment_lister{ cpts : test_compartments(), page_size : tt.page_size }
+			cpts, err := ListAllCompartments(client, test_tenancy)
+			if err != nil { t.Fatalf("unexpected error: %s", err) }
+			if !reflect.DeepEqual(ids(cpts), ids(client.cpts)) {
+				t.Errorf("got %v, want %v", ids(cpts), ids(client.cpts))
+			}
+			if len(client.requests) != tt.nb_requests {
+				t.Errorf("got %d requests, want %d", len(client.requests), tt.nb_requests)
+			}
+			for _, r := range client.requests {
+				if *r.CompartmentId != test_tenancy || r.CompartmentIdInSubtree == nil || !*r.CompartmentIdInSubtree {
+					t.Errorf("request must list all compartments of the tenancy: %v", r)
+				}
+				if r.RetryPolicy() == nil {
+					t.Errorf("request must have a retry policy")
+				}
+			}
+		})
+	}
+}
+
+func TestListAllCompartmentsError(t *testing.T) {
+	client := &fake_compartment_lister{ page_size : 10, err : errors.New("NotAuthenticated") }
+	if _, err := ListAllCompartments(client, test_tenancy); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestFullPath(t *testing.T) {
+	tree := NewCompartmentTree(test_tenancy, test_compartments())
+	tests := []struct {
+		cpt_id string
+		want   string
+	}{
+		{ test_tenancy,    "root" },
+		{ "ocid.prod",     "root/Prod" },
+		{ "ocid.prod.net", "root/Prod/Network" },
+		{ "ocid.dev.net",  "root/Dev/Network" },
+		{ "ocid.unknown",  "root/UNKNOWN" },
+	}
+	for _, tt := range tests {
+		if got := tree.FullPath(tt.cpt_id); got != tt.want {
+			t.Errorf("FullPath(%s) = %s, want %s", tt.cpt_id, got, tt.want)
+		}
+	}
+}
+
+func TestFindByPath(t *testing.T) {
+	tree := NewCompartmentTree(test_tenancy, test_compartments())
+	tests := []struct {
+		path  string
+		want  string
+		found bool
+	}{
+		{ "root",               test_tenancy,    true },
+		{ "root/Prod",          "ocid.prod",     true },
+		{ "Prod/Network",       "ocid.prod.net", true },
+		{ "/root/Dev/Network/", "ocid.dev.net",  true },
+		{ "root/Prod/Missing",  "",              false },
+		{ "root/old",           "",              false },     // deleted compartments are ignored
+	}
+	for _, tt := range tests {
+		got, found := tree.FindByPath(tt.path)
+		if got != tt.want || found != tt.found {
+			t.Errorf("FindByPath(%s) = %s, %v, want %s, %v", tt.path, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestActiveCompartmentIds(t *testing.T) {
+	tree := NewCompartmentTree(test_tenancy, test_compartments())
+	want := []string{ test_tenancy, "ocid.dev", "ocid.dev.net", "ocid.prod", "ocid.prod.app", "ocid.prod.net" }
+	if got := tree.ActiveCompartmentIds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindByName(t *testing.T) {
+	tree := NewCompartmentTree(test_tenancy, test_compartments())
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{ "Prod",    []string{ "ocid.prod" } },
+		{ "Network", []string{ "ocid.prod.net", "ocid.dev.net" } },
+		{ "old",     []string{} },
+		{ "prod",    []string{} },
+	}
+	for _, tt := range tests {
+		if got := tree.FindByName(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindByName(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChildren(t *testing.T) {
+	tests := []struct {
+		name      string
+		sort_by   string
+		grep      string
+		parent_id string
+		want      []string
+	}{
+		{ "sorted by name",          "name",    "",        test_tenancy, []string{ "ocid.dev", "ocid.old", "ocid.prod" } },
+		{ "sorted by creation date", "created", "",        test_tenancy, []string{ "ocid.old", "ocid.dev", "ocid.prod" } },
+		{ "sub-compartments",        "name",    "",        "ocid.prod",  []string{ "ocid.prod.app", "ocid.prod.net" } },
+		{ "leaf",                    "name",    "",        "ocid.dev.net", []string{} },
+		{ "grep keeps parents",      "name",    "^App$",   test_tenancy, []string{ "ocid.prod" } },
+		{ "grep on OCID",            "name",    "dev.net", "ocid.dev",   []string{ "ocid.dev.net" } },
+		{ "grep hides siblings",     "name",    "^App$",   "ocid.prod",  []string{ "ocid.prod.app" } },
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := NewCompartmentTree(test_tenancy, test_compartments())
+			tree.SortBy = tt.sort_by
+			if tt.grep != "" { tree.SetVisible(regexp.MustCompile(tt.grep)) }
+			if got := ids(tree.Children(tt.parent_id)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountDescendants(t *testing.T) {
+	tree := NewCompartmentTree(test_tenancy, test_compartments())
+	tests := []struct {
+		cpt_id string
+		want   int
+	}{
+		{ test_tenancy,   6 },
+		{ "ocid.prod",    2 },
+		{ "ocid.dev",     1 },
+		{ "ocid.dev.net", 0 },
+	}
+	for _, tt := range tests {
+		if got := tree.CountDescendants(tt.cpt_id); got != tt.want {
+			t.Errorf("CountDescendants(%s) = %d, want %d", tt.cpt_id, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		freeform map[string]string
+		defined  map[string]map[string]interface{}
+		want     string
+	}{
+		{ "no tags",       nil,                                          nil, "" },
+		{ "freeform tags", map[string]string{ "env" : "prod", "app" : "web" }, nil, "app=web, env=prod" },
+		{ "defined tags",  nil, map[string]map[string]interface{}{ "Oracle-Tags" : { "CreatedBy" : "admin" } }, "Oracle-Tags.CreatedBy=admin" },
+		{ "both",          map[string]string{ "env" : "dev" }, map[string]map[string]interface{}{ "ns" : { "cost" : 42 } }, "env=dev, ns.cost=42" },
+	}
+	for _, tt := range tests {
+		if got := FormatTags(tt.freeform, tt.defined); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: OCI config file, profiles and authentication
+// (API key, session token or instance principal)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/common/auth"
+)
+
+// -- constants
+
+// DefaultConfigFile is the OCI config file used when OCI_CONFIG_FILE and --config-file are not provided
+const DefaultConfigFile string = "~/.oci/config"
+
+// DefaultProfile is the profile used when no profile is given and OCI_CLI_PROFILE is not set
+const DefaultProfile string = "DEFAULT"
+
+// -- types
+
+// SessionTokenProvider is the configuration provider for profiles using a session token (security_token_file
+// created by "oci session authenticate"): requests are signed with the session token instead of the user OCID
+// and API key fingerprint
+type SessionTokenProvider struct {
+	common.ConfigurationProvider
+	TokenFile string
+}
+
+// -- functions
+
+// KeyID returns the key ID used to sign requests with a session token
+func (p SessionTokenProvider) KeyID() (string, error) {
+	token, err := ioutil.ReadFile(ExpandPath(p.TokenFile))
+	if err != nil { return "", err }
+	return "ST$" + strings.TrimSpace(string(token)), nil
+}
+
+// UserOCID returns an empty string as there is no user OCID in profiles using a session token
+func (p SessionTokenProvider) UserOCID() (string, error) {
+	return "", nil
+}
+
+// GetConfigFile returns the OCI config file to use: OCI_CONFIG_FILE environment variable or default config file
+// (scripts can then override it with their --config-file option)
+func GetConfigFile() string {
+	if os.Getenv("OCI_CONFIG_FILE") != "" { return os.Getenv("OCI_CONFIG_FILE") }
+	return DefaultConfigFile
+}
+
+// GetProfile returns the OCI profile to use: argument (if provided), OCI_CLI_PROFILE environment variable
+// or DEFAULT profile
+func GetProfile(args []string) string {
+	if len(args) == 1 { return args[0] }
+	if os.Getenv("OCI_CLI_PROFILE") != "" { return os.Getenv("OCI_CLI_PROFILE") }
+	return DefaultProfile
+}
+
+// GetProfileValue returns the value of a parameter for a profile in the OCI config file (empty string if not found)
+func GetProfileValue(config_file string, profile string, key string) string {
+	data, err := ioutil.ReadFile(ExpandPath(config_file))
+	if err != nil { return "" }
+	in_profile := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			in_profile = (line[1:len(line)-1] == profile)
+			continue
+		}
+		if in_profile {
+			if kv := strings.SplitN(line, "=", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == key {
+				return strings.TrimSpace(kv[1])
+			}
+		}
+	}
+	return ""
+}
+
+// ListProfiles returns the names of all the profiles in the OCI config file
+func ListProfiles(config_file string) ([]string, error) {
+	data, err := ioutil.ReadFile(ExpandPath(config_file))
+	if err != nil { return nil, err }
+	profiles := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			profiles = append(profiles, line[1:len(line)-1])
+		}
+	}
+	return profiles, nil
+}
+
+// ExpandPath replaces ~ by the home directory in a file path
+func ExpandPath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// GetConfigProvider returns the configuration provider: instance principal if instance_principal is true,
+// else the profile of the OCI config file (API key or session token).
+// Errors are returned as AuthError (exit code ExitAuthError)
+func GetConfigProvider(config_file string, profile string, instance_principal bool) (common.ConfigurationProvider, error) {
+	if instance_principal {
+		config, err := auth.InstancePrincipalConfigurationProvider()
+		if err != nil { return nil, AuthError{ err } }
+		return config, nil
+	}
+	file_config, err := common.ConfigurationProviderFromFileWithProfile(config_file, profile, "")
+	if err != nil { return nil, AuthError{ err } }
+	config := common.ConfigurationProvider(file_config)
+	checks := []func() (string, error){ config.TenancyOCID, config.Region, config.UserOCID, config.KeyFingerprint }
+	if token_file := GetProfileValue(config_file, profile, "security_token_file"); token_file != "" {
+		config = SessionTokenProvider{ file_config, token_file }
+		checks = []func() (string, error){ config.TenancyOCID, config.Region, config.KeyID }
+	}
+
+	// check the profile now (missing parameters, API key or session token file) to report an authentication error
+	for _, get_value := range checks {
+		if _, err := get_value(); err != nil { return nil, AuthError{ fmt.Errorf("profile %s: %s", profile, err) } }
+	}
+	if _, err := config.PrivateRSAKey(); err != nil { return nil, AuthError{ fmt.Errorf("profile %s: %s", profile, err) } }
+	return config, nil
+}
+
+// PrintAuthUsage displays the end of the usage message shared by all scripts: authentication options,
+// OCI config file and profile example
+func PrintAuthUsage(config_file string) {
+	fmt.Println("    If -ip (or --instance-principal) is provided, instance principal authentication is used")
+	fmt.Println("    instead of OCI_PROFILE (script executed from an OCI compute instance).")
+	fmt.Println("    If --config-file FILE is provided, this OCI config file is used instead of the default one")
+	fmt.Println("    (environment variable OCI_CONFIG_FILE can also be used).")
+	fmt.Println("    Profiles using a session token (security_token_file created by 'oci session authenticate')")
+	fmt.Println("    are supported: refresh the token with 'oci session refresh --profile OCI_PROFILE' if it expired.")
+	fmt.Println("")
+	fmt.Printf ("note: OCI_PROFILE must exist in %s file (see example below)\n", config_file)
+	fmt.Printf ("      if OCI_PROFILE is not provided, the OCI_CLI_PROFILE environment variable or the %s profile is used\n", DefaultProfile)
+	fmt.Println("")
+	fmt.Println("[EMEAOSCf]")
+	fmt.Println("tenancy     = ocid1.tenancy.oc1..aaaaaaaaw7e6nkszrry6d5hxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx")
+	fmt.Println("user        = ocid1.user.oc1..aaaaaaaayblfepjieoxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx")
+	fmt.Println("fingerprint = 19:1d:7b:3a:17:xx:xx:xx:xx:xx:xx:xx:xx:xx:xx:xx")
+	fmt.Println("key_file    = /Users/cpauliat/.oci/api_key.pem")
+	fmt.Println("region      = eu-frankfurt-1")
+}
@@ -0,0 +1,103 @@
+package ocihelpers
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const test_config = `[DEFAULT]
+tenancy     = ocid1.tenancy.oc1..default
+region      = eu-frankfurt-1
+
+[EMEAOSCf]
+tenancy     = ocid1.tenancy.oc1..emea
+region=us-ashburn-1
+security_token_file = ~/.oci/sessions/EMEAOSCf/token
+`
+
+func write_test_config(t *testing.T) string {
+	f, err := ioutil.TempFile("", "oci_config")
+	if err != nil { t.Fatal(err) }
+	f.WriteString(test_config)
+	f.Close()
+	return f.Name()
+}
+
+func TestGetProfileValue(t *testing.T) {
+	config_file := write_test_config(t)
+	defer os.Remove(config_file)
+
+	tests := []struct {
+		profile string
+		key     string
+		want    string
+	}{
+		{ "DEFAULT",  "region",              "eu-frankfurt-1" },
+		{ "EMEAOSCf", "region",              "us-ashburn-1" },
+		{ "EMEAOSCf", "security_token_file", "~/.oci/sessions/EMEAOSCf/token" },
+		{ "DEFAULT",  "security_token_file", "" },
+		{ "MISSING",  "region",              "" },
+	}
+	for _, tt := range tests {
+		if got := GetProfileValue(config_file, tt.profile, tt.key); got != tt.want {
+			t.Errorf("GetProfileValue(%s, %s) = %q, want %q", tt.profile, tt.key, got, tt.want)
+		}
+	}
+	if got := GetProfileValue("/nonexistent/config", "DEFAULT", "region"); got != "" {
+		t.Errorf("got %q for a missing config file, want empty string", got)
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	config_file := write_test_config(t)
+	defer os.Remove(config_file)
+
+	profiles, err := ListProfiles(config_file)
+	if err != nil { t.Fatalf("unexpected error: %s", err) }
+	if want := []string{ "DEFAULT", "EMEAOSCf" }; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("got %v, want %v", profiles, want)
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	saved := os.Getenv("OCI_CLI_PROFILE")
+	defer os.Setenv("OCI_CLI_PROFILE", saved)
+
+	tests := []struct {
+		args []string
+		env  string
+		want string
+	}{
+		{ []string{ "EMEAOSCf" }, "OTHER", "EMEAOSCf" },
+		{ []string{},             "OTHER", "OTHER" },
+		{ nil,                    "",      DefaultProfile },
+	}
+	for _, tt := range tests {
+		os.Setenv("OCI_CLI_PROFILE", tt.env)
+		if got := GetProfile(tt.args); got != tt.want {
+			t.Errorf("GetProfile(%v) with OCI_CLI_PROFILE=%q = %s, want %s", tt.args, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestGetConfigProviderAuthErrors(t *testing.T) {
+	config_file := write_test_config(t)
+	defer os.Remove(config_file)
+
+	tests := []struct {
+		name    string
+		profile string
+	}{
+		{ "missing profile",                     "MISSING" },
+		{ "missing user, fingerprint and key",   "DEFAULT" },
+		{ "missing session token and key",       "EMEAOSCf" },
+	}
+	for _, tt := range tests {
+		_, err := GetConfigProvider(config_file, tt.profile, false)
+		if !IsAuthError(err) {
+			t.Errorf("%s: got error %v, want an authentication error", tt.name, err)
+		}
+	}
+}
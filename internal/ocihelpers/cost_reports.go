@@ -0,0 +1,121 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: parsing of the cost reports (CSV files) generated by OCI
+// in the Object Storage bucket of the tenancy (namespace bling, bucket = tenancy OCID, prefix reports/cost-csv/)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -- constants
+
+// Object Storage location of the cost reports
+const CostReportsNamespace = "bling"
+const CostReportsPrefix    = "reports/cost-csv/"
+
+// -- types
+
+// CostLine is a line item of a cost report
+type CostLine struct {
+	ReferenceNo     string
+	BackreferenceNo string     // reference of the line item corrected by this one
+	IsCorrection    bool
+	IntervalStart   time.Time
+	Service         string
+	CompartmentId   string
+	CompartmentName string
+	Region          string
+	ResourceId      string
+	Sku             string
+	Description     string
+	Currency        string
+	Cost            float64    // cost/myCost + cost/myCostOverage
+}
+
+// -- functions
+
+// parse a time of a cost report (ex: 2026-10-01T05:00Z)
+func parse_cost_time(str string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02T15:04Z", str); err == nil { return t, nil }
+	return time.Parse(time.RFC3339, str)
+}
+
+// parse a cost of a cost report (empty cost = 0)
+func parse_cost(str string) (float64, error) {
+	if str == "" { return 0, nil }
+	return strconv.ParseFloat(str, 64)
+}
+
+// ParseCostReport parses a cost report (uncompressed CSV file with a header row)
+func ParseCostReport(r io.Reader) ([]CostLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	headers, err := reader.Read()
+	if err != nil { return nil, fmt.Errorf("invalid cost report: %s", err) }
+	columns := make(map[string]int)
+	for i, h := range headers { columns[strings.TrimPrefix(h, "\ufeff")] = i }
+	for _, c := range []string{ "lineItem/referenceNo", "lineItem/intervalUsageStart", "product/service", "product/compartmentId", "cost/myCost" } {
+		if _, found := columns[c]; !found { return nil, fmt.Errorf("invalid cost report: column %s not found", c) }
+	}
+	value := func(record []string, column string) string {
+		i, found := columns[column]
+		if !found || i >= len(record) { return "" }
+		return record[i]
+	}
+
+	lines := make([]CostLine, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF { break }
+		if err != nil { return nil, fmt.Errorf("invalid cost report: %s", err) }
+		line := CostLine{
+			ReferenceNo     : value(record, "lineItem/referenceNo"),
+			BackreferenceNo : value(record, "lineItem/backreferenceNo"),
+			IsCorrection    : strings.EqualFold(value(record, "lineItem/isCorrection"), "true"),
+			Service         : value(record, "product/service"),
+			CompartmentId   : value(record, "product/compartmentId"),
+			CompartmentName : value(record, "product/compartmentName"),
+			Region          : value(record, "product/region"),
+			ResourceId      : value(record, "product/resourceId"),
+			Sku             : value(record, "cost/productSku"),
+			Description     : value(record, "product/Description"),
+			Currency        : value(record, "cost/currencyCode"),
+		}
+		line.IntervalStart, err = parse_cost_time(value(record, "lineItem/intervalUsageStart"))
+		if err != nil { return nil, fmt.Errorf("invalid cost report: line %s: %s", line.ReferenceNo, err) }
+		cost, err := parse_cost(value(record, "cost/myCost"))
+		if err != nil { return nil, fmt.Errorf("invalid cost report: line %s: %s", line.ReferenceNo, err) }
+		overage, err := parse_cost(value(record, "cost/myCostOverage"))
+		if err != nil { return nil, fmt.Errorf("invalid cost report: line %s: %s", line.ReferenceNo, err) }
+		line.Cost = cost + overage
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// ApplyCostCorrections returns the line items without the ones replaced by corrections
+// (a correction replaces the line item given by its back reference, possibly in an older report)
+func ApplyCostCorrections(lines []CostLine) []CostLine {
+	corrected := make(map[string]bool)
+	for _, l := range lines {
+		if l.IsCorrection && l.BackreferenceNo != "" { corrected[l.BackreferenceNo] = true }
+	}
+	result := make([]CostLine, 0, len(lines))
+	for _, l := range lines {
+		if !corrected[l.ReferenceNo] { result = append(result, l) }
+	}
+	return result
+}
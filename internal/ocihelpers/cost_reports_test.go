@@ -0,0 +1,37 @@
+package ocihelpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const test_cost_report = "\ufefflineItem/referenceNo,lineItem/tenantId,lineItem/intervalUsageStart,lineItem/intervalUsageEnd,product/service,product/compartmentId,product/compartmentName,product/region,product/resourceId,cost/productSku,product/Description,cost/myCost,cost/myCostOverage,cost/currencyCode,lineItem/isCorrection,lineItem/backreferenceNo\n" +
+	"ref1,ocid1.tenancy.oc1..t,2026-10-01T05:00Z,2026-10-01T06:00Z,COMPUTE,ocid1.compartment.oc1..a,Prod,eu-frankfurt-1,ocid1.instance.oc1..i,B93113,Compute - Standard - E4 - OCPU,1.5,,EUR,,\n" +
+	"ref2,ocid1.tenancy.oc1..t,2026-10-01T05:00Z,2026-10-01T06:00Z,BLOCK_STORAGE,ocid1.compartment.oc1..b,Dev,eu-frankfurt-1,ocid1.volume.oc1..v,B91961,Block Volume - Storage,0.25,0.05,EUR,,\n" +
+	"ref3,ocid1.tenancy.oc1..t,2026-10-01T05:00Z,2026-10-01T06:00Z,COMPUTE,ocid1.compartment.oc1..a,Prod,eu-frankfurt-1,ocid1.instance.oc1..i,B93113,Compute - Standard - E4 - OCPU,1.2,,EUR,true,ref1\n"
+
+func TestParseCostReport(t *testing.T) {
+	lines, err := ParseCostReport(strings.NewReader(test_cost_report))
+	if err != nil { t.Fatal(err) }
+	if len(lines) != 3 { t.Fatalf("got %d lines, want 3", len(lines)) }
+	l := lines[1]
+	if l.ReferenceNo != "ref2" || l.Service != "BLOCK_STORAGE" || l.CompartmentId != "ocid1.compartment.oc1..b" || l.Currency != "EUR" {
+		t.Errorf("line 2 = %+v", l)
+	}
+	if l.Cost < 0.2999 || l.Cost > 0.3001 { t.Errorf("cost = %f, want 0.3 (cost + overage)", l.Cost) }
+	if !l.IntervalStart.Equal(time.Date(2026, 10, 1, 5, 0, 0, 0, time.UTC)) { t.Errorf("interval start = %v", l.IntervalStart) }
+	if !lines[2].IsCorrection || lines[2].BackreferenceNo != "ref1" { t.Errorf("line 3 is not a correction of ref1: %+v", lines[2]) }
+
+	if _, err := ParseCostReport(strings.NewReader("a,b,c\n1,2,3\n")); err == nil { t.Errorf("expected an error for a file which is not a cost report") }
+	if _, err := ParseCostReport(strings.NewReader(strings.Replace(test_cost_report, ",1.5,", ",abc,", 1))); err == nil { t.Errorf("expected an error for an invalid cost") }
+}
+
+func TestApplyCostCorrections(t *testing.T) {
+	lines, err := ParseCostReport(strings.NewReader(test_cost_report))
+	if err != nil { t.Fatal(err) }
+	lines = ApplyCostCorrections(lines)
+	if len(lines) != 2 || lines[0].ReferenceNo != "ref2" || lines[1].ReferenceNo != "ref3" {
+		t.Errorf("lines after corrections = %+v, want ref2 and ref3", lines)
+	}
+}
@@ -0,0 +1,82 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: exit codes and quiet mode, so that the scripts can be used
+// in automation and monitoring checks
+//    0 = OK
+//    1 = usage error or other error
+//    2 = authentication error (OCI config file, profile, API key, session token, instance principal)
+//    3 = partial results due to API errors (ex: a region or a profile could not be processed)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- constants
+
+// Exit codes of the scripts
+const (
+	ExitOK             = 0
+	ExitError          = 1
+	ExitAuthError      = 2
+	ExitPartialResults = 3
+)
+
+// -- global variables
+
+// Quiet is set by the --quiet option of the scripts: headers, banners and summaries are not displayed
+var Quiet bool
+
+// -- types
+
+// AuthError is an error in the authentication configuration (OCI config file, profile, API key, ...)
+type AuthError struct {
+	Err error
+}
+
+// -- functions
+
+func (e AuthError) Error() string {
+	return "authentication error: " + e.Err.Error()
+}
+
+func (e AuthError) Unwrap() error {
+	return e.Err
+}
+
+// IsAuthError returns true for authentication configuration errors and for requests rejected by OCI
+// because they are not authenticated (HTTP 401)
+func IsAuthError(err error) bool {
+	var auth_error AuthError
+	if errors.As(err, &auth_error) { return true }
+	if failure, ok := common.IsServiceError(err); ok {
+		return failure.GetHTTPStatusCode() == http.StatusUnauthorized
+	}
+	return false
+}
+
+// ExitCode returns the exit code for an error: ExitOK (no error), ExitAuthError or ExitError
+func ExitCode(err error) int {
+	if err == nil { return ExitOK }
+	if IsAuthError(err) { return ExitAuthError }
+	return ExitError
+}
+
+// FatalIfError displays the error on stderr and exits with ExitAuthError or ExitError if err is not nil
+func FatalIfError(err error) {
+	if err == nil { return }
+	fmt.Fprintf (os.Stderr, "ERROR: %s\n", err)
+	os.Exit (ExitCode(err))
+}
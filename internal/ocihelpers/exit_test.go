@@ -0,0 +1,25 @@
+package ocihelpers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{ "no error",           nil,                                                     ExitOK },
+		{ "other error",        errors.New("InternalServerError"),                       ExitError },
+		{ "auth error",         AuthError{ errors.New("can not read private key") },     ExitAuthError },
+		{ "wrapped auth error", fmt.Errorf("profile X: %w", AuthError{ errors.New("") }), ExitAuthError },
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: merge of kubeconfig files (Kubernetes clusters of OKE)
+// Note: no YAML library is used, only the block style layout written by kubectl and by OCI is supported
+//       (top level keys at column 0, clusters, contexts and users as lists of entries with a name)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"strings"
+)
+
+// -- types
+
+// top level key of a kubeconfig file, with its lines (including the "key:" line)
+type kubeconfig_section struct {
+	key   string
+	lines []string
+}
+
+// entry of the clusters, contexts or users lists
+type kubeconfig_entry struct {
+	name  string
+	lines []string
+}
+
+// -- functions
+
+// split a kubeconfig file in top level sections (comments and empty lines are kept with the previous section)
+func parse_kubeconfig(content string) []*kubeconfig_section {
+	sections := make([]*kubeconfig_section, 0)
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		top_level := line != "" && line[0] != ' ' && line[0] != '-' && line[0] != '#' && strings.Contains(line, ":")
+		if top_level || len(sections) == 0 {
+			key := ""
+			if top_level { key = strings.TrimSpace(line[:strings.Index(line, ":")]) }
+			sections = append(sections, &kubeconfig_section{ key : key })
+		}
+		section := sections[len(sections)-1]
+		section.lines = append(section.lines, line)
+	}
+	return sections
+}
+
+// split the lines of a clusters, contexts or users section in named entries
+func parse_kubeconfig_entries(section *kubeconfig_section) ([]kubeconfig_entry, error) {
+	entries := make([]kubeconfig_entry, 0)
+	value := strings.TrimSpace(section.lines[0][strings.Index(section.lines[0], ":")+1:])
+	if value != "" && value != "[]" && value != "null" && value != "~" {
+		return nil, fmt.Errorf("unsupported kubeconfig format for %s (flow style)", section.key)
+	}
+	entry_indent := -1
+	for _, line := range section.lines[1:] {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed[0] == '#' {
+			if len(entries) > 0 { entries[len(entries)-1].lines = append(entries[len(entries)-1].lines, line) }
+			continue
+		}
+		indent := len(line) - len(trimmed)
+		if entry_indent < 0 && strings.HasPrefix(trimmed, "-") { entry_indent = indent }
+		if indent == entry_indent && strings.HasPrefix(trimmed, "-") {
+			entries = append(entries, kubeconfig_entry{})
+			trimmed, indent = strings.TrimLeft(trimmed[1:], " "), indent+2      // first key of the entry
+		}
+		if len(entries) == 0 { return nil, fmt.Errorf("unsupported kubeconfig format for %s", section.key) }
+		entry := &entries[len(entries)-1]
+		entry.lines = append(entry.lines, line)
+		if indent == entry_indent+2 && strings.HasPrefix(trimmed, "name:") {
+			entry.name = strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "name:")), "\"'")
+		}
+	}
+	for _, entry := range entries {
+		if entry.name == "" { return nil, fmt.Errorf("unsupported kubeconfig format for %s (entry without name)", section.key) }
+	}
+	return entries, nil
+}
+
+// find a top level section of a kubeconfig file (nil if not found)
+func find_kubeconfig_section(sections []*kubeconfig_section, key string) *kubeconfig_section {
+	for _, s := range sections {
+		if s.key == key { return s }
+	}
+	return nil
+}
+
+// MergeKubeconfig merges the clusters, contexts and users of the kubeconfig new_config (ex: generated for an
+// OKE cluster) in the kubeconfig existing (entries with the same name are replaced), sets the current context
+// to the one of new_config, and returns the merged kubeconfig. new_config is returned if existing is empty.
+func MergeKubeconfig(existing string, new_config string) (string, error) {
+	new_sections := parse_kubeconfig(new_config)
+	if find_kubeconfig_section(new_sections, "clusters") == nil { return "", fmt.Errorf("invalid kubeconfig: no clusters") }
+	if strings.TrimSpace(existing) == "" { return new_config, nil }
+
+	sections := parse_kubeconfig(existing)
+	for _, key := range []string{ "clusters", "contexts", "users" } {
+		new_section := find_kubeconfig_section(new_sections, key)
+		if new_section == nil { continue }
+		new_entries, err := parse_kubeconfig_entries(new_section)
+		if err != nil { return "", err }
+		section := find_kubeconfig_section(sections, key)
+		if section == nil {
+			section = &kubeconfig_section{ key : key, lines : []string{ key+":" } }
+			sections = append(sections, section)
+		}
+		entries, err := parse_kubeconfig_entries(section)
+		if err != nil { return "", err }
+
+		// replace the entries with the same name and add the other ones
+		for _, new_entry := range new_entries {
+			replaced := false
+			for i := range entries {
+				if entries[i].name == new_entry.name { entries[i], replaced = new_entry, true }
+			}
+			if !replaced { entries = append(entries, new_entry) }
+		}
+		section.lines = []string{ key+":" }
+		for _, entry := range entries { section.lines = append(section.lines, entry.lines...) }
+	}
+
+	// current context
+	if new_section := find_kubeconfig_section(new_sections, "current-context"); new_section != nil {
+		section := find_kubeconfig_section(sections, "current-context")
+		if section == nil {
+			sections = append(sections, new_section)
+		} else {
+			section.lines = new_section.lines
+		}
+	}
+
+	lines := make([]string, 0)
+	for _, s := range sections { lines = append(lines, s.lines...) }
+	return strings.Join(lines, "\n")+"\n", nil
+}
@@ -0,0 +1,81 @@
+package ocihelpers
+
+import (
+	"strings"
+	"testing"
+)
+
+// kubeconfig generated by OCI for an OKE cluster
+const oke_kubeconfig = `---
+apiVersion: v1
+kind: ""
+clusters:
+- name: cluster-c4tqmzrg
+  cluster:
+    server: https://10.0.0.10:6443
+    certificate-authority-data: NEWCA
+users:
+- name: user-c4tqmzrg
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: oci
+      args:
+      - ce
+      - cluster
+      - generate-token
+contexts:
+- name: context-c4tqmzrg
+  context:
+    cluster: cluster-c4tqmzrg
+    user: user-c4tqmzrg
+current-context: context-c4tqmzrg
+`
+
+func TestMergeKubeconfig(t *testing.T) {
+	// no existing kubeconfig
+	merged, err := MergeKubeconfig("", oke_kubeconfig)
+	if err != nil || merged != oke_kubeconfig { t.Errorf("got %q, %v, want the new kubeconfig", merged, err) }
+
+	// kubeconfig written by kubectl with another cluster and an old version of the OKE cluster
+	existing := `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: OLDCA
+    server: https://10.0.0.10:6443
+  name: cluster-c4tqmzrg
+- cluster:
+    server: https://kind.local:6443
+  name: kind
+contexts:
+- context:
+    cluster: kind
+    user: kind
+  name: kind
+current-context: kind
+kind: Config
+preferences: {}
+users:
+- name: kind
+  user:
+    token: abc
+`
+	merged, err = MergeKubeconfig(existing, oke_kubeconfig)
+	if err != nil { t.Fatal(err) }
+	for _, want := range []string{ "NEWCA", "https://kind.local:6443", "  name: kind\n", "- name: context-c4tqmzrg", "- name: user-c4tqmzrg", "current-context: context-c4tqmzrg\n", "preferences: {}" } {
+		if !strings.Contains(merged, want) { t.Errorf("%q not found in merged kubeconfig:\n%s", want, merged) }
+	}
+	if strings.Contains(merged, "OLDCA") { t.Errorf("old entry not replaced:\n%s", merged) }
+	if strings.Count(merged, "name: cluster-c4tqmzrg") != 1 { t.Errorf("duplicated entry:\n%s", merged) }
+
+	// merging twice gives the same result
+	if merged2, _ := MergeKubeconfig(merged, oke_kubeconfig); merged2 != merged { t.Errorf("got %q, want %q", merged2, merged) }
+
+	// empty lists
+	merged, err = MergeKubeconfig("apiVersion: v1\nclusters: []\nusers: null\n", oke_kubeconfig)
+	if err != nil || !strings.Contains(merged, "clusters:\n- name: cluster-c4tqmzrg") { t.Errorf("got %q, %v", merged, err) }
+
+	// unsupported or invalid formats
+	if _, err := MergeKubeconfig("clusters: [{name: a}]\n", oke_kubeconfig); err == nil { t.Errorf("expected an error for flow style") }
+	if _, err := MergeKubeconfig(existing, "apiVersion: v1\n"); err == nil { t.Errorf("expected an error for a kubeconfig without clusters") }
+}
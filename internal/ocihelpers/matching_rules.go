@@ -0,0 +1,157 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: matching rules of IAM dynamic groups
+//    ANY {instance.compartment.id = 'ocid1.compartment...', instance.id = 'ocid1.instance...'}
+//    ALL {resource.type = 'fnfunc', resource.compartment.id = 'ocid1.compartment...'}
+//    tag.NAMESPACE.KEY.value = 'VALUE'
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// -- types
+
+// MatchingRule is a parsed matching rule: a condition (attribute = or != value),
+// or a list of rules combined with ANY or ALL
+type MatchingRule struct {
+	Operator  string           // "=", "!=", "ANY" or "ALL"
+	Attribute string           // lower case (ex: instance.compartment.id)
+	Value     string
+	Rules     []*MatchingRule
+}
+
+// tokens of a matching rule
+type rule_parser struct {
+	tokens []string
+	pos    int
+}
+
+// -- functions
+
+// split a matching rule in tokens: { } , = != quoted strings and words
+func tokenize_rule(str string) ([]string, error) {
+	tokens := make([]string, 0)
+	for i := 0; i < len(str); {
+		c := str[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '{' || c == '}' || c == ',' || c == '=':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '!':
+			if i+1 >= len(str) || str[i+1] != '=' { return nil, fmt.Errorf("invalid character '!' at position %d", i) }
+			tokens = append(tokens, "!=")
+			i += 2
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(str[i+1:], c)
+			if end < 0 { return nil, fmt.Errorf("unterminated string at position %d", i) }
+			tokens = append(tokens, str[i:i+end+2])
+			i += end + 2
+		default:
+			j := i
+			for j < len(str) && !unicode.IsSpace(rune(str[j])) && !strings.ContainsRune("{},=!'\"", rune(str[j])) { j++ }
+			tokens = append(tokens, str[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// next token (empty string at the end of the rule)
+func (p *rule_parser) next() string {
+	if p.pos >= len(p.tokens) { return "" }
+	p.pos++
+	return p.tokens[p.pos-1]
+}
+
+// parse a rule: ANY {...}, ALL {...} or a condition
+func (p *rule_parser) parse() (*MatchingRule, error) {
+	token := p.next()
+	if token == "" { return nil, fmt.Errorf("unexpected end of rule") }
+	if keyword := strings.ToUpper(token); keyword == "ANY" || keyword == "ALL" {
+		rule := &MatchingRule{ Operator : keyword }
+		if p.next() != "{" { return nil, fmt.Errorf("'{' expected after %s", keyword) }
+		for {
+			sub_rule, err := p.parse()
+			if err != nil { return nil, err }
+			rule.Rules = append(rule.Rules, sub_rule)
+			switch p.next() {
+			case ",":
+				continue
+			case "}":
+				return rule, nil
+			default:
+				return nil, fmt.Errorf("',' or '}' expected in %s {...}", keyword)
+			}
+		}
+	}
+	if strings.ContainsAny(token, "{},=!'\"") { return nil, fmt.Errorf("attribute expected instead of '%s'", token) }
+	rule := &MatchingRule{ Attribute : strings.ToLower(token), Operator : p.next() }
+	if rule.Operator != "=" && rule.Operator != "!=" { return nil, fmt.Errorf("'=' or '!=' expected after %s", token) }
+	value := p.next()
+	if value == "" || strings.ContainsAny(value[:1], "{},=!") { return nil, fmt.Errorf("value expected after %s %s", token, rule.Operator) }
+	rule.Value = strings.Trim(value, "'\"")
+	return rule, nil
+}
+
+// ParseMatchingRule parses the matching rule of a dynamic group
+func ParseMatchingRule(str string) (*MatchingRule, error) {
+	tokens, err := tokenize_rule(str)
+	if err != nil { return nil, fmt.Errorf("invalid matching rule: %s", err) }
+	p := &rule_parser{ tokens : tokens }
+	rule, err := p.parse()
+	if err != nil { return nil, fmt.Errorf("invalid matching rule: %s", err) }
+	if token := p.next(); token != "" { return nil, fmt.Errorf("invalid matching rule: unexpected '%s' at the end", token) }
+	return rule, nil
+}
+
+// Match returns true if a resource with these attributes (see ResourceAttributes) matches the rule.
+// A condition on an attribute the resource does not have never matches (even with !=)
+func (r *MatchingRule) Match(attributes map[string]string) bool {
+	switch r.Operator {
+	case "ANY":
+		for _, sub_rule := range r.Rules {
+			if sub_rule.Match(attributes) { return true }
+		}
+		return false
+	case "ALL":
+		for _, sub_rule := range r.Rules {
+			if !sub_rule.Match(attributes) { return false }
+		}
+		return true
+	}
+	value, found := attributes[r.Attribute]
+	if !found { return false }
+	equal := value == r.Value
+	if r.Attribute == "resource.type" { equal = strings.EqualFold(value, r.Value) }
+	return equal == (r.Operator == "=")
+}
+
+// ResourceAttributes returns the attributes of a resource used in matching rules:
+// resource.id, resource.type (from the OCID), resource.compartment.id, instance.id and instance.compartment.id
+// for compute instances, and tag.NAMESPACE.KEY.value for defined tags
+func ResourceAttributes(ocid string, compartment_id string, defined_tags map[string]map[string]interface{}) map[string]string {
+	attributes := map[string]string{ "resource.id" : ocid, "resource.compartment.id" : compartment_id }
+	if fields := strings.Split(ocid, "."); len(fields) > 1 { attributes["resource.type"] = fields[1] }
+	if attributes["resource.type"] == "instance" {
+		attributes["instance.id"] = ocid
+		attributes["instance.compartment.id"] = compartment_id
+	}
+	for namespace, tags := range defined_tags {
+		for key, value := range tags {
+			attributes[strings.ToLower("tag." + namespace + "." + key + ".value")] = fmt.Sprintf("%v", value)
+		}
+	}
+	return attributes
+}
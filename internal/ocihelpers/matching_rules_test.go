@@ -0,0 +1,55 @@
+package ocihelpers
+
+import (
+	"testing"
+)
+
+func TestParseMatchingRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		invalid bool
+	}{
+		{ "instance.compartment.id = 'ocid1.compartment.oc1..aaa'",                                   false },
+		{ "ANY {instance.compartment.id = 'ocid1.compartment.oc1..aaa', instance.id='ocid1.instance.oc1.phx.bbb'}", false },
+		{ "All {resource.type = 'fnfunc', resource.compartment.id != \"ocid1.compartment.oc1..aaa\"}", false },
+		{ "ANY {ALL {resource.type='fnfunc', tag.osc.env.value='prod'}, instance.id = 'x'}",           false },
+		{ "",                                                                                         true },
+		{ "ANY instance.id = 'x'",                                                                     true },
+		{ "ANY {instance.id = 'x'",                                                                    true },
+		{ "instance.id 'x'",                                                                           true },
+		{ "instance.id = 'x",                                                                          true },
+		{ "instance.id = 'x' instance.id = 'y'",                                                       true },
+		{ "instance.id ! 'x'",                                                                         true },
+	}
+	for _, tt := range tests {
+		_, err := ParseMatchingRule(tt.rule)
+		if tt.invalid && err == nil { t.Errorf("ParseMatchingRule(%s): expected an error", tt.rule) }
+		if !tt.invalid && err != nil { t.Errorf("ParseMatchingRule(%s): unexpected error: %s", tt.rule, err) }
+	}
+}
+
+func TestMatchingRuleMatch(t *testing.T) {
+	instance := ResourceAttributes("ocid1.instance.oc1.phx.bbb", "ocid1.compartment.oc1..aaa", map[string]map[string]interface{}{ "OSC" : { "Env" : "prod" } })
+	function := ResourceAttributes("ocid1.fnfunc.oc1.phx.ccc", "ocid1.compartment.oc1..aaa", nil)
+	tests := []struct {
+		rule         string
+		instance     bool
+		function     bool
+	}{
+		{ "instance.compartment.id = 'ocid1.compartment.oc1..aaa'",                                   true,  false },
+		{ "instance.compartment.id = 'ocid1.compartment.oc1..zzz'",                                   false, false },
+		{ "instance.compartment.id != 'ocid1.compartment.oc1..zzz'",                                  true,  false },
+		{ "resource.compartment.id = 'ocid1.compartment.oc1..aaa'",                                   true,  true },
+		{ "ALL {resource.type = 'FnFunc', resource.compartment.id = 'ocid1.compartment.oc1..aaa'}",   false, true },
+		{ "ANY {instance.id = 'ocid1.instance.oc1.phx.bbb', resource.type = 'fnfunc'}",               true,  true },
+		{ "tag.osc.env.value = 'prod'",                                                               true,  false },
+		{ "tag.osc.env.value = 'dev'",                                                                false, false },
+		{ "ANY {ALL {resource.type = 'fnfunc', tag.osc.env.value = 'prod'}, instance.id = 'x'}",      false, false },
+	}
+	for _, tt := range tests {
+		rule, err := ParseMatchingRule(tt.rule)
+		if err != nil { t.Errorf("ParseMatchingRule(%s): unexpected error: %s", tt.rule, err); continue }
+		if got := rule.Match(instance); got != tt.instance { t.Errorf("%s: instance match = %v, want %v", tt.rule, got, tt.instance) }
+		if got := rule.Match(function); got != tt.function { t.Errorf("%s: function match = %v, want %v", tt.rule, got, tt.function) }
+	}
+}
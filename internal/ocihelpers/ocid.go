@@ -0,0 +1,74 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: decoding and validation of OCIDs
+// (ocid1.RESOURCE_TYPE.REALM.[REGION][.FUTURE_USE].UNIQUE_ID)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- types
+
+// OCID contains the parts of an Oracle Cloud ID
+type OCID struct {
+	Version      string              // ocid1
+	ResourceType string              // ex: instance, vaultsecret, tenancy
+	Realm        string              // ex: oc1
+	RegionKey    string              // region field of the OCID (ex: fra or eu-frankfurt-1), empty for global resources
+	Region       string              // region name (ex: eu-frankfurt-1), empty for global resources
+	FutureUse    string              // optional field reserved for future use
+	UniqueId     string
+}
+
+// -- global variables
+var ocid_field_regexp  = regexp.MustCompile(`^[a-z0-9]+$`)
+var ocid_region_regexp = regexp.MustCompile(`^[a-z0-9-]*$`)
+var ocid_realm_regexp  = regexp.MustCompile(`^oc[0-9]+$`)
+
+// -- functions
+
+// ParseOCID decodes and validates an OCID
+// ex: ocid1.instance.oc1.eu-frankfurt-1.antheljt... or ocid1.tenancy.oc1..aaaaaaaa... (no region)
+func ParseOCID(ocid string) (OCID, error) {
+	fields := strings.Split(ocid, ".")
+	if len(fields) != 5 && len(fields) != 6 {
+		return OCID{}, fmt.Errorf("invalid OCID %s: %d fields instead of 5 or 6", ocid, len(fields))
+	}
+	o := OCID{ Version : fields[0], ResourceType : fields[1], Realm : fields[2], RegionKey : fields[3], UniqueId : fields[len(fields)-1] }
+	if len(fields) == 6 { o.FutureUse = fields[4] }
+
+	switch {
+	case o.Version != "ocid1":
+		return OCID{}, fmt.Errorf("invalid OCID %s: unknown version %q (ocid1 expected)", ocid, o.Version)
+	case !ocid_field_regexp.MatchString(o.ResourceType):
+		return OCID{}, fmt.Errorf("invalid OCID %s: invalid resource type %q", ocid, o.ResourceType)
+	case !ocid_realm_regexp.MatchString(o.Realm):
+		return OCID{}, fmt.Errorf("invalid OCID %s: invalid realm %q (ex: oc1)", ocid, o.Realm)
+	case !ocid_region_regexp.MatchString(o.RegionKey):
+		return OCID{}, fmt.Errorf("invalid OCID %s: invalid region %q", ocid, o.RegionKey)
+	case o.FutureUse != "" && !ocid_field_regexp.MatchString(o.FutureUse):
+		return OCID{}, fmt.Errorf("invalid OCID %s: invalid future use field %q", ocid, o.FutureUse)
+	case !ocid_field_regexp.MatchString(o.UniqueId):
+		return OCID{}, fmt.Errorf("invalid OCID %s: invalid unique ID %q", ocid, o.UniqueId)
+	}
+	if o.RegionKey != "" { o.Region = string(common.StringToRegion(o.RegionKey)) }
+	return o, nil
+}
+
+// IsOCID returns true if the string is a valid OCID
+func IsOCID(s string) bool {
+	_, err := ParseOCID(s)
+	return err == nil
+}
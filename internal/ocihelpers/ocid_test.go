@@ -0,0 +1,41 @@
+package ocihelpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOCID(t *testing.T) {
+	tests := []struct {
+		ocid          string
+		resource_type string
+		realm         string
+		region        string
+		future_use    string
+		err           string
+	}{
+		{ "ocid1.instance.oc1.eu-frankfurt-1.antheljtxxxx",   "instance",    "oc1", "eu-frankfurt-1", "",     "" },
+		{ "ocid1.vaultsecret.oc1.iad.amaaaaaaxxxx",           "vaultsecret", "oc1", "us-ashburn-1",   "",     "" },
+		{ "ocid1.tenancy.oc1..aaaaaaaaw7e6nkxxxx",            "tenancy",     "oc1", "",               "",     "" },
+		{ "ocid1.bucket.oc2.us-langley-1.fut.aaaaaaaaxxxx",   "bucket",      "oc2", "us-langley-1",   "fut",  "" },
+		{ "ocid2.instance.oc1.fra.xxxx",                      "", "", "", "", "unknown version" },
+		{ "ocid1.instance.oc1.fra",                           "", "", "", "", "4 fields" },
+		{ "ocid1.Instance.oc1.fra.xxxx",                      "", "", "", "", "invalid resource type" },
+		{ "ocid1.instance.xx1.fra.xxxx",                      "", "", "", "", "invalid realm" },
+		{ "ocid1.instance.oc1.fra_1.xxxx",                    "", "", "", "", "invalid region" },
+		{ "ocid1.instance.oc1.fra.",                          "", "", "", "", "invalid unique ID" },
+		{ "ocid1.instance.oc1.fra.xxxx,",                     "", "", "", "", "invalid unique ID" },
+	}
+	for _, tt := range tests {
+		o, err := ParseOCID(tt.ocid)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) { t.Errorf("%s: got error %v, want %q", tt.ocid, err, tt.err) }
+			if IsOCID(tt.ocid) { t.Errorf("%s: IsOCID = true", tt.ocid) }
+			continue
+		}
+		if err != nil { t.Errorf("%s: %s", tt.ocid, err); continue }
+		if o.ResourceType != tt.resource_type || o.Realm != tt.realm || o.Region != tt.region || o.FutureUse != tt.future_use || o.UniqueId == "" {
+			t.Errorf("%s: got %+v", tt.ocid, o)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: output of results (tables in text, JSON or CSV format)
+// and error messages
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Empty values for the missing columns of short rows in JSON format (no panic)
+//    2026-10-15: Add SetPrivateOutputFile (output file with mode 0600)
+//    2026-10-15: No header rows in Markdown format in quiet mode (same as text and CSV formats)
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// -- types
+
+// Table contains results to display in text, JSON, CSV or Markdown format (one row per resource)
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// -- functions
+
+// AddRow adds a row to the table (one value per header)
+func (t *Table) AddRow(values ...string) {
+	t.Rows = append(t.Rows, values)
+}
+
+// Print displays the table in text (aligned columns), JSON (array of objects), CSV or Markdown format on stdout
+func (t *Table) Print(format string) error {
+	return t.Fprint(os.Stdout, format)
+}
+
+// Fprint writes the table in text (aligned columns), JSON (array of objects), CSV or Markdown format to w
+// (in text, CSV and Markdown formats, the header rows are not displayed in quiet mode)
+func (t *Table) Fprint(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		items := make([]map[string]string, 0, len(t.Rows))
+		for _, row := range t.Rows {
+			item := make(map[string]string)
+			for i, h := range t.Headers {
+				item[h] = ""                    // missing values of short rows are empty
+				if i < len(row) { item[h] = row[i] }
+			}
+			items = append(items, item)
+		}
+		output, err := json.MarshalIndent(items, "", "  ")
+		if err != nil { return err }
+		fmt.Fprintln (w, string(output))
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		if !Quiet { cw.Write(t.Headers) }
+		cw.WriteAll(t.Rows)
+		return cw.Error()
+
+	case "markdown":
+		if !Quiet {
+			fmt.Fprintln (w, markdown_row(t.Headers))
+			separators := make([]string, len(t.Headers))
+			for i := range separators { separators[i] = "---" }
+			fmt.Fprintln (w, markdown_row(separators))
+		}
+		for _, row := range t.Rows {
+			fmt.Fprintln (w, markdown_row(row))
+		}
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if !Quiet { fmt.Fprintln (tw, strings.ToUpper(strings.Join(t.Headers, "\t"))) }
+		for _, row := range t.Rows {
+			fmt.Fprintln (tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// format a row of a GitHub-flavored Markdown table (| and line breaks in values are escaped)
+func markdown_row(values []string) string {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		v = strings.Replace(v, "|", "\\|", -1)
+		v = strings.Replace(v, "\r\n", "<br>", -1)
+		cells[i] = strings.Replace(v, "\n", "<br>", -1)
+	}
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+// SetOutputFile redirects stdout to a file (--output-file option) and removes colors from the output,
+// so that reports can be written to files from cron jobs without ANSI escape codes
+func SetOutputFile(path string) error {
+	f, err := os.OpenFile(ExpandPath(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil { return err }
+	os.Stdout = f
+	DisableColors()
+	return nil
+}
+
+// SetPrivateOutputFile is like SetOutputFile for sensitive outputs (ex: content of a secret): the file is only
+// readable and writable by its owner (mode 0600, also if the file already exists)
+func SetPrivateOutputFile(path string) error {
+	f, err := os.OpenFile(ExpandPath(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil { return err }
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	os.Stdout = f
+	DisableColors()
+	return nil
+}
+
+// Fatal displays an error message on stderr and exits with ExitError
+func Fatal(format string, args ...interface{}) {
+	fmt.Fprintf (os.Stderr, "ERROR: "+format+"\n", args...)
+	os.Exit (ExitError)
+}
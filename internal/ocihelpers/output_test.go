@@ -0,0 +1,138 @@
+package ocihelpers
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTableFprint(t *testing.T) {
+	table := Table{ Headers : []string{ "name", "state" } }
+	table.AddRow("Prod", "ACTIVE")
+	table.AddRow("Dev, old", "DELETED")
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{ "text", "NAME      STATE\nProd      ACTIVE\nDev, old  DELETED\n" },
+		{ "csv",  "name,state\nProd,ACTIVE\n\"Dev, old\",DELETED\n" },
+		{ "markdown", "| name | state |\n| --- | --- |\n| Prod | ACTIVE |\n| Dev, old | DELETED |\n" },
+		{ "json", "[\n  {\n    \"name\": \"Prod\",\n    \"state\": \"ACTIVE\"\n  },\n  {\n    \"name\": \"Dev, old\",\n    \"state\": \"DELETED\"\n  }\n]\n" },
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := table.Fprint(&buf, tt.format); err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestTableFprintShortRow(t *testing.T) {
+	table := Table{ Headers : []string{ "name", "state" } }
+	table.AddRow("Prod")
+	var buf bytes.Buffer
+	if err := table.Fprint(&buf, "json"); err != nil { t.Fatalf("unexpected error: %s", err) }
+	want := "[\n  {\n    \"name\": \"Prod\",\n    \"state\": \"\"\n  }\n]\n"
+	if buf.String() != want { t.Errorf("got\n%s\nwant\n%s", buf.String(), want) }
+}
+
+func TestTableFprintQuiet(t *testing.T) {
+	defer func() { Quiet = false }()
+	Quiet = true
+	table := Table{ Headers : []string{ "name", "state" } }
+	table.AddRow("Prod", "ACTIVE")
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{ "text", "Prod  ACTIVE\n" },
+		{ "csv",  "Prod,ACTIVE\n" },
+		{ "markdown", "| Prod | ACTIVE |\n" },
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		table.Fprint(&buf, test.format)
+		if buf.String() != test.want {
+			t.Errorf("%s: got %q, want %q (no header row in quiet mode)", test.format, buf.String(), test.want)
+		}
+	}
+}
+
+func TestTableFprintEmpty(t *testing.T) {
+	table := Table{ Headers : []string{ "name" } }
+	var buf bytes.Buffer
+	table.Fprint(&buf, "json")
+	if buf.String() != "[]\n" {
+		t.Errorf("got %q, want %q", buf.String(), "[]\n")
+	}
+}
+
+func TestMarkdownRow(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{ []string{ "a", "b" },                  "| a | b |" },
+		{ []string{ "a|b", "" },                 "| a\\|b |  |" },
+		{ []string{ "line 1\nline 2" },          "| line 1<br>line 2 |" },
+		{ []string{ "line 1\r\nline 2" },        "| line 1<br>line 2 |" },
+	}
+	for _, tt := range tests {
+		if got := markdown_row(tt.values); got != tt.want {
+			t.Errorf("markdown_row(%q) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestSetOutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocihelpers")
+	if err != nil { t.Fatal(err) }
+	defer os.RemoveAll(dir)
+	saved_stdout, saved_green, saved_normal := os.Stdout, COLOR_GREEN, COLOR_NORMAL
+	defer func() { os.Stdout, COLOR_GREEN, COLOR_NORMAL = saved_stdout, saved_green, saved_normal }()
+
+	path := filepath.Join(dir, "report.txt")
+	if err := SetOutputFile(path); err != nil { t.Fatalf("unexpected error: %s", err) }
+	fmt.Println (COLOR_GREEN+"root"+COLOR_NORMAL)
+	os.Stdout.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil { t.Fatal(err) }
+	if string(data) != "root\n" {
+		t.Errorf("got %q, want %q (without colors)", string(data), "root\n")
+	}
+
+	if err := SetOutputFile(filepath.Join(dir, "missing", "report.txt")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestSetPrivateOutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocihelpers")
+	if err != nil { t.Fatal(err) }
+	defer os.RemoveAll(dir)
+	saved_stdout, saved_green, saved_normal := os.Stdout, COLOR_GREEN, COLOR_NORMAL
+	defer func() { os.Stdout, COLOR_GREEN, COLOR_NORMAL = saved_stdout, saved_green, saved_normal }()
+
+	// existing file readable by everyone: permissions restricted
+	path := filepath.Join(dir, "secret.txt")
+	if err := ioutil.WriteFile(path, []byte("old"), 0644); err != nil { t.Fatal(err) }
+	if err := SetPrivateOutputFile(path); err != nil { t.Fatalf("unexpected error: %s", err) }
+	fmt.Print ("s3cr3t")
+	os.Stdout.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil { t.Fatal(err) }
+	if fi.Mode().Perm() != 0600 { t.Errorf("mode %o, want 600", fi.Mode().Perm()) }
+	data, err := ioutil.ReadFile(path)
+	if err != nil { t.Fatal(err) }
+	if string(data) != "s3cr3t" { t.Errorf("got %q, want %q", string(data), "s3cr3t") }
+}
@@ -0,0 +1,33 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: pagination of list API calls
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- functions
+
+// ListAllPages calls list_page for each page of results, starting with the first page (page = nil),
+// until there is no next page. list_page sets the Page field of its request, sends it, saves the items
+// of the response and returns its OpcNextPage field. Example:
+//
+//	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+//		request.Page = page
+//		response, err := client.ListInstances(context.Background(), request)
+//		if err != nil { return nil, err }
+//		instances = append(instances, response.Items...)
+//		return response.OpcNextPage, nil
+//	})
+func ListAllPages(list_page func(page *string) (*string, error)) error {
+	var page *string
+	for {
+		next_page, err := list_page(page)
+		if err != nil { return err }
+		if next_page == nil { return nil }
+		page = next_page
+	}
+}
@@ -0,0 +1,148 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: progress indicator of the long scans (compartments
+// processed, regions remaining, estimated time remaining), displayed on stderr so that the output is unchanged
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// -- constants
+const progress_interval = 250 * time.Millisecond
+const progress_spinner  = `|/-\`
+
+// -- types
+
+// Progress is the progress indicator of a scan of nb_compartments compartments in nb_regions regions
+// (updated by ForEachRegion and ForEachCompartment when started with StartProgress)
+type Progress struct {
+	label        string
+	writer       io.Writer
+	nb_regions   int
+	nb_jobs      int                 // compartments to process in all regions
+	regions_done int
+	jobs_done    int
+	start        time.Time
+	spinner      int
+	lock         sync.Mutex
+	stop         chan struct{}
+	stopped      chan struct{}
+}
+
+// -- global variables
+
+// progress indicator of the current scan (nil if none)
+var scan_progress *Progress
+
+// -- functions
+
+// StderrIsTerminal returns true if stderr is a terminal (not redirected to a file or a pipe)
+func StderrIsTerminal() bool {
+	fi, err := os.Stderr.Stat()
+	if err != nil { return false }
+	return (fi.Mode() & os.ModeCharDevice) != 0
+}
+
+// NewProgress creates a progress indicator written to w
+func NewProgress(w io.Writer, label string, nb_regions int, nb_compartments int) *Progress {
+	return &Progress{ label : label, writer : w, nb_regions : nb_regions, nb_jobs : nb_regions * nb_compartments, start : time.Now() }
+}
+
+// CompartmentDone counts a compartment processed (nothing if p is nil)
+func (p *Progress) CompartmentDone() {
+	if p == nil { return }
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.jobs_done++
+}
+
+// RegionDone counts a region processed (nothing if p is nil)
+func (p *Progress) RegionDone() {
+	if p == nil { return }
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.regions_done++
+}
+
+// Line returns the text of the progress indicator
+// ex: "| instances: 240/1600 compartments, 1/2 regions done, 15%, ETA 1m5s"
+func (p *Progress) Line() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	line := fmt.Sprintf("%c %s: ", progress_spinner[p.spinner % len(progress_spinner)], p.label)
+	p.spinner++
+
+	// scripts not using ForEachCompartment only count the regions
+	done := 0.0
+	if p.jobs_done > 0 && p.nb_jobs > 0 {
+		line += fmt.Sprintf("%d/%d compartments, ", p.jobs_done, p.nb_jobs)
+		done = float64(p.jobs_done) / float64(p.nb_jobs)
+	}
+	line += fmt.Sprintf("%d/%d regions done", p.regions_done, p.nb_regions)
+	if p.nb_regions > 0 && float64(p.regions_done) / float64(p.nb_regions) > done {
+		done = float64(p.regions_done) / float64(p.nb_regions)
+	}
+
+	// estimated time remaining from the elapsed time (nothing displayed before the first compartment or region)
+	elapsed := time.Since(p.start)
+	if done > 0 && done < 1 {
+		eta := time.Duration(float64(elapsed) * (1 - done) / done)
+		line += fmt.Sprintf(", %d%%, ETA %s", int(done * 100), eta.Round(time.Second))
+	} else {
+		line += fmt.Sprintf(", elapsed %s", elapsed.Round(time.Second))
+	}
+	return line
+}
+
+// Start displays the progress indicator every 250 ms (on the same line) until Stop is called
+func (p *Progress) Start() {
+	p.stop, p.stopped = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(progress_interval)
+		defer ticker.Stop()
+		for {
+			fmt.Fprintf (p.writer, "\r%s\033[K", p.Line())
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				fmt.Fprint (p.writer, "\r\033[K")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops and erases the progress indicator
+func (p *Progress) Stop() {
+	if p == nil || p.stop == nil { return }
+	close(p.stop)
+	<-p.stopped
+	p.stop = nil
+}
+
+// StartProgress starts the progress indicator of a scan on stderr, unless stderr is not a terminal
+// or quiet mode is enabled. Call StopProgress before displaying the results.
+func StartProgress(label string, nb_regions int, nb_compartments int) {
+	if Quiet || !StderrIsTerminal() { return }
+	scan_progress = NewProgress(os.Stderr, label, nb_regions, nb_compartments)
+	scan_progress.Start()
+}
+
+// StopProgress stops and erases the progress indicator started by StartProgress (if any)
+func StopProgress() {
+	scan_progress.Stop()
+	scan_progress = nil
+}
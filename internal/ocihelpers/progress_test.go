@@ -0,0 +1,52 @@
+package ocihelpers
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressLine(t *testing.T) {
+	p := NewProgress(&bytes.Buffer{}, "instances", 2, 10)
+	if line := p.Line(); !regexp.MustCompile(`^\| instances: 0/2 regions done, elapsed \d+s$`).MatchString(line) {
+		t.Errorf("got %q before the first compartment", line)
+	}
+
+	// compartments counted by ForEachCompartment, ETA computed from the elapsed time
+	p.start = time.Now().Add(-10 * time.Second)
+	for i := 0; i < 5; i++ { p.CompartmentDone() }
+	if line, want := p.Line(), "/ instances: 5/20 compartments, 0/2 regions done, 25%, ETA 30s"; line != want {
+		t.Errorf("got %q, want %q", line, want)
+	}
+
+	// only the regions counted (scripts not using ForEachCompartment)
+	p = NewProgress(&bytes.Buffer{}, "volumes", 4, 10)
+	p.start = time.Now().Add(-10 * time.Second)
+	p.RegionDone()
+	if line, want := p.Line(), "| volumes: 1/4 regions done, 25%, ETA 30s"; line != want {
+		t.Errorf("got %q, want %q", line, want)
+	}
+
+	// nil progress indicator (not started): no panic
+	var nil_progress *Progress
+	nil_progress.CompartmentDone()
+	nil_progress.RegionDone()
+	nil_progress.Stop()
+}
+
+func TestProgressStartStop(t *testing.T) {
+	var buffer bytes.Buffer
+	p := NewProgress(&buffer, "buckets", 1, 3)
+	p.Start()
+	// compartments counted by ForEachCompartment when the progress indicator is the one of StartProgress
+	scan_progress = p
+	ForEachCompartment([]string{ "a", "b", "c" }, 2, func(cpt_id string) (interface{}, error) { return nil, nil })
+	StopProgress()
+	if p.jobs_done != 3 { t.Errorf("%d compartments counted, want 3", p.jobs_done) }
+	output := buffer.String()
+	if !strings.HasPrefix(output, "\r| buckets: ") || !strings.HasSuffix(output, "\r\033[K") {
+		t.Errorf("got %q", output)
+	}
+}
@@ -0,0 +1,107 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: statements of compartment quota policies
+//    set compute-core quota standard2-core-count to 10 in compartment Prod:Network
+//    zero compute-core quotas in tenancy where request.region = us-phoenix-1
+//    unset database quota adb-ocpu-count, adw-ocpu-count in compartment id ocid1.compartment...
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- types
+
+// QuotaStatement is a parsed statement of a quota policy
+type QuotaStatement struct {
+	Action        string              // set, unset or zero
+	Family        string              // ex: compute-core
+	Names         []string            // quota names or /pattern/ (empty = all the quotas of the family)
+	Value         string              // value of set statements
+	Compartment   string              // compartment path of the statement (ex: Prod:Network), empty for the tenancy
+	CompartmentId string              // compartment OCID (in compartment id OCID)
+	Condition     string              // where clause (ex: request.region = us-phoenix-1), empty if none
+}
+
+// -- global variables
+var quota_statement_regexp = regexp.MustCompile(`(?i)^(set|unset|zero)\s+([a-z0-9-]+)\s+(quotas?)\b\s*(.*?)\s*\bin\s+(tenancy|compartment\s+id\s+\S+|compartment\s+\S+)(?:\s+where\s+(.+))?$`)
+var quota_value_regexp     = regexp.MustCompile(`(?i)^(.*?)\s*\bto\s+([0-9.]+)$`)
+var quota_region_regexp    = regexp.MustCompile(`(?i)^request\.region\s*(=|!=)\s*'?([a-z0-9-]+)'?$`)
+
+// -- functions
+
+// ParseQuotaStatement parses a statement of a quota policy:
+// set|unset|zero FAMILY quota NAME[, NAME...]|quotas [to VALUE] in tenancy|compartment PATH|compartment id OCID [where CONDITION]
+func ParseQuotaStatement(statement string) (QuotaStatement, error) {
+	m := quota_statement_regexp.FindStringSubmatch(strings.TrimSpace(statement))
+	if m == nil { return QuotaStatement{}, fmt.Errorf("invalid quota statement (set|unset|zero FAMILY quota[s] ... in tenancy|compartment PATH expected): %s", statement) }
+	q := QuotaStatement{ Action : strings.ToLower(m[1]), Family : strings.ToLower(m[2]), Condition : m[6] }
+
+	// value (set statements only)
+	names := m[4]
+	if q.Action == "set" {
+		v := quota_value_regexp.FindStringSubmatch(names)
+		if v == nil { return QuotaStatement{}, fmt.Errorf("invalid quota statement (set without to VALUE): %s", statement) }
+		names, q.Value = v[1], v[2]
+	}
+
+	// quota names (at least one name after quota, none or a pattern after quotas)
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" { q.Names = append(q.Names, name) }
+	}
+	if strings.ToLower(m[3]) == "quota" && len(q.Names) == 0 { return QuotaStatement{}, fmt.Errorf("invalid quota statement (no quota name after quota): %s", statement) }
+
+	// target: tenancy or compartment
+	target := strings.Fields(m[5])
+	switch {
+	case len(target) == 3:
+		q.CompartmentId = target[2]
+	case len(target) == 2:
+		q.Compartment = target[1]
+	}
+	return q, nil
+}
+
+// CompartmentPath returns the target compartment of the statement with the path format of the scripts
+// (root/Prod/Network). The path is empty if the compartment is given by its OCID.
+func (q QuotaStatement) CompartmentPath() string {
+	if q.CompartmentId != "" { return "" }
+	if q.Compartment == "" { return "root" }
+	return "root/" + strings.Replace(q.Compartment, ":", "/", -1)
+}
+
+// MatchesQuota returns true if the statement applies to a quota of a family (empty family or name = any).
+// Quota names between slashes are patterns (* = any characters, ex: /vm-standard*/).
+func (q QuotaStatement) MatchesQuota(family string, name string) bool {
+	if family != "" && !strings.EqualFold(family, q.Family) { return false }
+	if name == "" || len(q.Names) == 0 { return true }
+	for _, n := range q.Names {
+		if len(n) > 2 && strings.HasPrefix(n, "/") && strings.HasSuffix(n, "/") {
+			if matched, _ := path.Match(strings.ToLower(n[1:len(n)-1]), strings.ToLower(name)); matched { return true }
+		} else if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesRegion returns false if the condition of the statement excludes a region
+// (where request.region = REGION or != REGION, with region names or keys). Other conditions are not evaluated.
+func (q QuotaStatement) MatchesRegion(region string) bool {
+	m := quota_region_regexp.FindStringSubmatch(strings.TrimSpace(q.Condition))
+	if m == nil || region == "" { return true }
+	same := common.StringToRegion(strings.ToLower(m[2])) == common.StringToRegion(strings.ToLower(region))
+	return same == (m[1] == "=")
+}
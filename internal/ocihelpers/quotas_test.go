@@ -0,0 +1,65 @@
+package ocihelpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuotaStatement(t *testing.T) {
+	tests := []struct {
+		statement string
+		expected  QuotaStatement
+		path      string
+		invalid   bool
+	}{
+		{ "set compute-core quota standard2-core-count to 10 in compartment Prod:Network",
+		  QuotaStatement{ Action : "set", Family : "compute-core", Names : []string{ "standard2-core-count" }, Value : "10", Compartment : "Prod:Network" }, "root/Prod/Network", false },
+		{ "Zero compute-core quotas in tenancy where request.region = us-phoenix-1",
+		  QuotaStatement{ Action : "zero", Family : "compute-core", Condition : "request.region = us-phoenix-1" }, "root", false },
+		{ "unset database quota adb-ocpu-count, adw-ocpu-count in compartment id ocid1.compartment.oc1..aaa",
+		  QuotaStatement{ Action : "unset", Family : "database", Names : []string{ "adb-ocpu-count", "adw-ocpu-count" }, CompartmentId : "ocid1.compartment.oc1..aaa" }, "", false },
+		{ "set compute-core quota /vm-standard*/ to 0 in compartment Dev",
+		  QuotaStatement{ Action : "set", Family : "compute-core", Names : []string{ "/vm-standard*/" }, Value : "0", Compartment : "Dev" }, "root/Dev", false },
+		{ "set compute-core quota standard2-core-count in compartment Dev",    QuotaStatement{}, "", true },
+		{ "zero compute-core quota in tenancy",                                QuotaStatement{}, "", true },
+		{ "allow group Admins to manage all-resources in tenancy",             QuotaStatement{}, "", true },
+		{ "zero compute-core quotas",                                          QuotaStatement{}, "", true },
+	}
+	for _, tt := range tests {
+		q, err := ParseQuotaStatement(tt.statement)
+		if tt.invalid {
+			if err == nil { t.Errorf("ParseQuotaStatement(%s): expected an error", tt.statement) }
+			continue
+		}
+		if err != nil { t.Errorf("ParseQuotaStatement(%s): unexpected error: %s", tt.statement, err); continue }
+		if !reflect.DeepEqual(q, tt.expected) { t.Errorf("ParseQuotaStatement(%s) = %+v, want %+v", tt.statement, q, tt.expected) }
+		if q.CompartmentPath() != tt.path { t.Errorf("ParseQuotaStatement(%s).CompartmentPath() = %s, want %s", tt.statement, q.CompartmentPath(), tt.path) }
+	}
+}
+
+func TestQuotaStatementMatches(t *testing.T) {
+	tests := []struct {
+		statement string
+		family    string
+		name      string
+		region    string
+		quota     bool
+		region_ok bool
+	}{
+		{ "set compute-core quota standard2-core-count to 10 in tenancy",                       "compute-core", "standard2-core-count", "eu-frankfurt-1", true,  true },
+		{ "set compute-core quota standard2-core-count to 10 in tenancy",                       "compute-core", "standard-e4-core-count", "",           false, true },
+		{ "set compute-core quota standard2-core-count to 10 in tenancy",                       "database",     "",                     "",               false, true },
+		{ "zero compute-core quotas in tenancy where request.region = us-phoenix-1",            "compute-core", "standard2-core-count", "us-phoenix-1",   true,  true },
+		{ "zero compute-core quotas in tenancy where request.region = phx",                     "",             "",                     "eu-frankfurt-1", true,  false },
+		{ "zero compute-core quotas in tenancy where request.region != us-phoenix-1",           "",             "",                     "us-phoenix-1",   true,  false },
+		{ "set compute-core quota /vm-standard*/ to 0 in compartment Dev",                      "compute-core", "vm-standard2-count",   "",               true,  true },
+		{ "set compute-core quota /vm-standard*/ to 0 in compartment Dev",                      "compute-core", "bm-standard2-count",   "",               false, true },
+		{ "zero compute-core quotas in tenancy where request.ad = 'AD-1'",                      "compute-core", "",                     "us-phoenix-1",   true,  true },
+	}
+	for _, tt := range tests {
+		q, err := ParseQuotaStatement(tt.statement)
+		if err != nil { t.Errorf("ParseQuotaStatement(%s): unexpected error: %s", tt.statement, err); continue }
+		if q.MatchesQuota(tt.family, tt.name) != tt.quota { t.Errorf("%s: MatchesQuota(%s, %s) = %v", tt.statement, tt.family, tt.name, !tt.quota) }
+		if q.MatchesRegion(tt.region) != tt.region_ok { t.Errorf("%s: MatchesRegion(%s) = %v", tt.statement, tt.region, !tt.region_ok) }
+	}
+}
@@ -0,0 +1,150 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: execution of API calls in all subscribed regions
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+// Package ocihelpers contains the code shared by the Go scripts of this repository.
+package ocihelpers
+
+// -- import
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- constants
+
+// DefaultRegionParallelism is the default number of regions processed concurrently by ForEachRegion
+const DefaultRegionParallelism = 4
+
+// -- types
+
+// RegionSubscriptionLister is the part of the identity client used to list the subscribed regions
+// (implemented by identity.IdentityClient, and by fake clients in unit tests)
+type RegionSubscriptionLister interface {
+	ListRegionSubscriptions(ctx context.Context, request identity.ListRegionSubscriptionsRequest) (identity.ListRegionSubscriptionsResponse, error)
+}
+
+// AvailabilityDomainLister is the part of the identity client used to list the availability domains of a region
+// (implemented by identity.IdentityClient, and by fake clients in unit tests)
+type AvailabilityDomainLister interface {
+	ListAvailabilityDomains(ctx context.Context, request identity.ListAvailabilityDomainsRequest) (identity.ListAvailabilityDomainsResponse, error)
+}
+
+// RegionResult is the result of a function executed in a region by ForEachRegion
+type RegionResult struct {
+	Region string
+	Value  interface{}
+	Err    error
+}
+
+// -- functions
+
+// ListSubscribedRegions returns the names of the regions subscribed by the tenancy, home region first
+func ListSubscribedRegions(config common.ConfigurationProvider) ([]string, error) {
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	tenancy_ocid, err := config.TenancyOCID()
+	if err != nil { return nil, err }
+	return list_subscribed_regions(client, tenancy_ocid)
+}
+
+// list the READY regions subscribed by the tenancy, home region first
+func list_subscribed_regions(client RegionSubscriptionLister, tenancy_ocid string) ([]string, error) {
+	request := identity.ListRegionSubscriptionsRequest{
+		TenancyId       : common.String(tenancy_ocid),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : RetryPolicy() },
+	}
+	response, err := client.ListRegionSubscriptions(context.Background(), request)
+	if err != nil { return nil, err }
+
+	regions := make([]string, 0, len(response.Items))
+	for _, r := range response.Items {
+		if r.Status != identity.RegionSubscriptionStatusReady { continue }
+		if *r.IsHomeRegion {
+			regions = append([]string{ *r.RegionName }, regions...)
+		} else {
+			regions = append(regions, *r.RegionName)
+		}
+	}
+	return regions, nil
+}
+
+// GetHomeRegion returns the name of the home region of the tenancy (IAM write operations must be sent to it)
+func GetHomeRegion(config common.ConfigurationProvider) (string, error) {
+	regions, err := ListSubscribedRegions(config)
+	if err != nil { return "", err }
+	if len(regions) == 0 { return "", fmt.Errorf("cannot find home region of tenant") }
+	return regions[0], nil
+}
+
+// GetRegions returns the regions to process: all the subscribed regions (home region first) if all_regions is
+// true (-a or --all-regions option), or only the region of the profile
+func GetRegions(config common.ConfigurationProvider, all_regions bool) ([]string, error) {
+	if all_regions { return ListSubscribedRegions(config) }
+	region, err := config.Region()
+	if err != nil { return nil, err }
+	return []string{ region }, nil
+}
+
+// ListAvailabilityDomains returns the names of the availability domains of a region (region of the profile if
+// region is empty), for the API calls needing an availability domain (ex: boot volumes)
+func ListAvailabilityDomains(config common.ConfigurationProvider, region string) ([]string, error) {
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	if region != "" { client.SetRegion(region) }
+	tenancy_ocid, err := config.TenancyOCID()
+	if err != nil { return nil, err }
+	return list_availability_domains(client, tenancy_ocid)
+}
+
+// list the names of the availability domains, sorted by name (AD-1, AD-2, AD-3)
+func list_availability_domains(client AvailabilityDomainLister, tenancy_ocid string) ([]string, error) {
+	request := identity.ListAvailabilityDomainsRequest{
+		CompartmentId   : common.String(tenancy_ocid),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : RetryPolicy() },
+	}
+	response, err := client.ListAvailabilityDomains(context.Background(), request)
+	if err != nil { return nil, err }
+
+	ads := make([]string, 0, len(response.Items))
+	for _, ad := range response.Items { ads = append(ads, *ad.Name) }
+	sort.Strings(ads)
+	return ads, nil
+}
+
+// ForEachRegion executes fn in each region, with at most parallelism regions processed at the same time.
+// The results are returned in the same order as the regions. An error in a region does not stop the
+// processing of the other regions: it is returned in the Err field of the result for this region.
+// fn is responsible for creating the SDK clients it needs and calling SetRegion(region) on them.
+// The regions processed are counted by the progress indicator (see StartProgress).
+func ForEachRegion(regions []string, parallelism int, fn func(region string) (interface{}, error)) []RegionResult {
+	if parallelism < 1 { parallelism = DefaultRegionParallelism }
+	progress := scan_progress
+	jobs := RunParallel(len(regions), parallelism, func(i int) (interface{}, error) {
+		defer progress.RegionDone()
+		return fn(regions[i])
+	})
+	results := make([]RegionResult, len(regions))
+	for i, r := range jobs {
+		results[i] = RegionResult{ Region : regions[i], Value : r.Value, Err : r.Err }
+	}
+	return results
+}
+
+// FailedRegions returns the results of the regions where an error occurred
+func FailedRegions(results []RegionResult) []RegionResult {
+	failed := make([]RegionResult, 0)
+	for _, r := range results {
+		if r.Err != nil { failed = append(failed, r) }
+	}
+	return failed
+}
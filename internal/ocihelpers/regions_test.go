@@ -0,0 +1,115 @@
+package ocihelpers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// fake identity client returning a fixed list of region subscriptions
+type fake_region_subscription_lister struct {
+	subscriptions []identity.RegionSubscription
+	err           error
+}
+
+func (f fake_region_subscription_lister) ListRegionSubscriptions(ctx context.Context, request identity.ListRegionSubscriptionsRequest) (identity.ListRegionSubscriptionsResponse, error) {
+	return identity.ListRegionSubscriptionsResponse{ Items : f.subscriptions }, f.err
+}
+
+func new_subscription(name string, home bool, status identity.RegionSubscriptionStatusEnum) identity.RegionSubscription {
+	return identity.RegionSubscription{ RegionName : common.String(name), IsHomeRegion : common.Bool(home), Status : status }
+}
+
+func TestListSubscribedRegions(t *testing.T) {
+	ready       := identity.RegionSubscriptionStatusReady
+	in_progress := identity.RegionSubscriptionStatusInProgress
+	tests := []struct {
+		name          string
+		subscriptions []identity.RegionSubscription
+		want          []string
+	}{
+		{ "home region first", []identity.RegionSubscription{
+			new_subscription("us-ashburn-1", false, ready),
+			new_subscription("eu-frankfurt-1", true, ready),
+			new_subscription("uk-london-1", false, ready),
+		}, []string{ "eu-frankfurt-1", "us-ashburn-1", "uk-london-1" } },
+		{ "regions not ready are ignored", []identity.RegionSubscription{
+			new_subscription("eu-frankfurt-1", true, ready),
+			new_subscription("eu-paris-1", false, in_progress),
+		}, []string{ "eu-frankfurt-1" } },
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := list_subscribed_regions(fake_region_subscription_lister{ subscriptions : tt.subscriptions }, test_tenancy)
+			if err != nil { t.Fatalf("unexpected error: %s", err) }
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := list_subscribed_regions(fake_region_subscription_lister{ err : errors.New("NotAuthorized") }, test_tenancy); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestForEachRegion(t *testing.T) {
+	regions := []string{ "eu-frankfurt-1", "us-ashburn-1", "uk-london-1", "ap-tokyo-1", "eu-paris-1" }
+	var running, max_running int32
+	results := ForEachRegion(regions, 2, func(region string) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max_running)
+			if n <= m || atomic.CompareAndSwapInt32(&max_running, m, n) { break }
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		if region == "uk-london-1" { return nil, errors.New("failed") }
+		return len(region), nil
+	})
+
+	if max_running > 2 {
+		t.Errorf("%d regions processed at the same time, want at most 2", max_running)
+	}
+	if len(results) != len(regions) { t.Fatalf("got %d results, want %d", len(results), len(regions)) }
+	for i, r := range results {
+		if r.Region != regions[i] { t.Errorf("result %d is for region %s, want %s", i, r.Region, regions[i]) }
+		if r.Region != "uk-london-1" && r.Value != len(r.Region) { t.Errorf("%s: got value %v", r.Region, r.Value) }
+	}
+	failed := FailedRegions(results)
+	if len(failed) != 1 || failed[0].Region != "uk-london-1" {
+		t.Errorf("got failed regions %v, want uk-london-1 only", failed)
+	}
+}
+
+// fake identity client returning a fixed list of availability domains
+type fake_availability_domain_lister struct {
+	ads []string
+	err error
+}
+
+func (f fake_availability_domain_lister) ListAvailabilityDomains(ctx context.Context, request identity.ListAvailabilityDomainsRequest) (identity.ListAvailabilityDomainsResponse, error) {
+	items := make([]identity.AvailabilityDomain, 0, len(f.ads))
+	for _, ad := range f.ads { items = append(items, identity.AvailabilityDomain{ Name : common.String(ad) }) }
+	return identity.ListAvailabilityDomainsResponse{ Items : items }, f.err
+}
+
+func TestListAvailabilityDomains(t *testing.T) {
+	client := fake_availability_domain_lister{ ads : []string{ "Xyz:EU-FRANKFURT-1-AD-3", "Xyz:EU-FRANKFURT-1-AD-1", "Xyz:EU-FRANKFURT-1-AD-2" } }
+	got, err := list_availability_domains(client, test_tenancy)
+	if err != nil { t.Fatalf("unexpected error: %s", err) }
+	want := []string{ "Xyz:EU-FRANKFURT-1-AD-1", "Xyz:EU-FRANKFURT-1-AD-2", "Xyz:EU-FRANKFURT-1-AD-3" }
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := list_availability_domains(fake_availability_domain_lister{ err : errors.New("NotAuthorized") }, test_tenancy); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,104 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: signed requests to the OCI REST APIs of services
+// not available in the version of the OCI Go SDK used by the scripts (network load balancers, bastions...)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- types
+
+// RestClient sends requests signed with the credentials of a configuration provider to the REST API
+// of an OCI service in a region
+type RestClient struct {
+	client common.BaseClient
+}
+
+// HTTP response of a REST request (used to apply the retry policy)
+type rest_response struct {
+	response *http.Response
+}
+
+func (r rest_response) HTTPResponse() *http.Response { return r.response }
+
+// -- functions
+
+// NewRestClient returns a client for the REST API of an OCI service in a region. endpoint_template is the
+// endpoint of the service given in the API documentation, where {region} and {secondLevelDomain} are replaced
+// by the region and the domain of its realm. Example:
+//
+//	client, err := ocihelpers.NewRestClient(config, "https://network-load-balancer-api.{region}.oci.{secondLevelDomain}", "20200501", region)
+func NewRestClient(config common.ConfigurationProvider, endpoint_template string, api_version string, region string) (*RestClient, error) {
+	client, err := common.NewClientWithConfig(config)
+	if err != nil { return nil, err }
+	client.Host = common.StringToRegion(region).EndpointForTemplate("", endpoint_template)
+	client.BasePath = api_version
+	return &RestClient{ client : client }, nil
+}
+
+// Get sends a GET request to path (relative to the API version) with the query parameters and decodes the
+// JSON response in result. It returns the opc-next-page header (nil if there is no next page), so that it
+// can be used with ListAllPages by setting the "page" query parameter. Throttled requests are retried
+// like the requests sent by the SDK clients (see RetryPolicy).
+func (c *RestClient) Get(path string, query url.Values, result interface{}) (*string, error) {
+	header, err := c.send(http.MethodGet, path, query, nil, result)
+	if err != nil { return nil, err }
+	if next_page := header.Get("opc-next-page"); next_page != "" { return &next_page, nil }
+	return nil, nil
+}
+
+// Post sends a POST request to path (relative to the API version) with body encoded in JSON, and decodes
+// the JSON response in result (result can be nil if the response has no body). Throttled requests are retried.
+func (c *RestClient) Post(path string, body interface{}, result interface{}) error {
+	content, err := json.Marshal(body)
+	if err != nil { return err }
+	_, err = c.send(http.MethodPost, path, nil, content, result)
+	return err
+}
+
+// send a request (retried with the retry policy) and decode the JSON response in result if not nil
+func (c *RestClient) send(method string, path string, query url.Values, content []byte, result interface{}) (http.Header, error) {
+	policy := RetryPolicy()
+	for attempt := uint(1); ; attempt++ {
+		var body io.Reader
+		if content != nil { body = bytes.NewReader(content) }
+		request, err := http.NewRequest(method, path, body)
+		if err != nil { return nil, err }
+		request.URL.RawQuery = query.Encode()
+		request.Header.Set("Accept", "application/json")
+		if content != nil { request.Header.Set("Content-Type", "application/json") }
+		response, err := c.client.Call(context.Background(), request)
+		operation := common.NewOCIOperationResponse(rest_response{ response }, err, attempt)
+		if err != nil && attempt < policy.MaximumNumberAttempts && policy.ShouldRetryOperation(operation) {
+			common.CloseBodyIfValid(response)
+			time.Sleep(policy.NextDuration(operation))
+			continue
+		}
+		if err != nil {
+			common.CloseBodyIfValid(response)
+			return nil, err
+		}
+		defer response.Body.Close()
+		if result != nil && response.StatusCode != http.StatusNoContent {
+			if err := json.NewDecoder(response.Body).Decode(result); err != nil { return nil, err }
+		}
+		return response.Header, nil
+	}
+}
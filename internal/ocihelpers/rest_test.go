@@ -0,0 +1,98 @@
+package ocihelpers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+const nlb_endpoint = "https://network-load-balancer-api.{region}.oci.{secondLevelDomain}"
+
+// configuration provider with a generated private key (requests are signed but not verified by the test server)
+func test_config_provider(t *testing.T) common.ConfigurationProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil { t.Fatal(err) }
+	key_pem := pem.EncodeToMemory(&pem.Block{ Type : "RSA PRIVATE KEY", Bytes : x509.MarshalPKCS1PrivateKey(key) })
+	return common.NewRawConfigurationProvider("ocid1.tenancy.oc1..test", "ocid1.user.oc1..test", "eu-frankfurt-1", "11:22:33", string(key_pem), nil)
+}
+
+func TestNewRestClient(t *testing.T) {
+	client, err := NewRestClient(test_config_provider(t), nlb_endpoint, "20200501", "us-ashburn-1")
+	if err != nil { t.Fatal(err) }
+	if want := "https://network-load-balancer-api.us-ashburn-1.oci.oraclecloud.com"; client.client.Host != want {
+		t.Errorf("host = %q, want %q", client.client.Host, want)
+	}
+	if client.client.BasePath != "20200501" { t.Errorf("base path = %q, want 20200501", client.client.BasePath) }
+}
+
+func TestRestClientGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" { t.Errorf("request not signed") }
+		switch {
+		case r.URL.Path != "/20200501/networkLoadBalancers":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"NotAuthorizedOrNotFound","message":"not found"}`))
+		case r.URL.Query().Get("page") == "":
+			w.Header().Set("opc-next-page", "page2")
+			w.Write([]byte(`{"items":[{"displayName":"nlb1"}]}`))
+		default:
+			w.Write([]byte(`{"items":[{"displayName":"nlb2"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient(test_config_provider(t), nlb_endpoint, "20200501", "us-ashburn-1")
+	if err != nil { t.Fatal(err) }
+	client.client.Host = server.URL
+
+	type collection struct {
+		Items []struct { DisplayName string `json:"displayName"` } `json:"items"`
+	}
+	names := make([]string, 0)
+	query := url.Values{ "compartmentId" : { "ocid1.compartment.oc1..test" } }
+	err = ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var result collection
+		next_page, err := client.Get("/networkLoadBalancers", query, &result)
+		if err != nil { return nil, err }
+		for _, item := range result.Items { names = append(names, item.DisplayName) }
+		return next_page, nil
+	})
+	if err != nil { t.Fatal(err) }
+	if len(names) != 2 || names[0] != "nlb1" || names[1] != "nlb2" { t.Errorf("names = %v, want [nlb1 nlb2]", names) }
+
+	// errors are returned as service errors
+	var result collection
+	_, err = client.Get("/unknown", nil, &result)
+	if failure, ok := common.IsServiceError(err); !ok || failure.GetHTTPStatusCode() != http.StatusNotFound {
+		t.Errorf("got error %v, want a 404 service error", err)
+	}
+}
+
+func TestRestClientPost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/20160918/tags/actions/bulkEdit" { t.Errorf("unexpected request %s %s", r.Method, r.URL) }
+		if r.Header.Get("Content-Type") != "application/json" { t.Errorf("content type = %q, want application/json", r.Header.Get("Content-Type")) }
+		if r.Header.Get("x-content-sha256") == "" { t.Errorf("request body not signed") }
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["compartmentId"] != "ocid1.compartment.oc1..test" {
+			t.Errorf("body = %v (error %v)", body, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient(test_config_provider(t), "https://identity.{region}.{secondLevelDomain}", "20160918", "us-ashburn-1")
+	if err != nil { t.Fatal(err) }
+	client.client.Host = server.URL
+	var result struct{}
+	if err := client.Post("/tags/actions/bulkEdit", map[string]string{ "compartmentId" : "ocid1.compartment.oc1..test" }, &result); err != nil { t.Fatal(err) }
+}
@@ -0,0 +1,89 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: retry of requests throttled by OCI (HTTP 429 TooManyRequests)
+// with exponential backoff, honoring the retry-after header when it is returned
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- constants
+
+// RetryAttempts is the maximum number of attempts of a throttled request
+const RetryAttempts = 8
+
+const retry_base_delay = 1 * time.Second      // delay before the first retry (doubled for each retry)
+const retry_max_delay  = 60 * time.Second     // maximum delay between 2 attempts
+
+// -- functions
+
+// RetryPolicy returns the retry policy to use in the RequestMetadata of all requests sent by the scripts:
+// throttled requests (HTTP 429) are retried with an exponential backoff, other errors are returned immediately.
+// Example:
+//
+//	request := identity.ListUsersRequest{
+//		CompartmentId   : common.String(tenancy_ocid),
+//		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+//	}
+func RetryPolicy() *common.RetryPolicy {
+	policy := common.NewRetryPolicy(RetryAttempts, is_throttled, next_retry_delay)
+	return &policy
+}
+
+// get the HTTP response of an operation (nil if no response was received)
+func http_response(r common.OCIOperationResponse) *http.Response {
+	if r.Response == nil { return nil }
+	return r.Response.HTTPResponse()
+}
+
+// returns true if the request was throttled (HTTP 429 TooManyRequests)
+func is_throttled(r common.OCIOperationResponse) bool {
+	if r.Error == nil { return false }
+	if resp := http_response(r); resp != nil {
+		return resp.StatusCode == http.StatusTooManyRequests
+	}
+	if failure, ok := common.IsServiceError(r.Error); ok {
+		return failure.GetHTTPStatusCode() == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// get the delay before the next attempt: retry-after header (in seconds or HTTP date) if returned by the service,
+// else exponential backoff (1s, 2s, 4s, ... up to 60s) with a random jitter of up to 25%
+func next_retry_delay(r common.OCIOperationResponse) time.Duration {
+	if resp := http_response(r); resp != nil {
+		if retry_after := resp.Header.Get("retry-after"); retry_after != "" {
+			if seconds, err := strconv.Atoi(retry_after); err == nil && seconds >= 0 {
+				return cap_delay(time.Duration(seconds) * time.Second)
+			}
+			if date, err := http.ParseTime(retry_after); err == nil {
+				return cap_delay(time.Until(date))
+			}
+		}
+	}
+	delay := retry_max_delay
+	if r.AttemptNumber >= 1 && r.AttemptNumber <= 6 {
+		delay = retry_base_delay << (r.AttemptNumber - 1)
+	}
+	return cap_delay(delay + time.Duration(rand.Int63n(int64(delay)/4+1)))
+}
+
+// limit a delay to the range 0 .. retry_max_delay
+func cap_delay(delay time.Duration) time.Duration {
+	if delay < 0 { return 0 }
+	if delay > retry_max_delay { return retry_max_delay }
+	return delay
+}
@@ -0,0 +1,84 @@
+package ocihelpers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// fake SDK response containing only an HTTP response
+type fake_response struct {
+	raw *http.Response
+}
+
+func (r fake_response) HTTPResponse() *http.Response {
+	return r.raw
+}
+
+func new_operation_response(status int, retry_after string, attempt uint) common.OCIOperationResponse {
+	raw := &http.Response{ StatusCode : status, Header : http.Header{} }
+	if retry_after != "" { raw.Header.Set("retry-after", retry_after) }
+	var err error
+	if status >= 400 { err = errors.New(http.StatusText(status)) }
+	return common.OCIOperationResponse{ Response : fake_response{ raw }, Error : err, AttemptNumber : attempt }
+}
+
+func TestIsThrottled(t *testing.T) {
+	tests := []struct {
+		name     string
+		response common.OCIOperationResponse
+		want     bool
+	}{
+		{ "success",          new_operation_response(200, "", 1), false },
+		{ "too many requests", new_operation_response(429, "", 1), true },
+		{ "not found",        new_operation_response(404, "", 1), false },
+		{ "server error",     new_operation_response(500, "", 1), false },
+		{ "no response",      common.OCIOperationResponse{ Error : errors.New("timeout"), AttemptNumber : 1 }, false },
+	}
+	for _, tt := range tests {
+		if got := is_throttled(tt.response); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNextRetryDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		retry_after string
+		attempt     uint
+		min         time.Duration
+		max         time.Duration
+	}{
+		{ "first retry",              "",    1,  1 * time.Second,  1250 * time.Millisecond },
+		{ "third retry",              "",    3,  4 * time.Second,  5 * time.Second },
+		{ "capped",                   "",    7,  retry_max_delay,  retry_max_delay },
+		{ "retry-after in seconds",   "5",   1,  5 * time.Second,  5 * time.Second },
+		{ "retry-after too long",     "600", 1,  retry_max_delay,  retry_max_delay },
+		{ "invalid retry-after",      "abc", 2,  2 * time.Second,  2500 * time.Millisecond },
+	}
+	for _, tt := range tests {
+		got := next_retry_delay(new_operation_response(429, tt.retry_after, tt.attempt))
+		if got < tt.min || got > tt.max {
+			t.Errorf("%s: got %s, want between %s and %s", tt.name, got, tt.min, tt.max)
+		}
+	}
+
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if got := next_retry_delay(new_operation_response(429, date, 1)); got < 8 * time.Second || got > 10 * time.Second {
+		t.Errorf("retry-after as HTTP date: got %s, want about 10s", got)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy()
+	if policy.MaximumNumberAttempts != RetryAttempts {
+		t.Errorf("got %d attempts, want %d", policy.MaximumNumberAttempts, RetryAttempts)
+	}
+	if !policy.ShouldRetryOperation(new_operation_response(429, "", 1)) {
+		t.Error("throttled requests must be retried")
+	}
+}
@@ -0,0 +1,104 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: selection of resources by tag (--tag option)
+//    NAMESPACE.KEY=VALUE : defined tag
+//    KEY=VALUE           : freeform tag
+//    NAMESPACE.KEY or KEY: the tag is set, whatever its value
+// and bulk edition of the defined tags of resources (not available in the OCI Go SDK version used)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- constants
+
+// maximum number of resources in a bulk edit request
+const BulkEditMaxResources = 100
+
+// -- types
+
+// TagFilter selects resources by defined tag (Namespace is set) or by freeform tag (Namespace is empty)
+type TagFilter struct {
+	Namespace string
+	Key       string
+	Value     string
+	AnyValue  bool
+}
+
+// BulkEditResource is a resource whose tags are edited by BulkAddDefinedTags
+type BulkEditResource struct {
+	Id           string            `json:"id"`
+	ResourceType string            `json:"resourceType"`        // resource type returned by Resource Search
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// -- functions
+
+// ParseTagFilter parses the value of a --tag option (NAMESPACE.KEY=VALUE, KEY=VALUE, NAMESPACE.KEY or KEY)
+func ParseTagFilter(str string) (TagFilter, error) {
+	filter := TagFilter{ AnyValue : true }
+	name := str
+	if i := strings.Index(str, "="); i >= 0 {
+		name, filter.Value, filter.AnyValue = str[:i], str[i+1:], false
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		filter.Namespace, name = name[:i], name[i+1:]
+		if filter.Namespace == "" { return filter, fmt.Errorf("invalid tag '%s': empty namespace", str) }
+	}
+	if name == "" { return filter, fmt.Errorf("invalid tag '%s': empty key", str) }
+	filter.Key = name
+	return filter, nil
+}
+
+// Match returns true if the freeform tags or defined tags of a resource match the filter
+func (f TagFilter) Match(freeform_tags map[string]string, defined_tags map[string]map[string]interface{}) bool {
+	var value string
+	var found bool
+	if f.Namespace == "" {
+		value, found = freeform_tags[f.Key]
+	} else if v, ok := defined_tags[f.Namespace][f.Key]; ok {
+		value, found = fmt.Sprintf("%v", v), true
+	}
+	return found && (f.AnyValue || value == f.Value)
+}
+
+// String returns the filter in the format of the --tag option
+func (f TagFilter) String() string {
+	str := f.Key
+	if f.Namespace != "" { str = f.Namespace + "." + str }
+	if !f.AnyValue { str += "=" + f.Value }
+	return str
+}
+
+// BulkAddDefinedTags adds the defined tags to the resources of a compartment (at most BulkEditMaxResources)
+// where they are not already set (existing values are kept). The tags are added asynchronously by a work
+// request of the identity service, sent to the home region.
+func BulkAddDefinedTags(config common.ConfigurationProvider, compartment_id string, resources []BulkEditResource, defined_tags map[string]map[string]interface{}) error {
+	region, err := GetHomeRegion(config)
+	if err != nil { return err }
+	client, err := NewRestClient(config, identity_endpoint, identity_api_version, region)
+	if err != nil { return err }
+	return bulk_add_defined_tags(client, compartment_id, resources, defined_tags)
+}
+
+// send a bulk edit request with an ADD_WHERE_ABSENT operation
+func bulk_add_defined_tags(client *RestClient, compartment_id string, resources []BulkEditResource, defined_tags map[string]map[string]interface{}) error {
+	if len(resources) > BulkEditMaxResources { return fmt.Errorf("too many resources in bulk edit request (%d, maximum %d)", len(resources), BulkEditMaxResources) }
+	body := map[string]interface{}{
+		"compartmentId"      : compartment_id,
+		"resources"          : resources,
+		"bulkEditOperations" : []map[string]interface{}{ { "operationType" : "ADD_WHERE_ABSENT", "definedTags" : defined_tags } },
+	}
+	return client.Post("/tags/actions/bulkEdit", body, nil)
+}
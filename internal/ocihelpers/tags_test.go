@@ -0,0 +1,98 @@
+package ocihelpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTagFilter(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    TagFilter
+		invalid bool
+	}{
+		{ "osc.env=prod",   TagFilter{ Namespace : "osc", Key : "env", Value : "prod" }, false },
+		{ "env=prod",       TagFilter{ Key : "env", Value : "prod" },                    false },
+		{ "osc.env",        TagFilter{ Namespace : "osc", Key : "env", AnyValue : true }, false },
+		{ "env",            TagFilter{ Key : "env", AnyValue : true },                   false },
+		{ "osc.env=",       TagFilter{ Namespace : "osc", Key : "env" },                 false },
+		{ "env=a=b",        TagFilter{ Key : "env", Value : "a=b" },                     false },
+		{ ".env=prod",      TagFilter{},                                                 true },
+		{ "=prod",          TagFilter{},                                                 true },
+		{ "osc.=prod",      TagFilter{},                                                 true },
+	}
+	for _, tt := range tests {
+		got, err := ParseTagFilter(tt.str)
+		if tt.invalid {
+			if err == nil { t.Errorf("ParseTagFilter(%s): expected an error", tt.str) }
+			continue
+		}
+		if err != nil { t.Errorf("ParseTagFilter(%s): unexpected error: %s", tt.str, err); continue }
+		if got != tt.want { t.Errorf("ParseTagFilter(%s) = %+v, want %+v", tt.str, got, tt.want) }
+		if got.String() != tt.str { t.Errorf("String() = %s, want %s", got.String(), tt.str) }
+	}
+}
+
+func TestTagFilterMatch(t *testing.T) {
+	freeform := map[string]string{ "env" : "prod" }
+	defined  := map[string]map[string]interface{}{ "osc" : { "automatic_shutdown" : "19:00_UTC", "cost" : 12 } }
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{ "env=prod",                         true },
+		{ "env=dev",                          false },
+		{ "env",                              true },
+		{ "owner",                            false },
+		{ "osc.automatic_shutdown=19:00_UTC", true },
+		{ "osc.automatic_shutdown=20:00_UTC", false },
+		{ "osc.automatic_shutdown",           true },
+		{ "osc.cost=12",                      true },
+		{ "osc.env=prod",                     false },     // freeform tag, not defined tag
+		{ "other.automatic_shutdown",         false },
+	}
+	for _, tt := range tests {
+		filter, _ := ParseTagFilter(tt.filter)
+		if got := filter.Match(freeform, defined); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.filter, got, tt.want)
+		}
+		if filter.Match(nil, nil) {
+			t.Errorf("%s: Match() must be false for resources without tags", tt.filter)
+		}
+	}
+}
+
+func TestBulkAddDefinedTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/tags/actions/bulkEdit" { t.Errorf("unexpected request %s", r.URL) }
+		var body struct {
+			CompartmentId      string             `json:"compartmentId"`
+			Resources          []BulkEditResource `json:"resources"`
+			BulkEditOperations []struct {
+				OperationType string                       `json:"operationType"`
+				DefinedTags   map[string]map[string]string `json:"definedTags"`
+			} `json:"bulkEditOperations"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil { t.Fatal(err) }
+		if body.CompartmentId != "ocid1.compartment.oc1..a" || len(body.Resources) != 1 || body.Resources[0].ResourceType != "Instance" {
+			t.Errorf("body = %+v", body)
+		}
+		if len(body.BulkEditOperations) != 1 || body.BulkEditOperations[0].OperationType != "ADD_WHERE_ABSENT" || body.BulkEditOperations[0].DefinedTags["osc"]["owner"] != "unknown" {
+			t.Errorf("operations = %+v", body.BulkEditOperations)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient(test_config_provider(t), identity_endpoint, identity_api_version, "eu-frankfurt-1")
+	if err != nil { t.Fatal(err) }
+	client.client.Host = server.URL
+	resources := []BulkEditResource{ { Id : "ocid1.instance.oc1..i", ResourceType : "Instance" } }
+	tags := map[string]map[string]interface{}{ "osc" : { "owner" : "unknown" } }
+	if err := bulk_add_defined_tags(client, "ocid1.compartment.oc1..a", resources, tags); err != nil { t.Fatal(err) }
+
+	// requests with too many resources are rejected before being sent
+	if err := bulk_add_defined_tags(client, "ocid1.compartment.oc1..a", make([]BulkEditResource, BulkEditMaxResources+1), tags); err == nil { t.Errorf("expected an error") }
+}
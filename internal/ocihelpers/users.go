@@ -0,0 +1,73 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: list of the IAM users of a tenant, with their last
+// successful login (not available in the OCI Go SDK version used, so the identity REST API is called directly)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- constants
+const identity_endpoint    = "https://identity.{region}.{secondLevelDomain}"
+const identity_api_version = "20160918"
+
+// -- types
+
+// User is an IAM user of a tenant (fields of the identity API used by the scripts)
+type User struct {
+	Id                      string     `json:"id"`
+	Name                    string     `json:"name"`
+	Description             string     `json:"description"`
+	Email                   string     `json:"email"`
+	IsMfaActivated          bool       `json:"isMfaActivated"`
+	LifecycleState          string     `json:"lifecycleState"`
+	IdentityProviderId      string     `json:"identityProviderId"`     // empty for local users
+	TimeCreated             time.Time  `json:"timeCreated"`
+	LastSuccessfulLoginTime *time.Time `json:"lastSuccessfulLoginTime"` // nil if the user never logged in
+}
+
+// -- functions
+
+// ListUsers returns the users of the tenant of a configuration provider (sorted by name)
+func ListUsers(config common.ConfigurationProvider) ([]User, error) {
+	tenancy_ocid, err := config.TenancyOCID()
+	if err != nil { return nil, err }
+	region, err := config.Region()
+	if err != nil { return nil, err }
+	client, err := NewRestClient(config, identity_endpoint, identity_api_version, region)
+	if err != nil { return nil, err }
+	return list_users(client, tenancy_ocid)
+}
+
+// list the users of a tenant (all pages of results)
+func list_users(client *RestClient, tenancy_ocid string) ([]User, error) {
+	users := make([]User, 0)
+	query := url.Values{ "compartmentId" : { tenancy_ocid } }
+	err := ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []User
+		next_page, err := client.Get("/users", query, &items)
+		if err != nil { return nil, err }
+		users = append(users, items...)
+		return next_page, nil
+	})
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, err
+}
+
+// DaysSince returns the number of full days since a time
+func DaysSince(t time.Time) int {
+	return int(time.Since(t).Hours() / 24)
+}
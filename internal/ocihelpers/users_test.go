@@ -0,0 +1,45 @@
+package ocihelpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/20160918/users" || r.URL.Query().Get("compartmentId") != "ocid1.tenancy.oc1..test" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("opc-next-page", "page2")
+			w.Write([]byte(`[{"id":"ocid1.user.oc1..bob","name":"bob","isMfaActivated":true,"timeCreated":"2026-01-02T03:04:05.678Z","lastSuccessfulLoginTime":"2026-10-01T08:00:00.000Z"}]`))
+			return
+		}
+		w.Write([]byte(`[{"id":"ocid1.user.oc1..alice","name":"alice","email":"alice@example.com","timeCreated":"2026-03-04T05:06:07Z","lastSuccessfulLoginTime":null}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient(test_config_provider(t), identity_endpoint, identity_api_version, "eu-frankfurt-1")
+	if err != nil { t.Fatal(err) }
+	client.client.Host = server.URL
+
+	users, err := list_users(client, "ocid1.tenancy.oc1..test")
+	if err != nil { t.Fatal(err) }
+	if len(users) != 2 || users[0].Name != "alice" || users[1].Name != "bob" {
+		t.Fatalf("users = %+v, want alice and bob (sorted by name)", users)
+	}
+	if users[0].LastSuccessfulLoginTime != nil { t.Errorf("alice: last login = %v, want nil (never logged in)", users[0].LastSuccessfulLoginTime) }
+	if users[0].Email != "alice@example.com" { t.Errorf("alice: email = %q", users[0].Email) }
+	want := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	if users[1].LastSuccessfulLoginTime == nil || !users[1].LastSuccessfulLoginTime.Equal(want) {
+		t.Errorf("bob: last login = %v, want %v", users[1].LastSuccessfulLoginTime, want)
+	}
+	if !users[1].IsMfaActivated { t.Errorf("bob: MFA not activated") }
+}
+
+func TestDaysSince(t *testing.T) {
+	if days := DaysSince(time.Now().Add(-49 * time.Hour)); days != 2 { t.Errorf("DaysSince(49 hours ago) = %d, want 2", days) }
+	if days := DaysSince(time.Now()); days != 0 { t.Errorf("DaysSince(now) = %d, want 0", days) }
+}
@@ -0,0 +1,89 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: attachment status of block volumes and boot volumes
+// (attached, detached or no attachment record), to find orphaned volumes
+// Note: OCI does not return the time a volume was detached, only the creation time of its attachments, and it
+//       stops returning the DETACHED attachments after a while: a volume without attachment record may have been
+//       attached in the past
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: NEVER_ATTACHED renamed NO_ATTACHMENT_RECORD, minimum age of the volumes to delete (Deletable)
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"time"
+)
+
+// -- constants
+
+// attachment status of a volume
+const (
+	VolumeAttached           = "ATTACHED"
+	VolumeDetached           = "DETACHED"
+	VolumeNoAttachmentRecord = "NO_ATTACHMENT_RECORD"
+)
+
+// -- types
+
+// VolumeUsage is the attachment status of a volume. LastAttached is the creation time of its last attachment
+// (zero if no attachment record): it is NOT the time the volume was detached, which is not returned by OCI.
+type VolumeUsage struct {
+	Status       string
+	LastAttached time.Time
+}
+
+// VolumeAttachments records the attachments of volumes (block or boot volumes), by volume OCID
+type VolumeAttachments map[string]*VolumeUsage
+
+// -- functions
+
+// Add records an attachment of a volume (attached = false for an attachment in DETACHED state)
+func (a VolumeAttachments) Add(volume_id string, attached bool, time_created time.Time) {
+	u := a[volume_id]
+	if u == nil {
+		u = &VolumeUsage{ Status : VolumeDetached }
+		a[volume_id] = u
+	}
+	if attached { u.Status = VolumeAttached }
+	if time_created.After(u.LastAttached) { u.LastAttached = time_created }
+}
+
+// Usage returns the attachment status of a volume
+func (a VolumeAttachments) Usage(volume_id string) VolumeUsage {
+	if u := a[volume_id]; u != nil { return *u }
+	return VolumeUsage{ Status : VolumeNoAttachmentRecord }
+}
+
+// UnattachedDays returns the maximum number of days a volume without attachment record has not been attached for
+// (counted from the creation of the volume: it may have been attached in the past). known = false for attached
+// and detached volumes.
+func (u VolumeUsage) UnattachedDays(time_created time.Time, now time.Time) (days int, known bool) {
+	if u.Status != VolumeNoAttachmentRecord { return 0, false }
+	return int(now.Sub(time_created).Hours() / 24), true
+}
+
+// MaybeUnattachedFor returns true if the volume may not have been attached for at least days days: volumes without
+// attachment record created at least days days ago, and detached volumes whose last attachment was created at least days
+// days ago (they were detached later, maybe recently)
+func (u VolumeUsage) MaybeUnattachedFor(days int, time_created time.Time, now time.Time) bool {
+	switch u.Status {
+	case VolumeNoAttachmentRecord:
+		return now.Sub(time_created) >= time.Duration(days) * 24 * time.Hour
+	case VolumeDetached:
+		return now.Sub(u.LastAttached) >= time.Duration(days) * 24 * time.Hour
+	}
+	return false
+}
+
+// Deletable returns true if the volume can be deleted as an orphaned volume: only volumes without attachment record
+// created at least min_age_days days ago (the detach time of detached volumes is unknown, and a volume detached
+// long ago has no attachment record anymore: the age threshold is chosen by the user, none if min_age_days <= 0)
+func (u VolumeUsage) Deletable(min_age_days int, time_created time.Time, now time.Time) bool {
+	if min_age_days <= 0 { return false }
+	return u.Status == VolumeNoAttachmentRecord && now.Sub(time_created) >= time.Duration(min_age_days) * 24 * time.Hour
+}
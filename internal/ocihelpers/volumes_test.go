@@ -0,0 +1,48 @@
+package ocihelpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVolumeAttachments(t *testing.T) {
+	now     := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	days    := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+	created := days(800)
+
+	a := make(VolumeAttachments)
+	a.Add("attached",     false, days(700))             // detached then attached again
+	a.Add("attached",     true,  days(10))
+	a.Add("detached_old", false, days(400))
+	a.Add("detached",     false, days(730))             // attached 2 years ago, maybe detached yesterday
+	a.Add("detached",     false, days(5))               // last attachment 5 days ago
+
+	tests := []struct {
+		volume_id    string
+		status       string
+		last_days    int
+		maybe_90     bool
+		deletable_90 bool
+	}{
+		{ "attached",     VolumeAttached,           10,  false, false },
+		{ "detached_old", VolumeDetached,           400, true,  false },
+		{ "detached",     VolumeDetached,           5,   false, false },
+		{ "no_record",    VolumeNoAttachmentRecord, -1,  true,  true },
+	}
+	for _, tt := range tests {
+		u := a.Usage(tt.volume_id)
+		if u.Status != tt.status { t.Errorf("%s: status %s, want %s", tt.volume_id, u.Status, tt.status) }
+		if tt.last_days >= 0 && !u.LastAttached.Equal(days(tt.last_days)) { t.Errorf("%s: last attached %s, want %s", tt.volume_id, u.LastAttached, days(tt.last_days)) }
+		if tt.last_days < 0 && !u.LastAttached.IsZero() { t.Errorf("%s: last attached %s, want zero", tt.volume_id, u.LastAttached) }
+		if u.MaybeUnattachedFor(90, created, now) != tt.maybe_90 { t.Errorf("%s: MaybeUnattachedFor(90) = %v", tt.volume_id, !tt.maybe_90) }
+		if u.Deletable(90, created, now) != tt.deletable_90 { t.Errorf("%s: Deletable(90) = %v", tt.volume_id, !tt.deletable_90) }
+	}
+
+	// the number of days is only known for volumes without attachment record (never from the last attachment of a detached volume)
+	if n, known := a.Usage("no_record").UnattachedDays(created, now); !known || n != 800 { t.Errorf("no_record: UnattachedDays = %d, %v, want 800, true", n, known) }
+	if n, known := a.Usage("detached_old").UnattachedDays(created, now); known { t.Errorf("detached_old: UnattachedDays = %d, want unknown", n) }
+
+	// volumes without attachment record are only deletable with a minimum age, and if they are older than it
+	if a.Usage("no_record").Deletable(90, days(30), now) { t.Errorf("no_record: volume created 30 days ago deletable with --min-age-days 90") }
+	if a.Usage("no_record").Deletable(0, created, now) { t.Errorf("no_record: volume deletable without minimum age") }
+}
@@ -0,0 +1,39 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: wait for the end of long running operations of OCI
+// (work requests, lifecycle states), checking their status at regular intervals
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"fmt"
+	"time"
+)
+
+// -- functions
+
+// WaitFor calls check every interval until it returns true (operation completed) or an error, and returns an
+// error if the operation is not completed after timeout. check can display the progress of the operation.
+// Example:
+//
+//	err := ocihelpers.WaitFor(30*time.Second, 2*time.Hour, func() (bool, error) {
+//		response, err := client.GetWorkRequest(context.Background(), request)
+//		if err != nil { return false, err }
+//		return response.Status == workrequests.WorkRequestStatusSucceeded, nil
+//	})
+func WaitFor(interval time.Duration, timeout time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := check()
+		if err != nil { return err }
+		if done { return nil }
+		if time.Now().Add(interval).After(deadline) { return fmt.Errorf("operation not completed after %s", timeout) }
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,28 @@
+package ocihelpers
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitFor(t *testing.T) {
+	// completed after 3 checks
+	nb_checks := 0
+	err := WaitFor(time.Millisecond, time.Second, func() (bool, error) {
+		nb_checks++
+		return nb_checks == 3, nil
+	})
+	if err != nil || nb_checks != 3 { t.Errorf("got %v after %d checks, want nil after 3 checks", err, nb_checks) }
+
+	// errors returned by check are returned immediately
+	failed := errors.New("work request failed")
+	if err := WaitFor(time.Millisecond, time.Second, func() (bool, error) { return false, failed }); err != failed {
+		t.Errorf("got %v, want %v", err, failed)
+	}
+
+	// timeout
+	if err := WaitFor(5*time.Millisecond, 20*time.Millisecond, func() (bool, error) { return false, nil }); err == nil {
+		t.Errorf("expected a timeout error")
+	}
+}
@@ -0,0 +1,76 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: notifications posted to a Slack-compatible incoming webhook
+// (JSON body {"text": "..."}, also accepted by Mattermost, Rocket.Chat, Microsoft Teams workflows, ...) when the
+// number of findings of a report reaches a threshold
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// -- constants
+
+// WebhookMaxLines is the maximum number of finding lines in a notification (the next ones are summarized)
+const WebhookMaxLines = 20
+
+const webhook_timeout = 10 * time.Second
+
+// -- functions
+
+// WebhookURL returns the default webhook URL of the --webhook option (OCI_WEBHOOK_URL environment variable, or empty)
+func WebhookURL() string {
+	return os.Getenv("OCI_WEBHOOK_URL")
+}
+
+// FormatNotification returns the text of a notification: a title line, then one line per finding
+// (at most WebhookMaxLines lines, followed by the number of findings not displayed)
+func FormatNotification(title string, lines []string) string {
+	text := title
+	for i, line := range lines {
+		if i == WebhookMaxLines {
+			text += fmt.Sprintf("\n... and %d more", len(lines) - WebhookMaxLines)
+			break
+		}
+		text += "\n- " + line
+	}
+	return text
+}
+
+// PostWebhook posts a text to a Slack-compatible incoming webhook (error if the HTTP status is not 2xx)
+func PostWebhook(url string, text string) error {
+	body, err := json.Marshal(map[string]string{ "text" : text })
+	if err != nil { return err }
+	client := http.Client{ Timeout : webhook_timeout }
+	response, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil { return fmt.Errorf("webhook: %s", err) }
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 200))
+		return fmt.Errorf("webhook: HTTP %d %s", response.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// Notify posts a notification (title and finding lines) to a webhook if the URL is set and the number of
+// findings reaches the threshold. It returns true if the notification was posted.
+func Notify(url string, threshold int, title string, lines []string) (bool, error) {
+	if url == "" || len(lines) == 0 || len(lines) < threshold { return false, nil }
+	if err := PostWebhook(url, FormatNotification(title, lines)); err != nil { return false, err }
+	return true, nil
+}
@@ -0,0 +1,62 @@
+package ocihelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFormatNotification(t *testing.T) {
+	if got, want := FormatNotification("2 idle resources", []string{ "a", "b" }), "2 idle resources\n- a\n- b"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	lines := make([]string, 0)
+	for i := 0; i < WebhookMaxLines + 5; i++ { lines = append(lines, fmt.Sprintf("line %d", i)) }
+	text := FormatNotification("title", lines)
+	if n := strings.Count(text, "\n- "); n != WebhookMaxLines { t.Errorf("%d lines, want %d", n, WebhookMaxLines) }
+	if !strings.HasSuffix(text, "\n... and 5 more") { t.Errorf("text does not end with the number of lines not displayed: %q", text) }
+}
+
+func TestNotify(t *testing.T) {
+	texts := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct { Text string `json:"text"` }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil { t.Errorf("invalid body: %s", err) }
+		if r.Header.Get("Content-Type") != "application/json" { t.Errorf("content type = %q", r.Header.Get("Content-Type")) }
+		if strings.Contains(body.Text, "fail") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid_payload"))
+			return
+		}
+		texts = append(texts, body.Text)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		url       string
+		threshold int
+		lines     []string
+		want      bool
+	}{
+		{ "no url",          "",         1, []string{ "a" },      false },
+		{ "no findings",     server.URL, 0, []string{},           false },
+		{ "below threshold", server.URL, 3, []string{ "a", "b" }, false },
+		{ "threshold",       server.URL, 2, []string{ "a", "b" }, true },
+	}
+	for _, tt := range tests {
+		posted, err := Notify(tt.url, tt.threshold, "title", tt.lines)
+		if err != nil { t.Errorf("%s: %s", tt.name, err) }
+		if posted != tt.want { t.Errorf("%s: posted = %t, want %t", tt.name, posted, tt.want) }
+	}
+	if len(texts) != 1 || texts[0] != "title\n- a\n- b" { t.Errorf("texts = %q", texts) }
+
+	// HTTP errors are returned
+	if _, err := Notify(server.URL, 1, "title", []string{ "fail" }); err == nil || !strings.Contains(err.Error(), "HTTP 400 invalid_payload") {
+		t.Errorf("got error %v, want HTTP 400", err)
+	}
+}
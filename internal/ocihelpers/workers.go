@@ -0,0 +1,84 @@
+// --------------------------------------------------------------------------------------------------------------
+// Shared code for the Go scripts of this repository: bounded pool of workers to execute API calls concurrently
+// (ex: one job per compartment), with the results returned in the order of the jobs
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+package ocihelpers
+
+// -- import
+import (
+	"sync"
+)
+
+// -- constants
+
+// DefaultCompartmentParallelism is the default number of compartments processed concurrently by ForEachCompartment
+// (--parallelism option). Throttled requests (HTTP 429) are retried by RetryPolicy.
+const DefaultCompartmentParallelism = 8
+
+// -- types
+
+// JobResult is the result of a job executed by RunParallel
+type JobResult struct {
+	Value interface{}
+	Err   error
+}
+
+// CompartmentResult is the result of a function executed in a compartment by ForEachCompartment
+type CompartmentResult struct {
+	CompartmentId string
+	Value         interface{}
+	Err           error
+}
+
+// -- functions
+
+// RunParallel executes the jobs fn(0) ... fn(nb_jobs-1) with a pool of at most parallelism workers
+// (1 = sequential execution). The results are returned in the order of the jobs, whatever their end order.
+// An error in a job does not stop the other jobs: it is returned in the Err field of its result.
+func RunParallel(nb_jobs int, parallelism int, fn func(i int) (interface{}, error)) []JobResult {
+	if parallelism < 1 { parallelism = 1 }
+	if parallelism > nb_jobs { parallelism = nb_jobs }
+	results := make([]JobResult, nb_jobs)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				value, err := fn(i)
+				results[i] = JobResult{ Value : value, Err : err }
+			}
+		}()
+	}
+	for i := 0; i < nb_jobs; i++ { jobs <- i }
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// ForEachCompartment executes fn in each compartment, with at most parallelism compartments processed at the
+// same time. The results are returned in the same order as the compartments. An error in a compartment does
+// not stop the processing of the other compartments: it is returned in the Err field of the result.
+// Inside ForEachRegion, the number of concurrent requests can reach the product of both parallelisms.
+// The compartments processed are counted by the progress indicator (see StartProgress).
+func ForEachCompartment(cpt_ids []string, parallelism int, fn func(cpt_id string) (interface{}, error)) []CompartmentResult {
+	if parallelism < 1 { parallelism = DefaultCompartmentParallelism }
+	progress := scan_progress
+	jobs := RunParallel(len(cpt_ids), parallelism, func(i int) (interface{}, error) {
+		defer progress.CompartmentDone()
+		return fn(cpt_ids[i])
+	})
+	results := make([]CompartmentResult, len(cpt_ids))
+	for i, r := range jobs {
+		results[i] = CompartmentResult{ CompartmentId : cpt_ids[i], Value : r.Value, Err : r.Err }
+	}
+	return results
+}
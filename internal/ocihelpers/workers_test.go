@@ -0,0 +1,65 @@
+package ocihelpers
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunParallel(t *testing.T) {
+	tests := []struct {
+		name        string
+		nb_jobs     int
+		parallelism int
+	}{
+		{ "sequential",              5, 1 },
+		{ "bounded",                20, 3 },
+		{ "more workers than jobs",  2, 8 },
+		{ "invalid parallelism",     3, 0 },
+		{ "no jobs",                 0, 4 },
+	}
+	for _, tt := range tests {
+		var running, max_running int32
+		results := RunParallel(tt.nb_jobs, tt.parallelism, func(i int) (interface{}, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&max_running)
+				if n <= m || atomic.CompareAndSwapInt32(&max_running, m, n) { break }
+			}
+			// the first jobs end last: the results must still be in the order of the jobs
+			time.Sleep(time.Duration(tt.nb_jobs - i) * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			if i % 4 == 3 { return nil, fmt.Errorf("job %d failed", i) }
+			return i * 10, nil
+		})
+
+		want_max := int32(tt.parallelism)
+		if want_max < 1 { want_max = 1 }
+		if max_running > want_max { t.Errorf("%s: %d jobs executed at the same time, want at most %d", tt.name, max_running, want_max) }
+		if len(results) != tt.nb_jobs { t.Fatalf("%s: got %d results, want %d", tt.name, len(results), tt.nb_jobs) }
+		for i, r := range results {
+			if i % 4 == 3 {
+				if r.Err == nil || r.Err.Error() != fmt.Sprintf("job %d failed", i) { t.Errorf("%s: job %d: got error %v", tt.name, i, r.Err) }
+			} else if r.Err != nil || r.Value != i * 10 {
+				t.Errorf("%s: job %d: got %v, %v, want %d", tt.name, i, r.Value, r.Err, i * 10)
+			}
+		}
+	}
+}
+
+func TestForEachCompartment(t *testing.T) {
+	cpt_ids := []string{ "ocid1.compartment.oc1..a", "ocid1.compartment.oc1..b", "ocid1.compartment.oc1..c" }
+	results := ForEachCompartment(cpt_ids, 0, func(cpt_id string) (interface{}, error) {
+		if cpt_id == "ocid1.compartment.oc1..b" { return nil, errors.New("not authorized") }
+		return cpt_id[len(cpt_id)-1:], nil
+	})
+	if len(results) != len(cpt_ids) { t.Fatalf("got %d results, want %d", len(results), len(cpt_ids)) }
+	for i, r := range results {
+		if r.CompartmentId != cpt_ids[i] { t.Errorf("result %d is for compartment %s, want %s", i, r.CompartmentId, cpt_ids[i]) }
+	}
+	if results[0].Value != "a" || results[2].Value != "c" || results[1].Err == nil {
+		t.Errorf("got results %+v", results)
+	}
+}
@@ -0,0 +1,355 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the block volumes in all compartments of a OCI tenant using OCI Go SDK
+// For each block volume, it displays name, size, performance tier, attachment state, instance it is attached to,
+// availability domain and compartment path, or the storage capacity per compartment (--summary)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       terminated block volumes are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// block volume found in a region, with its attachments (not detached)
+type volume_item struct {
+	Region      string
+	Volume      core.Volume
+	Attachments []attachment_json
+}
+
+type attachment_json struct {
+	InstanceName   string `json:"instance_name"`
+	InstanceId     string `json:"instance_id"`
+	LifecycleState string `json:"lifecycle_state"`
+	Type           string `json:"type"`
+}
+
+type volume_json struct {
+	Name               string            `json:"name"`
+	Id                 string            `json:"id"`
+	SizeInGBs          int64             `json:"size_in_gbs"`
+	VpusPerGB          int64             `json:"vpus_per_gb"`
+	PerformanceTier    string            `json:"performance_tier"`
+	LifecycleState     string            `json:"lifecycle_state"`
+	AvailabilityDomain string            `json:"availability_domain"`
+	Region             string            `json:"region"`
+	CompartmentId      string            `json:"compartment_id"`
+	CompartmentPath    string            `json:"compartment_path"`
+	TimeCreated        string            `json:"time_created"`
+	Attachments        []attachment_json `json:"attachments"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If -a or --all-regions is provided, the block volumes of all subscribed regions are listed instead of")
+    fmt.Println("    the region of the profile.")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --summary is provided, the storage capacity (number of volumes, total, attached and unattached size)")
+    fmt.Println("    is displayed for each compartment instead of the list of block volumes.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// name of the performance tier of a block volume from its number of VPUs per GB
+func performance_tier(vpus_per_gb int64) string {
+	switch {
+	case vpus_per_gb == 0: return "Lower Cost"
+	case vpus_per_gb < 20: return "Balanced"
+	case vpus_per_gb < 30: return "Higher Performance"
+	default:               return "Ultra High Performance"
+	}
+}
+
+// list the block volumes (not terminated) and their attachments in all active compartments of a region
+// (the attachments are in the compartment of the instance, which can be different from the one of the volume)
+// at most parallelism compartments are processed at the same time
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]volume_item, error) {
+	bs_client, err := core.NewBlockstorageClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	bs_client.SetRegion(region)
+	compute_client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	compute_client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	type cpt_output struct {
+		volumes     []core.Volume
+		attachments []core.VolumeAttachment
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		output := cpt_output{ make([]core.Volume, 0), make([]core.VolumeAttachment, 0) }
+		volumes_request := core.ListVolumesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			volumes_request.Page = page
+			response, err := bs_client.ListVolumes(context.Background(), volumes_request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				if v.LifecycleState != core.VolumeLifecycleStateTerminated { output.volumes = append(output.volumes, v) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		attachments_request := core.ListVolumeAttachmentsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			attachments_request.Page = page
+			response, err := compute_client.ListVolumeAttachments(context.Background(), attachments_request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				if a.GetLifecycleState() != core.VolumeAttachmentLifecycleStateDetached { output.attachments = append(output.attachments, a) }
+			}
+			return response.OpcNextPage, nil
+		})
+		return output, err
+	})
+	volumes     := make([]core.Volume, 0)
+	attachments := make([]core.VolumeAttachment, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		output := r.Value.(cpt_output)
+		volumes     = append(volumes, output.volumes...)
+		attachments = append(attachments, output.attachments...)
+	}
+
+	// get the names of the instances the volumes are attached to
+	instance_names := make(map[string]string)
+	volume_attachments := make(map[string][]attachment_json)
+	for _, a := range attachments {
+		instance_id := *a.GetInstanceId()
+		if _, found := instance_names[instance_id]; !found {
+			response, err := compute_client.GetInstance(context.Background(), core.GetInstanceRequest{ InstanceId : a.GetInstanceId(), RequestMetadata : metadata })
+			if err != nil { return nil, err }
+			instance_names[instance_id] = *response.DisplayName
+		}
+		attachment_type := strings.TrimSuffix(strings.TrimPrefix(fmt.Sprintf("%T", a), "core."), "VolumeAttachment")
+		volume_attachments[*a.GetVolumeId()] = append(volume_attachments[*a.GetVolumeId()], attachment_json{
+			InstanceName   : instance_names[instance_id],
+			InstanceId     : instance_id,
+			LifecycleState : string(a.GetLifecycleState()),
+			Type           : strings.ToLower(attachment_type),
+		})
+	}
+
+	items := make([]volume_item, 0, len(volumes))
+	for _, v := range volumes {
+		items = append(items, volume_item{ region, v, volume_attachments[*v.Id] })
+	}
+	return items, nil
+}
+
+// attachment state and instance names of a block volume in text, CSV and Markdown formats
+func attachment_info(item volume_item) (string, string) {
+	if len(item.Attachments) == 0 { return "NOT_ATTACHED", "" }
+	states, names := make([]string, 0), make([]string, 0)
+	for _, a := range item.Attachments {
+		states = append(states, a.LifecycleState)
+		names  = append(names, a.InstanceName)
+	}
+	return strings.Join(states, " "), strings.Join(names, " ")
+}
+
+// display the storage capacity per compartment (and per region if all_regions is true)
+func display_summary(items []volume_item, paths map[string]string, all_regions bool, format string) {
+	type capacity struct {
+		nb_volumes    int
+		total_gb      int64
+		attached_gb   int64
+	}
+	capacities := make(map[string]*capacity)
+	keys := make([]string, 0)
+	for _, item := range items {
+		key := paths[*item.Volume.CompartmentId]
+		if all_regions { key += "\t" + item.Region }
+		if capacities[key] == nil {
+			capacities[key] = &capacity{}
+			keys = append(keys, key)
+		}
+		capacities[key].nb_volumes++
+		capacities[key].total_gb += *item.Volume.SizeInGBs
+		if len(item.Attachments) > 0 { capacities[key].attached_gb += *item.Volume.SizeInGBs }
+	}
+	sort.Strings(keys)
+
+	table := ocihelpers.Table{ Headers : []string{ "compartment_path" } }
+	if all_regions { table.Headers = append(table.Headers, "region") }
+	table.Headers = append(table.Headers, "volumes", "total_gb", "attached_gb", "unattached_gb")
+	var total capacity
+	for _, key := range keys {
+		c := capacities[key]
+		row := strings.Split(key, "\t")
+		row = append(row, fmt.Sprintf("%d", c.nb_volumes), fmt.Sprintf("%d", c.total_gb), fmt.Sprintf("%d", c.attached_gb), fmt.Sprintf("%d", c.total_gb-c.attached_gb))
+		table.AddRow(row...)
+		total.nb_volumes  += c.nb_volumes
+		total.total_gb    += c.total_gb
+		total.attached_gb += c.attached_gb
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet {
+		fmt.Println ("")
+		fmt.Printf ("%d block volumes, %d GB (%d GB attached, %d GB unattached)\n", total.nb_volumes, total.total_gb, total.attached_gb, total.total_gb-total.attached_gb)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list block volumes in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list block volumes in all subscribed regions")
+	summary         := flag.Bool("summary", false, "display the storage capacity per compartment")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of block volumes in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("block volumes", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	items := make([]volume_item, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		items = append(items, r.Value.([]volume_item)...)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		path_i, path_j := paths[*items[i].Volume.CompartmentId], paths[*items[j].Volume.CompartmentId]
+		if path_i != path_j { return path_i < path_j }
+		return *items[i].Volume.DisplayName < *items[j].Volume.DisplayName
+	})
+
+	// Display the results
+	switch {
+	case *summary:
+		display_summary(items, paths, all_regions, format)
+
+	case format == "json":
+		json_items := make([]volume_json, 0, len(items))
+		for _, item := range items {
+			v := item.Volume
+			vpus := int64(0)
+			if v.VpusPerGB != nil { vpus = *v.VpusPerGB }
+			attachments := item.Attachments
+			if attachments == nil { attachments = make([]attachment_json, 0) }
+			json_items = append(json_items, volume_json{
+				Name               : *v.DisplayName,
+				Id                 : *v.Id,
+				SizeInGBs          : *v.SizeInGBs,
+				VpusPerGB          : vpus,
+				PerformanceTier    : performance_tier(vpus),
+				LifecycleState     : string(v.LifecycleState),
+				AvailabilityDomain : *v.AvailabilityDomain,
+				Region             : item.Region,
+				CompartmentId      : *v.CompartmentId,
+				CompartmentPath    : paths[*v.CompartmentId],
+				TimeCreated        : v.TimeCreated.Format("2006-01-02T15:04:05Z"),
+				Attachments        : attachments,
+			})
+		}
+		output, err := json.MarshalIndent(json_items, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+
+	default:
+		table := ocihelpers.Table{ Headers : []string{ "name", "size_gb", "performance_tier", "state", "attachment", "instance", "availability_domain", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		for _, item := range items {
+			v := item.Volume
+			vpus := int64(0)
+			if v.VpusPerGB != nil { vpus = *v.VpusPerGB }
+			attachment, instances := attachment_info(item)
+			row := []string{ *v.DisplayName, fmt.Sprintf("%d", *v.SizeInGBs), performance_tier(vpus), string(v.LifecycleState), attachment, instances, *v.AvailabilityDomain, paths[*v.CompartmentId] }
+			if all_regions { row = append(row, item.Region) }
+			table.AddRow(append(row, *v.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,384 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the boot volumes and the boot volume backups in all compartments of a OCI tenant
+// using OCI Go SDK (per availability domain and compartment)
+// For each backup, it displays whether it is still associated with an existing instance (backup of the boot volume
+// of an instance) or not (boot volume deleted or not attached), to help identify cleanup candidates
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       terminated boot volumes and backups are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- constants
+
+// status of a boot volume backup
+const (
+	status_associated    = "ASSOCIATED"
+	status_not_attached  = "BOOT_VOLUME_NOT_ATTACHED"
+	status_volume_deleted = "BOOT_VOLUME_DELETED"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type boot_volume_json struct {
+	Name               string `json:"name"`
+	Id                 string `json:"id"`
+	SizeInGBs          int64  `json:"size_in_gbs"`
+	LifecycleState     string `json:"lifecycle_state"`
+	AvailabilityDomain string `json:"availability_domain"`
+	InstanceName       string `json:"instance_name"`
+	InstanceId         string `json:"instance_id"`
+	NbBackups          int    `json:"nb_backups"`
+	Region             string `json:"region"`
+	CompartmentId      string `json:"compartment_id"`
+	CompartmentPath    string `json:"compartment_path"`
+	TimeCreated        string `json:"time_created"`
+}
+
+type backup_json struct {
+	Name            string `json:"name"`
+	Id              string `json:"id"`
+	SizeInGBs       int64  `json:"size_in_gbs"`
+	Type            string `json:"type"`
+	SourceType      string `json:"source_type"`
+	LifecycleState  string `json:"lifecycle_state"`
+	BootVolumeName  string `json:"boot_volume_name"`
+	BootVolumeId    string `json:"boot_volume_id"`
+	InstanceName    string `json:"instance_name"`
+	InstanceId      string `json:"instance_id"`
+	Status          string `json:"status"`
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+	TimeCreated     string `json:"time_created"`
+}
+
+// boot volumes and backups found in a region
+type region_items struct {
+	boot_volumes []boot_volume_json
+	backups      []backup_json
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, the boot volumes are listed (with the instance they are attached to and their number of backups).")
+    fmt.Println("    If --backups is provided, the boot volume backups are listed instead, with their status:")
+    fmt.Println("      ASSOCIATED               : backup of the boot volume of an existing instance")
+    fmt.Println("      BOOT_VOLUME_NOT_ATTACHED : backup of a boot volume not attached to an instance")
+    fmt.Println("      BOOT_VOLUME_DELETED      : backup of a boot volume that no longer exists")
+    fmt.Println("    If --orphaned-backups is provided, only the backups not associated with an existing instance are listed.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the boot volumes, their attachments and the boot volume backups in all active compartments of a region
+// (the attachments are in the compartment of the instance, which can be different from the one of the boot volume)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) (region_items, error) {
+	var items region_items
+	bs_client, err := core.NewBlockstorageClientWithConfigurationProvider(config)
+	if err != nil { return items, err }
+	bs_client.SetRegion(region)
+	compute_client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return items, err }
+	compute_client.SetRegion(region)
+	ads, err := ocihelpers.ListAvailabilityDomains(config, region)
+	if err != nil { return items, err }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	type compartment_items struct {
+		boot_volumes []core.BootVolume
+		backups      []core.BootVolumeBackup
+		instance_ids map[string]string      // boot volume OCID -> instance OCID
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_items := compartment_items{ make([]core.BootVolume, 0), make([]core.BootVolumeBackup, 0), make(map[string]string) }
+		for _, ad := range ads {
+			boot_volumes_request := core.ListBootVolumesRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+			err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				boot_volumes_request.Page = page
+				response, err := bs_client.ListBootVolumes(context.Background(), boot_volumes_request)
+				if err != nil { return nil, err }
+				for _, v := range response.Items {
+					if v.LifecycleState != core.BootVolumeLifecycleStateTerminated { cpt_items.boot_volumes = append(cpt_items.boot_volumes, v) }
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+
+			attachments_request := core.ListBootVolumeAttachmentsRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+			err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				attachments_request.Page = page
+				response, err := compute_client.ListBootVolumeAttachments(context.Background(), attachments_request)
+				if err != nil { return nil, err }
+				for _, a := range response.Items {
+					if a.LifecycleState != core.BootVolumeAttachmentLifecycleStateDetached { cpt_items.instance_ids[*a.BootVolumeId] = *a.InstanceId }
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+		}
+
+		backups_request := core.ListBootVolumeBackupsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			backups_request.Page = page
+			response, err := bs_client.ListBootVolumeBackups(context.Background(), backups_request)
+			if err != nil { return nil, err }
+			for _, b := range response.Items {
+				if b.LifecycleState != core.BootVolumeBackupLifecycleStateTerminated { cpt_items.backups = append(cpt_items.backups, b) }
+			}
+			return response.OpcNextPage, nil
+		})
+		return cpt_items, err
+	})
+	boot_volumes := make([]core.BootVolume, 0)
+	backups      := make([]core.BootVolumeBackup, 0)
+	instance_ids := make(map[string]string)     // boot volume OCID -> instance OCID
+	for _, r := range results {
+		if r.Err != nil { return items, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		boot_volumes = append(boot_volumes, cpt_items.boot_volumes...)
+		backups = append(backups, cpt_items.backups...)
+		for volume_id, instance_id := range cpt_items.instance_ids { instance_ids[volume_id] = instance_id }
+	}
+
+	// get the names of the instances the boot volumes are attached to
+	instance_names := make(map[string]string)
+	for _, instance_id := range instance_ids {
+		if _, found := instance_names[instance_id]; found { continue }
+		response, err := compute_client.GetInstance(context.Background(), core.GetInstanceRequest{ InstanceId : common.String(instance_id), RequestMetadata : metadata })
+		if err != nil { return items, err }
+		instance_names[instance_id] = *response.DisplayName
+	}
+
+	// boot volumes
+	boot_volume_names := make(map[string]string)
+	nb_backups := make(map[string]int)
+	for _, b := range backups {
+		if b.BootVolumeId != nil { nb_backups[*b.BootVolumeId]++ }
+	}
+	for _, v := range boot_volumes {
+		boot_volume_names[*v.Id] = *v.DisplayName
+		instance_id := instance_ids[*v.Id]
+		items.boot_volumes = append(items.boot_volumes, boot_volume_json{
+			Name               : *v.DisplayName,
+			Id                 : *v.Id,
+			SizeInGBs          : *v.SizeInGBs,
+			LifecycleState     : string(v.LifecycleState),
+			AvailabilityDomain : *v.AvailabilityDomain,
+			InstanceName       : instance_names[instance_id],
+			InstanceId         : instance_id,
+			NbBackups          : nb_backups[*v.Id],
+			Region             : region,
+			CompartmentId      : *v.CompartmentId,
+			TimeCreated        : v.TimeCreated.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	// boot volume backups, with the instance of their boot volume
+	for _, b := range backups {
+		item := backup_json{
+			Name           : *b.DisplayName,
+			Id             : *b.Id,
+			Type           : string(b.Type),
+			SourceType     : string(b.SourceType),
+			LifecycleState : string(b.LifecycleState),
+			Status         : status_volume_deleted,
+			Region         : region,
+			CompartmentId  : *b.CompartmentId,
+			TimeCreated    : b.TimeCreated.Format("2006-01-02T15:04:05Z"),
+		}
+		if b.SizeInGBs != nil { item.SizeInGBs = *b.SizeInGBs }
+		if b.BootVolumeId != nil {
+			item.BootVolumeId = *b.BootVolumeId
+			if name, found := boot_volume_names[*b.BootVolumeId]; found {
+				item.BootVolumeName, item.Status = name, status_not_attached
+				if instance_id, attached := instance_ids[*b.BootVolumeId]; attached {
+					item.InstanceName, item.InstanceId, item.Status = instance_names[instance_id], instance_id, status_associated
+				}
+			}
+		}
+		items.backups = append(items.backups, item)
+	}
+	return items, nil
+}
+
+// display a list in JSON format
+func display_json(items interface{}) {
+	output, err := json.MarshalIndent(items, "", "  ")
+	ocihelpers.FatalIfError(err)
+	fmt.Println(string(output))
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	list_backups     := flag.Bool("backups", false, "list the boot volume backups instead of the boot volumes")
+	orphaned_backups := flag.Bool("orphaned-backups", false, "only list the backups not associated with an existing instance")
+	json_output      := flag.Bool("json", false, "display output in JSON format")
+	csv_output       := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output  := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism      := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file      := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if *orphaned_backups { *list_backups = true }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the boot volumes and backups in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("boot volumes", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	boot_volumes := make([]boot_volume_json, 0)
+	backups      := make([]backup_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		items := r.Value.(region_items)
+		for _, v := range items.boot_volumes {
+			v.CompartmentPath = paths[v.CompartmentId]
+			boot_volumes = append(boot_volumes, v)
+		}
+		for _, b := range items.backups {
+			if *orphaned_backups && b.Status == status_associated { continue }
+			b.CompartmentPath = paths[b.CompartmentId]
+			backups = append(backups, b)
+		}
+	}
+
+	// Display the boot volume backups, sorted by compartment and creation date
+	if *list_backups {
+		sort.SliceStable(backups, func(i, j int) bool {
+			if backups[i].CompartmentPath != backups[j].CompartmentPath { return backups[i].CompartmentPath < backups[j].CompartmentPath }
+			return backups[i].TimeCreated < backups[j].TimeCreated
+		})
+		if format == "json" {
+			display_json(backups)
+		} else {
+			table := ocihelpers.Table{ Headers : []string{ "name", "size_gb", "type", "source", "created", "boot_volume", "instance", "status", "compartment_path" } }
+			if all_regions { table.Headers = append(table.Headers, "region") }
+			table.Headers = append(table.Headers, "ocid")
+			total_gb := map[string]int64{}
+			for _, b := range backups {
+				row := []string{ b.Name, fmt.Sprintf("%d", b.SizeInGBs), b.Type, b.SourceType, b.TimeCreated[:10], b.BootVolumeName, b.InstanceName, b.Status, b.CompartmentPath }
+				if all_regions { row = append(row, b.Region) }
+				table.AddRow(append(row, b.Id)...)
+				total_gb[b.Status] += b.SizeInGBs
+			}
+			ocihelpers.FatalIfError(table.Print(format))
+			if format == "text" && !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d boot volume backups, not associated with an existing instance: %d GB (boot volume deleted) + %d GB (boot volume not attached)\n",
+					len(backups), total_gb[status_volume_deleted], total_gb[status_not_attached])
+			}
+		}
+
+	// Display the boot volumes, sorted by availability domain and compartment
+	} else {
+		sort.SliceStable(boot_volumes, func(i, j int) bool {
+			if boot_volumes[i].Region != boot_volumes[j].Region { return boot_volumes[i].Region < boot_volumes[j].Region }
+			if boot_volumes[i].AvailabilityDomain != boot_volumes[j].AvailabilityDomain { return boot_volumes[i].AvailabilityDomain < boot_volumes[j].AvailabilityDomain }
+			if boot_volumes[i].CompartmentPath != boot_volumes[j].CompartmentPath { return boot_volumes[i].CompartmentPath < boot_volumes[j].CompartmentPath }
+			return boot_volumes[i].Name < boot_volumes[j].Name
+		})
+		if format == "json" {
+			display_json(boot_volumes)
+		} else {
+			table := ocihelpers.Table{ Headers : []string{ "availability_domain", "compartment_path", "name", "size_gb", "state", "instance", "backups" } }
+			if all_regions { table.Headers = append(table.Headers, "region") }
+			table.Headers = append(table.Headers, "ocid")
+			for _, v := range boot_volumes {
+				row := []string{ v.AvailabilityDomain, v.CompartmentPath, v.Name, fmt.Sprintf("%d", v.SizeInGBs), v.LifecycleState, v.InstanceName, fmt.Sprintf("%d", v.NbBackups) }
+				if all_regions { row = append(row, v.Region) }
+				table.AddRow(append(row, v.Id)...)
+			}
+			ocihelpers.FatalIfError(table.Print(format))
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,409 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the file systems of the File Storage service in all compartments of a OCI tenant using
+// OCI Go SDK (per availability domain and compartment), with their metered size, their number of snapshots
+// and their exports (export path and mount target)
+// It can also list the export options of each export, to find insecure exports (identity squash NONE, i.e.
+// root_squash=none: root on a NFS client is root on the file system)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       deleted file systems, mount targets and exports are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/filestorage"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type export_option_json struct {
+	Source                      string `json:"source"`
+	Access                      string `json:"access"`                         // READ_WRITE or READ_ONLY
+	IdentitySquash              string `json:"identity_squash"`                // NONE, ROOT or ALL
+	RequirePrivilegedSourcePort bool   `json:"require_privileged_source_port"`
+	Insecure                    bool   `json:"insecure"`                       // identity squash NONE
+}
+
+type export_json struct {
+	Path             string               `json:"path"`
+	Id               string               `json:"id"`
+	MountTargetName  string               `json:"mount_target_name"`
+	MountTargetId    string               `json:"mount_target_id"`
+	MountTargetIps   []string             `json:"mount_target_ips"`
+	ExportOptions    []export_option_json `json:"export_options"`
+}
+
+type file_system_json struct {
+	Name               string        `json:"name"`
+	Id                 string        `json:"id"`
+	LifecycleState     string        `json:"lifecycle_state"`
+	MeteredBytes       int64         `json:"metered_bytes"`
+	NbSnapshots        int           `json:"nb_snapshots"`
+	Exports            []export_json `json:"exports"`
+	AvailabilityDomain string        `json:"availability_domain"`
+	Region             string        `json:"region"`
+	CompartmentId      string        `json:"compartment_id"`
+	CompartmentPath    string        `json:"compartment_path"`
+	TimeCreated        string        `json:"time_created"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, a row is displayed for each file system (with its metered size, snapshots and export paths).")
+    fmt.Println("    If --exports is provided, a row is displayed for each export option of each export instead. Insecure export")
+    fmt.Println("    options (identity squash NONE, i.e. root_squash=none) are displayed in red.")
+    fmt.Println("    If --insecure-only is provided, only the insecure export options are displayed (implies --exports).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format (with the exports and their options).")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the file systems of all active compartments of a region, with their snapshots and exports
+// (the mount targets can be in a compartment different from the one of the file systems)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]file_system_json, error) {
+	client, err := filestorage.NewFileStorageClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	vn_client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	vn_client.SetRegion(region)
+	ads, err := ocihelpers.ListAvailabilityDomains(config, region)
+	if err != nil { return nil, err }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	type compartment_items struct {
+		file_systems  []filestorage.FileSystemSummary
+		mount_targets map[string]filestorage.MountTargetSummary     // export set OCID -> mount target
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_items := compartment_items{ make([]filestorage.FileSystemSummary, 0), make(map[string]filestorage.MountTargetSummary) }
+		for _, ad := range ads {
+			fs_request := filestorage.ListFileSystemsRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+			err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				fs_request.Page = page
+				response, err := client.ListFileSystems(context.Background(), fs_request)
+				if err != nil { return nil, err }
+				for _, f := range response.Items {
+					if f.LifecycleState != filestorage.FileSystemSummaryLifecycleStateDeleted { cpt_items.file_systems = append(cpt_items.file_systems, f) }
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+
+			mt_request := filestorage.ListMountTargetsRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+			err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				mt_request.Page = page
+				response, err := client.ListMountTargets(context.Background(), mt_request)
+				if err != nil { return nil, err }
+				for _, mt := range response.Items {
+					if mt.LifecycleState != filestorage.MountTargetSummaryLifecycleStateDeleted && mt.ExportSetId != nil { cpt_items.mount_targets[*mt.ExportSetId] = mt }
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+		}
+		return cpt_items, nil
+	})
+	file_systems  := make([]filestorage.FileSystemSummary, 0)
+	mount_targets := make(map[string]filestorage.MountTargetSummary)     // export set OCID -> mount target
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		file_systems = append(file_systems, cpt_items.file_systems...)
+		for export_set_id, mt := range cpt_items.mount_targets { mount_targets[export_set_id] = mt }
+	}
+
+	// IP addresses of the mount targets
+	mount_target_ips := make(map[string][]string)
+	for _, mt := range mount_targets {
+		ips := make([]string, 0)
+		for _, ip_id := range mt.PrivateIpIds {
+			response, err := vn_client.GetPrivateIp(context.Background(), core.GetPrivateIpRequest{ PrivateIpId : common.String(ip_id), RequestMetadata : metadata })
+			if err != nil { return nil, err }
+			if response.IpAddress != nil { ips = append(ips, *response.IpAddress) }
+		}
+		mount_target_ips[*mt.Id] = ips
+	}
+
+	items := make([]file_system_json, 0)
+	for _, f := range file_systems {
+		item := file_system_json{
+			Name               : *f.DisplayName,
+			Id                 : *f.Id,
+			LifecycleState     : string(f.LifecycleState),
+			MeteredBytes       : *f.MeteredBytes,
+			Exports            : make([]export_json, 0),
+			AvailabilityDomain : *f.AvailabilityDomain,
+			Region             : region,
+			CompartmentId      : *f.CompartmentId,
+			TimeCreated        : f.TimeCreated.Format("2006-01-02T15:04:05Z"),
+		}
+
+		// snapshots
+		snapshots_request := filestorage.ListSnapshotsRequest{ FileSystemId : f.Id, RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			snapshots_request.Page = page
+			response, err := client.ListSnapshots(context.Background(), snapshots_request)
+			if err != nil { return nil, err }
+			for _, s := range response.Items {
+				if s.LifecycleState != filestorage.SnapshotSummaryLifecycleStateDeleted { item.NbSnapshots++ }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		// exports, with their options (only available with GetExport)
+		exports := make([]filestorage.ExportSummary, 0)
+		exports_request := filestorage.ListExportsRequest{ FileSystemId : f.Id, RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			exports_request.Page = page
+			response, err := client.ListExports(context.Background(), exports_request)
+			if err != nil { return nil, err }
+			for _, e := range response.Items {
+				if e.LifecycleState != filestorage.ExportSummaryLifecycleStateDeleted { exports = append(exports, e) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		for _, e := range exports {
+			response, err := client.GetExport(context.Background(), filestorage.GetExportRequest{ ExportId : e.Id, RequestMetadata : metadata })
+			if err != nil { return nil, err }
+			export := export_json{ Path : *e.Path, Id : *e.Id, MountTargetIps : make([]string, 0), ExportOptions : make([]export_option_json, 0) }
+			if mt, found := mount_targets[*e.ExportSetId]; found {
+				export.MountTargetName, export.MountTargetId, export.MountTargetIps = *mt.DisplayName, *mt.Id, mount_target_ips[*mt.Id]
+			}
+			for _, o := range response.ExportOptions {
+				option := export_option_json{ Source : *o.Source, Access : string(o.Access), IdentitySquash : string(o.IdentitySquash) }
+				if o.RequirePrivilegedSourcePort != nil { option.RequirePrivilegedSourcePort = *o.RequirePrivilegedSourcePort }
+				option.Insecure = o.IdentitySquash == filestorage.ClientOptionsIdentitySquashNone
+				export.ExportOptions = append(export.ExportOptions, option)
+			}
+			item.Exports = append(item.Exports, export)
+		}
+		sort.SliceStable(item.Exports, func(i, j int) bool { return item.Exports[i].Path < item.Exports[j].Path })
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// display the table in text format, with the insecure export options in red
+func display_text(table ocihelpers.Table, insecure []bool) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(insecure) { break }
+		if insecure[i] { line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL }
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	list_exports    := flag.Bool("exports", false, "display a row for each export option of each export")
+	insecure_only   := flag.Bool("insecure-only", false, "only display the insecure export options (identity squash NONE)")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if *insecure_only { *list_exports = true }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the file systems in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("file systems", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	file_systems := make([]file_system_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, f := range r.Value.([]file_system_json) {
+			f.CompartmentPath = paths[f.CompartmentId]
+			file_systems = append(file_systems, f)
+		}
+	}
+	sort.SliceStable(file_systems, func(i, j int) bool {
+		if file_systems[i].Region != file_systems[j].Region { return file_systems[i].Region < file_systems[j].Region }
+		if file_systems[i].AvailabilityDomain != file_systems[j].AvailabilityDomain { return file_systems[i].AvailabilityDomain < file_systems[j].AvailabilityDomain }
+		if file_systems[i].CompartmentPath != file_systems[j].CompartmentPath { return file_systems[i].CompartmentPath < file_systems[j].CompartmentPath }
+		return file_systems[i].Name < file_systems[j].Name
+	})
+
+	// Display the export options of each export
+	if *list_exports {
+		if format == "json" {
+			if *insecure_only {
+				insecure_file_systems := make([]file_system_json, 0)
+				for _, f := range file_systems {
+					exports := make([]export_json, 0)
+					for _, e := range f.Exports {
+						options := make([]export_option_json, 0)
+						for _, o := range e.ExportOptions {
+							if o.Insecure { options = append(options, o) }
+						}
+						if len(options) > 0 { e.ExportOptions = options; exports = append(exports, e) }
+					}
+					if len(exports) > 0 { f.Exports = exports; insecure_file_systems = append(insecure_file_systems, f) }
+				}
+				file_systems = insecure_file_systems
+			}
+			output, err := json.MarshalIndent(file_systems, "", "  ")
+			ocihelpers.FatalIfError(err)
+			fmt.Println(string(output))
+		} else {
+			table := ocihelpers.Table{ Headers : []string{ "file_system", "export_path", "mount_target", "mount_target_ips", "source", "access", "identity_squash", "privileged_port", "compartment_path" } }
+			if all_regions { table.Headers = append(table.Headers, "region") }
+			table.Headers = append(table.Headers, "export_ocid")
+			insecure := make([]bool, 0)
+			nb_exports, nb_insecure := 0, 0
+			for _, f := range file_systems {
+				for _, e := range f.Exports {
+					nb_exports++
+					for _, o := range e.ExportOptions {
+						if o.Insecure { nb_insecure++ }
+						if *insecure_only && !o.Insecure { continue }
+						row := []string{ f.Name, e.Path, e.MountTargetName, strings.Join(e.MountTargetIps, " "), o.Source, o.Access, o.IdentitySquash, fmt.Sprintf("%t", o.RequirePrivilegedSourcePort), f.CompartmentPath }
+						if all_regions { row = append(row, f.Region) }
+						table.AddRow(append(row, e.Id)...)
+						insecure = append(insecure, o.Insecure)
+					}
+				}
+			}
+			if format != "text" {
+				ocihelpers.FatalIfError(table.Print(format))
+			} else {
+				display_text(table, insecure)
+				if !ocihelpers.Quiet {
+					fmt.Println ("")
+					fmt.Printf ("%d exports, %d insecure export options (identity squash NONE)\n", nb_exports, nb_insecure)
+				}
+			}
+		}
+
+	// Display the file systems
+	} else {
+		if format == "json" {
+			output, err := json.MarshalIndent(file_systems, "", "  ")
+			ocihelpers.FatalIfError(err)
+			fmt.Println(string(output))
+		} else {
+			table := ocihelpers.Table{ Headers : []string{ "availability_domain", "compartment_path", "name", "metered_gb", "state", "snapshots", "export_paths", "mount_targets" } }
+			if all_regions { table.Headers = append(table.Headers, "region") }
+			table.Headers = append(table.Headers, "ocid")
+			var total_bytes int64
+			for _, f := range file_systems {
+				total_bytes += f.MeteredBytes
+				export_paths, mount_target_names := make([]string, 0), make([]string, 0)
+				for _, e := range f.Exports {
+					export_paths = append(export_paths, e.Path)
+					if e.MountTargetName != "" { mount_target_names = append(mount_target_names, e.MountTargetName) }
+				}
+				row := []string{ f.AvailabilityDomain, f.CompartmentPath, f.Name, fmt.Sprintf("%.2f", float64(f.MeteredBytes) / 1e9), f.LifecycleState, fmt.Sprintf("%d", f.NbSnapshots),
+					strings.Join(export_paths, " "), strings.Join(mount_target_names, " ") }
+				if all_regions { row = append(row, f.Region) }
+				table.AddRow(append(row, f.Id)...)
+			}
+			ocihelpers.FatalIfError(table.Print(format))
+			if format == "text" && !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d file systems, %.2f GB metered\n", len(file_systems), float64(total_bytes) / 1e9)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
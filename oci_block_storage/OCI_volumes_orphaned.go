@@ -0,0 +1,388 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script looks for orphaned block volumes and boot volumes in all compartments of a OCI tenant using
+// OCI Go SDK: volumes detached (or without attachment record) for more than N days, with their estimated monthly cost
+// It can optionally delete the volumes without attachment record older than a minimum age (--delete --yes)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       OCI does not return the time a volume was detached: for detached volumes, only the creation time of the
+//       last attachment is known (last_attached), so they are listed as candidates but never deleted
+//       OCI stops returning the DETACHED attachments after a while: a volume without attachment record may have
+//       been attached in the past, so --delete requires a minimum age of the volumes (--min-age-days)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Do not use the creation time of the last attachment as detach time, never delete detached volumes
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+//    2026-10-15: NEVER_ATTACHED status renamed NO_ATTACHMENT_RECORD, --delete requires --min-age-days
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- constants
+
+// default prices used to estimate the monthly cost (USD, pay as you go): storage per GB per month
+// and performance units per VPU per GB per month (0 VPU for Lower Cost, 10 for Balanced, 20 for Higher Performance)
+const default_gb_price  = 0.0255
+const default_vpu_price = 0.0017
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// block volume or boot volume not attached to an instance
+type orphan struct {
+	Type               string  `json:"type"`
+	Name               string  `json:"name"`
+	Id                 string  `json:"id"`
+	SizeInGBs          int64   `json:"size_in_gbs"`
+	VpusPerGB          int64   `json:"vpus_per_gb"`
+	Status             string  `json:"status"`                       // DETACHED or NO_ATTACHMENT_RECORD
+	UnattachedSince    string  `json:"unattached_since,omitempty"`   // creation date of volumes without attachment record
+	UnattachedDays     *int    `json:"unattached_days"`              // null for detached volumes (detach time unknown)
+	LastAttached       string  `json:"last_attached,omitempty"`      // creation date of the last attachment of detached volumes
+	Deletable          bool    `json:"deletable"`                    // deleted by --delete --yes (no attachment record, older than --min-age-days)
+	MonthlyCost        float64 `json:"estimated_monthly_cost"`
+	AvailabilityDomain string  `json:"availability_domain"`
+	Region             string  `json:"region"`
+	CompartmentId      string  `json:"compartment_id"`
+	CompartmentPath    string  `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If --days N is provided, the volumes not attached for more than N days are displayed (default: 30). Detached")
+    fmt.Println("    volumes are displayed if their last attachment was created more than N days ago (they may have been detached")
+    fmt.Println("    later: OCI does not return the detach time).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If --delete is provided without --yes, the volumes that would be deleted are only listed (dry run).")
+    fmt.Println("    If --delete --yes is provided, the volumes without attachment record created more than --min-age-days days ago")
+    fmt.Println("    are DELETED (cannot be undone). --min-age-days N is required with --delete: OCI stops returning the DETACHED")
+    fmt.Println("    attachments after a while, so a volume without attachment record may have been attached in the past.")
+    fmt.Println("    Detached volumes are never deleted: check them and delete them manually.")
+    fmt.Printf ("    If --gb-price PRICE is provided, this price per GB per month is used to estimate the cost (default: %.4f).\n", default_gb_price)
+    fmt.Printf ("    If --vpu-price PRICE is provided, this price per VPU per GB per month is used (default: %.4f).\n", default_vpu_price)
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions or deletions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// create the orphan for a volume not attached for at least days days (nil otherwise)
+// (deletable if it has no attachment record and was created at least min_age_days days ago)
+func new_orphan(attachments ocihelpers.VolumeAttachments, volume_id string, time_created time.Time, days int, min_age_days int) *orphan {
+	now := time.Now()
+	u := attachments.Usage(volume_id)
+	if !u.MaybeUnattachedFor(days, time_created, now) { return nil }
+	o := &orphan{ Status : u.Status, Deletable : u.Deletable(min_age_days, time_created, now) }
+	if n, known := u.UnattachedDays(time_created, now); known {
+		o.UnattachedSince = time_created.Format("2006-01-02")
+		o.UnattachedDays  = &n
+	} else {
+		o.LastAttached = u.LastAttached.Format("2006-01-02")
+	}
+	return o
+}
+
+// look for the block volumes and boot volumes not attached to an instance in all active compartments of a region
+// (the attachments are in the compartment of the instance, which can be different from the one of the volume)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, days int, min_age_days int, parallelism int) ([]orphan, error) {
+	bs_client, err := core.NewBlockstorageClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	bs_client.SetRegion(region)
+	compute_client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	compute_client.SetRegion(region)
+	ads, err := ocihelpers.ListAvailabilityDomains(config, region)
+	if err != nil { return nil, err }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	// attachment of a volume (recorded in the VolumeAttachments of the region after processing the compartments)
+	type attachment struct {
+		volume_id    string
+		attached     bool
+		time_created time.Time
+	}
+	type compartment_items struct {
+		volumes          []core.Volume
+		boot_volumes     []core.BootVolume
+		attachments      []attachment
+		boot_attachments []attachment
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		var cpt_items compartment_items
+
+		// block volumes and their attachments
+		volumes_request := core.ListVolumesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			volumes_request.Page = page
+			response, err := bs_client.ListVolumes(context.Background(), volumes_request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				if v.LifecycleState == core.VolumeLifecycleStateAvailable { cpt_items.volumes = append(cpt_items.volumes, v) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		attachments_request := core.ListVolumeAttachmentsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			attachments_request.Page = page
+			response, err := compute_client.ListVolumeAttachments(context.Background(), attachments_request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				attached := a.GetLifecycleState() != core.VolumeAttachmentLifecycleStateDetached
+				cpt_items.attachments = append(cpt_items.attachments, attachment{ *a.GetVolumeId(), attached, a.GetTimeCreated().Time })
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		// boot volumes and their attachments (availability domain needed)
+		for _, ad := range ads {
+			boot_volumes_request := core.ListBootVolumesRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+			err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				boot_volumes_request.Page = page
+				response, err := bs_client.ListBootVolumes(context.Background(), boot_volumes_request)
+				if err != nil { return nil, err }
+				for _, v := range response.Items {
+					if v.LifecycleState == core.BootVolumeLifecycleStateAvailable { cpt_items.boot_volumes = append(cpt_items.boot_volumes, v) }
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+
+			boot_attachments_request := core.ListBootVolumeAttachmentsRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+			err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				boot_attachments_request.Page = page
+				response, err := compute_client.ListBootVolumeAttachments(context.Background(), boot_attachments_request)
+				if err != nil { return nil, err }
+				for _, a := range response.Items {
+					attached := a.LifecycleState != core.BootVolumeAttachmentLifecycleStateDetached
+					cpt_items.boot_attachments = append(cpt_items.boot_attachments, attachment{ *a.BootVolumeId, attached, a.TimeCreated.Time })
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+		}
+		return cpt_items, nil
+	})
+	volumes          := make([]core.Volume, 0)
+	boot_volumes     := make([]core.BootVolume, 0)
+	attachments      := make(ocihelpers.VolumeAttachments)
+	boot_attachments := make(ocihelpers.VolumeAttachments)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		volumes = append(volumes, cpt_items.volumes...)
+		boot_volumes = append(boot_volumes, cpt_items.boot_volumes...)
+		for _, a := range cpt_items.attachments { attachments.Add(a.volume_id, a.attached, a.time_created) }
+		for _, a := range cpt_items.boot_attachments { boot_attachments.Add(a.volume_id, a.attached, a.time_created) }
+	}
+
+	// keep the volumes not attached for at least days days
+	orphans := make([]orphan, 0)
+	for _, v := range volumes {
+		o := new_orphan(attachments, *v.Id, v.TimeCreated.Time, days, min_age_days)
+		if o == nil { continue }
+		o.Type, o.Name, o.Id, o.SizeInGBs = "block", *v.DisplayName, *v.Id, *v.SizeInGBs
+		if v.VpusPerGB != nil { o.VpusPerGB = *v.VpusPerGB }
+		o.AvailabilityDomain, o.Region, o.CompartmentId = *v.AvailabilityDomain, region, *v.CompartmentId
+		orphans = append(orphans, *o)
+	}
+	for _, v := range boot_volumes {
+		o := new_orphan(boot_attachments, *v.Id, v.TimeCreated.Time, days, min_age_days)
+		if o == nil { continue }
+		o.Type, o.Name, o.Id, o.SizeInGBs = "boot", *v.DisplayName, *v.Id, *v.SizeInGBs
+		if v.VpusPerGB != nil { o.VpusPerGB = *v.VpusPerGB }
+		o.AvailabilityDomain, o.Region, o.CompartmentId = *v.AvailabilityDomain, region, *v.CompartmentId
+		orphans = append(orphans, *o)
+	}
+	return orphans, nil
+}
+
+// delete an orphaned block volume or boot volume
+func delete_volume(config common.ConfigurationProvider, o orphan) error {
+	client, err := core.NewBlockstorageClientWithConfigurationProvider(config)
+	if err != nil { return err }
+	client.SetRegion(o.Region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	if o.Type == "boot" {
+		_, err = client.DeleteBootVolume(context.Background(), core.DeleteBootVolumeRequest{ BootVolumeId : common.String(o.Id), RequestMetadata : metadata })
+	} else {
+		_, err = client.DeleteVolume(context.Background(), core.DeleteVolumeRequest{ VolumeId : common.String(o.Id), RequestMetadata : metadata })
+	}
+	return err
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	days            := flag.Int("days", 30, "minimum number of days since the volume was created (no attachment record) or last attached (detached)")
+	delete_volumes  := flag.Bool("delete", false, "delete the volumes without attachment record (with --yes)")
+	min_age_days    := flag.Int("min-age-days", 0, "minimum age in days of the volumes to delete (required with --delete)")
+	yes             := flag.Bool("yes", false, "confirm the deletion of the orphaned volumes")
+	gb_price        := flag.Float64("gb-price", default_gb_price, "storage price per GB per month")
+	vpu_price       := flag.Float64("vpu-price", default_vpu_price, "performance price per VPU per GB per month")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *days < 0 || *parallelism < 1 { usage() }
+	if *yes && !*delete_volumes { usage() }
+	if *min_age_days < 0 || (*delete_volumes && *min_age_days == 0) { usage() }
+	if *delete_volumes && format != "text" { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Look for orphaned volumes in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("orphaned volumes", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *days, *min_age_days, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	orphans := make([]orphan, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, o := range r.Value.([]orphan) {
+			o.CompartmentPath = paths[o.CompartmentId]
+			o.MonthlyCost     = float64(o.SizeInGBs) * (*gb_price + float64(o.VpusPerGB) * *vpu_price)
+			orphans = append(orphans, o)
+		}
+	}
+	sort.SliceStable(orphans, func(i, j int) bool { return orphans[i].MonthlyCost > orphans[j].MonthlyCost })
+
+	// Display the list of orphaned volumes (most expensive first)
+	total_gb, total_cost := int64(0), 0.0
+	for _, o := range orphans {
+		total_gb   += o.SizeInGBs
+		total_cost += o.MonthlyCost
+	}
+	if format == "json" {
+		output, err := json.MarshalIndent(orphans, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "type", "name", "size_gb", "vpus_per_gb", "status", "unattached_since", "days", "last_attached", "deletable", "monthly_cost", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		for _, o := range orphans {
+			unattached_days := ""
+			if o.UnattachedDays != nil { unattached_days = fmt.Sprintf("%d", *o.UnattachedDays) }
+			row := []string{ o.Type, o.Name, fmt.Sprintf("%d", o.SizeInGBs), fmt.Sprintf("%d", o.VpusPerGB), o.Status, o.UnattachedSince, unattached_days, o.LastAttached,
+				fmt.Sprintf("%t", o.Deletable), fmt.Sprintf("%.2f", o.MonthlyCost), o.CompartmentPath }
+			if all_regions { row = append(row, o.Region) }
+			table.AddRow(append(row, o.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d orphaned volumes (not attached for more than %d days), %d GB, estimated monthly cost %.2f\n", len(orphans), *days, total_gb, total_cost)
+		}
+	}
+
+	// Delete the volumes without attachment record older than --min-age-days (the detach time of detached volumes
+	// is unknown: they are never deleted)
+	nb_deletable := 0
+	for _, o := range orphans {
+		if o.Deletable { nb_deletable++ }
+	}
+	if *delete_volumes && len(orphans) > 0 {
+		fmt.Println ("")
+		if nb_deletable < len(orphans) {
+			fmt.Printf ("%d volumes are not deleted (detached, or created less than %d days ago): check them and delete them manually\n", len(orphans) - nb_deletable, *min_age_days)
+		}
+		if nb_deletable == 0 {
+			fmt.Printf ("No volume to delete (only volumes without attachment record created more than %d days ago are deleted)\n", *min_age_days)
+		} else if !*yes {
+			fmt.Printf ("Re-run the script with --delete --yes to actually DELETE the %d volumes marked deletable above (cannot be undone)\n", nb_deletable)
+		} else {
+			for _, o := range orphans {
+				if !o.Deletable { continue }
+				fmt.Printf ("DELETING %s volume %s (%s) in region %s\n", o.Type, o.Name, o.Id, o.Region)
+				if err := delete_volume(config, o); err != nil {
+					fmt.Fprintf (os.Stderr, "ERROR: %s: %s\n", o.Name, err)
+					nb_failed++
+				}
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed or some volumes could not be deleted
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,311 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the compute capacity reservations in all compartments of a OCI tenant, with the number of
+// reserved and used instances per availability domain and shape, using OCI Go SDK
+// Reservations with 0% utilization (still billed for the reserved capacity) are displayed in red
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       capacity reservations are not available in the OCI Go SDK version used, so the compute REST API
+//       is called directly
+//       deleted reservations are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- constants
+const compute_endpoint    = "https://iaas.{region}.{secondLevelDomain}"
+const compute_api_version = "20160918"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// capacity reservation returned by the compute REST API
+type reservation_rest struct {
+	Id                         string `json:"id"`
+	DisplayName                string `json:"displayName"`
+	CompartmentId              string `json:"compartmentId"`
+	AvailabilityDomain         string `json:"availabilityDomain"`
+	LifecycleState             string `json:"lifecycleState"`
+	IsDefaultReservation       bool   `json:"isDefaultReservation"`
+	ReservedInstanceCount      int    `json:"reservedInstanceCount"`
+	UsedInstanceCount          int    `json:"usedInstanceCount"`
+	InstanceReservationConfigs []struct {
+		InstanceShape       string `json:"instanceShape"`
+		FaultDomain         string `json:"faultDomain"`
+		ReservedCount       int    `json:"reservedCount"`
+		UsedCount           int    `json:"usedCount"`
+		InstanceShapeConfig *struct {
+			Ocpus       float64 `json:"ocpus"`
+			MemoryInGBs float64 `json:"memoryInGBs"`
+		} `json:"instanceShapeConfig"`
+	} `json:"instanceReservationConfigs"`
+}
+
+type shape_json struct {
+	Shape         string  `json:"shape"`
+	FaultDomain   string  `json:"fault_domain,omitempty"`
+	Ocpus         float64 `json:"ocpus,omitempty"`
+	MemoryInGBs   float64 `json:"memory_in_gbs,omitempty"`
+	ReservedCount int     `json:"reserved_count"`
+	UsedCount     int     `json:"used_count"`
+}
+
+type reservation_json struct {
+	Name               string       `json:"name"`
+	Id                 string       `json:"id"`
+	AvailabilityDomain string       `json:"availability_domain"`
+	LifecycleState     string       `json:"lifecycle_state"`
+	IsDefault          bool         `json:"is_default"`
+	ReservedCount      int          `json:"reserved_count"`
+	UsedCount          int          `json:"used_count"`
+	Percent            float64      `json:"utilization_percent"`
+	Shapes             []shape_json `json:"shapes"`
+	Region             string       `json:"region"`
+	CompartmentId      string       `json:"compartment_id"`
+	CompartmentPath    string       `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each shape (and fault domain) of each reservation. The reservations with 0% utilization")
+    fmt.Println("    (billed but not used) are displayed in red.")
+    fmt.Println("    If --unused-only is provided, only the reservations with 0% utilization are displayed.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// short name of an availability domain (ex: AD-1)
+func short_ad(ad string) string {
+	if i := strings.LastIndex(ad, "-AD-"); i >= 0 { return ad[i+1:] }
+	return ad
+}
+
+// list the capacity reservations (not deleted) of a compartment, with their reserved and used counts per shape
+func list_reservations(client *ocihelpers.RestClient, cpt_id string, region string) ([]reservation_json, error) {
+	ids := make([]string, 0)
+	query := url.Values{ "compartmentId" : { cpt_id } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []reservation_rest
+		next_page, err := client.Get("/computeCapacityReservations", query, &items)
+		if err != nil { return nil, err }
+		for _, r := range items {
+			if r.LifecycleState != "DELETED" && r.LifecycleState != "DELETING" { ids = append(ids, r.Id) }
+		}
+		return next_page, nil
+	})
+	if err != nil { return nil, err }
+
+	reservations := make([]reservation_json, 0, len(ids))
+	for _, id := range ids {
+		var r reservation_rest
+		if _, err := client.Get("/computeCapacityReservations/"+id, nil, &r); err != nil { return nil, err }
+		item := reservation_json{ Name : r.DisplayName, Id : r.Id, AvailabilityDomain : r.AvailabilityDomain, LifecycleState : r.LifecycleState, IsDefault : r.IsDefaultReservation,
+			ReservedCount : r.ReservedInstanceCount, UsedCount : r.UsedInstanceCount, Shapes : make([]shape_json, 0), Region : region, CompartmentId : r.CompartmentId }
+		if item.ReservedCount > 0 { item.Percent = 100 * float64(item.UsedCount) / float64(item.ReservedCount) }
+		for _, c := range r.InstanceReservationConfigs {
+			shape := shape_json{ Shape : c.InstanceShape, FaultDomain : c.FaultDomain, ReservedCount : c.ReservedCount, UsedCount : c.UsedCount }
+			if c.InstanceShapeConfig != nil { shape.Ocpus, shape.MemoryInGBs = c.InstanceShapeConfig.Ocpus, c.InstanceShapeConfig.MemoryInGBs }
+			item.Shapes = append(item.Shapes, shape)
+		}
+		reservations = append(reservations, item)
+	}
+	return reservations, nil
+}
+
+// list the capacity reservations in all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]reservation_json, error) {
+	client, err := ocihelpers.NewRestClient(config, compute_endpoint, compute_api_version, region)
+	if err != nil { return nil, err }
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return list_reservations(client, cpt_id, region)
+	})
+	reservations := make([]reservation_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		reservations = append(reservations, r.Value.([]reservation_json)...)
+	}
+	return reservations, nil
+}
+
+// is a reservation billed without being used
+func is_unused(r reservation_json) bool {
+	return r.ReservedCount > 0 && r.UsedCount == 0
+}
+
+// display the table in text format, with the rows of unused reservations in red
+func display_text(table ocihelpers.Table, unused []bool) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(unused) { break }
+		if unused[i] { line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL }
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list capacity reservations in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list capacity reservations in all subscribed regions")
+	unused_only     := flag.Bool("unused-only", false, "only display the reservations with 0% utilization")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the capacity reservations in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("capacity reservations", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	nb_unused := 0
+	reservations := make([]reservation_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, item := range r.Value.([]reservation_json) {
+			if is_unused(item) { nb_unused++ } else if *unused_only { continue }
+			item.CompartmentPath = paths[item.CompartmentId]
+			reservations = append(reservations, item)
+		}
+	}
+	sort.SliceStable(reservations, func(i, j int) bool {
+		if reservations[i].Region != reservations[j].Region { return reservations[i].Region < reservations[j].Region }
+		if reservations[i].AvailabilityDomain != reservations[j].AvailabilityDomain { return reservations[i].AvailabilityDomain < reservations[j].AvailabilityDomain }
+		return reservations[i].Name < reservations[j].Name
+	})
+
+	// Display the results (a row per shape and fault domain)
+	if format == "json" {
+		output, err := json.MarshalIndent(reservations, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "reservation", "ad", "shape", "fault_domain", "ocpus", "memory_gb", "reserved", "used", "utilization_%", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		unused := make([]bool, 0)
+		total_reserved, total_used := 0, 0
+		for _, r := range reservations {
+			total_reserved += r.ReservedCount
+			total_used     += r.UsedCount
+			shapes := r.Shapes
+			if len(shapes) == 0 { shapes = []shape_json{ {} } }
+			for _, s := range shapes {
+				percent := ""
+				if s.ReservedCount > 0 { percent = fmt.Sprintf("%.1f", 100 * float64(s.UsedCount) / float64(s.ReservedCount)) }
+				row := []string{ r.Name, short_ad(r.AvailabilityDomain), s.Shape, s.FaultDomain, fmt.Sprintf("%g", s.Ocpus), fmt.Sprintf("%g", s.MemoryInGBs),
+					fmt.Sprintf("%d", s.ReservedCount), fmt.Sprintf("%d", s.UsedCount), percent, r.CompartmentPath }
+				if all_regions { row = append(row, r.Region) }
+				table.AddRow(append(row, r.Id)...)
+				unused = append(unused, is_unused(r))
+			}
+		}
+		if format != "text" {
+			ocihelpers.FatalIfError(table.Print(format))
+		} else {
+			display_text(table, unused)
+			if !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d capacity reservations, %d instances reserved, %d used, %d reservations with 0%% utilization\n", len(reservations), total_reserved, total_used, nb_unused)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,309 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the dedicated virtual machine hosts in all compartments of a OCI tenant using OCI Go SDK
+// For each host, it displays shape, placement (availability domain, fault domain), total and remaining
+// OCPUs and memory, and the instances placed on it
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the memory of the hosts is not available in the OCI Go SDK version used, so the compute REST API
+//       is called directly to get it
+//       deleted hosts and terminated instances are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- constants
+const compute_endpoint    = "https://iaas.{region}.{secondLevelDomain}"
+const compute_api_version = "20160918"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type instance_json struct {
+	Name            string  `json:"name"`
+	Id              string  `json:"id"`
+	Shape           string  `json:"shape"`
+	Ocpus           float32 `json:"ocpus"`
+	MemoryInGBs     float32 `json:"memory_in_gbs"`
+	LifecycleState  string  `json:"lifecycle_state"`
+	CompartmentPath string  `json:"compartment_path"`
+}
+
+type host_json struct {
+	Name                 string          `json:"name"`
+	Id                   string          `json:"id"`
+	Shape                string          `json:"shape"`
+	AvailabilityDomain   string          `json:"availability_domain"`
+	FaultDomain          string          `json:"fault_domain"`
+	LifecycleState       string          `json:"lifecycle_state"`
+	TotalOcpus           float32         `json:"total_ocpus"`
+	RemainingOcpus       float32         `json:"remaining_ocpus"`
+	TotalMemoryInGBs     float32         `json:"total_memory_in_gbs"`
+	RemainingMemoryInGBs float32         `json:"remaining_memory_in_gbs"`
+	Instances            []instance_json `json:"instances"`
+	Region               string          `json:"region"`
+	CompartmentId        string          `json:"compartment_id"`
+	CompartmentPath      string          `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If --instances is provided, a row is displayed for each instance placed on a host (instead of a row per host).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// short name of an availability domain (ex: AD-1)
+func short_ad(ad string) string {
+	if i := strings.LastIndex(ad, "-AD-"); i >= 0 { return ad[i+1:] }
+	return ad
+}
+
+// float value of an optional float
+func float_value(f *float32) float32 {
+	if f == nil { return 0 }
+	return *f
+}
+
+// list the dedicated VM hosts (not deleted) of a compartment
+func list_hosts(client core.ComputeClient, compartment_id string) ([]core.DedicatedVmHostSummary, error) {
+	hosts := make([]core.DedicatedVmHostSummary, 0)
+	request := core.ListDedicatedVmHostsRequest{ CompartmentId : common.String(compartment_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListDedicatedVmHosts(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, h := range response.Items {
+			if h.LifecycleState != core.DedicatedVmHostSummaryLifecycleStateDeleted { hosts = append(hosts, h) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return hosts, err
+}
+
+// list the instances (not terminated) of a compartment placed on a dedicated VM host
+func list_host_instances(client core.ComputeClient, compartment_id string) ([]core.Instance, error) {
+	instances := make([]core.Instance, 0)
+	request := core.ListInstancesRequest{ CompartmentId : common.String(compartment_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListInstances(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, i := range response.Items {
+			if i.LifecycleState != core.InstanceLifecycleStateTerminated && i.DedicatedVmHostId != nil { instances = append(instances, i) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return instances, err
+}
+
+// list the dedicated VM hosts of all active compartments of a region, with the instances placed on them
+// (the instances can be in another compartment than their host)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, paths map[string]string, region string, parallelism int) ([]host_json, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	rest_client, err := ocihelpers.NewRestClient(config, compute_endpoint, compute_api_version, region)
+	if err != nil { return nil, err }
+
+	type compartment_items struct {
+		hosts     []host_json
+		instances []core.Instance
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		var cpt_items compartment_items
+		cpt_hosts, err := list_hosts(client, cpt_id)
+		if err != nil { return nil, err }
+		for _, h := range cpt_hosts {
+			item := host_json{ Name : *h.DisplayName, Id : *h.Id, Shape : *h.DedicatedVmHostShape, AvailabilityDomain : *h.AvailabilityDomain, LifecycleState : string(h.LifecycleState),
+				TotalOcpus : float_value(h.TotalOcpus), RemainingOcpus : float_value(h.RemainingOcpus), Instances : make([]instance_json, 0), Region : region, CompartmentId : *h.CompartmentId, CompartmentPath : paths[*h.CompartmentId] }
+			if h.FaultDomain != nil { item.FaultDomain = *h.FaultDomain }
+			var memory struct {
+				TotalMemoryInGBs     float32 `json:"totalMemoryInGBs"`
+				RemainingMemoryInGBs float32 `json:"remainingMemoryInGBs"`
+			}
+			if _, err := rest_client.Get("/dedicatedVmHosts/"+item.Id, nil, &memory); err != nil { return nil, err }
+			item.TotalMemoryInGBs, item.RemainingMemoryInGBs = memory.TotalMemoryInGBs, memory.RemainingMemoryInGBs
+			cpt_items.hosts = append(cpt_items.hosts, item)
+		}
+		cpt_items.instances, err = list_host_instances(client, cpt_id)
+		return cpt_items, err
+	})
+	hosts := make([]host_json, 0)
+	instances := make([]core.Instance, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		hosts = append(hosts, cpt_items.hosts...)
+		instances = append(instances, cpt_items.instances...)
+	}
+
+	// instances placed on each host
+	host_index := make(map[string]int)
+	for n, h := range hosts { host_index[h.Id] = n }
+	for _, i := range instances {
+		n, found := host_index[*i.DedicatedVmHostId]
+		if !found { continue }
+		item := instance_json{ Name : *i.DisplayName, Id : *i.Id, Shape : *i.Shape, LifecycleState : string(i.LifecycleState), CompartmentPath : paths[*i.CompartmentId] }
+		if i.ShapeConfig != nil { item.Ocpus, item.MemoryInGBs = float_value(i.ShapeConfig.Ocpus), float_value(i.ShapeConfig.MemoryInGBs) }
+		hosts[n].Instances = append(hosts[n].Instances, item)
+	}
+	for n := range hosts {
+		sort.Slice(hosts[n].Instances, func(i, j int) bool { return hosts[n].Instances[i].Name < hosts[n].Instances[j].Name })
+	}
+	return hosts, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list dedicated VM hosts in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list dedicated VM hosts in all subscribed regions")
+	by_instance     := flag.Bool("instances", false, "display a row per instance placed on a host")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the dedicated VM hosts in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("dedicated VM hosts", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, paths, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	hosts := make([]host_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		hosts = append(hosts, r.Value.([]host_json)...)
+	}
+	sort.SliceStable(hosts, func(i, j int) bool {
+		if hosts[i].CompartmentPath != hosts[j].CompartmentPath { return hosts[i].CompartmentPath < hosts[j].CompartmentPath }
+		return hosts[i].Name < hosts[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(hosts, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "host", "shape", "ad", "fault_domain", "ocpus", "remaining_ocpus", "memory_gb", "remaining_memory_gb" } }
+		if *by_instance {
+			table.Headers = append(table.Headers, "instance", "instance_shape", "instance_ocpus", "instance_memory_gb", "instance_state", "instance_compartment")
+		} else {
+			table.Headers = append(table.Headers, "instances", "compartment_path")
+		}
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_instances := 0
+		for _, h := range hosts {
+			nb_instances += len(h.Instances)
+			row := []string{ h.Name, h.Shape, short_ad(h.AvailabilityDomain), h.FaultDomain, fmt.Sprintf("%g", h.TotalOcpus), fmt.Sprintf("%g", h.RemainingOcpus), fmt.Sprintf("%g", h.TotalMemoryInGBs), fmt.Sprintf("%g", h.RemainingMemoryInGBs) }
+			end := []string{}
+			if all_regions { end = append(end, h.Region) }
+			end = append(end, h.Id)
+			if !*by_instance {
+				names := make([]string, 0, len(h.Instances))
+				for _, i := range h.Instances { names = append(names, i.Name) }
+				table.AddRow(append(append(row, strings.Join(names, ", "), h.CompartmentPath), end...)...)
+				continue
+			}
+			if len(h.Instances) == 0 { table.AddRow(append(append(row, "", "", "", "", "", ""), end...)...) }
+			for _, i := range h.Instances {
+				instance := []string{ i.Name, i.Shape, fmt.Sprintf("%g", i.Ocpus), fmt.Sprintf("%g", i.MemoryInGBs), i.LifecycleState, i.CompartmentPath }
+				table.AddRow(append(append(append([]string{}, row...), instance...), end...)...)
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d dedicated VM hosts, %d instances placed on them\n", len(hosts), nb_instances)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
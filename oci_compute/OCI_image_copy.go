@@ -0,0 +1,250 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script copies a custom compute image to another region using OCI Go SDK:
+// the image is exported to an object storage bucket, the exported object is copied to a bucket of the destination
+// region, and a new image is imported from this object in the destination region
+// The progress of the work requests is displayed until the new image is available
+// Note: OCI tenant given by an OCI CLI PROFILE, source region given by the OCID of the image
+//       the buckets must exist in the source and destination regions
+//       the exported objects are deleted at the end, unless --keep-objects is provided
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/objectstorage"
+	"github.com/oracle/oci-go-sdk/workrequests"
+)
+
+// -- constants
+const poll_interval   = 30 * time.Second     // interval between 2 checks of a work request
+const default_timeout = 240                  // maximum duration of each step (minutes)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] IMAGE_OCID DEST_REGION [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip IMAGE_OCID DEST_REGION\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    --bucket BUCKET is mandatory: bucket of the source region where the image is exported.")
+    fmt.Println("    If --dest-bucket BUCKET is provided, the exported image is copied to this bucket of the destination region")
+    fmt.Println("    (by default, a bucket with the same name as the source bucket).")
+    fmt.Println("    If --name NAME is provided, the new image is named NAME (by default, the name of the source image).")
+    fmt.Println("    If --compartment OCID is provided, the new image is created in this compartment (by default, the compartment")
+    fmt.Println("    of the source image).")
+    fmt.Println("    If --keep-objects is provided, the exported objects are not deleted from the buckets at the end.")
+    fmt.Printf ("    If --timeout MINUTES is provided, each step (export, copy, import) fails after MINUTES minutes instead of %d.\n", default_timeout)
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// display a progress message
+func progress(format string, args ...interface{}) {
+	fmt.Printf ("%s  %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// region of a resource, given by its OCID (ex: ocid1.image.oc1.eu-frankfurt-1.xxx or ocid1.image.oc1.fra.xxx)
+func ocid_region(ocid string) string {
+	fields := strings.Split(ocid, ".")
+	if len(fields) < 5 || fields[3] == "" { return "" }
+	return string(common.StringToRegion(fields[3]))
+}
+
+// percentage of a work request
+func percent(p *float32) float32 {
+	if p == nil { return 0 }
+	return *p
+}
+
+// wait for the end of a compute work request (export or import of an image)
+func wait_compute_work_request(client workrequests.WorkRequestClient, step string, work_request_id string, timeout time.Duration) error {
+	last := ""
+	request := workrequests.GetWorkRequestRequest{ WorkRequestId : common.String(work_request_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	return ocihelpers.WaitFor(poll_interval, timeout, func() (bool, error) {
+		response, err := client.GetWorkRequest(context.Background(), request)
+		if err != nil { return false, err }
+		status := fmt.Sprintf("%s %.0f%%", response.Status, percent(response.PercentComplete))
+		if status != last { progress("%s: %s", step, status) }
+		last = status
+		switch response.Status {
+		case workrequests.WorkRequestStatusSucceeded: return true, nil
+		case workrequests.WorkRequestStatusFailed, workrequests.WorkRequestStatusCanceled: return false, fmt.Errorf("%s: work request %s %s", step, work_request_id, response.Status)
+		}
+		return false, nil
+	})
+}
+
+// wait for the end of an object storage work request (copy of an object)
+func wait_os_work_request(client objectstorage.ObjectStorageClient, step string, work_request_id string, timeout time.Duration) error {
+	last := ""
+	request := objectstorage.GetWorkRequestRequest{ WorkRequestId : common.String(work_request_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	return ocihelpers.WaitFor(poll_interval, timeout, func() (bool, error) {
+		response, err := client.GetWorkRequest(context.Background(), request)
+		if err != nil { return false, err }
+		status := fmt.Sprintf("%s %.0f%%", response.Status, percent(response.PercentComplete))
+		if status != last { progress("%s: %s", step, status) }
+		last = status
+		switch response.Status {
+		case objectstorage.WorkRequestStatusCompleted: return true, nil
+		case objectstorage.WorkRequestStatusFailed, objectstorage.WorkRequestStatusCanceled: return false, fmt.Errorf("%s: work request %s %s", step, work_request_id, response.Status)
+		}
+		return false, nil
+	})
+}
+
+// delete an object (exported image) from a bucket
+func delete_object(client objectstorage.ObjectStorageClient, namespace string, bucket string, object_name string) {
+	request := objectstorage.DeleteObjectRequest{
+		NamespaceName   : common.String(namespace),
+		BucketName      : common.String(bucket),
+		ObjectName      : common.String(object_name),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	if _, err := client.DeleteObject(context.Background(), request); err != nil {
+		fmt.Fprintf (os.Stderr, "WARNING: cannot delete object %s from bucket %s: %s\n", object_name, bucket, err)
+		return
+	}
+	progress("object %s deleted from bucket %s", object_name, bucket)
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	bucket         := flag.String("bucket", "", "bucket of the source region where the image is exported")
+	dest_bucket    := flag.String("dest-bucket", "", "bucket of the destination region (default: same name as --bucket)")
+	name           := flag.String("name", "", "name of the new image (default: name of the source image)")
+	compartment_id := flag.String("compartment", "", "OCID of the compartment of the new image (default: compartment of the source image)")
+	keep_objects   := flag.Bool("keep-objects", false, "do not delete the exported objects at the end")
+	timeout_min    := flag.Int("timeout", default_timeout, "maximum duration of each step in minutes")
+	flag.Parse()
+	if *bucket == "" || *timeout_min <= 0 { usage() }
+	if *dest_bucket == "" { *dest_bucket = *bucket }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 2) { usage() }
+	} else {
+		if (flag.NArg() < 2 || flag.NArg() > 3) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args()[2:])
+	}
+	image_id, dest_region := flag.Arg(0), string(common.StringToRegion(flag.Arg(1)))
+	source_region := ocid_region(image_id)
+	if !strings.HasPrefix(image_id, "ocid1.image.") || source_region == "" { ocihelpers.Fatal ("invalid image OCID %s", image_id) }
+	if source_region == dest_region { ocihelpers.Fatal ("the image %s is already in region %s", image_id, dest_region) }
+	timeout := time.Duration(*timeout_min) * time.Minute
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Clients in the source and destination regions
+	compute_client, err := core.NewComputeClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	compute_client.SetRegion(source_region)
+	dest_compute_client, err := core.NewComputeClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	dest_compute_client.SetRegion(dest_region)
+	wr_client, err := workrequests.NewWorkRequestClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	wr_client.SetRegion(source_region)
+	dest_wr_client, err := workrequests.NewWorkRequestClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	dest_wr_client.SetRegion(dest_region)
+	os_client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	os_client.SetRegion(source_region)
+	dest_os_client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	dest_os_client.SetRegion(dest_region)
+	retry := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	// Get the source image and the object storage namespace
+	image, err := compute_client.GetImage(context.Background(), core.GetImageRequest{ ImageId : common.String(image_id), RequestMetadata : retry })
+	ocihelpers.FatalIfError(err)
+	if image.CompartmentId == nil { ocihelpers.Fatal ("%s is a platform image: it is already available in all regions", image_id) }
+	if *name == "" { *name = *image.DisplayName }
+	if *compartment_id == "" { *compartment_id = *image.CompartmentId }
+	namespace, err := os_client.GetNamespace(context.Background(), objectstorage.GetNamespaceRequest{ RequestMetadata : retry })
+	ocihelpers.FatalIfError(err)
+	object_name := fmt.Sprintf("%s_%s.oci", strings.ReplaceAll(*image.DisplayName, " ", "_"), time.Now().Format("20060102_150405"))
+	progress("copying image %s (%s) from region %s to region %s", *image.DisplayName, image_id, source_region, dest_region)
+
+	// 1. Export the image to the bucket of the source region
+	export, err := compute_client.ExportImage(context.Background(), core.ExportImageRequest{
+		ImageId            : common.String(image_id),
+		ExportImageDetails : core.ExportImageViaObjectStorageTupleDetails{ NamespaceName : namespace.Value, BucketName : bucket, ObjectName : common.String(object_name) },
+		RequestMetadata    : retry,
+	})
+	ocihelpers.FatalIfError(err)
+	progress("export: exporting image to object %s in bucket %s", object_name, *bucket)
+	ocihelpers.FatalIfError(wait_compute_work_request(wr_client, "export", *export.OpcWorkRequestId, timeout))
+
+	// 2. Copy the exported object to the bucket of the destination region
+	copy_response, err := os_client.CopyObject(context.Background(), objectstorage.CopyObjectRequest{
+		NamespaceName     : namespace.Value,
+		BucketName        : bucket,
+		CopyObjectDetails : objectstorage.CopyObjectDetails{
+			SourceObjectName      : common.String(object_name),
+			DestinationRegion     : common.String(dest_region),
+			DestinationNamespace  : namespace.Value,
+			DestinationBucket     : dest_bucket,
+			DestinationObjectName : common.String(object_name),
+		},
+		RequestMetadata   : retry,
+	})
+	ocihelpers.FatalIfError(err)
+	progress("copy: copying object to bucket %s in region %s", *dest_bucket, dest_region)
+	ocihelpers.FatalIfError(wait_os_work_request(os_client, "copy", *copy_response.OpcWorkRequestId, timeout))
+
+	// 3. Import the new image in the destination region
+	create, err := dest_compute_client.CreateImage(context.Background(), core.CreateImageRequest{
+		CreateImageDetails : core.CreateImageDetails{
+			CompartmentId      : compartment_id,
+			DisplayName        : name,
+			ImageSourceDetails : core.ImageSourceViaObjectStorageTupleDetails{
+				NamespaceName : namespace.Value,
+				BucketName    : dest_bucket,
+				ObjectName    : common.String(object_name),
+			},
+			FreeformTags       : image.FreeformTags,
+			DefinedTags        : image.DefinedTags,
+		},
+		RequestMetadata    : retry,
+	})
+	ocihelpers.FatalIfError(err)
+	progress("import: image %s being created in region %s (%s)", *name, dest_region, *create.Id)
+	ocihelpers.FatalIfError(wait_compute_work_request(dest_wr_client, "import", *create.OpcWorkRequestId, timeout))
+
+	// Delete the exported objects
+	if !*keep_objects {
+		delete_object(os_client, *namespace.Value, *bucket, object_name)
+		delete_object(dest_os_client, *namespace.Value, *dest_bucket, object_name)
+	}
+	progress("image %s available in region %s: %s", *name, dest_region, *create.Id)
+}
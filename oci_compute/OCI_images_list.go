@@ -0,0 +1,323 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the custom compute images in all compartments of a OCI tenant using OCI Go SDK
+// For each image, it displays name, size, operating system, base image, creation date and compatible shapes
+// It can also list the platform images currently available in the region (--platform)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       deleted images are ignored
+//       the base image of a custom image is flagged as outdated when it is no longer in the list of current
+//       platform images of the region
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type image_json struct {
+	Name                   string   `json:"name"`
+	Id                     string   `json:"id"`
+	OperatingSystem        string   `json:"operating_system"`
+	OperatingSystemVersion string   `json:"operating_system_version"`
+	SizeInGBs              float64  `json:"size_in_gbs"`
+	LifecycleState         string   `json:"lifecycle_state"`
+	TimeCreated            string   `json:"time_created"`
+	AgeDays                int      `json:"age_days"`
+	BaseImage              string   `json:"base_image,omitempty"`
+	BaseImageId            string   `json:"base_image_id,omitempty"`
+	BaseImageOutdated      bool     `json:"base_image_outdated"`
+	CompatibleShapes       []string `json:"compatible_shapes,omitempty"`
+	Region                 string   `json:"region"`
+	CompartmentId          string   `json:"compartment_id,omitempty"`
+	CompartmentPath        string   `json:"compartment_path,omitempty"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If --platform is provided, the platform images currently available are listed instead of the custom images.")
+    fmt.Println("    If --older-than DAYS is provided, only the images created more than DAYS days ago are listed.")
+    fmt.Println("    If --shapes is provided, the compatible shapes of the custom images are also displayed.")
+    fmt.Println("    If -a or --all-regions is provided, the images of all subscribed regions are listed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the images (not deleted) returned for a compartment: custom images of the compartment and platform images
+func list_images(client core.ComputeClient, compartment_id string) ([]core.Image, error) {
+	images := make([]core.Image, 0)
+	request := core.ListImagesRequest{
+		CompartmentId   : common.String(compartment_id),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListImages(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, i := range response.Items {
+			if i.LifecycleState != core.ImageLifecycleStateDeleted { images = append(images, i) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return images, err
+}
+
+// list the shapes compatible with an image
+func list_compatible_shapes(client core.ComputeClient, image_id string) ([]string, error) {
+	shapes := make([]string, 0)
+	request := core.ListImageShapeCompatibilityEntriesRequest{ ImageId : common.String(image_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListImageShapeCompatibilityEntries(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, s := range response.Items { shapes = append(shapes, *s.Shape) }
+		return response.OpcNextPage, nil
+	})
+	sort.Strings(shapes)
+	return shapes, err
+}
+
+// convert an image to its JSON representation
+func image_item(i core.Image, region string) image_json {
+	item := image_json{
+		Id                     : *i.Id,
+		OperatingSystem        : *i.OperatingSystem,
+		OperatingSystemVersion : *i.OperatingSystemVersion,
+		LifecycleState         : string(i.LifecycleState),
+		TimeCreated            : i.TimeCreated.Format("2006-01-02"),
+		AgeDays                : ocihelpers.DaysSince(i.TimeCreated.Time),
+		Region                 : region,
+	}
+	if i.DisplayName != nil { item.Name = *i.DisplayName }
+	if i.SizeInMBs != nil { item.SizeInGBs = float64(*i.SizeInMBs) / 1024 }
+	if i.CompartmentId != nil { item.CompartmentId = *i.CompartmentId }
+	return item
+}
+
+// list the custom images of all active compartments (or the platform images) of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, platform bool, show_shapes bool, parallelism int) ([]image_json, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	// platform images (no compartment), returned whatever the compartment
+	all_images, err := list_images(client, tree.TenancyOCID)
+	if err != nil { return nil, err }
+	items := make([]image_json, 0)
+	platform_names := make(map[string]string)
+	for _, i := range all_images {
+		if i.CompartmentId != nil { continue }
+		platform_names[*i.Id] = *i.DisplayName
+		if platform { items = append(items, image_item(i, region)) }
+	}
+	if platform { return items, nil }
+
+	// custom images of each compartment
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		images := all_images
+		if cpt_id != tree.TenancyOCID {
+			var err error
+			images, err = list_images(client, cpt_id)
+			if err != nil { return nil, err }
+		}
+		cpt_items := make([]image_json, 0)
+		for _, i := range images {
+			if i.CompartmentId == nil { continue }
+			item := image_item(i, region)
+			if i.BaseImageId != nil { item.BaseImageId = *i.BaseImageId }
+			if show_shapes {
+				var err error
+				item.CompatibleShapes, err = list_compatible_shapes(client, *i.Id)
+				if err != nil { return nil, err }
+			}
+			cpt_items = append(cpt_items, item)
+		}
+		return cpt_items, nil
+	})
+	custom_names := make(map[string]string)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		for _, item := range r.Value.([]image_json) {
+			custom_names[item.Id] = item.Name
+			items = append(items, item)
+		}
+	}
+
+	// name of the base images: current platform image, other custom image, or old platform image (outdated)
+	old_names := make(map[string]string)
+	for n := range items {
+		base_id := items[n].BaseImageId
+		if base_id == "" { continue }
+		if name, found := platform_names[base_id]; found { items[n].BaseImage = name; continue }
+		if name, found := custom_names[base_id]; found { items[n].BaseImage = name; continue }
+		items[n].BaseImageOutdated = true
+		if _, found := old_names[base_id]; !found {
+			old_names[base_id] = base_id
+			response, err := client.GetImage(context.Background(), core.GetImageRequest{ ImageId : common.String(base_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+			if err == nil && response.DisplayName != nil { old_names[base_id] = *response.DisplayName }
+		}
+		items[n].BaseImage = old_names[base_id]
+	}
+	return items, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list images in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list images in all subscribed regions")
+	platform        := flag.Bool("platform", false, "list the platform images instead of the custom images")
+	older_than      := flag.Int("older-than", 0, "only list the images created more than this number of days ago")
+	show_shapes     := flag.Bool("shapes", false, "display the compatible shapes of the custom images")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if *older_than < 0 { usage() }
+	if *platform && *show_shapes { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of images in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("images", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *platform, *show_shapes, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	images := make([]image_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, item := range r.Value.([]image_json) {
+			if item.AgeDays < *older_than { continue }
+			if item.CompartmentId != "" { item.CompartmentPath = paths[item.CompartmentId] }
+			images = append(images, item)
+		}
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].CompartmentPath != images[j].CompartmentPath { return images[i].CompartmentPath < images[j].CompartmentPath }
+		if images[i].OperatingSystem != images[j].OperatingSystem { return images[i].OperatingSystem < images[j].OperatingSystem }
+		return images[i].TimeCreated > images[j].TimeCreated
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(images, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "name", "operating_system", "version", "created", "age_days" } }
+		if !*platform { table.Headers = append(table.Headers, "size_gb", "state", "base_image", "compartment_path") }
+		if *show_shapes { table.Headers = append(table.Headers, "compatible_shapes") }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_outdated := 0
+		for _, i := range images {
+			row := []string{ i.Name, i.OperatingSystem, i.OperatingSystemVersion, i.TimeCreated, fmt.Sprintf("%d", i.AgeDays) }
+			if !*platform {
+				base := i.BaseImage
+				if i.BaseImageOutdated {
+					base += " (outdated)"
+					nb_outdated++
+				}
+				row = append(row, fmt.Sprintf("%.1f", i.SizeInGBs), i.LifecycleState, base, i.CompartmentPath)
+			}
+			if *show_shapes { row = append(row, strings.Join(i.CompatibleShapes, " ")) }
+			if all_regions { row = append(row, i.Region) }
+			table.AddRow(append(row, i.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			if *platform {
+				fmt.Printf ("%d platform images\n", len(images))
+			} else {
+				total_gb := 0.0
+				for _, i := range images { total_gb += i.SizeInGBs }
+				fmt.Printf ("%d custom images (%.1f GB), %d with an outdated base image\n", len(images), total_gb, nb_outdated)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,427 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the instance pools in all compartments of a OCI tenant using OCI Go SDK
+// For each pool, it displays size, state, placement (availability domains, fault domains), instance configuration
+// (shape) and the attached autoscaling configuration with its policies
+// It can also list the instance configurations with the pools using them (--configurations)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       terminated instance pools are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/autoscaling"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type autoscaling_json struct {
+	Name      string   `json:"name"`
+	Id        string   `json:"id"`
+	IsEnabled bool     `json:"is_enabled"`
+	Min       int      `json:"min"`
+	Max       int      `json:"max"`
+	Policies  []string `json:"policies"`     // ex: threshold CPU_UTILIZATION>80:+1, scheduled cron(0 8 * * *):4
+}
+
+type pool_json struct {
+	Name                  string            `json:"name"`
+	Id                    string            `json:"id"`
+	LifecycleState        string            `json:"lifecycle_state"`
+	Size                  int               `json:"size"`
+	Placement             []string          `json:"placement"`      // ex: AD-1 (FAULT-DOMAIN-1 FAULT-DOMAIN-2)
+	LoadBalancers         int               `json:"load_balancers"`
+	InstanceConfiguration string            `json:"instance_configuration"`
+	InstanceConfigId      string            `json:"instance_configuration_id"`
+	Shape                 string            `json:"shape"`
+	Ocpus                 float32           `json:"ocpus,omitempty"`
+	Autoscaling           *autoscaling_json `json:"autoscaling,omitempty"`
+	Region                string            `json:"region"`
+	CompartmentId         string            `json:"compartment_id"`
+	CompartmentPath       string            `json:"compartment_path"`
+}
+
+type configuration_json struct {
+	Name            string   `json:"name"`
+	Id              string   `json:"id"`
+	Shape           string   `json:"shape"`
+	Ocpus           float32  `json:"ocpus,omitempty"`
+	ImageId         string   `json:"image_id"`
+	TimeCreated     string   `json:"time_created"`
+	Pools           []string `json:"pools"`
+	Region          string   `json:"region"`
+	CompartmentId   string   `json:"compartment_id"`
+	CompartmentPath string   `json:"compartment_path"`
+}
+
+// pools and instance configurations of a region
+type region_result struct {
+	pools          []pool_json
+	configurations []configuration_json
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If --configurations is provided, the instance configurations are listed (with the pools using them)")
+    fmt.Println("    instead of the instance pools.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// short name of an availability domain (ex: AD-1)
+func short_ad(ad string) string {
+	if i := strings.LastIndex(ad, "-AD-"); i >= 0 { return ad[i+1:] }
+	return ad
+}
+
+// int value of an optional int
+func int_value(i *int) int {
+	if i == nil { return 0 }
+	return *i
+}
+
+// list the instance pools (not terminated) of a compartment, with their details (placement, load balancers)
+func list_pools(client core.ComputeManagementClient, compartment_id string) ([]core.InstancePool, error) {
+	summaries := make([]core.InstancePoolSummary, 0)
+	request := core.ListInstancePoolsRequest{ CompartmentId : common.String(compartment_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListInstancePools(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, p := range response.Items {
+			if p.LifecycleState != core.InstancePoolSummaryLifecycleStateTerminated { summaries = append(summaries, p) }
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+	pools := make([]core.InstancePool, 0, len(summaries))
+	for _, p := range summaries {
+		response, err := client.GetInstancePool(context.Background(), core.GetInstancePoolRequest{ InstancePoolId : p.Id, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+		if err != nil { return nil, err }
+		pools = append(pools, response.InstancePool)
+	}
+	return pools, nil
+}
+
+// list the instance configurations of a compartment, with their details (shape, image)
+func list_configurations(client core.ComputeManagementClient, compartment_id string) ([]core.InstanceConfiguration, error) {
+	ids := make([]string, 0)
+	request := core.ListInstanceConfigurationsRequest{ CompartmentId : common.String(compartment_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListInstanceConfigurations(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, c := range response.Items { ids = append(ids, *c.Id) }
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+	configurations := make([]core.InstanceConfiguration, 0, len(ids))
+	for _, id := range ids {
+		response, err := client.GetInstanceConfiguration(context.Background(), core.GetInstanceConfigurationRequest{ InstanceConfigurationId : common.String(id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+		if err != nil { return nil, err }
+		configurations = append(configurations, response.InstanceConfiguration)
+	}
+	return configurations, nil
+}
+
+// list the autoscaling configurations of a compartment, with their policies, by OCID of the autoscaled resource
+func list_autoscaling(client autoscaling.AutoScalingClient, compartment_id string) (map[string]*autoscaling_json, error) {
+	ids := make([]string, 0)
+	request := autoscaling.ListAutoScalingConfigurationsRequest{ CompartmentId : common.String(compartment_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListAutoScalingConfigurations(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, c := range response.Items { ids = append(ids, *c.Id) }
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+	configurations := make(map[string]*autoscaling_json)
+	for _, id := range ids {
+		response, err := client.GetAutoScalingConfiguration(context.Background(), autoscaling.GetAutoScalingConfigurationRequest{ AutoScalingConfigurationId : common.String(id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+		if err != nil { return nil, err }
+		c := response.AutoScalingConfiguration
+		item := &autoscaling_json{ Id : id, Min : int_value(c.MinResourceCount), Max : int_value(c.MaxResourceCount), Policies : make([]string, 0) }
+		if c.DisplayName != nil { item.Name = *c.DisplayName }
+		if c.IsEnabled != nil { item.IsEnabled = *c.IsEnabled }
+		for _, p := range c.Policies { item.Policies = append(item.Policies, describe_policy(p)) }
+		if c.Resource != nil && c.Resource.GetId() != nil { configurations[*c.Resource.GetId()] = item }
+	}
+	return configurations, nil
+}
+
+// short description of an autoscaling policy (ex: threshold CPU_UTILIZATION>80:+1, scheduled cron(0 8 * * *):4)
+func describe_policy(policy autoscaling.AutoScalingPolicy) string {
+	operators := map[autoscaling.ThresholdOperatorEnum]string{ "GT" : ">", "GTE" : ">=", "LT" : "<", "LTE" : "<=" }
+	rules := make([]string, 0)
+	description := "unknown"
+	switch p := policy.(type) {
+	case autoscaling.ThresholdPolicy:
+		for _, r := range p.Rules {
+			if r.Metric == nil || r.Metric.Threshold == nil || r.Action == nil { continue }
+			rules = append(rules, fmt.Sprintf("%s%s%d:%+d", r.Metric.MetricType, operators[r.Metric.Threshold.Operator], int_value(r.Metric.Threshold.Value), int_value(r.Action.Value)))
+		}
+		description = "threshold " + strings.Join(rules, " ")
+	case autoscaling.ScheduledPolicy:
+		schedule := "?"
+		if cron, ok := p.ExecutionSchedule.(autoscaling.CronExecutionSchedule); ok && cron.Expression != nil { schedule = *cron.Expression }
+		initial := 0
+		if p.Capacity != nil { initial = int_value(p.Capacity.Initial) }
+		description = fmt.Sprintf("scheduled cron(%s):%d", schedule, initial)
+	}
+	if enabled := policy.GetIsEnabled(); enabled != nil && !*enabled { description += " (disabled)" }
+	return description
+}
+
+// shape, OCPUs and image of an instance configuration
+func launch_details(c core.InstanceConfiguration) (string, float32, string) {
+	details, ok := c.InstanceDetails.(core.ComputeInstanceDetails)
+	if !ok || details.LaunchDetails == nil { return "", 0, "" }
+	shape, ocpus, image_id := "", float32(0), ""
+	launch := details.LaunchDetails
+	if launch.Shape != nil { shape = *launch.Shape }
+	if launch.ShapeConfig != nil && launch.ShapeConfig.Ocpus != nil { ocpus = *launch.ShapeConfig.Ocpus }
+	if source, ok := launch.SourceDetails.(core.InstanceConfigurationInstanceSourceViaImageDetails); ok && source.ImageId != nil { image_id = *source.ImageId }
+	return shape, ocpus, image_id
+}
+
+// list the instance pools and instance configurations of all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) (region_result, error) {
+	result := region_result{ make([]pool_json, 0), make([]configuration_json, 0) }
+	client, err := core.NewComputeManagementClientWithConfigurationProvider(config)
+	if err != nil { return result, err }
+	client.SetRegion(region)
+	as_client, err := autoscaling.NewAutoScalingClientWithConfigurationProvider(config)
+	if err != nil { return result, err }
+	as_client.SetRegion(region)
+
+	type compartment_items struct {
+		pools          []pool_json
+		configurations []configuration_json
+		autoscaling    map[string]*autoscaling_json
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		var cpt_items compartment_items
+		pools, err := list_pools(client, cpt_id)
+		if err != nil { return nil, err }
+		cpt_configurations, err := list_configurations(client, cpt_id)
+		if err != nil { return nil, err }
+		cpt_items.autoscaling, err = list_autoscaling(as_client, cpt_id)
+		if err != nil { return nil, err }
+		for _, c := range cpt_configurations {
+			item := configuration_json{ Id : *c.Id, TimeCreated : c.TimeCreated.Format("2006-01-02"), Pools : make([]string, 0), Region : region, CompartmentId : *c.CompartmentId }
+			if c.DisplayName != nil { item.Name = *c.DisplayName }
+			item.Shape, item.Ocpus, item.ImageId = launch_details(c)
+			cpt_items.configurations = append(cpt_items.configurations, item)
+		}
+		for _, p := range pools {
+			item := pool_json{ Id : *p.Id, LifecycleState : string(p.LifecycleState), Size : int_value(p.Size), Placement : make([]string, 0), LoadBalancers : len(p.LoadBalancers), InstanceConfigId : *p.InstanceConfigurationId, Region : region, CompartmentId : *p.CompartmentId }
+			if p.DisplayName != nil { item.Name = *p.DisplayName }
+			for _, pc := range p.PlacementConfigurations {
+				placement := short_ad(*pc.AvailabilityDomain)
+				if len(pc.FaultDomains) > 0 { placement += " (" + strings.Join(pc.FaultDomains, " ") + ")" }
+				item.Placement = append(item.Placement, placement)
+			}
+			cpt_items.pools = append(cpt_items.pools, item)
+		}
+		return cpt_items, nil
+	})
+	autoscaling_configurations := make(map[string]*autoscaling_json)
+	configurations := make(map[string]configuration_json)
+	for _, r := range results {
+		if r.Err != nil { return result, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		result.pools = append(result.pools, cpt_items.pools...)
+		for _, c := range cpt_items.configurations { configurations[c.Id] = c }
+		for id, a := range cpt_items.autoscaling { autoscaling_configurations[id] = a }
+	}
+
+	// instance configurations and autoscaling configurations of the pools (can be in other compartments)
+	for i, p := range result.pools {
+		if c, found := configurations[p.InstanceConfigId]; found {
+			result.pools[i].InstanceConfiguration, result.pools[i].Shape, result.pools[i].Ocpus = c.Name, c.Shape, c.Ocpus
+			c.Pools = append(c.Pools, p.Name)
+			configurations[p.InstanceConfigId] = c
+		}
+		result.pools[i].Autoscaling = autoscaling_configurations[p.Id]
+	}
+	for _, c := range configurations { result.configurations = append(result.configurations, c) }
+	return result, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list instance pools in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list instance pools in all subscribed regions")
+	list_configs    := flag.Bool("configurations", false, "list the instance configurations instead of the instance pools")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the instance pools and configurations in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("instance pools", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	pools := make([]pool_json, 0)
+	configurations := make([]configuration_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		pools = append(pools, r.Value.(region_result).pools...)
+		configurations = append(configurations, r.Value.(region_result).configurations...)
+	}
+	for i := range pools { pools[i].CompartmentPath = paths[pools[i].CompartmentId] }
+	for i := range configurations { configurations[i].CompartmentPath = paths[configurations[i].CompartmentId] }
+	sort.SliceStable(pools, func(i, j int) bool {
+		if pools[i].CompartmentPath != pools[j].CompartmentPath { return pools[i].CompartmentPath < pools[j].CompartmentPath }
+		return pools[i].Name < pools[j].Name
+	})
+	sort.SliceStable(configurations, func(i, j int) bool {
+		if configurations[i].CompartmentPath != configurations[j].CompartmentPath { return configurations[i].CompartmentPath < configurations[j].CompartmentPath }
+		return configurations[i].Name < configurations[j].Name
+	})
+
+	// Display the instance configurations
+	if *list_configs {
+		if format == "json" {
+			output, err := json.MarshalIndent(configurations, "", "  ")
+			ocihelpers.FatalIfError(err)
+			fmt.Println(string(output))
+		} else {
+			table := ocihelpers.Table{ Headers : []string{ "name", "shape", "ocpus", "created", "pools", "compartment_path" } }
+			if all_regions { table.Headers = append(table.Headers, "region") }
+			table.Headers = append(table.Headers, "ocid")
+			nb_unused := 0
+			for _, c := range configurations {
+				if len(c.Pools) == 0 { nb_unused++ }
+				row := []string{ c.Name, c.Shape, fmt.Sprintf("%g", c.Ocpus), c.TimeCreated, strings.Join(c.Pools, ", "), c.CompartmentPath }
+				if all_regions { row = append(row, c.Region) }
+				table.AddRow(append(row, c.Id)...)
+			}
+			ocihelpers.FatalIfError(table.Print(format))
+			if format == "text" && !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d instance configurations, %d not used by any instance pool\n", len(configurations), nb_unused)
+			}
+		}
+
+	// Display the instance pools
+	} else if format == "json" {
+		output, err := json.MarshalIndent(pools, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "name", "state", "size", "placement", "instance_configuration", "shape", "autoscaling", "min", "max", "policies", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		total_size := 0
+		for _, p := range pools {
+			total_size += p.Size
+			row := []string{ p.Name, p.LifecycleState, fmt.Sprintf("%d", p.Size), strings.Join(p.Placement, ", "), p.InstanceConfiguration, p.Shape }
+			if a := p.Autoscaling; a != nil {
+				name := a.Name
+				if !a.IsEnabled { name += " (disabled)" }
+				row = append(row, name, fmt.Sprintf("%d", a.Min), fmt.Sprintf("%d", a.Max), strings.Join(a.Policies, ", "))
+			} else {
+				row = append(row, "", "", "", "")
+			}
+			row = append(row, p.CompartmentPath)
+			if all_regions { row = append(row, p.Region) }
+			table.AddRow(append(row, p.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d instance pools, %d instances\n", len(pools), total_size)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
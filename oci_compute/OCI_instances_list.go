@@ -0,0 +1,414 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the compute instances in all compartments of a OCI tenant using OCI Go SDK
+// For each instance, it displays name, OCID, shape, availability domain, lifecycle state and compartment path
+// and optionally the private IP, public IP, subnet and NSGs of the attached VNICs
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       terminated instances are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Add --ips option to display private IP, public IP, subnet and NSGs of the VNICs
+//    2026-10-15: Add -a/--all-regions option to list instances in all subscribed regions (concurrently)
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// names of subnets and NSGs (cache, to get each of them only once, shared by the regions processed concurrently)
+var subnet_names = make(map[string]string)
+var nsg_names    = make(map[string]string)
+var names_lock   sync.Mutex
+
+// -- types
+
+// instance found in a region, with its VNICs if --ips is provided
+type instance_item struct {
+	Region   string
+	Instance core.Instance
+	Vnics    []vnic_json
+}
+
+type vnic_json struct {
+	Name      string   `json:"name"`
+	Id        string   `json:"id"`
+	IsPrimary bool     `json:"is_primary"`
+	PrivateIp string   `json:"private_ip"`
+	PublicIp  string   `json:"public_ip"`
+	Subnet    string   `json:"subnet"`
+	SubnetId  string   `json:"subnet_id"`
+	Nsgs      []string `json:"nsgs"`
+}
+
+type instance_json struct {
+	Name               string      `json:"name"`
+	Id                 string      `json:"id"`
+	Shape              string      `json:"shape"`
+	AvailabilityDomain string      `json:"availability_domain"`
+	LifecycleState     string      `json:"lifecycle_state"`
+	CompartmentId      string      `json:"compartment_id"`
+	CompartmentPath    string      `json:"compartment_path"`
+	Region             string      `json:"region"`
+	TimeCreated        string      `json:"time_created"`
+	Vnics              []vnic_json `json:"vnics,omitempty"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If -a or --all-regions is provided, the instances of all subscribed regions are listed instead of")
+    fmt.Println("    the region of the profile (a region column is added).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --ips is provided, the private IP, public IP, subnet and NSGs of the primary VNIC are also displayed")
+    fmt.Println("    (all the attached VNICs in JSON format).")
+    fmt.Println("    If --grep REGEX is provided, only the instances whose name or OCID matches the regular expression")
+    fmt.Println("    are displayed.")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout (without colors).")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the compute instances (not terminated) in a compartment
+func list_instances(client core.ComputeClient, compartment_id string) ([]core.Instance, error) {
+	instances := make([]core.Instance, 0)
+	request := core.ListInstancesRequest{
+		CompartmentId   : common.String(compartment_id),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListInstances(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, i := range response.Items {
+			if i.LifecycleState != core.InstanceLifecycleStateTerminated { instances = append(instances, i) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return instances, err
+}
+
+// get the name of a subnet (from cache if already known)
+func get_subnet_name(vcn_client core.VirtualNetworkClient, subnet_id string) (string, error) {
+	names_lock.Lock()
+	name, found := subnet_names[subnet_id]
+	names_lock.Unlock()
+	if found { return name, nil }
+	response, err := vcn_client.GetSubnet(context.Background(), core.GetSubnetRequest{
+		SubnetId        : common.String(subnet_id),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	})
+	if err != nil { return "", err }
+	names_lock.Lock()
+	subnet_names[subnet_id] = *response.DisplayName
+	names_lock.Unlock()
+	return *response.DisplayName, nil
+}
+
+// get the name of a network security group (from cache if already known)
+func get_nsg_name(vcn_client core.VirtualNetworkClient, nsg_id string) (string, error) {
+	names_lock.Lock()
+	name, found := nsg_names[nsg_id]
+	names_lock.Unlock()
+	if found { return name, nil }
+	response, err := vcn_client.GetNetworkSecurityGroup(context.Background(), core.GetNetworkSecurityGroupRequest{
+		NetworkSecurityGroupId : common.String(nsg_id),
+		RequestMetadata        : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	})
+	if err != nil { return "", err }
+	names_lock.Lock()
+	nsg_names[nsg_id] = *response.DisplayName
+	names_lock.Unlock()
+	return *response.DisplayName, nil
+}
+
+// get the VNICs attached to the instances of a compartment (map instance OCID -> VNICs, primary VNIC first)
+func list_vnics(client core.ComputeClient, vcn_client core.VirtualNetworkClient, compartment_id string) (map[string][]vnic_json, error) {
+	vnics := make(map[string][]vnic_json)
+	request := core.ListVnicAttachmentsRequest{
+		CompartmentId   : common.String(compartment_id),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListVnicAttachments(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, a := range response.Items {
+			if a.LifecycleState != core.VnicAttachmentLifecycleStateAttached || a.VnicId == nil { continue }
+			vnic, err := vcn_client.GetVnic(context.Background(), core.GetVnicRequest{
+				VnicId          : a.VnicId,
+				RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+			})
+			if err != nil { return nil, err }
+
+			item := vnic_json{
+				Name      : *vnic.DisplayName,
+				Id        : *vnic.Id,
+				IsPrimary : vnic.IsPrimary != nil && *vnic.IsPrimary,
+				PrivateIp : *vnic.PrivateIp,
+				Nsgs      : make([]string, 0, len(vnic.NsgIds)),
+			}
+			if vnic.PublicIp != nil { item.PublicIp = *vnic.PublicIp }
+			if vnic.SubnetId != nil {
+				item.SubnetId = *vnic.SubnetId
+				item.Subnet, err = get_subnet_name(vcn_client, *vnic.SubnetId)
+				if err != nil { return nil, err }
+			}
+			for _, nsg_id := range vnic.NsgIds {
+				name, err := get_nsg_name(vcn_client, nsg_id)
+				if err != nil { return nil, err }
+				item.Nsgs = append(item.Nsgs, name)
+			}
+			if item.IsPrimary {
+				vnics[*a.InstanceId] = append([]vnic_json{ item }, vnics[*a.InstanceId]...)
+			} else {
+				vnics[*a.InstanceId] = append(vnics[*a.InstanceId], item)
+			}
+		}
+		return response.OpcNextPage, nil
+	})
+	return vnics, err
+}
+
+// list the instances (and their VNICs if show_ips is true) in all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, show_ips bool, parallelism int) ([]instance_item, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	vcn_client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	vcn_client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		instances, err := list_instances(client, cpt_id)
+		if err != nil { return nil, err }
+		vnics := make(map[string][]vnic_json)
+		if show_ips && len(instances) > 0 {
+			vnics, err = list_vnics(client, vcn_client, cpt_id)
+			if err != nil { return nil, err }
+		}
+		items := make([]instance_item, 0, len(instances))
+		for _, i := range instances {
+			items = append(items, instance_item{ region, i, vnics[*i.Id] })
+		}
+		return items, nil
+	})
+	items := make([]instance_item, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		items = append(items, r.Value.([]instance_item)...)
+	}
+	return items, nil
+}
+
+// color of the lifecycle state in text format
+func state_color(state string) string {
+	switch state {
+	case string(core.InstanceLifecycleStateRunning): return ocihelpers.COLOR_GREEN
+	case string(core.InstanceLifecycleStateStopped): return ocihelpers.COLOR_RED
+	default:                                         return ocihelpers.COLOR_YELLOW
+	}
+}
+
+// display the list in text format (aligned columns, colored lifecycle state, OCID in grey at the end of the line)
+// the state is the 5th column and the OCID the 2nd column of the rows
+func display_text(headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for _, row := range append([][]string{ headers }, rows...) {
+		for i, value := range row {
+			if len(value) > widths[i] { widths[i] = len(value) }
+		}
+	}
+	format_line := func(row []string, color_state string, color_ocid string) string {
+		cells := make([]string, 0, len(row))
+		for i, value := range row {
+			if i == 1 { continue }
+			cell := fmt.Sprintf("%-*s", widths[i], value)
+			if i == 4 { cell = color_state+cell+ocihelpers.COLOR_NORMAL }
+			cells = append(cells, cell)
+		}
+		return strings.Join(cells, "  ")+"  "+color_ocid+row[1]+ocihelpers.COLOR_NORMAL
+	}
+	if !ocihelpers.Quiet {
+		upper := make([]string, len(headers))
+		for i, h := range headers { upper[i] = strings.ToUpper(h) }
+		fmt.Println (format_line(upper, "", ""))
+	}
+	for _, row := range rows {
+		fmt.Println (format_line(row, state_color(row[4]), ocihelpers.COLOR_GREY))
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	show_ips        := flag.Bool("ips", false, "display private IP, public IP, subnet and NSGs of the VNICs")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list instances in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list instances in all subscribed regions")
+	grep            := flag.String("grep", "", "only display instances whose name or OCID matches this regular expression")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows")
+	flag.Parse()
+	nb_formats := 0
+	for _, f := range []bool{ *json_output, *csv_output, *markdown_output } {
+		if f { nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	var re *regexp.Regexp
+	if *grep != "" {
+		var err error
+		re, err = regexp.Compile(*grep)
+		if err != nil {
+			fmt.Fprintf (os.Stderr, "ERROR: invalid regular expression '%s': %s\n", *grep, err)
+			os.Exit (1)
+		}
+	}
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of instances in each active compartment (and their VNICs if --ips is provided)
+	// in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("instances", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *show_ips, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	items := make([]instance_item, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, item := range r.Value.([]instance_item) {
+			i := item.Instance
+			if re == nil || re.MatchString(*i.DisplayName) || re.MatchString(*i.Id) { items = append(items, item) }
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		path_i, path_j := paths[*items[i].Instance.CompartmentId], paths[*items[j].Instance.CompartmentId]
+		if path_i != path_j { return path_i < path_j }
+		return *items[i].Instance.DisplayName < *items[j].Instance.DisplayName
+	})
+
+	// JSON format (all attached VNICs)
+	if *json_output {
+		json_items := make([]instance_json, 0, len(items))
+		for _, item := range items {
+			i := item.Instance
+			json_items = append(json_items, instance_json{
+				Name               : *i.DisplayName,
+				Id                 : *i.Id,
+				Shape              : *i.Shape,
+				AvailabilityDomain : *i.AvailabilityDomain,
+				LifecycleState     : string(i.LifecycleState),
+				CompartmentId      : *i.CompartmentId,
+				CompartmentPath    : paths[*i.CompartmentId],
+				Region             : item.Region,
+				TimeCreated        : i.TimeCreated.Format("2006-01-02T15:04:05Z"),
+				Vnics              : item.Vnics,
+			})
+		}
+		output, err := json.MarshalIndent(json_items, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+
+		// Other formats (primary VNIC only)
+		table := ocihelpers.Table{ Headers : []string{ "name", "ocid", "shape", "availability_domain", "state", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		if *show_ips { table.Headers = append(table.Headers, "private_ip", "public_ip", "subnet", "nsgs") }
+		for _, item := range items {
+			i := item.Instance
+			row := []string{ *i.DisplayName, *i.Id, *i.Shape, *i.AvailabilityDomain, string(i.LifecycleState), paths[*i.CompartmentId] }
+			if all_regions { row = append(row, item.Region) }
+			if *show_ips {
+				if v := item.Vnics; len(v) > 0 {
+					row = append(row, v[0].PrivateIp, v[0].PublicIp, v[0].Subnet, strings.Join(v[0].Nsgs, " "))
+				} else {
+					row = append(row, "", "", "", "")
+				}
+			}
+			table.AddRow(row...)
+		}
+		switch {
+		case *csv_output:      ocihelpers.FatalIfError(table.Print("csv"))
+		case *markdown_output: ocihelpers.FatalIfError(table.Print("markdown"))
+		default:               display_text(table.Headers, table.Rows)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,268 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script looks for compute instances with a specific tag key and stop (or start) them if the
+//     tag value for the tag key matches the current UTC time.
+// You can use it to automatically stop some compute instances during non working hours
+//     and start them again at the beginning of working hours to save cloud credits
+// This script needs to be executed every hour during working days by an external scheduler (cron table on Linux for example)
+// You can add the 2 tag keys to the default tags for root compartment so that every new compute
+//     instance get those 2 tag keys with default value ("off" or a specific UTC time)
+//
+// This script looks in all compartments in a OCI tenant in a region (or in all subscribed regions) using OCI Go SDK
+// Go port of OCI_instances_stop_start_tagged.py
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+//                 - OCI user with enough privileges to be able to read, stop and start compute instances (policy example below)
+//                       allow group osc_stop_and_start to read instances in tenancy
+//                       allow group osc_stop_and_start to manage instances in tenancy where request.operation = 'InstanceAction'
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Display a progress indicator on stderr during the scan
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Log the compartments that cannot be listed and process the other compartments (exit code 3)
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- constants
+
+// Tag namespace and keys to look for (instances tagged using this will be stopped/started)
+// Update these to match your tags (or use the --tag-ns, --tag-key-stop and --tag-key-start options)
+const default_tag_ns        = "osc"
+const default_tag_key_stop  = "automatic_shutdown"
+const default_tag_key_start = "automatic_startup"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// options
+var tag_ns        string
+var tag_key_stop  string
+var tag_key_start string
+var confirm_stop  bool
+var confirm_start bool
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [-a] [--confirm_stop] [--confirm_start] [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [-a] [--confirm_stop] [--confirm_start] [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If -a or --all-regions is provided, the script processes all subscribed regions instead of the region of the profile.")
+    fmt.Println("    If --confirm_stop  is not provided, the instances to stop are listed but not actually stopped.")
+    fmt.Println("    If --confirm_start is not provided, the instances to start are listed but not actually started.")
+    fmt.Printf ("    If --tag-ns NS is provided, this tag namespace is used instead of %s.\n", default_tag_ns)
+    fmt.Printf ("    If --tag-key-stop KEY is provided, this tag key is used instead of %s.\n", default_tag_key_stop)
+    fmt.Printf ("    If --tag-key-start KEY is provided, this tag key is used instead of %s.\n", default_tag_key_start)
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is appended to this file instead of stdout (log file).")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("")
+    fmt.Println("    Tag values must have the format HH:00_UTC (ex: 19:00_UTC), other values (ex: off) are ignored.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected, ex: from cron jobs).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions,")
+    fmt.Println("    compartments or instance actions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// get the value of a defined tag (empty string if not set)
+func get_tag_value(instance core.Instance, key string) string {
+	if value, ok := instance.DefinedTags[tag_ns][key]; ok {
+		return fmt.Sprintf("%v", value)
+	}
+	return ""
+}
+
+// list the compute instances in a compartment
+func list_instances(client core.ComputeClient, compartment_id string) ([]core.Instance, error) {
+	instances := make([]core.Instance, 0)
+	request := core.ListInstancesRequest{
+		CompartmentId   : common.String(compartment_id),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListInstances(context.Background(), request)
+		if err != nil { return nil, err }
+		instances = append(instances, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	return instances, err
+}
+
+// check the compute instances in all active compartments of a region and stop or start them if needed
+// returns the log lines (the regions are processed concurrently, so the lines are displayed at the end)
+// and the number of compartments and instance actions that failed (at most parallelism compartments processed
+// at the same time)
+func process_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, current_utc_time string, parallelism int) ([]string, int, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, 0, err }
+	client.SetRegion(region)
+
+	type compartment_output struct {
+		lines     []string
+		nb_failed int
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		var output compartment_output
+		log := func(format string, args ...interface{}) {
+			prefix := fmt.Sprintf("%s, %s, %s: ", time.Now().UTC().Format("15:04:05"), region, tree.FullPath(cpt_id))
+			output.lines = append(output.lines, prefix+fmt.Sprintf(format, args...))
+		}
+
+		// an error in a compartment (ex: no permission) does not prevent the other compartments from being processed
+		instances, err := list_instances(client, cpt_id)
+		if err != nil {
+			log("ERROR: cannot list the instances: %s", err)
+			output.nb_failed++
+			return output, nil
+		}
+
+		// for each instance, check if it needs to be stopped or started
+		for _, instance := range instances {
+			var action core.InstanceActionActionEnum
+			var confirm bool
+			switch {
+			case instance.LifecycleState == core.InstanceLifecycleStateStopped && get_tag_value(instance, tag_key_start) == current_utc_time:
+				action, confirm = core.InstanceActionActionStart, confirm_start
+			case instance.LifecycleState == core.InstanceLifecycleStateRunning && get_tag_value(instance, tag_key_stop) == current_utc_time:
+				action, confirm = core.InstanceActionActionSoftstop, confirm_stop
+			default:
+				continue
+			}
+
+			if !confirm {
+				if action == core.InstanceActionActionStart {
+					log("Instance %s (%s) SHOULD BE STARTED --> re-run script with --confirm_start to actually start instances", *instance.DisplayName, *instance.Id)
+				} else {
+					log("Instance %s (%s) SHOULD BE STOPPED --> re-run script with --confirm_stop to actually stop instances", *instance.DisplayName, *instance.Id)
+				}
+				continue
+			}
+
+			if action == core.InstanceActionActionStart {
+				log("STARTING instance %s (%s)", *instance.DisplayName, *instance.Id)
+			} else {
+				log("STOPPING instance %s (%s)", *instance.DisplayName, *instance.Id)
+			}
+			request := core.InstanceActionRequest{
+				InstanceId      : instance.Id,
+				Action          : action,
+				RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+			}
+			if _, err := client.InstanceAction(context.Background(), request); err != nil {
+				log("ERROR: %s", err)
+				output.nb_failed++
+			}
+		}
+		return output, nil
+	})
+
+	lines := make([]string, 0)
+	nb_failed := 0
+	for _, r := range results {
+		lines = append(lines, r.Value.(compartment_output).lines...)
+		nb_failed += r.Value.(compartment_output).nb_failed
+	}
+	return lines, nb_failed, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	flag.BoolVar(&confirm_stop, "confirm_stop", false, "actually stop the instances")
+	flag.BoolVar(&confirm_start, "confirm_start", false, "actually start the instances")
+	flag.StringVar(&tag_ns, "tag-ns", default_tag_ns, "tag namespace")
+	flag.StringVar(&tag_key_stop, "tag-key-stop", default_tag_key_stop, "tag key for the stop time")
+	flag.StringVar(&tag_key_start, "tag-key-start", default_tag_key_start, "tag key for the start time")
+	parallelism := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file := flag.String("output-file", "", "append the output to this file instead of stdout")
+	flag.Parse()
+	if *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Append the output to a log file instead of stdout (cron jobs)
+	if *output_file != "" {
+		f, err := os.OpenFile(ocihelpers.ExpandPath(*output_file), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		ocihelpers.FatalIfError(err)
+		os.Stdout = f
+	}
+
+	// Get UTC time (format 10:00_UTC, 11:00_UTC ...)
+	current_utc_time := time.Now().UTC().Format("15") + ":00_UTC"
+
+	// Starting
+	pid := os.Getpid()
+	fmt.Printf ("%s: BEGIN SCRIPT PID=%d\n", time.Now().UTC().Format("2006/01/02 15:04:05"), pid)
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of regions to process
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+
+	// Do the job (regions processed concurrently)
+	type region_output struct {
+		lines     []string
+		nb_failed int
+	}
+	ocihelpers.StartProgress("tagged instances", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		lines, nb_failed, err := process_region(config, tree, region, current_utc_time, *parallelism)
+		return region_output{ lines, nb_failed }, err
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	for _, r := range results {
+		output, _ := r.Value.(region_output)
+		for _, line := range output.lines { fmt.Println (line) }
+		nb_failed += output.nb_failed
+		if r.Err != nil {
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+		}
+	}
+
+	// The end
+	fmt.Printf ("%s: END SCRIPT PID=%d\n", time.Now().UTC().Format("2006/01/02 15:04:05"), pid)
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
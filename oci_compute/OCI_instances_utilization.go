@@ -0,0 +1,309 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script reports the CPU and memory utilization of the running compute instances in all compartments of a
+// OCI tenant over the last days, using the metrics of the Monitoring service and OCI Go SDK, to drive
+// rightsizing decisions
+// For each instance, it displays average and maximum CPU and memory utilization, and a hint:
+// oversized (maximum utilization below --low) or undersized (average utilization above --high)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       metrics are the ones of the Compute Instance Monitoring plugin of Oracle Cloud Agent (namespace
+//       oci_computeagent), instances without this plugin have no metrics
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/monitoring"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type instance_json struct {
+	Name            string   `json:"name"`
+	Id              string   `json:"id"`
+	Shape           string   `json:"shape"`
+	Ocpus           float32  `json:"ocpus"`
+	MemoryInGBs     float32  `json:"memory_in_gbs"`
+	CpuAverage      *float64 `json:"cpu_average"`        // percentages, null if no metrics
+	CpuMaximum      *float64 `json:"cpu_maximum"`
+	MemoryAverage   *float64 `json:"memory_average"`
+	MemoryMaximum   *float64 `json:"memory_maximum"`
+	Hint            string   `json:"hint"`               // oversized, undersized, no metrics or empty
+	Region          string   `json:"region"`
+	CompartmentId   string   `json:"compartment_id"`
+	CompartmentPath string   `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If --days N is provided, the utilization is computed over the last N days (default 7, max 90).")
+    fmt.Println("    If --low PERCENT is provided, instances whose maximum CPU and memory utilization are below PERCENT are")
+    fmt.Println("    reported as oversized (default 20).")
+    fmt.Println("    If --high PERCENT is provided, instances whose average CPU or memory utilization is above PERCENT are")
+    fmt.Println("    reported as undersized (default 80).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the running instances of a compartment
+func list_instances(client core.ComputeClient, cpt_id string) ([]core.Instance, error) {
+	instances := make([]core.Instance, 0)
+	request := core.ListInstancesRequest{ CompartmentId : common.String(cpt_id), LifecycleState : core.InstanceLifecycleStateRunning,
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListInstances(context.Background(), request)
+		if err != nil { return nil, err }
+		instances = append(instances, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	return instances, err
+}
+
+// run a query of the monitoring service (1 day intervals) on the instances of a compartment
+// and return the aggregated datapoints per instance
+func query_metrics(client monitoring.MonitoringClient, cpt_id string, query string, days int) (map[string][]float64, error) {
+	end := time.Now().UTC()
+	details := monitoring.SummarizeMetricsDataDetails{
+		Namespace  : common.String("oci_computeagent"),
+		Query      : common.String(query),
+		StartTime  : &common.SDKTime{ Time : end.AddDate(0, 0, -days) },
+		EndTime    : &common.SDKTime{ Time : end },
+		Resolution : common.String("1d"),
+	}
+	request := monitoring.SummarizeMetricsDataRequest{ CompartmentId : common.String(cpt_id), SummarizeMetricsDataDetails : details,
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	response, err := client.SummarizeMetricsData(context.Background(), request)
+	if err != nil { return nil, err }
+
+	values := make(map[string][]float64)
+	for _, metric := range response.Items {
+		id := metric.Dimensions["resourceId"]
+		for _, dp := range metric.AggregatedDatapoints {
+			if dp.Value != nil { values[id] = append(values[id], *dp.Value) }
+		}
+	}
+	return values, nil
+}
+
+// average of daily averages (nil if no values)
+func average(values []float64) *float64 {
+	if len(values) == 0 { return nil }
+	sum := 0.0
+	for _, v := range values { sum += v }
+	avg := sum / float64(len(values))
+	return &avg
+}
+
+// maximum of daily maximums (nil if no values)
+func maximum(values []float64) *float64 {
+	if len(values) == 0 { return nil }
+	max := values[0]
+	for _, v := range values[1:] {
+		if v > max { max = v }
+	}
+	return &max
+}
+
+// rightsizing hint of an instance
+func hint(i instance_json, low float64, high float64) string {
+	if i.CpuAverage == nil { return "no metrics" }
+	if (i.MemoryAverage != nil && *i.MemoryAverage > high) || *i.CpuAverage > high { return "undersized" }
+	if i.CpuMaximum != nil && *i.CpuMaximum < low && (i.MemoryMaximum == nil || *i.MemoryMaximum < low) { return "oversized" }
+	return ""
+}
+
+// report the utilization of the running instances of all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, days int, low float64, high float64, parallelism int) ([]instance_json, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	mon_client, err := monitoring.NewMonitoringClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	mon_client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		items := make([]instance_json, 0)
+		instances, err := list_instances(client, cpt_id)
+		if err != nil { return nil, err }
+		if len(instances) == 0 { return items, nil }
+
+		// metrics of the instances of the compartment
+		metrics := make(map[string]map[string][]float64)
+		for _, query := range []string{ "CpuUtilization[1d].mean()", "CpuUtilization[1d].max()", "MemoryUtilization[1d].mean()", "MemoryUtilization[1d].max()" } {
+			metrics[query], err = query_metrics(mon_client, cpt_id, query, days)
+			if err != nil { return nil, err }
+		}
+
+		for _, i := range instances {
+			item := instance_json{ Name : *i.DisplayName, Id : *i.Id, Shape : *i.Shape, Region : region, CompartmentId : cpt_id,
+				CpuAverage    : average(metrics["CpuUtilization[1d].mean()"][*i.Id]),
+				CpuMaximum    : maximum(metrics["CpuUtilization[1d].max()"][*i.Id]),
+				MemoryAverage : average(metrics["MemoryUtilization[1d].mean()"][*i.Id]),
+				MemoryMaximum : maximum(metrics["MemoryUtilization[1d].max()"][*i.Id]) }
+			if i.ShapeConfig != nil && i.ShapeConfig.Ocpus != nil       { item.Ocpus = *i.ShapeConfig.Ocpus }
+			if i.ShapeConfig != nil && i.ShapeConfig.MemoryInGBs != nil { item.MemoryInGBs = *i.ShapeConfig.MemoryInGBs }
+			item.Hint = hint(item, low, high)
+			items = append(items, item)
+		}
+		return items, nil
+	})
+	items := make([]instance_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		items = append(items, r.Value.([]instance_json)...)
+	}
+	return items, nil
+}
+
+// percentage with 1 decimal (- if no metrics)
+func percent(value *float64) string {
+	if value == nil { return "-" }
+	return fmt.Sprintf("%.1f", *value)
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "report instances in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "report instances in all subscribed regions")
+	days            := flag.Int("days", 7, "compute the utilization over the last N days")
+	low             := flag.Float64("low", 20, "maximum utilization (percent) below which instances are oversized")
+	high            := flag.Float64("high", 80, "average utilization (percent) above which instances are undersized")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if *days < 1 || *days > 90 || *low < 0 || *high > 100 || *low >= *high { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the utilization of the instances in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("instances utilization", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *days, *low, *high, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	instances := make([]instance_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, i := range r.Value.([]instance_json) {
+			i.CompartmentPath = paths[i.CompartmentId]
+			instances = append(instances, i)
+		}
+	}
+	sort.SliceStable(instances, func(i, j int) bool {
+		if instances[i].CompartmentPath != instances[j].CompartmentPath { return instances[i].CompartmentPath < instances[j].CompartmentPath }
+		return instances[i].Name < instances[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(instances, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "instance", "shape", "ocpus", "memory_gb", "cpu_avg", "cpu_max", "mem_avg", "mem_max", "hint", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_oversized, nb_undersized := 0, 0
+		for _, i := range instances {
+			switch i.Hint {
+			case "oversized":  nb_oversized++
+			case "undersized": nb_undersized++
+			}
+			row := []string{ i.Name, i.Shape, fmt.Sprintf("%g", i.Ocpus), fmt.Sprintf("%g", i.MemoryInGBs),
+				percent(i.CpuAverage), percent(i.CpuMaximum), percent(i.MemoryAverage), percent(i.MemoryMaximum), i.Hint, i.CompartmentPath }
+			if all_regions { row = append(row, i.Region) }
+			table.AddRow(append(row, i.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d running instances over the last %d days: %d oversized, %d undersized\n", len(instances), *days, nb_oversized, nb_undersized)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
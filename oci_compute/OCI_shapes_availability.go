@@ -0,0 +1,349 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script reports which compute shapes are available in each availability domain of the regions of a
+// OCI tenant using OCI Go SDK, and optionally (--capacity) if there is host capacity to launch them now
+// (compute capacity report, no test launch needed), to help with placement decisions for large shapes
+// Shapes out of host capacity are displayed in red
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       capacity reports are not available in the OCI Go SDK version used, so the compute REST API
+//       is called directly
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- constants
+const compute_endpoint    = "https://iaas.{region}.{secondLevelDomain}"
+const compute_api_version = "20160918"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// shape configuration of a capacity report (flexible shapes only)
+type shape_config_rest struct {
+	Ocpus       float32 `json:"ocpus"`
+	MemoryInGBs float32 `json:"memoryInGBs"`
+}
+
+// shape availability sent to and returned by the compute capacity report REST API
+type shape_availability_rest struct {
+	InstanceShape       string             `json:"instanceShape"`
+	InstanceShapeConfig *shape_config_rest `json:"instanceShapeConfig,omitempty"`
+	AvailabilityStatus  string             `json:"availabilityStatus,omitempty"`
+	AvailableCount      *int64             `json:"availableCount,omitempty"`
+}
+
+type shape_json struct {
+	Region             string  `json:"region"`
+	AvailabilityDomain string  `json:"availability_domain"`
+	Shape              string  `json:"shape"`
+	Flexible           bool    `json:"flexible"`
+	Ocpus              float32 `json:"ocpus"`               // requested OCPUs for flexible shapes
+	MemoryInGBs        float32 `json:"memory_in_gbs"`       // requested memory for flexible shapes
+	Gpus               int     `json:"gpus"`
+	Processor          string  `json:"processor"`
+	CapacityStatus     string  `json:"capacity_status,omitempty"`       // AVAILABLE, OUT_OF_HOST_CAPACITY... (--capacity)
+	AvailableCount     *int64  `json:"available_count,omitempty"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each shape, with a column per availability domain: yes if the shape is available")
+    fmt.Println("    in the availability domain for the tenant, - otherwise.")
+    fmt.Println("    If --capacity is provided, a compute capacity report is requested for each availability domain (no instance")
+    fmt.Println("    is launched): the columns contain the capacity status (AVAILABLE, OUT_OF_HOST_CAPACITY, HARDWARE_NOT_SUPPORTED)")
+    fmt.Println("    and the number of instances that could be launched when known. Shapes out of host capacity are displayed in red.")
+    fmt.Println("    If --ocpus N and --memory GB are provided, they are used for the capacity of flexible shapes (default: 1 OCPU")
+    fmt.Println("    and the default memory per OCPU of the shape).")
+    fmt.Println("    If --shape REGEX is provided, only the shapes matching the regular expression (case insensitive) are displayed")
+    fmt.Println("    (ex: --shape \"BM|GPU\"). Recommended with --capacity.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format (one item per shape and availability domain).")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// short name of an availability domain (ex: AD-1)
+func short_ad(ad string) string {
+	if i := strings.LastIndex(ad, "-AD-"); i >= 0 { return ad[i+1:] }
+	return ad
+}
+
+// list the shapes available in an availability domain for the tenant (matching the regular expression)
+func list_shapes(client core.ComputeClient, tenancy_ocid string, ad string, re *regexp.Regexp) ([]core.Shape, error) {
+	shapes := make([]core.Shape, 0)
+	request := core.ListShapesRequest{ CompartmentId : common.String(tenancy_ocid), AvailabilityDomain : common.String(ad), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListShapes(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, s := range response.Items {
+			if re == nil || re.MatchString(*s.Shape) { shapes = append(shapes, s) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return shapes, err
+}
+
+// request a compute capacity report for shapes in an availability domain (status of each shape)
+func capacity_report(client *ocihelpers.RestClient, tenancy_ocid string, ad string, shapes []shape_json) (map[string]shape_availability_rest, error) {
+	request := struct {
+		CompartmentId       string                    `json:"compartmentId"`
+		AvailabilityDomain  string                    `json:"availabilityDomain"`
+		ShapeAvailabilities []shape_availability_rest `json:"shapeAvailabilities"`
+	}{ CompartmentId : tenancy_ocid, AvailabilityDomain : ad }
+	for _, s := range shapes {
+		item := shape_availability_rest{ InstanceShape : s.Shape }
+		if s.Flexible { item.InstanceShapeConfig = &shape_config_rest{ Ocpus : s.Ocpus, MemoryInGBs : s.MemoryInGBs } }
+		request.ShapeAvailabilities = append(request.ShapeAvailabilities, item)
+	}
+	var report struct {
+		ShapeAvailabilities []shape_availability_rest `json:"shapeAvailabilities"`
+	}
+	if err := client.Post("/computeCapacityReports", request, &report); err != nil { return nil, err }
+	statuses := make(map[string]shape_availability_rest)
+	for _, s := range report.ShapeAvailabilities { statuses[s.InstanceShape] = s }
+	return statuses, nil
+}
+
+// list the shapes of each availability domain of a region, with their capacity status if capacity is true
+func list_region(config common.ConfigurationProvider, tenancy_ocid string, region string, re *regexp.Regexp, capacity bool, ocpus float32, memory float32) ([]shape_json, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	rest_client, err := ocihelpers.NewRestClient(config, compute_endpoint, compute_api_version, region)
+	if err != nil { return nil, err }
+	ads, err := ocihelpers.ListAvailabilityDomains(config, region)
+	if err != nil { return nil, err }
+
+	items := make([]shape_json, 0)
+	for _, ad := range ads {
+		shapes, err := list_shapes(client, tenancy_ocid, ad, re)
+		if err != nil { return nil, err }
+		ad_items := make([]shape_json, 0, len(shapes))
+		for _, s := range shapes {
+			item := shape_json{ Region : region, AvailabilityDomain : ad, Shape : *s.Shape, Flexible : s.OcpuOptions != nil }
+			if s.Ocpus != nil                { item.Ocpus = *s.Ocpus }
+			if s.MemoryInGBs != nil          { item.MemoryInGBs = *s.MemoryInGBs }
+			if s.Gpus != nil                 { item.Gpus = *s.Gpus }
+			if s.ProcessorDescription != nil { item.Processor = *s.ProcessorDescription }
+			if item.Flexible {
+				item.Ocpus, item.MemoryInGBs = ocpus, memory
+				if memory == 0 && s.MemoryOptions != nil && s.MemoryOptions.DefaultPerOcpuInGBs != nil { item.MemoryInGBs = ocpus * *s.MemoryOptions.DefaultPerOcpuInGBs }
+			}
+			ad_items = append(ad_items, item)
+		}
+		if capacity && len(ad_items) > 0 {
+			statuses, err := capacity_report(rest_client, tenancy_ocid, ad, ad_items)
+			if err != nil { return nil, err }
+			for i := range ad_items {
+				s := statuses[ad_items[i].Shape]
+				ad_items[i].CapacityStatus, ad_items[i].AvailableCount = s.AvailabilityStatus, s.AvailableCount
+			}
+		}
+		items = append(items, ad_items...)
+	}
+	return items, nil
+}
+
+// display the table in text format, with the rows of shapes out of host capacity in red
+func display_text(table ocihelpers.Table, out_of_capacity []bool) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(out_of_capacity) { break }
+		if out_of_capacity[i] { line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL }
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "report the shapes of all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "report the shapes of all subscribed regions")
+	capacity        := flag.Bool("capacity", false, "request a compute capacity report for each availability domain")
+	ocpus           := flag.Float64("ocpus", 1, "number of OCPUs of flexible shapes for the capacity report")
+	memory          := flag.Float64("memory", 0, "memory in GB of flexible shapes for the capacity report (default: default memory per OCPU)")
+	shape           := flag.String("shape", "", "only report the shapes matching this regular expression")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *ocpus <= 0 || *memory < 0 { usage() }
+	var re *regexp.Regexp
+	if *shape != "" {
+		var err error
+		re, err = regexp.Compile("(?i)" + *shape)
+		if err != nil { ocihelpers.Fatal ("invalid regular expression '%s': %s", *shape, err) }
+	}
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	tenancy_ocid, err := config.TenancyOCID()
+	ocihelpers.FatalIfError(err)
+
+	// Get the shapes of each availability domain in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("shapes", len(regions), 0)
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tenancy_ocid, region, re, *capacity, float32(*ocpus), float32(*memory))
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	shapes := make([]shape_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		shapes = append(shapes, r.Value.([]shape_json)...)
+	}
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(shapes, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+		return
+	}
+
+	// one row per region and shape, one column per availability domain (AD-1, AD-2, AD-3)
+	ad_names := make([]string, 0)
+	cells := make(map[string]map[string]string)
+	rows := make([]shape_json, 0)
+	out_of_capacity := make(map[string]bool)
+	nb_out_of_capacity := 0
+	for _, s := range shapes {
+		ad, key := short_ad(s.AvailabilityDomain), s.Region + " " + s.Shape
+		found := false
+		for _, name := range ad_names {
+			if name == ad { found = true }
+		}
+		if !found { ad_names = append(ad_names, ad) }
+		if _, exists := cells[key]; !exists {
+			cells[key] = make(map[string]string)
+			rows = append(rows, s)
+		}
+		cell := "yes"
+		if *capacity {
+			cell = s.CapacityStatus
+			if s.AvailableCount != nil { cell += fmt.Sprintf(" (%d)", *s.AvailableCount) }
+			if s.CapacityStatus == "OUT_OF_HOST_CAPACITY" {
+				out_of_capacity[key] = true
+				nb_out_of_capacity++
+			}
+		}
+		cells[key][ad] = cell
+	}
+	sort.Strings(ad_names)
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Region != rows[j].Region { return rows[i].Region < rows[j].Region }
+		return rows[i].Shape < rows[j].Shape
+	})
+	table := ocihelpers.Table{ Headers : []string{ "shape", "ocpus", "memory_gb", "gpus", "processor" } }
+	if all_regions { table.Headers = append([]string{ "region" }, table.Headers...) }
+	table.Headers = append(table.Headers, ad_names...)
+	red := make([]bool, 0)
+	for _, s := range rows {
+		key := s.Region + " " + s.Shape
+		row := []string{ s.Shape, fmt.Sprintf("%g", s.Ocpus), fmt.Sprintf("%g", s.MemoryInGBs), fmt.Sprintf("%d", s.Gpus), s.Processor }
+		if all_regions { row = append([]string{ s.Region }, row...) }
+		for _, ad := range ad_names {
+			cell, available := cells[key][ad]
+			if !available { cell = "-" }
+			row = append(row, cell)
+		}
+		table.AddRow(row...)
+		red = append(red, out_of_capacity[key])
+	}
+	if format != "text" {
+		ocihelpers.FatalIfError(table.Print(format))
+	} else {
+		display_text(table, red)
+		if !ocihelpers.Quiet {
+			fmt.Println ("")
+			if *capacity {
+				fmt.Printf ("%d shapes in %d regions, %d shapes out of host capacity in an availability domain\n", len(rows), len(regions) - nb_failed, nb_out_of_capacity)
+			} else {
+				fmt.Printf ("%d shapes in %d regions\n", len(rows), len(regions) - nb_failed)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
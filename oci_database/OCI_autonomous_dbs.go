@@ -0,0 +1,294 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists, starts or stops the Autonomous Databases in all compartments of a OCI tenant using OCI Go SDK
+// in the region of the profile or in all subscribed regions
+//    list  : list all Autonomous Databases (name, workload type, OCPUs, state, compartment)
+//    status: display the state of an Autonomous Database or of all Autonomous Databases matching a tag
+//    start : start an Autonomous Database or all Autonomous Databases matching a tag
+//    stop  : stop an Autonomous Database or all Autonomous Databases matching a tag
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       terminated Autonomous Databases are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+//                 - OCI user with enough privileges to be able to read, stop and start Autonomous Databases
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/database"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// Autonomous Database found in a region
+type adb struct {
+	Region  string
+	Summary database.AutonomousDatabaseSummary
+}
+
+type adb_json struct {
+	Name            string `json:"name"`
+	DbName          string `json:"db_name"`
+	Id              string `json:"id"`
+	Workload        string `json:"workload"`
+	Ocpus           int    `json:"ocpus"`
+	StorageTBs      int    `json:"storage_tbs"`
+	IsFreeTier      bool   `json:"is_free_tier"`
+	LifecycleState  string `json:"lifecycle_state"`
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] list\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] status|start|stop ADB_NAME\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --tag TAG status|start|stop\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    ADB_NAME is the display name or the database name of the Autonomous Database (not case sensitive).")
+    fmt.Println("    If --tag TAG is provided, only the Autonomous Databases matching the tag are processed:")
+    fmt.Println("      NAMESPACE.KEY=VALUE for a defined tag, KEY=VALUE for a freeform tag (ex: --tag osc.env=dev).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If --profile PROFILE is provided, this OCI profile is used (default: OCI_CLI_PROFILE or DEFAULT).")
+    fmt.Println("    If -json is provided, the list/status is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list/status is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list/status is displayed as a Markdown table.")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error (ex: no Autonomous Database found), 2 = authentication error,")
+    fmt.Println("                3 = partial results (some regions or start/stop requests failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the Autonomous Databases (not terminated) in all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_adbs(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]adb, error) {
+	client, err := database.NewDatabaseClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		adbs := make([]adb, 0)
+		request := database.ListAutonomousDatabasesRequest{
+			CompartmentId   : common.String(cpt_id),
+			RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+		}
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListAutonomousDatabases(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				if a.LifecycleState == database.AutonomousDatabaseSummaryLifecycleStateTerminated { continue }
+				adbs = append(adbs, adb{ region, a })
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		return adbs, nil
+	})
+	adbs := make([]adb, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		adbs = append(adbs, r.Value.([]adb)...)
+	}
+	return adbs, nil
+}
+
+// start or stop an Autonomous Database (nothing to do if it is already started or stopped)
+func start_stop_adb(config common.ConfigurationProvider, a adb, action string, path string) error {
+	client, err := database.NewDatabaseClientWithConfigurationProvider(config)
+	if err != nil { return err }
+	client.SetRegion(a.Region)
+
+	name  := *a.Summary.DisplayName
+	state := a.Summary.LifecycleState
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	switch {
+	case action == "start" && state == database.AutonomousDatabaseSummaryLifecycleStateStopped:
+		fmt.Printf ("%s, %s: STARTING Autonomous Database %s (%s)\n", a.Region, path, name, *a.Summary.Id)
+		_, err = client.StartAutonomousDatabase(context.Background(), database.StartAutonomousDatabaseRequest{ AutonomousDatabaseId : a.Summary.Id, RequestMetadata : metadata })
+	case action == "stop" && state == database.AutonomousDatabaseSummaryLifecycleStateAvailable:
+		fmt.Printf ("%s, %s: STOPPING Autonomous Database %s (%s)\n", a.Region, path, name, *a.Summary.Id)
+		_, err = client.StopAutonomousDatabase(context.Background(), database.StopAutonomousDatabaseRequest{ AutonomousDatabaseId : a.Summary.Id, RequestMetadata : metadata })
+	default:
+		fmt.Printf ("%s, %s: Autonomous Database %s (%s) is %s: nothing to do\n", a.Region, path, name, *a.Summary.Id, state)
+	}
+	return err
+}
+
+// display the list of Autonomous Databases in JSON, CSV, Markdown or text format
+func display_adbs(adbs []adb, paths map[string]string, format string) {
+	if format == "json" {
+		items := make([]adb_json, 0, len(adbs))
+		for _, a := range adbs {
+			s := a.Summary
+			items = append(items, adb_json{
+				Name            : *s.DisplayName,
+				DbName          : *s.DbName,
+				Id              : *s.Id,
+				Workload        : string(s.DbWorkload),
+				Ocpus           : *s.CpuCoreCount,
+				StorageTBs      : *s.DataStorageSizeInTBs,
+				IsFreeTier      : s.IsFreeTier != nil && *s.IsFreeTier,
+				LifecycleState  : string(s.LifecycleState),
+				Region          : a.Region,
+				CompartmentId   : *s.CompartmentId,
+				CompartmentPath : paths[*s.CompartmentId],
+			})
+		}
+		output, err := json.MarshalIndent(items, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+
+	table := ocihelpers.Table{ Headers : []string{ "name", "workload", "ocpus", "state", "region", "compartment_path", "ocid" } }
+	for _, a := range adbs {
+		s := a.Summary
+		ocpus := fmt.Sprintf("%d", *s.CpuCoreCount)
+		if s.IsFreeTier != nil && *s.IsFreeTier { ocpus = "free" }
+		table.AddRow(*s.DisplayName, string(s.DbWorkload), ocpus, string(s.LifecycleState), a.Region, paths[*s.CompartmentId], *s.Id)
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	profile := flag.String("profile", ocihelpers.GetProfile(nil), "OCI profile")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	tag             := flag.String("tag", "", "only process the Autonomous Databases matching this tag")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows")
+	flag.Parse()
+
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+
+	// action and Autonomous Database name (or --tag)
+	if flag.NArg() < 1 || flag.NArg() > 2 { usage() }
+	action := flag.Arg(0)
+	adb_name := flag.Arg(1)
+	switch action {
+	case "list":
+		if adb_name != "" { usage() }
+	case "status", "start", "stop":
+		if (adb_name == "") == (*tag == "") { usage() }
+	default:
+		usage()
+	}
+	var tag_filter *ocihelpers.TagFilter
+	if *tag != "" {
+		f, err := ocihelpers.ParseTagFilter(*tag)
+		ocihelpers.FatalIfError(err)
+		tag_filter = &f
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, *profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of regions to process
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of Autonomous Databases in each region (regions processed concurrently)
+	// and keep the ones matching the name or the tag
+	nb_failed := 0
+	adbs := make([]adb, 0)
+	ocihelpers.StartProgress("autonomous databases", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_adbs(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, a := range r.Value.([]adb) {
+			if adb_name != "" && !strings.EqualFold(*a.Summary.DisplayName, adb_name) && !strings.EqualFold(*a.Summary.DbName, adb_name) { continue }
+			if tag_filter != nil && !tag_filter.Match(a.Summary.FreeformTags, a.Summary.DefinedTags) { continue }
+			adbs = append(adbs, a)
+		}
+	}
+	sort.SliceStable(adbs, func(i, j int) bool {
+		if paths[*adbs[i].Summary.CompartmentId] != paths[*adbs[j].Summary.CompartmentId] {
+			return paths[*adbs[i].Summary.CompartmentId] < paths[*adbs[j].Summary.CompartmentId]
+		}
+		return *adbs[i].Summary.DisplayName < *adbs[j].Summary.DisplayName
+	})
+	if action != "list" && len(adbs) == 0 {
+		if adb_name != "" { ocihelpers.Fatal("no Autonomous Database found with name %s", adb_name) }
+		ocihelpers.Fatal("no Autonomous Database found with tag %s", tag_filter)
+	}
+
+	// Do the job
+	switch action {
+	case "list", "status":
+		display_adbs(adbs, paths, format)
+	case "start", "stop":
+		for _, a := range adbs {
+			if err := start_stop_adb(config, a, action, paths[*a.Summary.CompartmentId]); err != nil {
+				fmt.Fprintf (os.Stderr, "ERROR: %s: %s\n", *a.Summary.DisplayName, err)
+				nb_failed++
+			}
+		}
+	}
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
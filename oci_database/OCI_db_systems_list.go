@@ -0,0 +1,310 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the DB systems (VM and Bare Metal) in all compartments of a OCI tenant using OCI Go SDK,
+// with their database homes and databases: edition, version, shape, storage and Data Guard role
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       terminated DB systems, database homes and databases are ignored
+//       Exadata DB systems are listed too (shape Exadata.*)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/database"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type database_json struct {
+	Name                   string `json:"name"`
+	UniqueName             string `json:"unique_name"`
+	Id                     string `json:"id"`
+	PdbName                string `json:"pdb_name"`
+	Workload               string `json:"workload"`
+	LifecycleState         string `json:"lifecycle_state"`
+	DataGuardRole          string `json:"data_guard_role"`            // PRIMARY, STANDBY, DISABLED_STANDBY or NONE
+	DataGuardPeerDbSystem  string `json:"data_guard_peer_db_system_id"`
+	DataGuardProtection    string `json:"data_guard_protection_mode"`
+}
+
+type db_home_json struct {
+	Name      string          `json:"name"`
+	Id        string          `json:"id"`
+	DbVersion string          `json:"db_version"`
+	Databases []database_json `json:"databases"`
+}
+
+type db_system_json struct {
+	Name                 string         `json:"name"`
+	Id                   string         `json:"id"`
+	Shape                string         `json:"shape"`
+	CpuCoreCount         int            `json:"cpu_core_count"`
+	NodeCount            int            `json:"node_count"`
+	DatabaseEdition      string         `json:"database_edition"`
+	Version              string         `json:"version"`
+	LicenseModel         string         `json:"license_model"`
+	DataStorageSizeInGBs int            `json:"data_storage_size_in_gbs"`
+	RecoStorageSizeInGBs int            `json:"reco_storage_size_in_gbs"`
+	LifecycleState       string         `json:"lifecycle_state"`
+	DbHomes              []db_home_json `json:"db_homes"`
+	AvailabilityDomain   string         `json:"availability_domain"`
+	Region               string         `json:"region"`
+	CompartmentId        string         `json:"compartment_id"`
+	CompartmentPath      string         `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each database (DB systems without databases are displayed with an empty database).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format (DB systems with their database homes and databases).")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the databases (not terminated) of a database home, with their Data Guard role
+func list_databases(client database.DatabaseClient, cpt_id string, db_home_id *string) ([]database_json, error) {
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	databases := make([]database_json, 0)
+	request := database.ListDatabasesRequest{ CompartmentId : common.String(cpt_id), DbHomeId : db_home_id, RequestMetadata : metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListDatabases(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, d := range response.Items {
+			if d.LifecycleState == database.DatabaseSummaryLifecycleStateTerminated { continue }
+			item := database_json{ Name : *d.DbName, UniqueName : *d.DbUniqueName, Id : *d.Id, LifecycleState : string(d.LifecycleState), DataGuardRole : "NONE" }
+			if d.PdbName != nil    { item.PdbName = *d.PdbName }
+			if d.DbWorkload != nil { item.Workload = *d.DbWorkload }
+			databases = append(databases, item)
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	// Data Guard role (a database has at most one Data Guard association)
+	for i := range databases {
+		response, err := client.ListDataGuardAssociations(context.Background(), database.ListDataGuardAssociationsRequest{ DatabaseId : common.String(databases[i].Id), RequestMetadata : metadata })
+		if err != nil { return nil, err }
+		for _, a := range response.Items {
+			if a.LifecycleState == database.DataGuardAssociationSummaryLifecycleStateTerminated { continue }
+			databases[i].DataGuardRole, databases[i].DataGuardProtection = string(a.Role), string(a.ProtectionMode)
+			if a.PeerDbSystemId != nil { databases[i].DataGuardPeerDbSystem = *a.PeerDbSystemId }
+		}
+	}
+	return databases, nil
+}
+
+// list the DB systems (not terminated) of all active compartments of a region, with their database homes and databases
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]db_system_json, error) {
+	client, err := database.NewDatabaseClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		db_systems := make([]db_system_json, 0)
+		summaries := make([]database.DbSystemSummary, 0)
+		request := database.ListDbSystemsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListDbSystems(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, s := range response.Items {
+				if s.LifecycleState != database.DbSystemSummaryLifecycleStateTerminated { summaries = append(summaries, s) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		for _, s := range summaries {
+			item := db_system_json{
+				Name               : *s.DisplayName,
+				Id                 : *s.Id,
+				Shape              : *s.Shape,
+				CpuCoreCount       : *s.CpuCoreCount,
+				DatabaseEdition    : string(s.DatabaseEdition),
+				LicenseModel       : string(s.LicenseModel),
+				LifecycleState     : string(s.LifecycleState),
+				DbHomes            : make([]db_home_json, 0),
+				AvailabilityDomain : *s.AvailabilityDomain,
+				Region             : region,
+				CompartmentId      : *s.CompartmentId,
+			}
+			if s.NodeCount != nil            { item.NodeCount = *s.NodeCount }
+			if s.Version != nil              { item.Version = *s.Version }
+			if s.DataStorageSizeInGBs != nil { item.DataStorageSizeInGBs = *s.DataStorageSizeInGBs }
+			if s.RecoStorageSizeInGB != nil  { item.RecoStorageSizeInGBs = *s.RecoStorageSizeInGB }
+
+			homes_request := database.ListDbHomesRequest{ CompartmentId : s.CompartmentId, DbSystemId : s.Id, RequestMetadata : metadata }
+			err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				homes_request.Page = page
+				response, err := client.ListDbHomes(context.Background(), homes_request)
+				if err != nil { return nil, err }
+				for _, h := range response.Items {
+					if h.LifecycleState == database.DbHomeSummaryLifecycleStateTerminated { continue }
+					databases, err := list_databases(client, *h.CompartmentId, h.Id)
+					if err != nil { return nil, err }
+					item.DbHomes = append(item.DbHomes, db_home_json{ Name : *h.DisplayName, Id : *h.Id, DbVersion : *h.DbVersion, Databases : databases })
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+			sort.SliceStable(item.DbHomes, func(i, j int) bool { return item.DbHomes[i].Name < item.DbHomes[j].Name })
+			db_systems = append(db_systems, item)
+		}
+		return db_systems, nil
+	})
+	db_systems := make([]db_system_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		db_systems = append(db_systems, r.Value.([]db_system_json)...)
+	}
+	return db_systems, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list DB systems in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list DB systems in all subscribed regions")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the DB systems in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("DB systems", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	db_systems := make([]db_system_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, s := range r.Value.([]db_system_json) {
+			s.CompartmentPath = paths[s.CompartmentId]
+			db_systems = append(db_systems, s)
+		}
+	}
+	sort.SliceStable(db_systems, func(i, j int) bool {
+		if db_systems[i].CompartmentPath != db_systems[j].CompartmentPath { return db_systems[i].CompartmentPath < db_systems[j].CompartmentPath }
+		return db_systems[i].Name < db_systems[j].Name
+	})
+
+	// Display the results (a row per database)
+	if format == "json" {
+		output, err := json.MarshalIndent(db_systems, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "db_system", "shape", "cores", "nodes", "edition", "data_gb", "reco_gb", "db_home", "db_version", "database", "unique_name", "data_guard_role", "state", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_databases := 0
+		for _, s := range db_systems {
+			system_columns := []string{ s.Name, s.Shape, fmt.Sprintf("%d", s.CpuCoreCount), fmt.Sprintf("%d", s.NodeCount), s.DatabaseEdition, fmt.Sprintf("%d", s.DataStorageSizeInGBs), fmt.Sprintf("%d", s.RecoStorageSizeInGBs) }
+			nb_rows := 0
+			for _, h := range s.DbHomes {
+				for _, d := range h.Databases {
+					nb_rows++
+					row := append(append([]string{}, system_columns...), h.Name, h.DbVersion, d.Name, d.UniqueName, d.DataGuardRole, d.LifecycleState, s.CompartmentPath)
+					if all_regions { row = append(row, s.Region) }
+					table.AddRow(append(row, d.Id)...)
+				}
+			}
+			if nb_rows == 0 {
+				row := append(append([]string{}, system_columns...), "", s.Version, "", "", "", s.LifecycleState, s.CompartmentPath)
+				if all_regions { row = append(row, s.Region) }
+				table.AddRow(append(row, s.Id)...)
+			}
+			nb_databases += nb_rows
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d DB systems, %d databases\n", len(db_systems), nb_databases)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
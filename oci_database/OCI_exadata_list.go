@@ -0,0 +1,392 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the Exadata infrastructures in all compartments of a OCI tenant using OCI Go SDK, with
+// their VM clusters (OCPUs enabled, nodes, Grid Infrastructure version) and the databases of each VM cluster
+//    EXACS: Exadata Cloud Service (cloud Exadata infrastructures and cloud VM clusters)
+//    EXACC: Exadata Cloud@Customer (Exadata infrastructures and VM clusters)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       cloud Exadata infrastructures and cloud VM clusters are not available in the version of the OCI Go SDK
+//       used, so the Database REST API is used directly for them (signed REST requests, see
+//       internal/ocihelpers/rest.go)
+//       Exadata DB systems (older ExaCS model) are listed by OCI_db_systems_list.go
+//       terminated or deleted resources are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/database"
+)
+
+// -- constants
+const database_endpoint    = "https://database.{region}.oci.{secondLevelDomain}"
+const database_api_version = "20160918"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// cloud Exadata infrastructure and cloud VM cluster returned by the Database REST API
+type cloud_infrastructure_rest struct {
+	Id             string `json:"id"`
+	DisplayName    string `json:"displayName"`
+	CompartmentId  string `json:"compartmentId"`
+	LifecycleState string `json:"lifecycleState"`
+	Shape          string `json:"shape"`
+	ComputeCount   int    `json:"computeCount"`
+	StorageCount   int    `json:"storageCount"`
+}
+
+type cloud_vm_cluster_rest struct {
+	Id                           string `json:"id"`
+	DisplayName                  string `json:"displayName"`
+	CompartmentId                string `json:"compartmentId"`
+	LifecycleState               string `json:"lifecycleState"`
+	CloudExadataInfrastructureId string `json:"cloudExadataInfrastructureId"`
+	CpuCoreCount                 int    `json:"cpuCoreCount"`
+	NodeCount                    int    `json:"nodeCount"`
+	GiVersion                    string `json:"giVersion"`
+	LicenseModel                 string `json:"licenseModel"`
+}
+
+type database_json struct {
+	Name           string `json:"name"`
+	UniqueName     string `json:"unique_name"`
+	Id             string `json:"id"`
+	Workload       string `json:"workload"`
+	LifecycleState string `json:"lifecycle_state"`
+}
+
+type vm_cluster_json struct {
+	Name            string          `json:"name"`
+	Id              string          `json:"id"`
+	Ocpus           int             `json:"ocpus"`              // CPU cores enabled
+	NodeCount       int             `json:"node_count"`
+	GiVersion       string          `json:"gi_version"`
+	LicenseModel    string          `json:"license_model"`
+	LifecycleState  string          `json:"lifecycle_state"`
+	Databases       []database_json `json:"databases"`
+	CompartmentId   string          `json:"compartment_id"`
+	CompartmentPath string          `json:"compartment_path"`
+}
+
+type infrastructure_json struct {
+	Type            string            `json:"type"`             // EXACS or EXACC
+	Name            string            `json:"name"`
+	Id              string            `json:"id"`
+	Shape           string            `json:"shape"`
+	LifecycleState  string            `json:"lifecycle_state"`
+	Ocpus           int               `json:"ocpus"`            // total of the OCPUs of the VM clusters
+	VmClusters      []vm_cluster_json `json:"vm_clusters"`
+	Region          string            `json:"region"`
+	CompartmentId   string            `json:"compartment_id"`
+	CompartmentPath string            `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, a row is displayed for each VM cluster, with its OCPUs and number of databases (infrastructures")
+    fmt.Println("    without VM clusters are displayed with an empty VM cluster).")
+    fmt.Println("    If --databases is provided, a row is displayed for each database of each VM cluster instead.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format (infrastructures with their VM clusters and databases).")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the cloud Exadata infrastructures (not terminated) of a compartment
+func list_cloud_infrastructures(client *ocihelpers.RestClient, cpt_id string) ([]cloud_infrastructure_rest, error) {
+	infrastructures := make([]cloud_infrastructure_rest, 0)
+	query := url.Values{ "compartmentId" : { cpt_id } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []cloud_infrastructure_rest
+		next_page, err := client.Get("/cloudExadataInfrastructures", query, &items)
+		if err != nil { return nil, err }
+		for _, i := range items {
+			if i.LifecycleState != "TERMINATED" { infrastructures = append(infrastructures, i) }
+		}
+		return next_page, nil
+	})
+	return infrastructures, err
+}
+
+// list the cloud VM clusters (not terminated) of a compartment
+func list_cloud_vm_clusters(client *ocihelpers.RestClient, cpt_id string) ([]cloud_vm_cluster_rest, error) {
+	clusters := make([]cloud_vm_cluster_rest, 0)
+	query := url.Values{ "compartmentId" : { cpt_id } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []cloud_vm_cluster_rest
+		next_page, err := client.Get("/cloudVmClusters", query, &items)
+		if err != nil { return nil, err }
+		for _, c := range items {
+			if c.LifecycleState != "TERMINATED" { clusters = append(clusters, c) }
+		}
+		return next_page, nil
+	})
+	return clusters, err
+}
+
+// list the databases (not terminated) of a VM cluster
+func list_databases(client database.DatabaseClient, cpt_id string, vm_cluster_id string) ([]database_json, error) {
+	databases := make([]database_json, 0)
+	request := database.ListDatabasesRequest{ CompartmentId : common.String(cpt_id), SystemId : common.String(vm_cluster_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListDatabases(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, d := range response.Items {
+			if d.LifecycleState == database.DatabaseSummaryLifecycleStateTerminated { continue }
+			item := database_json{ Name : *d.DbName, UniqueName : *d.DbUniqueName, Id : *d.Id, LifecycleState : string(d.LifecycleState) }
+			if d.DbWorkload != nil { item.Workload = *d.DbWorkload }
+			databases = append(databases, item)
+		}
+		return response.OpcNextPage, nil
+	})
+	sort.SliceStable(databases, func(i, j int) bool { return databases[i].Name < databases[j].Name })
+	return databases, err
+}
+
+// list the Exadata infrastructures (ExaCS and ExaCC) of all active compartments of a region, with their VM clusters
+// (the VM clusters can be in a compartment different from the one of their infrastructure)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]infrastructure_json, error) {
+	client, err := database.NewDatabaseClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	rest_client, err := ocihelpers.NewRestClient(config, database_endpoint, database_api_version, region)
+	if err != nil { return nil, err }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	type compartment_items struct {
+		infrastructures []infrastructure_json
+		vm_clusters     map[string][]vm_cluster_json     // infrastructure OCID -> VM clusters
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		infrastructures := make([]infrastructure_json, 0)
+		vm_clusters := make(map[string][]vm_cluster_json)
+
+		// Exadata Cloud Service
+		infras, err := list_cloud_infrastructures(rest_client, cpt_id)
+		if err != nil { return nil, err }
+		for _, i := range infras {
+			shape := fmt.Sprintf("%s (%d compute, %d storage)", i.Shape, i.ComputeCount, i.StorageCount)
+			infrastructures = append(infrastructures, infrastructure_json{ Type : "EXACS", Name : i.DisplayName, Id : i.Id, Shape : shape, LifecycleState : i.LifecycleState, Region : region, CompartmentId : i.CompartmentId })
+		}
+		clusters, err := list_cloud_vm_clusters(rest_client, cpt_id)
+		if err != nil { return nil, err }
+		for _, c := range clusters {
+			vm_clusters[c.CloudExadataInfrastructureId] = append(vm_clusters[c.CloudExadataInfrastructureId], vm_cluster_json{
+				Name : c.DisplayName, Id : c.Id, Ocpus : c.CpuCoreCount, NodeCount : c.NodeCount, GiVersion : c.GiVersion, LicenseModel : c.LicenseModel, LifecycleState : c.LifecycleState, CompartmentId : c.CompartmentId })
+		}
+
+		// Exadata Cloud@Customer
+		infras_request := database.ListExadataInfrastructuresRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			infras_request.Page = page
+			response, err := client.ListExadataInfrastructures(context.Background(), infras_request)
+			if err != nil { return nil, err }
+			for _, i := range response.Items {
+				if i.LifecycleState == database.ExadataInfrastructureSummaryLifecycleStateDeleted { continue }
+				infrastructures = append(infrastructures, infrastructure_json{ Type : "EXACC", Name : *i.DisplayName, Id : *i.Id, Shape : *i.Shape, LifecycleState : string(i.LifecycleState), Region : region, CompartmentId : *i.CompartmentId })
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		clusters_request := database.ListVmClustersRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			clusters_request.Page = page
+			response, err := client.ListVmClusters(context.Background(), clusters_request)
+			if err != nil { return nil, err }
+			for _, c := range response.Items {
+				if c.LifecycleState == database.VmClusterSummaryLifecycleStateTerminated || c.ExadataInfrastructureId == nil { continue }
+				item := vm_cluster_json{ Name : *c.DisplayName, Id : *c.Id, LicenseModel : string(c.LicenseModel), LifecycleState : string(c.LifecycleState), CompartmentId : *c.CompartmentId }
+				if c.CpusEnabled != nil { item.Ocpus = *c.CpusEnabled }
+				if c.GiVersion != nil   { item.GiVersion = *c.GiVersion }
+				vm_clusters[*c.ExadataInfrastructureId] = append(vm_clusters[*c.ExadataInfrastructureId], item)
+			}
+			return response.OpcNextPage, nil
+		})
+		return compartment_items{ infrastructures, vm_clusters }, err
+	})
+	infrastructures := make([]infrastructure_json, 0)
+	vm_clusters := make(map[string][]vm_cluster_json)     // infrastructure OCID -> VM clusters
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		infrastructures = append(infrastructures, cpt_items.infrastructures...)
+		for id, clusters := range cpt_items.vm_clusters { vm_clusters[id] = append(vm_clusters[id], clusters...) }
+	}
+
+	// VM clusters of each infrastructure, with their databases
+	for i := range infrastructures {
+		clusters := vm_clusters[infrastructures[i].Id]
+		if clusters == nil { clusters = make([]vm_cluster_json, 0) }
+		for j := range clusters {
+			clusters[j].Databases, err = list_databases(client, clusters[j].CompartmentId, clusters[j].Id)
+			if err != nil { return nil, err }
+			infrastructures[i].Ocpus += clusters[j].Ocpus
+		}
+		sort.SliceStable(clusters, func(a, b int) bool { return clusters[a].Name < clusters[b].Name })
+		infrastructures[i].VmClusters = clusters
+	}
+	return infrastructures, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list Exadata infrastructures in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list Exadata infrastructures in all subscribed regions")
+	list_dbs        := flag.Bool("databases", false, "display a row for each database of each VM cluster")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the Exadata infrastructures in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("Exadata infrastructures", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	infrastructures := make([]infrastructure_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, i := range r.Value.([]infrastructure_json) {
+			i.CompartmentPath = paths[i.CompartmentId]
+			for j := range i.VmClusters { i.VmClusters[j].CompartmentPath = paths[i.VmClusters[j].CompartmentId] }
+			infrastructures = append(infrastructures, i)
+		}
+	}
+	sort.SliceStable(infrastructures, func(i, j int) bool {
+		if infrastructures[i].CompartmentPath != infrastructures[j].CompartmentPath { return infrastructures[i].CompartmentPath < infrastructures[j].CompartmentPath }
+		return infrastructures[i].Name < infrastructures[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(infrastructures, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		var table ocihelpers.Table
+		if *list_dbs {
+			table = ocihelpers.Table{ Headers : []string{ "type", "infrastructure", "vm_cluster", "database", "unique_name", "workload", "state", "compartment_path" } }
+		} else {
+			table = ocihelpers.Table{ Headers : []string{ "type", "infrastructure", "shape", "vm_cluster", "ocpus", "nodes", "gi_version", "license", "databases", "state", "compartment_path" } }
+		}
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_clusters, nb_databases, total_ocpus := 0, 0, 0
+		for _, i := range infrastructures {
+			total_ocpus += i.Ocpus
+			if len(i.VmClusters) == 0 && !*list_dbs {
+				row := []string{ i.Type, i.Name, i.Shape, "", "", "", "", "", "", i.LifecycleState, i.CompartmentPath }
+				if all_regions { row = append(row, i.Region) }
+				table.AddRow(append(row, i.Id)...)
+			}
+			for _, c := range i.VmClusters {
+				nb_clusters++
+				nb_databases += len(c.Databases)
+				if !*list_dbs {
+					row := []string{ i.Type, i.Name, i.Shape, c.Name, fmt.Sprintf("%d", c.Ocpus), fmt.Sprintf("%d", c.NodeCount), c.GiVersion, c.LicenseModel, fmt.Sprintf("%d", len(c.Databases)), c.LifecycleState, c.CompartmentPath }
+					if all_regions { row = append(row, i.Region) }
+					table.AddRow(append(row, c.Id)...)
+					continue
+				}
+				for _, d := range c.Databases {
+					row := []string{ i.Type, i.Name, c.Name, d.Name, d.UniqueName, d.Workload, d.LifecycleState, c.CompartmentPath }
+					if all_regions { row = append(row, i.Region) }
+					table.AddRow(append(row, d.Id)...)
+				}
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d Exadata infrastructures, %d VM clusters, %d OCPUs enabled, %d databases\n", len(infrastructures), nb_clusters, total_ocpus, nb_databases)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
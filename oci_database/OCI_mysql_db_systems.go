@@ -0,0 +1,379 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists, starts or stops the MySQL DB systems (MySQL HeatWave) in all compartments of a OCI tenant
+// using OCI Go SDK in the region of the profile or in all subscribed regions
+//    list     : list all MySQL DB systems (shape, MySQL version, HeatWave cluster, state, compartment)
+//    status   : display the state of a MySQL DB system or of all MySQL DB systems matching a tag
+//    start    : start a MySQL DB system or all MySQL DB systems matching a tag
+//    stop     : stop a MySQL DB system or all MySQL DB systems matching a tag
+//    scheduled: stop or start the MySQL DB systems whose schedule tag matches the current UTC time (same tags
+//               as OCI_instances_stop_start_tagged.go, to be executed every hour by an external scheduler)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the shape and the HeatWave cluster of the DB systems are not available in the version of the OCI Go SDK
+//       used, so the MySQL REST API is used directly to list the DB systems (signed REST requests, see
+//       internal/ocihelpers/rest.go)
+//       deleted MySQL DB systems are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+//                 - OCI user with enough privileges to be able to read, stop and start MySQL DB systems
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/mysql"
+)
+
+// -- constants
+const mysql_endpoint    = "https://mysql.{region}.oci.{secondLevelDomain}"
+const mysql_api_version = "20190415"
+
+// Tag namespace and keys of the schedule (same as OCI_instances_stop_start_tagged.go)
+// Update these to match your tags (or use the --tag-ns, --tag-key-stop and --tag-key-start options)
+const default_tag_ns        = "osc"
+const default_tag_key_stop  = "automatic_shutdown"
+const default_tag_key_start = "automatic_startup"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// MySQL DB system returned by the MySQL REST API
+type db_system_rest struct {
+	Id                        string                            `json:"id"`
+	DisplayName               string                            `json:"displayName"`
+	CompartmentId             string                            `json:"compartmentId"`
+	LifecycleState            string                            `json:"lifecycleState"`
+	MysqlVersion              string                            `json:"mysqlVersion"`
+	ShapeName                 string                            `json:"shapeName"`
+	IsHeatWaveClusterAttached bool                              `json:"isHeatWaveClusterAttached"`
+	HeatWaveCluster           *heatwave_cluster_rest            `json:"heatWaveCluster"`
+	FreeformTags              map[string]string                 `json:"freeformTags"`
+	DefinedTags               map[string]map[string]interface{} `json:"definedTags"`
+}
+
+type heatwave_cluster_rest struct {
+	ShapeName      string `json:"shapeName"`
+	ClusterSize    int    `json:"clusterSize"`
+	LifecycleState string `json:"lifecycleState"`
+}
+
+// MySQL DB system found in a region
+type db_system struct {
+	Region string
+	Rest   db_system_rest
+}
+
+type db_system_json struct {
+	Name            string `json:"name"`
+	Id              string `json:"id"`
+	Shape           string `json:"shape"`
+	MysqlVersion    string `json:"mysql_version"`
+	HeatWave        bool   `json:"heatwave"`          // HeatWave cluster attached
+	HeatWaveShape   string `json:"heatwave_shape"`
+	HeatWaveNodes   int    `json:"heatwave_nodes"`
+	HeatWaveState   string `json:"heatwave_state"`
+	LifecycleState  string `json:"lifecycle_state"`
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] list\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] status|start|stop DB_SYSTEM_NAME\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --tag TAG status|start|stop\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] [--confirm_stop] [--confirm_start] scheduled\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    DB_SYSTEM_NAME is the display name of the MySQL DB system (not case sensitive).")
+    fmt.Println("    If --tag TAG is provided, only the MySQL DB systems matching the tag are processed:")
+    fmt.Println("      NAMESPACE.KEY=VALUE for a defined tag, KEY=VALUE for a freeform tag (ex: --tag osc.env=dev).")
+    fmt.Println("    The scheduled action stops (or starts) the MySQL DB systems whose stop (or start) tag value matches the")
+    fmt.Println("    current UTC time. Tag values must have the format HH:00_UTC (ex: 19:00_UTC), other values (ex: off) are ignored.")
+    fmt.Println("    If --confirm_stop  is not provided, the DB systems to stop are listed but not actually stopped (scheduled).")
+    fmt.Println("    If --confirm_start is not provided, the DB systems to start are listed but not actually started (scheduled).")
+    fmt.Printf ("    If --tag-ns NS is provided, this tag namespace is used instead of %s (scheduled).\n", default_tag_ns)
+    fmt.Printf ("    If --tag-key-stop KEY is provided, this tag key is used instead of %s (scheduled).\n", default_tag_key_stop)
+    fmt.Printf ("    If --tag-key-start KEY is provided, this tag key is used instead of %s (scheduled).\n", default_tag_key_start)
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If --profile PROFILE is provided, this OCI profile is used (default: OCI_CLI_PROFILE or DEFAULT).")
+    fmt.Println("    If -json is provided, the list/status is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list/status is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list/status is displayed as a Markdown table.")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error (ex: no MySQL DB system found), 2 = authentication error,")
+    fmt.Println("                3 = partial results (some regions or start/stop requests failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the MySQL DB systems (not deleted) in all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_db_systems(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]db_system, error) {
+	client, err := ocihelpers.NewRestClient(config, mysql_endpoint, mysql_api_version, region)
+	if err != nil { return nil, err }
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		db_systems := make([]db_system, 0)
+		query := url.Values{ "compartmentId" : { cpt_id } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			if page != nil { query.Set("page", *page) }
+			var items []db_system_rest
+			next_page, err := client.Get("/dbSystems", query, &items)
+			if err != nil { return nil, err }
+			for _, s := range items {
+				if s.LifecycleState == string(mysql.DbSystemLifecycleStateDeleted) { continue }
+				db_systems = append(db_systems, db_system{ region, s })
+			}
+			return next_page, nil
+		})
+		if err != nil { return nil, err }
+		return db_systems, nil
+	})
+	db_systems := make([]db_system, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		db_systems = append(db_systems, r.Value.([]db_system)...)
+	}
+	return db_systems, nil
+}
+
+// start or stop a MySQL DB system (nothing to do if it is already started or stopped)
+func start_stop_db_system(config common.ConfigurationProvider, s db_system, action string, path string) error {
+	client, err := mysql.NewDbSystemClientWithConfigurationProvider(config)
+	if err != nil { return err }
+	client.SetRegion(s.Region)
+
+	name  := s.Rest.DisplayName
+	state := s.Rest.LifecycleState
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	switch {
+	case action == "start" && state == string(mysql.DbSystemLifecycleStateInactive):
+		fmt.Printf ("%s, %s: STARTING MySQL DB system %s (%s)\n", s.Region, path, name, s.Rest.Id)
+		_, err = client.StartDbSystem(context.Background(), mysql.StartDbSystemRequest{ DbSystemId : common.String(s.Rest.Id), RequestMetadata : metadata })
+	case action == "stop" && state == string(mysql.DbSystemLifecycleStateActive):
+		fmt.Printf ("%s, %s: STOPPING MySQL DB system %s (%s)\n", s.Region, path, name, s.Rest.Id)
+		details := mysql.StopDbSystemDetails{ ShutdownType : mysql.InnoDbShutdownModeFast }
+		_, err = client.StopDbSystem(context.Background(), mysql.StopDbSystemRequest{ DbSystemId : common.String(s.Rest.Id), StopDbSystemDetails : details, RequestMetadata : metadata })
+	default:
+		fmt.Printf ("%s, %s: MySQL DB system %s (%s) is %s: nothing to do\n", s.Region, path, name, s.Rest.Id, state)
+	}
+	return err
+}
+
+// get the value of a defined tag (empty string if not set)
+func get_tag_value(s db_system, tag_ns string, key string) string {
+	if value, ok := s.Rest.DefinedTags[tag_ns][key]; ok {
+		return fmt.Sprintf("%v", value)
+	}
+	return ""
+}
+
+// display the list of MySQL DB systems in JSON, CSV, Markdown or text format
+func display_db_systems(db_systems []db_system, paths map[string]string, format string) {
+	items := make([]db_system_json, 0, len(db_systems))
+	for _, s := range db_systems {
+		r := s.Rest
+		item := db_system_json{
+			Name            : r.DisplayName,
+			Id              : r.Id,
+			Shape           : r.ShapeName,
+			MysqlVersion    : r.MysqlVersion,
+			HeatWave        : r.IsHeatWaveClusterAttached,
+			LifecycleState  : r.LifecycleState,
+			Region          : s.Region,
+			CompartmentId   : r.CompartmentId,
+			CompartmentPath : paths[r.CompartmentId],
+		}
+		if r.HeatWaveCluster != nil {
+			item.HeatWaveShape, item.HeatWaveNodes, item.HeatWaveState = r.HeatWaveCluster.ShapeName, r.HeatWaveCluster.ClusterSize, r.HeatWaveCluster.LifecycleState
+		}
+		items = append(items, item)
+	}
+	if format == "json" {
+		output, err := json.MarshalIndent(items, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+
+	table := ocihelpers.Table{ Headers : []string{ "name", "shape", "mysql_version", "heatwave", "state", "region", "compartment_path", "ocid" } }
+	for _, i := range items {
+		heatwave := "no"
+		if i.HeatWave { heatwave = fmt.Sprintf("%d x %s (%s)", i.HeatWaveNodes, i.HeatWaveShape, i.HeatWaveState) }
+		table.AddRow(i.Name, i.Shape, i.MysqlVersion, heatwave, i.LifecycleState, i.Region, i.CompartmentPath, i.Id)
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	profile := flag.String("profile", ocihelpers.GetProfile(nil), "OCI profile")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	tag             := flag.String("tag", "", "only process the MySQL DB systems matching this tag")
+	confirm_stop    := flag.Bool("confirm_stop", false, "actually stop the MySQL DB systems (scheduled)")
+	confirm_start   := flag.Bool("confirm_start", false, "actually start the MySQL DB systems (scheduled)")
+	tag_ns          := flag.String("tag-ns", default_tag_ns, "tag namespace of the schedule")
+	tag_key_stop    := flag.String("tag-key-stop", default_tag_key_stop, "tag key for the stop time")
+	tag_key_start   := flag.String("tag-key-start", default_tag_key_start, "tag key for the start time")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows")
+	flag.Parse()
+
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+
+	// action and MySQL DB system name (or --tag)
+	if flag.NArg() < 1 || flag.NArg() > 2 { usage() }
+	action := flag.Arg(0)
+	db_system_name := flag.Arg(1)
+	switch action {
+	case "list", "scheduled":
+		if db_system_name != "" { usage() }
+	case "status", "start", "stop":
+		if (db_system_name == "") == (*tag == "") { usage() }
+	default:
+		usage()
+	}
+	var tag_filter *ocihelpers.TagFilter
+	if *tag != "" {
+		f, err := ocihelpers.ParseTagFilter(*tag)
+		ocihelpers.FatalIfError(err)
+		tag_filter = &f
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, *profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of regions to process
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of MySQL DB systems in each region (regions processed concurrently)
+	// and keep the ones matching the name or the tag
+	nb_failed := 0
+	db_systems := make([]db_system, 0)
+	ocihelpers.StartProgress("MySQL DB systems", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_db_systems(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, s := range r.Value.([]db_system) {
+			if db_system_name != "" && !strings.EqualFold(s.Rest.DisplayName, db_system_name) { continue }
+			if tag_filter != nil && !tag_filter.Match(s.Rest.FreeformTags, s.Rest.DefinedTags) { continue }
+			db_systems = append(db_systems, s)
+		}
+	}
+	sort.SliceStable(db_systems, func(i, j int) bool {
+		if paths[db_systems[i].Rest.CompartmentId] != paths[db_systems[j].Rest.CompartmentId] {
+			return paths[db_systems[i].Rest.CompartmentId] < paths[db_systems[j].Rest.CompartmentId]
+		}
+		return db_systems[i].Rest.DisplayName < db_systems[j].Rest.DisplayName
+	})
+	if action != "list" && action != "scheduled" && len(db_systems) == 0 {
+		if db_system_name != "" { ocihelpers.Fatal("no MySQL DB system found with name %s", db_system_name) }
+		ocihelpers.Fatal("no MySQL DB system found with tag %s", tag_filter)
+	}
+
+	// Do the job
+	switch action {
+	case "list", "status":
+		display_db_systems(db_systems, paths, format)
+	case "start", "stop":
+		for _, s := range db_systems {
+			if err := start_stop_db_system(config, s, action, paths[s.Rest.CompartmentId]); err != nil {
+				fmt.Fprintf (os.Stderr, "ERROR: %s: %s\n", s.Rest.DisplayName, err)
+				nb_failed++
+			}
+		}
+	case "scheduled":
+		// current UTC time (format 10:00_UTC, 11:00_UTC ...)
+		current_utc_time := time.Now().UTC().Format("15") + ":00_UTC"
+		for _, s := range db_systems {
+			path := paths[s.Rest.CompartmentId]
+			scheduled_action, confirm := "", false
+			switch {
+			case s.Rest.LifecycleState == string(mysql.DbSystemLifecycleStateInactive) && get_tag_value(s, *tag_ns, *tag_key_start) == current_utc_time:
+				scheduled_action, confirm = "start", *confirm_start
+			case s.Rest.LifecycleState == string(mysql.DbSystemLifecycleStateActive) && get_tag_value(s, *tag_ns, *tag_key_stop) == current_utc_time:
+				scheduled_action, confirm = "stop", *confirm_stop
+			default:
+				continue
+			}
+			if !confirm {
+				if scheduled_action == "start" {
+					fmt.Printf ("%s, %s: MySQL DB system %s (%s) SHOULD BE STARTED --> re-run script with --confirm_start to actually start DB systems\n", s.Region, path, s.Rest.DisplayName, s.Rest.Id)
+				} else {
+					fmt.Printf ("%s, %s: MySQL DB system %s (%s) SHOULD BE STOPPED --> re-run script with --confirm_stop to actually stop DB systems\n", s.Region, path, s.Rest.DisplayName, s.Rest.Id)
+				}
+				continue
+			}
+			if err := start_stop_db_system(config, s, scheduled_action, path); err != nil {
+				fmt.Fprintf (os.Stderr, "ERROR: %s: %s\n", s.Rest.DisplayName, err)
+				nb_failed++
+			}
+		}
+	}
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,226 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the NoSQL Database tables in all compartments of a OCI tenant using OCI Go SDK, with their
+// provisioned capacity (read units, write units and storage) and their last usage record, to track the
+// provisioned-capacity spend
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the usage of a table is its most recent complete usage record (usually the last minute)
+//       deleted tables are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/nosql"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type table_json struct {
+	Name            string `json:"name"`
+	Id              string `json:"id"`
+	LifecycleState  string `json:"lifecycle_state"`
+	MaxReadUnits    int    `json:"max_read_units"`
+	MaxWriteUnits   int    `json:"max_write_units"`
+	MaxStorageInGBs int    `json:"max_storage_in_gbs"`
+	ReadUnits       int    `json:"read_units"`          // last usage record
+	WriteUnits      int    `json:"write_units"`         // last usage record
+	StorageInGBs    int    `json:"storage_in_gbs"`      // last usage record
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+	TimeCreated     string `json:"time_created"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each table, with its provisioned capacity (max read/write units and storage) and its")
+    fmt.Println("    last usage record (read/write units and storage used).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the NoSQL tables (not deleted) of all active compartments of a region, with their last usage record
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]table_json, error) {
+	client, err := nosql.NewNosqlClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		tables := make([]table_json, 0)
+		request := nosql.ListTablesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListTables(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, t := range response.Items {
+				if t.LifecycleState == nosql.TableLifecycleStateDeleted { continue }
+				item := table_json{ Name : *t.Name, Id : *t.Id, LifecycleState : string(t.LifecycleState), Region : region, CompartmentId : *t.CompartmentId }
+				if t.TimeCreated != nil { item.TimeCreated = t.TimeCreated.Format("2006-01-02T15:04:05Z") }
+				if t.TableLimits != nil {
+					item.MaxReadUnits, item.MaxWriteUnits, item.MaxStorageInGBs = *t.TableLimits.MaxReadUnits, *t.TableLimits.MaxWriteUnits, *t.TableLimits.MaxStorageInGBs
+				}
+				tables = append(tables, item)
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		return tables, nil
+	})
+	tables := make([]table_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		tables = append(tables, r.Value.([]table_json)...)
+	}
+
+	// most recent usage record of the active tables
+	for i := range tables {
+		if tables[i].LifecycleState != string(nosql.TableLifecycleStateActive) { continue }
+		response, err := client.ListTableUsage(context.Background(), nosql.ListTableUsageRequest{ TableNameOrId : common.String(tables[i].Id), RequestMetadata : metadata })
+		if err != nil { return nil, err }
+		if len(response.Items) == 0 { continue }
+		u := response.Items[len(response.Items)-1]
+		if u.ReadUnits != nil    { tables[i].ReadUnits = *u.ReadUnits }
+		if u.WriteUnits != nil   { tables[i].WriteUnits = *u.WriteUnits }
+		if u.StorageInGBs != nil { tables[i].StorageInGBs = *u.StorageInGBs }
+	}
+	return tables, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list tables in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list tables in all subscribed regions")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the tables in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("NoSQL tables", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	tables := make([]table_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, t := range r.Value.([]table_json) {
+			t.CompartmentPath = paths[t.CompartmentId]
+			tables = append(tables, t)
+		}
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		if tables[i].CompartmentPath != tables[j].CompartmentPath { return tables[i].CompartmentPath < tables[j].CompartmentPath }
+		return tables[i].Name < tables[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(tables, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "compartment_path", "name", "state", "max_read_units", "max_write_units", "max_storage_gb", "read_units", "write_units", "storage_gb" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		total_read, total_write, total_storage := 0, 0, 0
+		for _, t := range tables {
+			total_read, total_write, total_storage = total_read + t.MaxReadUnits, total_write + t.MaxWriteUnits, total_storage + t.MaxStorageInGBs
+			row := []string{ t.CompartmentPath, t.Name, t.LifecycleState, fmt.Sprintf("%d", t.MaxReadUnits), fmt.Sprintf("%d", t.MaxWriteUnits), fmt.Sprintf("%d", t.MaxStorageInGBs),
+				fmt.Sprintf("%d", t.ReadUnits), fmt.Sprintf("%d", t.WriteUnits), fmt.Sprintf("%d", t.StorageInGBs) }
+			if all_regions { row = append(row, t.Region) }
+			table.AddRow(append(row, t.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d tables, provisioned: %d read units, %d write units, %d GB storage\n", len(tables), total_read, total_write, total_storage)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
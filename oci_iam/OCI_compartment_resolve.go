@@ -0,0 +1,111 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script translates a compartment name (or full path) into its OCID, or a compartment OCID into its
+// full path (root/parent/.../name), using OCI Go SDK
+// It can be used by other scripts and shell pipelines (only the result is displayed on stdout)
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Use shared code from internal/ocihelpers
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE] COMPARTMENT\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip COMPARTMENT\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    COMPARTMENT can be:")
+    fmt.Println("    - a compartment OCID           : the full path of the compartment is displayed")
+    fmt.Println("    - a full path (root/Prod/Net)  : the OCID of the compartment is displayed")
+    fmt.Println("    - a compartment name           : the OCID of the compartment is displayed")
+    fmt.Println("      (error if several active compartments have this name: use full path instead)")
+    fmt.Println("")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)	
+}
+
+// -- main
+func main() {
+	
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	flag.Parse()
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 1) { usage() }
+	} else {
+		if (flag.NArg() < 1) || (flag.NArg() > 2) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args()[:flag.NArg()-1])
+	}
+	compartment := flag.Arg(flag.NArg()-1)
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+
+	// OCID --> full path
+	if strings.HasPrefix(compartment, "ocid1.") {
+		if compartment == tree.TenancyOCID {
+			fmt.Println ("root")
+			return
+		}
+		if _, found := tree.Get(compartment); !found {
+			ocihelpers.Fatal ("compartment %s not found in tenancy !", compartment)
+		}
+		fmt.Println (tree.FullPath(compartment))
+		return
+	}
+
+	// full path --> OCID
+	if strings.Contains(compartment, "/") || compartment == "root" {
+		cpt_id, found := tree.FindByPath(compartment)
+		if !found { ocihelpers.Fatal ("compartment %s not found in tenancy !", compartment) }
+		fmt.Println (cpt_id)
+		return
+	}
+
+	// name --> OCID
+	ocids := tree.FindByName(compartment)
+	switch len(ocids) {
+	case 0:
+		ocihelpers.Fatal ("compartment %s not found in tenancy !", compartment)
+	case 1:
+		fmt.Println (ocids[0])
+	default:
+		fmt.Fprintf (os.Stderr, "ERROR: %d active compartments named %s, please use full path instead:\n", len(ocids), compartment)
+		for _, id := range ocids {
+			fmt.Fprintf (os.Stderr, "    %s %s\n", tree.FullPath(id), id)
+		}
+		os.Exit (1)
+	}
+}
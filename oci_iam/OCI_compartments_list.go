@@ -8,6 +8,22 @@
 //                 - OCI config file configured with profiles
 // Versions
 //    2020-06-25: Initial Version
+//    2026-10-15: Add -json option to get the list in JSON format
+//    2026-10-15: Handle pagination in ListCompartments (tenants with many compartments)
+//    2026-10-15: Add -ip option to use instance principal authentication
+//    2026-10-15: Add -csv option to get the list in CSV format (with full path of compartments)
+//    2026-10-15: Add -path option to display full path of compartments instead of names
+//    2026-10-15: Add --show-tags option to display freeform and defined tags
+//    2026-10-15: Add --grep option to filter compartments by name or OCID (regular expression)
+//    2026-10-15: Add --config-file option and OCI_CONFIG_FILE environment variable
+//    2026-10-15: OCI_PROFILE is now optional (OCI_CLI_PROFILE environment variable or DEFAULT profile)
+//    2026-10-15: Support session token authentication (profiles with security_token_file)
+//    2026-10-15: Add --long option to display description and creation date of compartments
+//    2026-10-15: Add --all-profiles option to list compartments of all tenants in the config file
+//    2026-10-15: Use shared code from internal/ocihelpers
+//    2026-10-15: Add --markdown option to get the list as a Markdown table
+//    2026-10-15: Add --output-file option to write the output to a file
+//    2026-10-15: Add --quiet option and exit codes (2 = authentication error, 3 = some profiles failed)
 // --------------------------------------------------------------------------------------------------------------
 
 
@@ -15,68 +31,259 @@ package main
 
 // -- import
 import (
-	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"regexp"
 
 	"github.com/oracle/oci-go-sdk/common"
 	"github.com/oracle/oci-go-sdk/identity"
-	"github.com/oracle/oci-go-sdk/example/helpers"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
 )
 
-// -- constants
-const config_file string = "~/.oci/config"    // Define config file to be used.
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// options
+var json_output        bool
+var csv_output         bool
+var markdown_output    bool
+var full_path          bool
+var show_tags          bool
+var long               bool
+var all_profiles       bool
+var instance_principal bool
+var re                 *regexp.Regexp
+
+// -- types
+type compartment_json struct {
+	Profile        string `json:"profile,omitempty"`
+	Name           string `json:"name"`
+	Id             string `json:"id"`
+	ParentId       string `json:"parent_id"`
+	FullPath       string `json:"full_path"`
+	LifecycleState string `json:"lifecycle_state"`
+	Description    string `json:"description"`
+	TimeCreated    string `json:"time_created"`
+	FreeformTags   map[string]string                 `json:"freeform_tags,omitempty"`
+	DefinedTags    map[string]map[string]interface{} `json:"defined_tags,omitempty"`
+}
 
 // -- functions
 func usage() {
-    fmt.Printf ("Usage: %s OCI_PROFILE\n",os.Args[0])
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --all-profiles\n",os.Args[0])
     fmt.Println("")
-	fmt.Printf ("note: OCI_PROFILE must exist in %s file (see example below)\n",config_file)
-	fmt.Println("")
-    fmt.Println("[EMEAOSCf]")
-    fmt.Println("tenancy     = ocid1.tenancy.oc1..aaaaaaaaw7e6nkszrry6d5hxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx")
-    fmt.Println("user        = ocid1.user.oc1..aaaaaaaayblfepjieoxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx")
-    fmt.Println("fingerprint = 19:1d:7b:3a:17:xx:xx:xx:xx:xx:xx:xx:xx:xx:xx:xx")
-    fmt.Println("key_file    = /Users/cpauliat/.oci/api_key.pem")
-    fmt.Println("region      = eu-frankfurt-1")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If -path is provided, the full path of compartments (root/parent/name) is displayed instead of names.")
+    fmt.Println("    If --long is provided, the description and creation date of compartments are also displayed.")
+    fmt.Println("    If --show-tags is provided, the freeform tags and defined tags of compartments are also displayed.")
+    fmt.Println("    If --grep REGEX is provided, only the compartments whose name or OCID matches the regular expression")
+    fmt.Println("    are displayed.")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --all-profiles is provided, the compartments of all the profiles in the OCI config file are listed")
+    fmt.Println("    (each line is prefixed with the profile name).")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the profile banner and the CSV header row are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some profiles failed)")
+    ocihelpers.PrintAuthUsage(config_file)
 	os.Exit (1)	
 }
 
+// get the list of compartments in the JSON output format
+func get_json_items(cpts []identity.Compartment, paths map[string]string, profile string) []compartment_json {
+	slice := make([]compartment_json, 0, len(cpts))
+	for _, c := range cpts {
+		slice = append(slice, compartment_json{
+			Name           : *c.Name,
+			Id             : *c.Id,
+			ParentId       : *c.CompartmentId,
+			FullPath       : paths[*c.Id],
+			LifecycleState : string(c.LifecycleState),
+			Description    : *c.Description,
+			TimeCreated    : c.TimeCreated.Format("2006-01-02T15:04:05Z"),
+		})
+		if all_profiles {
+			slice[len(slice)-1].Profile = profile
+		}
+		if show_tags {
+			slice[len(slice)-1].FreeformTags = c.FreeformTags
+			slice[len(slice)-1].DefinedTags  = c.DefinedTags
+		}
+	}
+	return slice
+}
+
+func display_json(items []compartment_json) {
+	output, err := json.MarshalIndent(items, "", "  ")
+	ocihelpers.FatalIfError(err)
+	fmt.Println(string(output))
+}
+
+// get the columns of the CSV and Markdown formats
+func get_table_headers() []string {
+	headers := []string{"name", "ocid", "parent_ocid", "full_path", "state"}
+	if all_profiles { headers = append([]string{"profile"}, headers...) }
+	if long { headers = append(headers, "description", "time_created") }
+	if show_tags { headers = append(headers, "tags") }
+	return headers
+}
+
+// get the rows of the CSV and Markdown formats
+func get_table_rows(cpts []identity.Compartment, paths map[string]string, profile string) [][]string {
+	rows := make([][]string, 0, len(cpts))
+	for _, c := range cpts {
+		row := []string{ *c.Name, *c.Id, *c.CompartmentId, paths[*c.Id], string(c.LifecycleState) }
+		if all_profiles { row = append([]string{profile}, row...) }
+		if long { row = append(row, *c.Description, c.TimeCreated.Format("2006-01-02T15:04:05Z")) }
+		if show_tags { row = append(row, ocihelpers.FormatTags(c.FreeformTags, c.DefinedTags)) }
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// display the list in text format (prefixed with profile name if --all-profiles is used)
+func display_text(cpts []identity.Compartment, paths map[string]string, profile string) {
+	for i := range cpts {
+		cpt := cpts[i]
+		name := *cpt.Name
+		if full_path { name = paths[*cpt.Id] }
+		line := fmt.Sprintf("%s, %s, %s", name, *cpt.Id, cpt.LifecycleState)
+		if all_profiles { line = profile + ", " + line }
+		if long {
+			line += fmt.Sprintf(", %s, \"%s\"", cpt.TimeCreated.Format("2006-01-02 15:04"), *cpt.Description)
+		}
+		if show_tags {
+			line += fmt.Sprintf(", [%s]", ocihelpers.FormatTags(cpt.FreeformTags, cpt.DefinedTags))
+		}
+		fmt.Println(line)
+	}
+}
+
+// display information about the profile (or instance principal) used
+func display_profile_banner(config common.ConfigurationProvider, profile string) {
+	tenancy_ocid, _ := config.TenancyOCID()
+	region, _       := config.Region()
+	if instance_principal {
+		fmt.Println("Auth         = ","instance principal")
+		fmt.Println("Tenancy OCID = ",tenancy_ocid)
+	} else if _, ok := config.(ocihelpers.SessionTokenProvider); ok {
+		fmt.Println("OCI profile  = ",profile)
+		fmt.Println("Auth         = ","session token")
+		fmt.Println("Tenancy OCID = ",tenancy_ocid)
+	} else {
+		user_ocid, _   := config.UserOCID()
+		fingerprint, _ := config.KeyFingerprint()
+		fmt.Println("OCI profile  = ",profile)
+		fmt.Println("Tenancy OCID = ",tenancy_ocid)
+		fmt.Println("User OCID    = ",user_ocid)
+		fmt.Println("Fingerprint  = ",fingerprint)
+	}
+	fmt.Println("Region       = ",region)
+	fmt.Println("")
+}
+
+// get the list of compartments (filtered if --grep is used) and their full paths for a profile
+func get_compartments(profile string) (common.ConfigurationProvider, []identity.Compartment, map[string]string, error) {
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	if err != nil { return nil, nil, nil, err }
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	if err != nil { return nil, nil, nil, err }
+
+	cpts := tree.Compartments
+	if re != nil { cpts = tree.Filter(re) }
+	return config, cpts, tree.FullPaths(), nil
+}
+
 // -- main
 func main() {
 	
 	// Check arguments passed
-	if (len(os.Args) != 2) { usage() }
-	profile := os.Args[1]
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	flag.BoolVar(&json_output, "json", false, "display output in JSON format")
+	flag.BoolVar(&csv_output, "csv", false, "display output in CSV format")
+	flag.BoolVar(&markdown_output, "markdown", false, "display output as a Markdown table")
+	flag.BoolVar(&full_path, "path", false, "display full path of compartments instead of names")
+	flag.BoolVar(&show_tags, "show-tags", false, "display freeform and defined tags")
+	flag.BoolVar(&long, "long", false, "display description and creation date")
+	flag.BoolVar(&all_profiles, "all-profiles", false, "list compartments for all profiles in the OCI config file")
+	grep := flag.String("grep", "", "only display compartments whose name or OCID matches this regular expression")
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	output_file := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the profile banner and the CSV header row")
+	flag.Parse()
+	nb_formats := 0
+	for _, f := range []bool{ json_output, csv_output, markdown_output } {
+		if f { nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if all_profiles && instance_principal { usage() }
+	if *grep != "" {
+		var err error
+		re, err = regexp.Compile(*grep)
+		if err != nil {
+			fmt.Printf ("ERROR: invalid regular expression '%s': %s\n", *grep, err)
+			os.Exit (1)
+		}
+	}
 
-	// Try to load OCI config from profile
-	config, err := common.ConfigurationProviderFromFileWithProfile(config_file, profile, "")
-	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
-	helpers.FatalIfError(err)
+	// Get the list of profiles to process
+	profiles := []string{ "" }
+	if all_profiles {
+		if (flag.NArg() != 0) { usage() }
+		var err error
+		profiles, err = ocihelpers.ListProfiles(config_file)
+		ocihelpers.FatalIfError(err)
+	} else if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profiles = []string{ ocihelpers.GetProfile(flag.Args()) }
+	}
 
-	// Get info from profile
-	tenancy_ocid, _ := config.TenancyOCID()
-	user_ocid, _    := config.UserOCID()
-	fingerprint, _  := config.KeyFingerprint()
-	region, _       := config.Region()
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
 
-	fmt.Println("OCI profile  = ",profile)
-	fmt.Println("Tenancy OCID = ",tenancy_ocid)
-	fmt.Println("User OCID    = ",user_ocid)
-	fmt.Println("Fingerprint  = ",fingerprint)
-	fmt.Println("Region       = ",region)
-	fmt.Println("")
+	// Get and display the list of compartments for each profile
+	// (with --all-profiles, errors on a profile are displayed and the next profiles are processed)
+	json_items := make([]compartment_json, 0)
+	table := ocihelpers.Table{ Headers : get_table_headers() }
+	nb_failed := 0
+	for _, profile := range profiles {
+		config, cpts, paths, err := get_compartments(profile)
+		if err != nil {
+			if !all_profiles { ocihelpers.FatalIfError(err) }
+			fmt.Fprintf (os.Stderr, "ERROR: profile %s: %s\n", profile, err)
+			nb_failed++
+			continue
+		}
+
+		switch {
+		// JSON format (no profile banner, so output can be piped to jq)
+		case json_output:
+			json_items = append(json_items, get_json_items(cpts, paths, profile)...)
+
+		// CSV format (no profile banner, so output can be imported in a spreadsheet)
+		// and Markdown format (no profile banner, so output can be pasted in a wiki)
+		case csv_output, markdown_output:
+			table.Rows = append(table.Rows, get_table_rows(cpts, paths, profile)...)
 
-	// Get the list of compartments
-	vrai := true
-	request := identity.ListCompartmentsRequest{ 
-		CompartmentId : common.String(tenancy_ocid), 
-		CompartmentIdInSubtree : &vrai, 
+		default:
+			if !all_profiles && !ocihelpers.Quiet { display_profile_banner(config, profile) }
+			display_text(cpts, paths, profile)
+		}
 	}
-	list, err := client.ListCompartments(context.Background(), request)
-	helpers.FatalIfError(err)
 
-	for i := range list.Items {
-		cpt := list.Items[i]
-		fmt.Printf("%s, %s, %s\n", *cpt.Name, *cpt.Id, cpt.LifecycleState)	}	
+	if json_output { display_json(json_items) }
+	if csv_output { ocihelpers.FatalIfError(table.Print("csv")) }
+	if markdown_output { ocihelpers.FatalIfError(table.Print("markdown")) }
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
 }
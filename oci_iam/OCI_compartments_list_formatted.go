@@ -8,6 +8,21 @@
 //                 - OCI config file configured with profiles
 // Versions
 //    2020-06-25: Initial Version
+//    2026-10-15: Handle pagination in ListCompartments (tenants with many compartments)
+//    2026-10-15: Add -ip option to use instance principal authentication
+//    2026-10-15: Add --no-color option and disable colors when output is not a terminal
+//    2026-10-15: Add --show-tags option to display freeform and defined tags
+//    2026-10-15: Add --max-depth option to limit the depth of the tree
+//    2026-10-15: Sort sub-compartments by name (or by creation date with --sort created)
+//    2026-10-15: Add -dot option to get the tree in Graphviz DOT format
+//    2026-10-15: Add --html option to get the tree as a collapsible HTML list
+//    2026-10-15: Add --grep option to filter compartments by name or OCID (regular expression)
+//    2026-10-15: Add --config-file option and OCI_CONFIG_FILE environment variable
+//    2026-10-15: OCI_PROFILE is now optional (OCI_CLI_PROFILE environment variable or DEFAULT profile)
+//    2026-10-15: Support session token authentication (profiles with security_token_file)
+//    2026-10-15: Use shared code from internal/ocihelpers
+//    2026-10-15: Add --output-file option to write the output to a file (without colors)
+//    2026-10-15: Use the tree renderers (text, DOT, HTML) shared with ocitools compartments tree
 // --------------------------------------------------------------------------------------------------------------
 
 
@@ -15,130 +30,106 @@ package main
 
 // -- import
 import (
-	"context"
+	"flag"
 	"fmt"
 	"os"
+	"regexp"
 
-	"github.com/oracle/oci-go-sdk/common"
-	"github.com/oracle/oci-go-sdk/identity"
-	"github.com/oracle/oci-go-sdk/example/helpers"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
 )
 
-// -- constants
-const config_file string = "~/.oci/config"    // Define config file to be used.
-const COLOR_YELLOW = "\033[93m"
-const COLOR_RED    = "\033[91m"
-const COLOR_GREEN  = "\033[32m"
-const COLOR_NORMAL = "\033[39m"
-const COLOR_CYAN   = "\033[96m"
-const COLOR_BLUE   = "\033[94m"
-const COLOR_GREY   = "\033[90m"
-
 // -- global variables
-var flag [10]int
+var config_file = ocihelpers.GetConfigFile()
 
 // -- functions
 func usage() {
-    fmt.Printf ("Usage: %s OCI_PROFILE\n",os.Args[0])
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
     fmt.Println("")
-	fmt.Printf ("note: OCI_PROFILE must exist in %s file (see example below)\n",config_file)
-	fmt.Println("")
-    fmt.Println("[EMEAOSCf]")
-    fmt.Println("tenancy     = ocid1.tenancy.oc1..aaaaaaaaw7e6nkszrry6d5hxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx")
-    fmt.Println("user        = ocid1.user.oc1..aaaaaaaayblfepjieoxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx")
-    fmt.Println("fingerprint = 19:1d:7b:3a:17:xx:xx:xx:xx:xx:xx:xx:xx:xx:xx:xx")
-    fmt.Println("key_file    = /Users/cpauliat/.oci/api_key.pem")
-    fmt.Println("region      = eu-frankfurt-1")
+    fmt.Println("    If -dot is provided, the tree is displayed as a Graphviz digraph (DOT format)")
+    fmt.Println("    which can be rendered with: dot -Tpng -o compartments.png")
+    fmt.Println("    If --html is provided, the tree is displayed as a collapsible HTML list (HTML page).")
+    fmt.Println("    By default, sub-compartments are sorted by name. If --sort created is provided, they are")
+    fmt.Println("    sorted by creation date.")
+    fmt.Println("    If --grep REGEX is provided, only the compartments whose name or OCID matches the regular expression")
+    fmt.Println("    are displayed, with their parent compartments.")
+    fmt.Println("    If --max-depth N is provided, sub-compartments below level N are not displayed")
+    fmt.Println("    (a count of hidden sub-compartments is displayed instead).")
+    fmt.Println("    If --show-tags is provided, the freeform tags and defined tags of compartments are also displayed.")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout (without colors).")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    Colors are also disabled when the output is not a terminal (redirected to a file or a pipe).")
+    ocihelpers.PrintAuthUsage(config_file)
 	os.Exit (1)	
 }
 
-func get_cpt_name_and_state_from_id(cpt_id string, cpts []identity.Compartment) (string, string) {
-	for _, c := range cpts {
-        if (*c.Id == cpt_id) {
-            return *c.Name, string(c.LifecycleState)
-		}
-	}
-	return "UNKNOWN", "UNKNOWN"
-}
+// -- main
+func main() {
 	
-func display_formatted_list (parent_id string, level int, cpts []identity.Compartment) {
-    // level = 0 for root, 1 for 1st level compartments, ...
-
-	var cptname string
-	var state string
-
-    for i := 1; i < level; i++ {
-        if flag[i] == 0 {
-			fmt.Printf (COLOR_CYAN+"│      "+COLOR_NORMAL)
-		} else {
-            fmt.Printf ("       ")
-		}
-	}
-
-    if level > 0 {
-        cptname, state = get_cpt_name_and_state_from_id (parent_id, cpts)   
-        if flag[level] == 0 {
-			fmt.Printf (COLOR_CYAN+"├───── "+COLOR_NORMAL)
-		} else {
-            fmt.Printf (COLOR_CYAN+"└───── "+COLOR_NORMAL)
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	no_color := flag.Bool("no-color", false, "display output without colors")
+	show_tags := flag.Bool("show-tags", false, "display freeform and defined tags")
+	max_depth := flag.Int("max-depth", -1, "maximum depth of the tree (no limit by default)")
+	sort_by := flag.String("sort", "name", "sort sub-compartments by name or by creation date (name|created)")
+	dot_output := flag.Bool("dot", false, "display the tree in Graphviz DOT format")
+	html_output := flag.Bool("html", false, "display the tree as a collapsible HTML list")
+	grep := flag.String("grep", "", "only display compartments whose name or OCID matches this regular expression")
+	output_file := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.Parse()
+	if *dot_output && *html_output { usage() }
+	var re *regexp.Regexp
+	if *grep != "" {
+		var err error
+		re, err = regexp.Compile(*grep)
+		if err != nil {
+			fmt.Printf ("ERROR: invalid regular expression '%s': %s\n", *grep, err)
+			os.Exit (1)
 		}
-    } else {
-        cptname = "root"
-        state   = "ACTIVE"
 	}
-	
-    if state == "ACTIVE" {
-		fmt.Println (COLOR_GREEN+cptname+COLOR_NORMAL+" "+parent_id+COLOR_YELLOW+" ACTIVE"+COLOR_NORMAL)
+	if *sort_by != "name" && *sort_by != "created" { usage() }
+	ocihelpers.SetupColors(*no_color)
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
 	} else {
-        fmt.Println (COLOR_BLUE+cptname+COLOR_GREY+" "+parent_id+COLOR_RED+" DELETED"+COLOR_NORMAL)
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
 	}
 
-	// get the list of ids of the direct sub-compartments and store it in a Go slice
-	slice := make([]string,0)
-	for _, c := range cpts {
-        if (*c.CompartmentId == parent_id) {
-            slice = append (slice, *c.Id)
-		}
-	}
-    
-    // then for each of those cpt ids, display the sub-compartments details
-	for i, cid := range slice {
-		// if processing the last sub dir
-		if i == len(slice)-1 {
-			flag[level+1] = 1
-		} else {
-			flag[level+1] = 0
-		}
-		
-		// display list of direct sub-folders
-		display_formatted_list (cid, level+1, cpts)
-	}
-}
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
 
-// -- main
-func main() {
-	
-	// Check arguments passed
-	if (len(os.Args) != 2) { usage() }
-	profile := os.Args[1]
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	tree.SortBy = *sort_by
 
-	// Try to load OCI config from profile
-	config, err := common.ConfigurationProviderFromFileWithProfile(config_file, profile, "")
-	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
-	helpers.FatalIfError(err)
+	// Keep only the compartments matching the regular expression (and their parents)
+	if re != nil { tree.SetVisible(re) }
 
-	// Get tenancy OCID from profile
-	tenancy_ocid, _ := config.TenancyOCID()
+	// Display the tree in Graphviz DOT format
+	if *dot_output {
+		tree.FprintDot(os.Stdout, *max_depth, *show_tags)
+		return
+	}
 
-	// Get the list of all compartments and sub-comparments
-	vrai := true
-	request := identity.ListCompartmentsRequest{ 
-		CompartmentId : common.String(tenancy_ocid), 
-		CompartmentIdInSubtree : &vrai, 
+	// Display the tree as an HTML page
+	if *html_output {
+		tree.FprintHTML(os.Stdout, *max_depth, *show_tags)
+		return
 	}
-	list, err := client.ListCompartments(context.Background(), request)
-	helpers.FatalIfError(err)
 
 	// Display the list in a formatted output
-	display_formatted_list (tenancy_ocid, 0, list.Items)
+	tree.FprintText(os.Stdout, *max_depth, *show_tags)
 }
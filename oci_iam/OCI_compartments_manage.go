@@ -0,0 +1,233 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script manages compartments in a OCI tenant using OCI Go SDK:
+// - create a compartment under a parent compartment
+// - rename a compartment
+// - move a compartment under another parent compartment
+// - delete a compartment
+// Compartments can be given by OCID, full path (root/Prod/Networking) or name (if unique)
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Note: requests are sent to the home region of the tenant (required for IAM write operations)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+//                 - OCI user with enough privileges to manage compartments (policy example below)
+//                       allow group cpt_admins to manage compartments in tenancy
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Use shared code from internal/ocihelpers
+//    2026-10-15: Retry requests throttled by OCI (HTTP 429) with exponential backoff
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+var dry_run bool
+var yes bool
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE] create PARENT_COMPARTMENT NAME DESCRIPTION\n",os.Args[0])
+    fmt.Printf ("       %s [options] [OCI_PROFILE] rename COMPARTMENT NEW_NAME\n",os.Args[0])
+    fmt.Printf ("       %s [options] [OCI_PROFILE] move   COMPARTMENT NEW_PARENT_COMPARTMENT\n",os.Args[0])
+    fmt.Printf ("       %s [options] [OCI_PROFILE] delete COMPARTMENT\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip ACTION ...\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    Compartments can be given by OCID, by full path (ex: root/Prod/Networking) or by name (if unique).")
+    fmt.Println("")
+    fmt.Println("    If --dry-run is provided, the actions are displayed but not executed.")
+    fmt.Println("    Move and delete actions ask for a confirmation, unless --yes is provided.")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)	
+}
+
+// ask for a confirmation (unless --yes is provided)
+func confirm(question string) bool {
+	if yes { return true }
+	fmt.Printf ("%s ? (y/n): ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// get the OCID of an active compartment given by OCID, full path or name (if unique)
+func resolve_compartment(compartment string, tree *ocihelpers.CompartmentTree) string {
+	if compartment == "root" || compartment == tree.TenancyOCID { return tree.TenancyOCID }
+
+	cpt_id := compartment
+	switch {
+	// OCID
+	case strings.HasPrefix(compartment, "ocid1."):
+
+	// full path
+	case strings.Contains(compartment, "/"):
+		found := false
+		cpt_id, found = tree.FindByPath(compartment)
+		if !found { ocihelpers.Fatal ("compartment %s not found in tenancy !", compartment) }
+
+	// name
+	default:
+		ocids := tree.FindByName(compartment)
+		if len(ocids) == 0 { ocihelpers.Fatal ("compartment %s not found in tenancy !", compartment) }
+		if len(ocids) > 1  { ocihelpers.Fatal ("%d active compartments named %s, please use full path or OCID instead", len(ocids), compartment) }
+		cpt_id = ocids[0]
+	}
+
+	if c, found := tree.Get(cpt_id); !found || c.LifecycleState != identity.CompartmentLifecycleStateActive {
+		ocihelpers.Fatal ("active compartment %s not found in tenancy !", compartment)
+	}
+	return cpt_id
+}
+
+func create_compartment(client identity.IdentityClient, parent_id string, name string, description string) {
+	request := identity.CreateCompartmentRequest{
+		CreateCompartmentDetails : identity.CreateCompartmentDetails{
+			CompartmentId : common.String(parent_id),
+			Name          : common.String(name),
+			Description   : common.String(description),
+		},
+		OpcRetryToken   : common.String(common.RetryToken()),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	response, err := client.CreateCompartment(context.Background(), request)
+	ocihelpers.FatalIfError(err)
+	fmt.Printf ("Compartment %s created: %s\n", name, *response.Id)
+}
+
+func rename_compartment(client identity.IdentityClient, cpt_id string, new_name string) {
+	request := identity.UpdateCompartmentRequest{
+		CompartmentId            : common.String(cpt_id),
+		UpdateCompartmentDetails : identity.UpdateCompartmentDetails{ Name : common.String(new_name) },
+		RequestMetadata          : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	_, err := client.UpdateCompartment(context.Background(), request)
+	ocihelpers.FatalIfError(err)
+	fmt.Printf ("Compartment %s renamed to %s\n", cpt_id, new_name)
+}
+
+func move_compartment(client identity.IdentityClient, cpt_id string, new_parent_id string) {
+	request := identity.MoveCompartmentRequest{
+		CompartmentId          : common.String(cpt_id),
+		MoveCompartmentDetails : identity.MoveCompartmentDetails{ TargetCompartmentId : common.String(new_parent_id) },
+		RequestMetadata        : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	response, err := client.MoveCompartment(context.Background(), request)
+	ocihelpers.FatalIfError(err)
+	fmt.Printf ("Move of compartment %s started (work request %s)\n", cpt_id, *response.OpcWorkRequestId)
+}
+
+func delete_compartment(client identity.IdentityClient, cpt_id string) {
+	request := identity.DeleteCompartmentRequest{
+		CompartmentId   : common.String(cpt_id),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	response, err := client.DeleteCompartment(context.Background(), request)
+	ocihelpers.FatalIfError(err)
+	fmt.Printf ("Deletion of compartment %s started (work request %s)\n", cpt_id, *response.OpcWorkRequestId)
+}
+
+// -- main
+func main() {
+	
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	flag.BoolVar(&dry_run, "dry-run", false, "display the actions without executing them")
+	flag.BoolVar(&yes, "yes", false, "do not ask for confirmation")
+	flag.Parse()
+
+	// OCI_PROFILE is optional: the first argument is the profile if it is not an action
+	args := flag.Args()
+	if len(args) == 0 { usage() }
+	profile := ""
+	if !instance_principal {
+		switch args[0] {
+		case "create", "rename", "move", "delete":
+			profile = ocihelpers.GetProfile([]string{})
+		default:
+			profile = ocihelpers.GetProfile(args[:1])
+			args = args[1:]
+		}
+	}
+	if len(args) == 0 { usage() }
+	action := args[0]
+	switch {
+	case action == "create" && len(args) == 4:
+	case action == "rename" && len(args) == 3:
+	case action == "move"   && len(args) == 3:
+	case action == "delete" && len(args) == 2:
+	default:
+		usage()
+	}
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+
+	// Send requests to the home region
+	home_region, err := ocihelpers.GetHomeRegion(config)
+	ocihelpers.FatalIfError(err)
+	client.SetRegion(home_region)
+
+	// Get the list of all compartments and sub-comparments to resolve names and paths
+	// (never from the cache file, which is removed after the action as it is no longer up to date)
+	ocihelpers.RefreshCompartmentCache = true
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	tenancy_ocid := tree.TenancyOCID
+	cpt_id := resolve_compartment(args[1], tree)
+	cpt_path := tree.FullPath(cpt_id)
+	if dry_run { fmt.Print ("DRY RUN: ") }
+
+	switch action {
+	case "create":
+		fmt.Printf ("Create compartment %s/%s\n", cpt_path, args[2])
+		if !dry_run { create_compartment(client, cpt_id, args[2], args[3]) }
+
+	case "rename":
+		if cpt_id == tenancy_ocid { ocihelpers.Fatal ("cannot rename root compartment") }
+		fmt.Printf ("Rename compartment %s to %s\n", cpt_path, args[2])
+		if !dry_run { rename_compartment(client, cpt_id, args[2]) }
+
+	case "move":
+		if cpt_id == tenancy_ocid { ocihelpers.Fatal ("cannot move root compartment") }
+		new_parent_id := resolve_compartment(args[2], tree)
+		new_parent_path := tree.FullPath(new_parent_id)
+		if new_parent_id == cpt_id || strings.HasPrefix(new_parent_path+"/", cpt_path+"/") {
+			ocihelpers.Fatal ("cannot move compartment %s under itself", cpt_path)
+		}
+		fmt.Printf ("Move compartment %s under %s\n", cpt_path, new_parent_path)
+		if !dry_run && confirm("Do you really want to move compartment "+cpt_path) {
+			move_compartment(client, cpt_id, new_parent_id)
+		}
+
+	case "delete":
+		if cpt_id == tenancy_ocid { ocihelpers.Fatal ("cannot delete root compartment") }
+		fmt.Printf ("Delete compartment %s (%s)\n", cpt_path, cpt_id)
+		if !dry_run && confirm("Do you really want to delete compartment "+cpt_path) {
+			delete_compartment(client, cpt_id)
+		}
+	}
+	if !dry_run { ocihelpers.ClearCompartmentCache(tenancy_ocid) }
+}
@@ -0,0 +1,218 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script displays, for each compartment in a OCI tenant, the number of resources it contains
+// (optionally broken down by resource type) using the Resource Search service of OCI Go SDK
+// It helps to find empty compartments that could be deleted
+// Note: sub-compartments are counted as resources of their parent compartment
+//       terminated and deleted resources are ignored
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process all regions concurrently with -a (ocihelpers.ForEachRegion)
+//    2026-10-15: Use shared code from internal/ocihelpers
+//    2026-10-15: Retry requests throttled by OCI (HTTP 429) with exponential backoff
+//    2026-10-15: Add --markdown option to get the results as a Markdown table
+//    2026-10-15: Add --output-file option to write the output to a file
+//    2026-10-15: Add --quiet option and exit codes (2 = authentication error, 3 = some regions failed)
+//    2026-10-15: Add --all-regions option (same as -a)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+	"github.com/oracle/oci-go-sdk/resourcesearch"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If -a or --all-regions is provided, resources are counted in all subscribed regions instead of the region")
+    fmt.Println("    of the profile.")
+    fmt.Println("    If --by-type is provided, the number of resources is also displayed for each resource type.")
+    fmt.Println("    If --empty-only is provided, only the empty compartments are displayed.")
+    fmt.Println("    If --markdown is provided, the results are displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the summary line is not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed with -a)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)	
+}
+
+// count the resources per compartment and per resource type in a region, using a single search query
+// counts[compartment_ocid][resource_type] is the number of resources found
+func count_resources(config common.ConfigurationProvider, region string) (map[string]map[string]int, error) {
+	client, err := resourcesearch.NewResourceSearchClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	if region != "" { client.SetRegion(region) }
+
+	counts := make(map[string]map[string]int)
+	request := resourcesearch.SearchResourcesRequest{
+		SearchDetails   : resourcesearch.StructuredSearchDetails{ Query : common.String("query all resources") },
+		Limit           : common.Int(1000),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.SearchResources(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, r := range response.Items {
+			if r.LifecycleState != nil {
+				state := strings.ToUpper(*r.LifecycleState)
+				if state == "TERMINATED" || state == "DELETED" { continue }
+			}
+			if counts[*r.CompartmentId] == nil { counts[*r.CompartmentId] = make(map[string]int) }
+			counts[*r.CompartmentId][*r.ResourceType]++
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+	return counts, nil
+}
+
+// add the counts of a region to the total counts
+func add_counts(total map[string]map[string]int, counts map[string]map[string]int) {
+	for cpt_id, types := range counts {
+		if total[cpt_id] == nil { total[cpt_id] = make(map[string]int) }
+		for t, n := range types { total[cpt_id][t] += n }
+	}
+}
+
+// -- main
+func main() {
+	
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "count resources in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "count resources in all subscribed regions")
+	by_type     := flag.Bool("by-type", false, "display the number of resources per resource type")
+	empty_only  := flag.Bool("empty-only", false, "only display empty compartments")
+	markdown    := flag.Bool("markdown", false, "display the results as a Markdown table")
+	output_file := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the summary line")
+	flag.Parse()
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+
+	// Count the resources in the region of the profile or in all subscribed regions (concurrently)
+	counts := make(map[string]map[string]int)
+	nb_failed := 0
+	if all_regions {
+		regions, err := ocihelpers.ListSubscribedRegions(config)
+		ocihelpers.FatalIfError(err)
+		ocihelpers.StartProgress("resources", len(regions), 0)
+		results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+			return count_resources(config, region)
+		})
+		ocihelpers.StopProgress()
+		for _, r := range results {
+			if r.Err != nil {
+				fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+				nb_failed++
+				continue
+			}
+			add_counts(counts, r.Value.(map[string]map[string]int))
+		}
+	} else {
+		region_counts, err := count_resources(config, "")
+		ocihelpers.FatalIfError(err)
+		add_counts(counts, region_counts)
+	}
+
+	// Display the results for active compartments, sorted by full path
+	paths := make(map[string]string)
+	paths[tree.TenancyOCID] = "root"
+	for _, c := range tree.Compartments {
+		if c.LifecycleState == identity.CompartmentLifecycleStateActive {
+			paths[*c.Id] = tree.FullPath(*c.Id)
+		}
+	}
+	ids := make([]string, 0, len(paths))
+	for id := range paths { ids = append(ids, id) }
+	sort.Slice(ids, func(i, j int) bool { return paths[ids[i]] < paths[ids[j]] })
+
+	nb_empty := 0
+	table := ocihelpers.Table{ Headers : []string{ "resources", "compartment", "ocid" } }
+	if *by_type { table.Headers = append(table.Headers, "resources_by_type") }
+	for _, id := range ids {
+		total := 0
+		for _, n := range counts[id] { total += n }
+		if total == 0 { nb_empty++ }
+		if *empty_only && total > 0 { continue }
+		types := make([]string, 0)
+		for t := range counts[id] { types = append(types, t) }
+		sort.Strings(types)
+
+		// Markdown table: resource types and counts in a single cell
+		if *markdown {
+			row := []string{ fmt.Sprintf("%d", total), paths[id], id }
+			if *by_type {
+				by_type_list := make([]string, 0, len(types))
+				for _, t := range types { by_type_list = append(by_type_list, fmt.Sprintf("%s: %d", t, counts[id][t])) }
+				row = append(row, strings.Join(by_type_list, ", "))
+			}
+			table.AddRow(row...)
+			continue
+		}
+
+		fmt.Printf ("%6d  %-60s %s\n", total, paths[id], id)
+		if *by_type && total > 0 {
+			for _, t := range types {
+				fmt.Printf ("%6s      %-30s %d\n", "", t, counts[id][t])
+			}
+		}
+	}
+	if *markdown { ocihelpers.FatalIfError(table.Print("markdown")) }
+	if !ocihelpers.Quiet {
+		fmt.Println ("")
+		fmt.Printf ("%d active compartments (including root), %d empty\n", len(ids), nb_empty)
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
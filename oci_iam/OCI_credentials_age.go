@@ -0,0 +1,199 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the API keys, auth tokens and customer secret keys of all the IAM users of a OCI tenant
+// with their creation date and age using OCI Go SDK, to check credential rotation (compliance evidence)
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type credential_json struct {
+	UserName    string `json:"user_name"`
+	UserId      string `json:"user_id"`
+	Type        string `json:"type"`          // api_key, auth_token or customer_secret_key
+	Name        string `json:"name"`          // fingerprint of API keys, description or display name of the others
+	Id          string `json:"id"`
+	TimeCreated string `json:"time_created"`
+	AgeDays     int    `json:"age_days"`
+	TooOld      bool   `json:"too_old"`       // age above the threshold
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    The active API keys, auth tokens and customer secret keys are listed (oldest first). Credentials older")
+    fmt.Println("    than the threshold are displayed in red.")
+    fmt.Println("    If --max-age N is provided, N days is used as threshold (default 90).")
+    fmt.Println("    If --old-only is provided, only the credentials older than the threshold are listed.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// get the active credentials of a user (API keys, auth tokens and customer secret keys)
+func list_credentials(client identity.IdentityClient, user ocihelpers.User) ([]credential_json, error) {
+	creds := make([]credential_json, 0)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	add := func(cred_type string, name string, id string, time_created *common.SDKTime) {
+		creds = append(creds, credential_json{ UserName : user.Name, UserId : user.Id, Type : cred_type, Name : name, Id : id, TimeCreated : time_created.Format("2006-01-02"), AgeDays : ocihelpers.DaysSince(time_created.Time) })
+	}
+
+	api_keys, err := client.ListApiKeys(context.Background(), identity.ListApiKeysRequest{ UserId : common.String(user.Id), RequestMetadata : metadata })
+	if err != nil { return nil, err }
+	for _, k := range api_keys.Items {
+		if k.LifecycleState == identity.ApiKeyLifecycleStateActive { add("api_key", *k.Fingerprint, *k.KeyId, k.TimeCreated) }
+	}
+
+	auth_tokens, err := client.ListAuthTokens(context.Background(), identity.ListAuthTokensRequest{ UserId : common.String(user.Id), RequestMetadata : metadata })
+	if err != nil { return nil, err }
+	for _, t := range auth_tokens.Items {
+		if t.LifecycleState == identity.AuthTokenLifecycleStateActive { add("auth_token", *t.Description, *t.Id, t.TimeCreated) }
+	}
+
+	secret_keys, err := client.ListCustomerSecretKeys(context.Background(), identity.ListCustomerSecretKeysRequest{ UserId : common.String(user.Id), RequestMetadata : metadata })
+	if err != nil { return nil, err }
+	for _, k := range secret_keys.Items {
+		if k.LifecycleState == identity.CustomerSecretKeySummaryLifecycleStateActive { add("customer_secret_key", *k.DisplayName, *k.Id, k.TimeCreated) }
+	}
+	return creds, nil
+}
+
+// sort the credentials by age (oldest first), then by user name
+func sort_by_age(creds []credential_json) {
+	sort.SliceStable(creds, func(i, j int) bool {
+		if creds[i].AgeDays != creds[j].AgeDays { return creds[i].AgeDays > creds[j].AgeDays }
+		return creds[i].UserName < creds[j].UserName
+	})
+}
+
+// display the table in text format, with the credentials older than the threshold in red
+func display_text(table ocihelpers.Table, creds []credential_json) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(creds) { break }
+		if creds[i].TooOld { line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL }
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	max_age         := flag.Int("max-age", 90, "threshold in days")
+	old_only        := flag.Bool("old-only", false, "only list the credentials older than the threshold")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *max_age < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of users (IAM users are global resources)
+	users, err := ocihelpers.ListUsers(config)
+	ocihelpers.FatalIfError(err)
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+
+	// Get the credentials of all users
+	results := make([]credential_json, 0)
+	nb_old := 0
+	for _, u := range users {
+		creds, err := list_credentials(client, u)
+		ocihelpers.FatalIfError(err)
+		for _, c := range creds {
+			c.TooOld = c.AgeDays > *max_age
+			if c.TooOld { nb_old++ }
+			if *old_only && !c.TooOld { continue }
+			results = append(results, c)
+		}
+	}
+	sort_by_age(results)
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(results, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "user", "type", "name", "created", "age_days", "ocid" } }
+	for _, c := range results { table.AddRow(c.UserName, c.Type, c.Name, c.TimeCreated, fmt.Sprintf("%d", c.AgeDays), c.Id) }
+	if format != "text" {
+		ocihelpers.FatalIfError(table.Print(format))
+		return
+	}
+	display_text(table, results)
+	if !ocihelpers.Quiet {
+		fmt.Printf ("\n%d credentials older than %d days\n", nb_old, *max_age)
+	}
+}
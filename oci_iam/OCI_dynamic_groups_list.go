@@ -0,0 +1,200 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the dynamic groups of a OCI tenant with their matching rules using OCI Go SDK,
+// and can check which dynamic groups a resource (instance, function, ...) belongs to
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       the compartment and defined tags of the resource are found with the Resource Search service
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+	"github.com/oracle/oci-go-sdk/resourcesearch"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type dynamic_group_json struct {
+	Name         string `json:"name"`
+	Id           string `json:"id"`
+	Description  string `json:"description"`
+	MatchingRule string `json:"matching_rule"`
+	Match        *bool  `json:"match,omitempty"`   // only with --ocid
+	Error        string `json:"error,omitempty"`   // invalid matching rule
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --ocid RESOURCE_OCID [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip ...\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, the dynamic groups are listed with their matching rules.")
+    fmt.Println("    If --ocid RESOURCE_OCID is provided, the matching rules are evaluated for this resource")
+    fmt.Println("    (instance.id, instance.compartment.id, resource.id, resource.type, resource.compartment.id")
+    fmt.Println("    and tag.NAMESPACE.KEY.value attributes).")
+    fmt.Println("    If --matching-only is provided with --ocid, only the dynamic groups matching the resource are listed.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the active dynamic groups of a tenant (sorted by name)
+func list_dynamic_groups(client identity.IdentityClient, tenancy_ocid string) ([]dynamic_group_json, error) {
+	groups := make([]dynamic_group_json, 0)
+	request := identity.ListDynamicGroupsRequest{ CompartmentId : common.String(tenancy_ocid), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListDynamicGroups(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, g := range response.Items {
+			if g.LifecycleState != identity.DynamicGroupLifecycleStateActive { continue }
+			groups = append(groups, dynamic_group_json{ Name : *g.Name, Id : *g.Id, Description : *g.Description, MatchingRule : *g.MatchingRule })
+		}
+		return response.OpcNextPage, nil
+	})
+	sort.Slice(groups, func(i, j int) bool { return strings.ToLower(groups[i].Name) < strings.ToLower(groups[j].Name) })
+	return groups, err
+}
+
+// get the attributes of a resource used in matching rules, with the Resource Search service
+// (in the region of the resource, given by its OCID)
+func get_resource_attributes(config common.ConfigurationProvider, ocid string) (map[string]string, error) {
+	client, err := resourcesearch.NewResourceSearchClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	if fields := strings.Split(ocid, "."); len(fields) > 3 && fields[3] != "" { client.SetRegion(fields[3]) }
+
+	request := resourcesearch.SearchResourcesRequest{
+		SearchDetails   : resourcesearch.StructuredSearchDetails{ Query : common.String("query all resources where identifier = '" + ocid + "'") },
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	response, err := client.SearchResources(context.Background(), request)
+	if err != nil { return nil, err }
+	if len(response.Items) == 0 { return nil, fmt.Errorf("resource %s not found", ocid) }
+	r := response.Items[0]
+	return ocihelpers.ResourceAttributes(ocid, *r.CompartmentId, r.DefinedTags), nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	ocid            := flag.String("ocid", "", "evaluate the matching rules for this resource")
+	matching_only   := flag.Bool("matching-only", false, "only list the dynamic groups matching the resource")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *matching_only && *ocid == "" { usage() }
+	if *ocid != "" && !strings.HasPrefix(*ocid, "ocid1.") { ocihelpers.Fatal ("invalid OCID %s", *ocid) }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	tenancy_ocid, err := config.TenancyOCID()
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of dynamic groups (IAM dynamic groups are global resources)
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	groups, err := list_dynamic_groups(client, tenancy_ocid)
+	ocihelpers.FatalIfError(err)
+
+	// Evaluate the matching rules for a resource
+	nb_matching := 0
+	if *ocid != "" {
+		attributes, err := get_resource_attributes(config, *ocid)
+		ocihelpers.FatalIfError(err)
+		selected := make([]dynamic_group_json, 0)
+		for _, g := range groups {
+			rule, err := ocihelpers.ParseMatchingRule(g.MatchingRule)
+			if err != nil {
+				g.Error = err.Error()
+			} else {
+				match := rule.Match(attributes)
+				g.Match = &match
+				if match { nb_matching++ }
+			}
+			if *matching_only && (g.Match == nil || !*g.Match) { continue }
+			selected = append(selected, g)
+		}
+		groups = selected
+	}
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(groups, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	var table ocihelpers.Table
+	if *ocid != "" {
+		table.Headers = []string{ "dynamic_group", "match", "matching_rule" }
+		for _, g := range groups {
+			match := "no"
+			if g.Match == nil { match = "invalid rule" } else if *g.Match { match = "yes" }
+			table.AddRow(g.Name, match, g.MatchingRule)
+		}
+	} else {
+		table.Headers = []string{ "dynamic_group", "matching_rule", "description", "ocid" }
+		for _, g := range groups { table.AddRow(g.Name, g.MatchingRule, g.Description, g.Id) }
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet {
+		if *ocid != "" {
+			fmt.Printf ("\nresource %s matches %d dynamic groups\n", *ocid, nb_matching)
+		} else {
+			fmt.Printf ("\n%d dynamic groups\n", len(groups))
+		}
+	}
+}
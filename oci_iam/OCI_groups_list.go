@@ -0,0 +1,209 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the IAM groups of a OCI tenant with their member users using OCI Go SDK,
+// or the groups a given user belongs to
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type group_json struct {
+	Name        string   `json:"name"`
+	Id          string   `json:"id"`
+	Description string   `json:"description"`
+	Members     []string `json:"members"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --user USER [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip ...\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, all the groups are listed with their member users.")
+    fmt.Println("    If --user USER is provided, the groups the user (name, email or OCID) belongs to are listed.")
+    fmt.Println("    If --empty is provided, only the groups without members are listed.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the active groups of a tenant (sorted by name)
+func list_groups(client identity.IdentityClient, tenancy_ocid string) ([]identity.Group, error) {
+	groups := make([]identity.Group, 0)
+	request := identity.ListGroupsRequest{ CompartmentId : common.String(tenancy_ocid), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListGroups(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, g := range response.Items {
+			if g.LifecycleState == identity.GroupLifecycleStateActive { groups = append(groups, g) }
+		}
+		return response.OpcNextPage, nil
+	})
+	sort.Slice(groups, func(i, j int) bool { return strings.ToLower(*groups[i].Name) < strings.ToLower(*groups[j].Name) })
+	return groups, err
+}
+
+// list the active group memberships of a group or of a user (returns the OCIDs of the users or of the groups)
+func list_memberships(client identity.IdentityClient, tenancy_ocid string, group_id *string, user_id *string) ([]string, error) {
+	ids := make([]string, 0)
+	request := identity.ListUserGroupMembershipsRequest{
+		CompartmentId   : common.String(tenancy_ocid),
+		GroupId         : group_id,
+		UserId          : user_id,
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListUserGroupMemberships(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, m := range response.Items {
+			if m.LifecycleState != identity.UserGroupMembershipLifecycleStateActive { continue }
+			if group_id != nil { ids = append(ids, *m.UserId) } else { ids = append(ids, *m.GroupId) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return ids, err
+}
+
+// find a user by name, email or OCID
+func find_user(users []ocihelpers.User, name string) (ocihelpers.User, bool) {
+	for _, u := range users {
+		if u.Id == name || u.Name == name || (u.Email != "" && strings.EqualFold(u.Email, name)) { return u, true }
+	}
+	return ocihelpers.User{}, false
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	user_name       := flag.String("user", "", "list the groups of this user (name, email or OCID)")
+	empty_only      := flag.Bool("empty", false, "list only the groups without members")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *user_name != "" && *empty_only { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	tenancy_ocid, err := config.TenancyOCID()
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of groups and users (IAM groups and users are global resources)
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	groups, err := list_groups(client, tenancy_ocid)
+	ocihelpers.FatalIfError(err)
+	users, err := ocihelpers.ListUsers(config)
+	ocihelpers.FatalIfError(err)
+	user_names := make(map[string]string)
+	for _, u := range users { user_names[u.Id] = u.Name }
+
+	// Groups of a user
+	results := make([]group_json, 0)
+	if *user_name != "" {
+		user, found := find_user(users, *user_name)
+		if !found { ocihelpers.Fatal ("user %s not found in tenancy !", *user_name) }
+		group_ids, err := list_memberships(client, tenancy_ocid, nil, common.String(user.Id))
+		ocihelpers.FatalIfError(err)
+		member_of := make(map[string]bool)
+		for _, id := range group_ids { member_of[id] = true }
+		for _, g := range groups {
+			if member_of[*g.Id] { results = append(results, group_json{ Name : *g.Name, Id : *g.Id, Description : *g.Description, Members : []string{ user.Name } }) }
+		}
+		if format == "json" {
+			output, err := json.MarshalIndent(results, "", "  ")
+			ocihelpers.FatalIfError(err)
+			fmt.Println(string(output))
+			return
+		}
+		table := ocihelpers.Table{ Headers : []string{ "group", "description", "ocid" } }
+		for _, g := range results { table.AddRow(g.Name, g.Description, g.Id) }
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet { fmt.Printf ("\nuser %s is a member of %d groups\n", user.Name, len(results)) }
+		return
+	}
+
+	// Groups with their members
+	for _, g := range groups {
+		user_ids, err := list_memberships(client, tenancy_ocid, g.Id, nil)
+		ocihelpers.FatalIfError(err)
+		if *empty_only && len(user_ids) > 0 { continue }
+		group := group_json{ Name : *g.Name, Id : *g.Id, Description : *g.Description, Members : make([]string, 0) }
+		for _, id := range user_ids {
+			name, found := user_names[id]
+			if !found { name = id }
+			group.Members = append(group.Members, name)
+		}
+		sort.Strings(group.Members)
+		results = append(results, group)
+	}
+	if format == "json" {
+		output, err := json.MarshalIndent(results, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "group", "nb_members", "members", "ocid" } }
+	for _, g := range results { table.AddRow(g.Name, fmt.Sprintf("%d", len(g.Members)), strings.Join(g.Members, ", "), g.Id) }
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet { fmt.Printf ("\n%d groups\n", len(results)) }
+}
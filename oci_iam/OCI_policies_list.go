@@ -0,0 +1,197 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the IAM policies in all compartments of a OCI tenant using OCI Go SDK,
+// and can search the policy statements (ex: find who can "manage all-resources")
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type policy_json struct {
+	Name            string   `json:"name"`
+	Id              string   `json:"id"`
+	Description     string   `json:"description"`
+	CompartmentId   string   `json:"compartment_id"`
+	CompartmentPath string   `json:"compartment_path"`
+	Statements      []string `json:"statements"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, the policies are listed with their number of statements.")
+    fmt.Println("    If --statements is provided, all the statements of the policies are displayed (one row per statement).")
+    fmt.Println("    If --grep REGEX is provided, only the statements matching the regular expression (case insensitive)")
+    fmt.Println("    are displayed (ex: --grep \"manage all-resources\").")
+    fmt.Println("    If -json is provided, the policies are displayed in JSON format (only the matching statements with --grep).")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the active policies in all active compartments (sorted by compartment path)
+// (at most parallelism compartments processed at the same time)
+func list_policies(client identity.IdentityClient, tree *ocihelpers.CompartmentTree, paths map[string]string, parallelism int) ([]policy_json, error) {
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		policies := make([]policy_json, 0)
+		request := identity.ListPoliciesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListPolicies(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, p := range response.Items {
+				if p.LifecycleState != identity.PolicyLifecycleStateActive { continue }
+				policies = append(policies, policy_json{ Name : *p.Name, Id : *p.Id, Description : *p.Description, CompartmentId : cpt_id, CompartmentPath : paths[cpt_id], Statements : p.Statements })
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		return policies, nil
+	})
+	policies := make([]policy_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		policies = append(policies, r.Value.([]policy_json)...)
+	}
+	return policies, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	statements      := flag.Bool("statements", false, "display all the statements of the policies")
+	grep            := flag.String("grep", "", "only display the statements matching this regular expression")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	var re *regexp.Regexp
+	if *grep != "" {
+		var err error
+		re, err = regexp.Compile("(?i)" + *grep)
+		if err != nil { ocihelpers.Fatal ("invalid regular expression '%s': %s", *grep, err) }
+	}
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of policies (IAM policies are global resources)
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("policies", 1, len(tree.ActiveCompartmentIds()))
+	policies, err := list_policies(client, tree, paths, *parallelism)
+	ocihelpers.StopProgress()
+	ocihelpers.FatalIfError(err)
+
+	// Keep only the matching statements
+	nb_statements := 0
+	if re != nil {
+		matching := make([]policy_json, 0)
+		for _, p := range policies {
+			lines := make([]string, 0)
+			for _, s := range p.Statements {
+				if re.MatchString(s) { lines = append(lines, s) }
+			}
+			if len(lines) == 0 { continue }
+			p.Statements = lines
+			matching = append(matching, p)
+		}
+		policies = matching
+	}
+	for _, p := range policies { nb_statements += len(p.Statements) }
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(policies, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	var table ocihelpers.Table
+	if *statements || re != nil {
+		table.Headers = []string{ "compartment_path", "policy", "statement" }
+		for _, p := range policies {
+			for _, s := range p.Statements { table.AddRow(p.CompartmentPath, p.Name, s) }
+		}
+	} else {
+		table.Headers = []string{ "compartment_path", "policy", "nb_statements", "description", "ocid" }
+		for _, p := range policies { table.AddRow(p.CompartmentPath, p.Name, fmt.Sprintf("%d", len(p.Statements)), p.Description, p.Id) }
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet {
+		if re != nil {
+			fmt.Printf ("\n%d matching statements in %d policies\n", nb_statements, len(policies))
+		} else {
+			fmt.Printf ("\n%d policies, %d statements\n", len(policies), nb_statements)
+		}
+	}
+}
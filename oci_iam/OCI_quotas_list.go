@@ -0,0 +1,260 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the statements of the compartment quota policies of a OCI tenant using OCI Go SDK,
+// and can evaluate which quota statements apply to a compartment (to debug "quota exceeded" errors)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/limits"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type quota_statement_json struct {
+	Policy          string   `json:"policy"`
+	PolicyId        string   `json:"policy_id"`
+	Statement       string   `json:"statement"`
+	Action          string   `json:"action"`
+	Family          string   `json:"family"`
+	Quotas          []string `json:"quotas"`
+	Value           string   `json:"value,omitempty"`
+	CompartmentId   string   `json:"compartment_id"`
+	CompartmentPath string   `json:"compartment_path"`
+	Condition       string   `json:"condition,omitempty"`
+	Scope           string   `json:"scope,omitempty"`
+	Status          string   `json:"status,omitempty"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, all the statements of the quota policies are listed with their target compartment")
+    fmt.Println("    (quota policies are created in the root compartment).")
+    fmt.Println("    If --compartment PATH is provided (ex: root/Prod/Network), only the statements applying to this compartment")
+    fmt.Println("    are displayed, in evaluation order: statements targeting the compartment itself and statements targeting")
+    fmt.Println("    its parent compartments (they limit the total usage of the parent and all its sub-compartments).")
+    fmt.Println("    If --family FAMILY is provided (ex: compute-core), only the statements of this quota family are displayed.")
+    fmt.Println("    If --quota NAME is provided with --family (ex: standard2-core-count), only the statements for this quota are")
+    fmt.Println("    displayed, with their status (EFFECTIVE or OVERRIDDEN by a later statement) and the most restrictive value.")
+    fmt.Println("    If --region REGION is provided, the statements with a request.region condition excluding this region are")
+    fmt.Println("    ignored (region of the profile by default with --compartment).")
+    fmt.Println("    If -json is provided, the statements are displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the statements of the active quota policies (created in the root compartment)
+func list_quota_statements(client limits.QuotasClient, tenancy_ocid string) ([]quota_statement_json, error) {
+	quota_ids := make([]string, 0)
+	request := limits.ListQuotasRequest{ CompartmentId : common.String(tenancy_ocid), LifecycleState : limits.ListQuotasLifecycleStateActive, SortBy : limits.ListQuotasSortByName, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListQuotas(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, q := range response.Items { quota_ids = append(quota_ids, *q.Id) }
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	// the statements are only returned by GetQuota
+	statements := make([]quota_statement_json, 0)
+	for _, quota_id := range quota_ids {
+		response, err := client.GetQuota(context.Background(), limits.GetQuotaRequest{ QuotaId : common.String(quota_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+		if err != nil { return nil, err }
+		for _, s := range response.Statements {
+			statements = append(statements, quota_statement_json{ Policy : *response.Name, PolicyId : quota_id, Statement : s })
+		}
+	}
+	return statements, nil
+}
+
+// ancestors of a compartment (compartment itself first, root compartment last)
+func ancestors(tree *ocihelpers.CompartmentTree, cpt_id string) []string {
+	ids := []string{ cpt_id }
+	for cpt_id != tree.TenancyOCID {
+		c, found := tree.Get(cpt_id)
+		if !found { break }
+		cpt_id = *c.CompartmentId
+		ids = append(ids, cpt_id)
+	}
+	return ids
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	compartment     := flag.String("compartment", "", "only display the statements applying to this compartment (full path)")
+	family          := flag.String("family", "", "only display the statements of this quota family")
+	quota           := flag.String("quota", "", "only display the statements for this quota")
+	region          := flag.String("region", "", "ignore the statements with a condition excluding this region")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *quota != "" && *family == "" { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Compartment to evaluate and its parent compartments
+	var cpt_ids []string
+	if *compartment != "" {
+		cpt_id, found := tree.FindByPath(*compartment)
+		if !found { ocihelpers.Fatal ("compartment %s not found", *compartment) }
+		cpt_ids = ancestors(tree, cpt_id)
+		if *region == "" {
+			*region, err = config.Region()
+			ocihelpers.FatalIfError(err)
+		}
+	}
+
+	// Get the statements of the quota policies
+	client, err := limits.NewQuotasClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	all_statements, err := list_quota_statements(client, tree.TenancyOCID)
+	ocihelpers.FatalIfError(err)
+
+	// Parse the statements and keep the matching ones
+	statements := make([]quota_statement_json, 0)
+	for _, s := range all_statements {
+		q, err := ocihelpers.ParseQuotaStatement(s.Statement)
+		if err != nil {
+			fmt.Fprintf (os.Stderr, "WARNING: quota policy %s: %s\n", s.Policy, err)
+			continue
+		}
+		if !q.MatchesQuota(*family, *quota) || !q.MatchesRegion(*region) { continue }
+		s.Action, s.Family, s.Quotas, s.Value, s.Condition = q.Action, q.Family, q.Names, q.Value, q.Condition
+		if s.Quotas == nil { s.Quotas = []string{} }
+		s.CompartmentId = q.CompartmentId
+		if s.CompartmentId == "" {
+			s.CompartmentId, _ = tree.FindByPath(q.CompartmentPath())
+		}
+		s.CompartmentPath = paths[s.CompartmentId]
+		if s.CompartmentPath == "" { s.CompartmentPath = "NOT FOUND: " + q.CompartmentPath() + q.CompartmentId }
+		statements = append(statements, s)
+	}
+
+	// Keep the statements applying to the compartment: the parent compartments first (order of evaluation)
+	if cpt_ids != nil {
+		applying := make([]quota_statement_json, 0)
+		for i := len(cpt_ids) - 1; i >= 0; i-- {
+			for _, s := range statements {
+				if s.CompartmentId != cpt_ids[i] { continue }
+				s.Scope = "parent"
+				if i == 0 { s.Scope = "compartment" }
+				applying = append(applying, s)
+			}
+		}
+		statements = applying
+	} else {
+		sort.SliceStable(statements, func(i, j int) bool { return statements[i].CompartmentPath < statements[j].CompartmentPath })
+	}
+
+	// Status of the statements for a quota: for a target compartment (and condition), the last statement wins
+	if *quota != "" {
+		last := make(map[string]int)
+		for i, s := range statements { last[s.CompartmentId + "|" + s.Condition] = i }
+		for i := range statements {
+			statements[i].Status = "OVERRIDDEN"
+			if last[statements[i].CompartmentId + "|" + statements[i].Condition] == i { statements[i].Status = "EFFECTIVE" }
+		}
+	}
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(statements, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "compartment_path", "policy", "statement" } }
+	if cpt_ids != nil { table.Headers = append(table.Headers, "scope") }
+	if *quota != ""   { table.Headers = append(table.Headers, "status") }
+	for _, s := range statements {
+		row := []string{ s.CompartmentPath, s.Policy, s.Statement }
+		if cpt_ids != nil { row = append(row, s.Scope) }
+		if *quota != ""   { row = append(row, s.Status) }
+		table.AddRow(row...)
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format != "text" || ocihelpers.Quiet { return }
+
+	// Summary line: most restrictive quota value among the effective statements (unset = service limits only)
+	fmt.Printf ("\n%d quota statements\n", len(statements))
+	if *quota == "" { return }
+	limit, limit_statement := -1.0, ""
+	for _, s := range statements {
+		if s.Status != "EFFECTIVE" || s.Action == "unset" { continue }
+		value := 0.0
+		if s.Action == "set" { value, _ = strconv.ParseFloat(s.Value, 64) }
+		if limit < 0 || value < limit { limit, limit_statement = value, s.Statement }
+	}
+	if limit < 0 {
+		fmt.Printf ("No quota restricts %s %s: the service limits apply\n", *family, *quota)
+	} else {
+		fmt.Printf ("Most restrictive quota for %s %s: %g (%s)\n", *family, *quota, limit, limit_statement)
+	}
+}
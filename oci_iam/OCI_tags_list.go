@@ -0,0 +1,314 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the tag namespaces and defined tag keys of a OCI tenant with their validators (list of
+// allowed values) and retired status using OCI Go SDK, or the tag defaults applying to each compartment
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type tag_key_json struct {
+	Name           string   `json:"name"`
+	Id             string   `json:"id"`
+	Description    string   `json:"description"`
+	IsRetired      bool     `json:"is_retired"`
+	IsCostTracking bool     `json:"is_cost_tracking"`
+	ValidatorType  string   `json:"validator_type"`    // DEFAULT (any value) or ENUM (list of values)
+	Values         []string `json:"values,omitempty"`  // allowed values for ENUM validators
+}
+
+type namespace_json struct {
+	Name            string         `json:"name"`
+	Id              string         `json:"id"`
+	Description     string         `json:"description"`
+	IsRetired       bool           `json:"is_retired"`
+	CompartmentPath string         `json:"compartment_path"`
+	Keys            []tag_key_json `json:"keys"`
+}
+
+type tag_default_json struct {
+	CompartmentPath string `json:"compartment_path"`   // compartment the tag default applies to
+	Tag             string `json:"tag"`                // NAMESPACE.KEY
+	Value           string `json:"value"`
+	IsRequired      bool   `json:"is_required"`
+	DefinedIn       string `json:"defined_in"`         // compartment where the tag default is defined
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, the tag namespaces and defined tag keys are listed (one row per tag key).")
+    fmt.Println("    If --active-only is provided, the retired tag namespaces and tag keys are not listed.")
+    fmt.Println("    If --defaults is provided, the tag defaults applying to each compartment are listed instead (including")
+    fmt.Println("    the tag defaults inherited from parent compartments).")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the tag namespaces of the tenancy (all compartments), sorted by name
+func list_namespaces(client identity.IdentityClient, tenancy_ocid string, paths map[string]string) ([]namespace_json, error) {
+	namespaces := make([]namespace_json, 0)
+	request := identity.ListTagNamespacesRequest{
+		CompartmentId          : common.String(tenancy_ocid),
+		IncludeSubcompartments : common.Bool(true),
+		RequestMetadata        : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListTagNamespaces(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, n := range response.Items {
+			if n.LifecycleState != identity.TagNamespaceLifecycleStateActive && n.LifecycleState != identity.TagNamespaceLifecycleStateInactive { continue }
+			namespace := namespace_json{ Name : *n.Name, Id : *n.Id, Description : *n.Description, CompartmentPath : paths[*n.CompartmentId], Keys : make([]tag_key_json, 0) }
+			if n.IsRetired != nil { namespace.IsRetired = *n.IsRetired }
+			namespaces = append(namespaces, namespace)
+		}
+		return response.OpcNextPage, nil
+	})
+	sort.Slice(namespaces, func(i, j int) bool { return strings.ToLower(namespaces[i].Name) < strings.ToLower(namespaces[j].Name) })
+	return namespaces, err
+}
+
+// list the tag keys of a namespace with their validator (sorted by name)
+func list_keys(client identity.IdentityClient, namespace_id string) ([]tag_key_json, error) {
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	keys := make([]tag_key_json, 0)
+	request := identity.ListTagsRequest{ TagNamespaceId : common.String(namespace_id), RequestMetadata : metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListTags(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, t := range response.Items {
+			if t.LifecycleState != identity.TagLifecycleStateActive && t.LifecycleState != identity.TagLifecycleStateInactive { continue }
+			keys = append(keys, tag_key_json{ Name : *t.Name, Id : *t.Id, Description : *t.Description })
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	// the validator is only returned by GetTag
+	for i := range keys {
+		response, err := client.GetTag(context.Background(), identity.GetTagRequest{ TagNamespaceId : common.String(namespace_id), TagName : common.String(keys[i].Name), RequestMetadata : metadata })
+		if err != nil { return nil, err }
+		if response.IsRetired != nil { keys[i].IsRetired = *response.IsRetired }
+		if response.IsCostTracking != nil { keys[i].IsCostTracking = *response.IsCostTracking }
+		keys[i].ValidatorType = "DEFAULT"
+		if validator, ok := response.Validator.(identity.EnumTagDefinitionValidator); ok {
+			keys[i].ValidatorType = "ENUM"
+			keys[i].Values = validator.Values
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return strings.ToLower(keys[i].Name) < strings.ToLower(keys[j].Name) })
+	return keys, nil
+}
+
+// list the tag defaults defined in all active compartments (compartment OCID -> tag defaults)
+// (at most parallelism compartments processed at the same time)
+func list_tag_defaults(client identity.IdentityClient, tree *ocihelpers.CompartmentTree, parallelism int) (map[string][]identity.TagDefaultSummary, error) {
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_defaults := make([]identity.TagDefaultSummary, 0)
+		request := identity.ListTagDefaultsRequest{
+			CompartmentId   : common.String(cpt_id),
+			LifecycleState  : identity.TagDefaultSummaryLifecycleStateActive,
+			RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+		}
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListTagDefaults(context.Background(), request)
+			if err != nil { return nil, err }
+			cpt_defaults = append(cpt_defaults, response.Items...)
+			return response.OpcNextPage, nil
+		})
+		return cpt_defaults, err
+	})
+	defaults := make(map[string][]identity.TagDefaultSummary)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		if cpt_defaults := r.Value.([]identity.TagDefaultSummary); len(cpt_defaults) > 0 { defaults[r.CompartmentId] = cpt_defaults }
+	}
+	return defaults, nil
+}
+
+// tag defaults applying to a compartment: tag defaults of the compartment and of its parent compartments
+// (for a given tag key, the tag default of the closest compartment is used)
+func effective_tag_defaults(tree *ocihelpers.CompartmentTree, cpt_id string, defaults map[string][]identity.TagDefaultSummary, namespaces map[string]string, paths map[string]string) []tag_default_json {
+	result := make([]tag_default_json, 0)
+	seen := make(map[string]bool)
+	for id := cpt_id; ; {
+		for _, d := range defaults[id] {
+			if seen[*d.TagDefinitionId] { continue }
+			seen[*d.TagDefinitionId] = true
+			tag := namespaces[*d.TagNamespaceId] + "." + *d.TagDefinitionName
+			result = append(result, tag_default_json{ CompartmentPath : paths[cpt_id], Tag : tag, Value : *d.Value, IsRequired : *d.IsRequired, DefinedIn : paths[id] })
+		}
+		if id == tree.TenancyOCID { break }
+		c, found := tree.Get(id)
+		if !found { break }
+		id = *c.CompartmentId
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Tag < result[j].Tag })
+	return result
+}
+
+// display results in JSON format
+func display_json(value interface{}) {
+	output, err := json.MarshalIndent(value, "", "  ")
+	ocihelpers.FatalIfError(err)
+	fmt.Println(string(output))
+}
+
+// yes or no
+func yes_no(b bool) string {
+	if b { return "yes" }
+	return "no"
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	active_only     := flag.Bool("active-only", false, "do not list the retired tag namespaces and tag keys")
+	show_defaults   := flag.Bool("defaults", false, "list the tag defaults applying to each compartment")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if *active_only && *show_defaults { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of tag namespaces (tag namespaces are global resources)
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	namespaces, err := list_namespaces(client, tree.TenancyOCID, paths)
+	ocihelpers.FatalIfError(err)
+
+	// Tag defaults applying to each compartment
+	if *show_defaults {
+		namespace_names := make(map[string]string)
+		for _, n := range namespaces { namespace_names[n.Id] = n.Name }
+		ocihelpers.StartProgress("tag defaults", 1, len(tree.ActiveCompartmentIds()))
+		defaults, err := list_tag_defaults(client, tree, *parallelism)
+		ocihelpers.StopProgress()
+		ocihelpers.FatalIfError(err)
+		results := make([]tag_default_json, 0)
+		for _, cpt_id := range tree.ActiveCompartmentIds() {
+			results = append(results, effective_tag_defaults(tree, cpt_id, defaults, namespace_names, paths)...)
+		}
+		if format == "json" {
+			display_json(results)
+			return
+		}
+		table := ocihelpers.Table{ Headers : []string{ "compartment_path", "tag", "value", "required", "defined_in" } }
+		for _, d := range results { table.AddRow(d.CompartmentPath, d.Tag, d.Value, yes_no(d.IsRequired), d.DefinedIn) }
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			nb_defined := 0
+			for _, list := range defaults { nb_defined += len(list) }
+			fmt.Printf ("\n%d tag defaults defined, %d applying to compartments (including inherited ones)\n", nb_defined, len(results))
+		}
+		return
+	}
+
+	// Tag namespaces and tag keys
+	selected := make([]namespace_json, 0)
+	nb_keys := 0
+	for _, n := range namespaces {
+		if *active_only && n.IsRetired { continue }
+		keys, err := list_keys(client, n.Id)
+		ocihelpers.FatalIfError(err)
+		for _, k := range keys {
+			if *active_only && k.IsRetired { continue }
+			n.Keys = append(n.Keys, k)
+		}
+		nb_keys += len(n.Keys)
+		selected = append(selected, n)
+	}
+	if format == "json" {
+		display_json(selected)
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "namespace", "key", "retired", "cost_tracking", "allowed_values", "description", "compartment_path" } }
+	for _, n := range selected {
+		if len(n.Keys) == 0 { table.AddRow(n.Name, "-", yes_no(n.IsRetired), "-", "-", n.Description, n.CompartmentPath) }
+		for _, k := range n.Keys {
+			values := "any"
+			if k.ValidatorType == "ENUM" { values = strings.Join(k.Values, "|") }
+			table.AddRow(n.Name, k.Name, yes_no(n.IsRetired || k.IsRetired), yes_no(k.IsCostTracking), values, k.Description, n.CompartmentPath)
+		}
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet { fmt.Printf ("\n%d tag namespaces, %d tag keys\n", len(selected), nb_keys) }
+}
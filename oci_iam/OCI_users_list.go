@@ -0,0 +1,176 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the IAM users of a OCI tenant using OCI Go SDK, with their email, MFA status, API keys
+// (fingerprint and age) and last successful login, for periodic access reviews
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       the last successful login is not available in the OCI Go SDK version used: the REST API is called directly
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type api_key_json struct {
+	Fingerprint string `json:"fingerprint"`
+	TimeCreated string `json:"time_created"`
+	AgeDays     int    `json:"age_days"`
+}
+
+type user_json struct {
+	Name           string         `json:"name"`
+	Id             string         `json:"id"`
+	Email          string         `json:"email"`
+	MfaActivated   bool           `json:"mfa_activated"`
+	Federated      bool           `json:"federated"`
+	LifecycleState string         `json:"lifecycle_state"`
+	TimeCreated    string         `json:"time_created"`
+	LastLogin      string         `json:"last_login"`               // empty if the user never logged in
+	DaysSinceLogin *int           `json:"days_since_login"`         // null if the user never logged in
+	ApiKeys        []api_key_json `json:"api_keys"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// get the active API keys of a user
+func list_api_keys(client identity.IdentityClient, user_id string) ([]api_key_json, error) {
+	request := identity.ListApiKeysRequest{ UserId : common.String(user_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	response, err := client.ListApiKeys(context.Background(), request)
+	if err != nil { return nil, err }
+	keys := make([]api_key_json, 0)
+	for _, k := range response.Items {
+		if k.LifecycleState != identity.ApiKeyLifecycleStateActive { continue }
+		keys = append(keys, api_key_json{ Fingerprint : *k.Fingerprint, TimeCreated : k.TimeCreated.Format("2006-01-02"), AgeDays : ocihelpers.DaysSince(k.TimeCreated.Time) })
+	}
+	return keys, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of users (IAM users are global resources)
+	users, err := ocihelpers.ListUsers(config)
+	ocihelpers.FatalIfError(err)
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+
+	results := make([]user_json, 0)
+	nb_no_mfa := 0
+	nb_never_logged := 0
+	for _, u := range users {
+		user := user_json{
+			Name           : u.Name,
+			Id             : u.Id,
+			Email          : u.Email,
+			MfaActivated   : u.IsMfaActivated,
+			Federated      : u.IdentityProviderId != "",
+			LifecycleState : u.LifecycleState,
+			TimeCreated    : u.TimeCreated.Format("2006-01-02"),
+		}
+		if u.LastSuccessfulLoginTime != nil {
+			days := ocihelpers.DaysSince(*u.LastSuccessfulLoginTime)
+			user.LastLogin = u.LastSuccessfulLoginTime.Format("2006-01-02 15:04")
+			user.DaysSinceLogin = &days
+		} else {
+			nb_never_logged++
+		}
+		if !u.IsMfaActivated { nb_no_mfa++ }
+		user.ApiKeys, err = list_api_keys(client, u.Id)
+		ocihelpers.FatalIfError(err)
+		results = append(results, user)
+	}
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(results, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "name", "email", "mfa", "state", "last_login", "days_since_login", "api_keys", "ocid" } }
+	for _, u := range results {
+		last_login := "never"
+		days := "-"
+		if u.DaysSinceLogin != nil {
+			last_login = u.LastLogin
+			days = fmt.Sprintf("%d", *u.DaysSinceLogin)
+		}
+		keys := make([]string, 0)
+		for _, k := range u.ApiKeys { keys = append(keys, fmt.Sprintf("%s (%dd)", k.Fingerprint, k.AgeDays)) }
+		mfa := "no"
+		if u.MfaActivated { mfa = "yes" }
+		table.AddRow(u.Name, u.Email, mfa, u.LifecycleState, last_login, days, strings.Join(keys, " "), u.Id)
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet {
+		fmt.Printf ("\n%d users: %d without MFA, %d never logged in\n", len(results), nb_no_mfa, nb_never_logged)
+	}
+}
@@ -0,0 +1,203 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script reports the stale IAM users of a OCI tenant using OCI Go SDK: users who never logged in,
+// users who did not log in for N days, and users whose only credentials are API keys older than N days
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       the last successful login is not available in the OCI Go SDK version used: the REST API is called directly
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type stale_user_json struct {
+	Name           string   `json:"name"`
+	Id             string   `json:"id"`
+	Email          string   `json:"email"`
+	TimeCreated    string   `json:"time_created"`
+	LastLogin      string   `json:"last_login"`          // empty if the user never logged in
+	DaysSinceLogin *int     `json:"days_since_login"`    // null if the user never logged in
+	NbApiKeys      int      `json:"nb_api_keys"`
+	OldestKeyDays  *int     `json:"oldest_api_key_days"` // null if the user has no API key
+	Reasons        []string `json:"reasons"`
+}
+
+// credentials of a user
+type credentials struct {
+	api_keys_days        []int  // age of the active API keys in days
+	nb_other_credentials int    // active auth tokens and customer secret keys
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    The users are reported if they never logged in, if they did not log in for N days, or if their only")
+    fmt.Println("    credentials are API keys older than N days.")
+    fmt.Println("    If --days N is provided, N days is used as threshold (default 90).")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// get the active credentials of a user (API keys, auth tokens and customer secret keys)
+func get_credentials(client identity.IdentityClient, user_id string) (credentials, error) {
+	var creds credentials
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	api_keys, err := client.ListApiKeys(context.Background(), identity.ListApiKeysRequest{ UserId : common.String(user_id), RequestMetadata : metadata })
+	if err != nil { return creds, err }
+	for _, k := range api_keys.Items {
+		if k.LifecycleState == identity.ApiKeyLifecycleStateActive { creds.api_keys_days = append(creds.api_keys_days, ocihelpers.DaysSince(k.TimeCreated.Time)) }
+	}
+
+	auth_tokens, err := client.ListAuthTokens(context.Background(), identity.ListAuthTokensRequest{ UserId : common.String(user_id), RequestMetadata : metadata })
+	if err != nil { return creds, err }
+	for _, t := range auth_tokens.Items {
+		if t.LifecycleState == identity.AuthTokenLifecycleStateActive { creds.nb_other_credentials++ }
+	}
+
+	secret_keys, err := client.ListCustomerSecretKeys(context.Background(), identity.ListCustomerSecretKeysRequest{ UserId : common.String(user_id), RequestMetadata : metadata })
+	if err != nil { return creds, err }
+	for _, k := range secret_keys.Items {
+		if k.LifecycleState == identity.CustomerSecretKeySummaryLifecycleStateActive { creds.nb_other_credentials++ }
+	}
+	return creds, nil
+}
+
+// reasons why a user is stale (empty if the user is not stale)
+func stale_reasons(user stale_user_json, creds credentials, days int) []string {
+	reasons := make([]string, 0)
+	if user.DaysSinceLogin == nil {
+		reasons = append(reasons, "never logged in")
+	} else if *user.DaysSinceLogin > days {
+		reasons = append(reasons, fmt.Sprintf("no login for %d days", *user.DaysSinceLogin))
+	}
+	if len(creds.api_keys_days) > 0 && creds.nb_other_credentials == 0 {
+		only_old_keys := true
+		for _, age := range creds.api_keys_days {
+			if age <= days { only_old_keys = false }
+		}
+		if only_old_keys { reasons = append(reasons, fmt.Sprintf("only API keys older than %d days", days)) }
+	}
+	return reasons
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	days            := flag.Int("days", 90, "threshold in days")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *days < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of users (IAM users are global resources)
+	users, err := ocihelpers.ListUsers(config)
+	ocihelpers.FatalIfError(err)
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+
+	// Find the stale users
+	results := make([]stale_user_json, 0)
+	nb_active := 0
+	for _, u := range users {
+		if u.LifecycleState != string(identity.UserLifecycleStateActive) { continue }
+		nb_active++
+		user := stale_user_json{ Name : u.Name, Id : u.Id, Email : u.Email, TimeCreated : u.TimeCreated.Format("2006-01-02") }
+		if u.LastSuccessfulLoginTime != nil {
+			days_since_login := ocihelpers.DaysSince(*u.LastSuccessfulLoginTime)
+			user.LastLogin = u.LastSuccessfulLoginTime.Format("2006-01-02 15:04")
+			user.DaysSinceLogin = &days_since_login
+		}
+		creds, err := get_credentials(client, u.Id)
+		ocihelpers.FatalIfError(err)
+		user.NbApiKeys = len(creds.api_keys_days)
+		for _, age := range creds.api_keys_days {
+			if user.OldestKeyDays == nil || age > *user.OldestKeyDays {
+				oldest := age
+				user.OldestKeyDays = &oldest
+			}
+		}
+		user.Reasons = stale_reasons(user, creds, *days)
+		if len(user.Reasons) > 0 { results = append(results, user) }
+	}
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(results, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "name", "email", "created", "last_login", "api_keys", "oldest_key_days", "reasons", "ocid" } }
+	for _, u := range results {
+		last_login := "never"
+		if u.DaysSinceLogin != nil { last_login = u.LastLogin }
+		oldest := "-"
+		if u.OldestKeyDays != nil { oldest = fmt.Sprintf("%d", *u.OldestKeyDays) }
+		table.AddRow(u.Name, u.Email, u.TimeCreated, last_login, fmt.Sprintf("%d", u.NbApiKeys), oldest, strings.Join(u.Reasons, ", "), u.Id)
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet {
+		fmt.Printf ("\n%d stale users out of %d active users (threshold %d days)\n", len(results), nb_active, *days)
+	}
+}
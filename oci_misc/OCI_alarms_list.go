@@ -0,0 +1,267 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the alarms of the Monitoring service in all compartments of a OCI tenant using OCI Go SDK,
+// with their severity, status (OK, FIRING, SUSPENDED or DISABLED), destinations and suppression window
+// Firing alarms are displayed in red and suspended alarms in yellow, for a quick operational health check
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       deleted alarms are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/monitoring"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type alarm_json struct {
+	Name               string   `json:"name"`
+	Id                 string   `json:"id"`
+	Severity           string   `json:"severity"`
+	Status             string   `json:"status"`                // OK, FIRING, SUSPENDED or DISABLED
+	TimestampTriggered string   `json:"timestamp_triggered"`
+	Namespace          string   `json:"namespace"`
+	Query              string   `json:"query"`
+	Destinations       []string `json:"destinations"`
+	SuppressionFrom    string   `json:"suppression_from"`
+	SuppressionUntil   string   `json:"suppression_until"`
+	Region             string   `json:"region"`
+	CompartmentId      string   `json:"compartment_id"`
+	CompartmentPath    string   `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    Firing alarms are displayed in red and suspended alarms in yellow.")
+    fmt.Println("    If --firing-only is provided, only the firing alarms are displayed.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the alarms of a compartment, with their status
+func list_alarms(client monitoring.MonitoringClient, cpt_id string, region string) ([]alarm_json, error) {
+	// status of the enabled alarms
+	statuses := make(map[string]monitoring.AlarmStatusSummary)
+	status_request := monitoring.ListAlarmsStatusRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		status_request.Page = page
+		response, err := client.ListAlarmsStatus(context.Background(), status_request)
+		if err != nil { return nil, err }
+		for _, s := range response.Items { statuses[*s.Id] = s }
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	alarms := make([]alarm_json, 0)
+	request := monitoring.ListAlarmsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListAlarms(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, a := range response.Items {
+			if a.LifecycleState == monitoring.AlarmLifecycleStateDeleted { continue }
+			item := alarm_json{ Name : *a.DisplayName, Id : *a.Id, Severity : string(a.Severity), Status : "DISABLED", Namespace : *a.Namespace, Query : *a.Query,
+				Destinations : a.Destinations, Region : region, CompartmentId : cpt_id }
+			if item.Destinations == nil { item.Destinations = make([]string, 0) }
+			if s, found := statuses[*a.Id]; found && *a.IsEnabled {
+				item.Status = string(s.Status)
+				if s.TimestampTriggered != nil { item.TimestampTriggered = s.TimestampTriggered.Format("2006-01-02T15:04:05Z") }
+			}
+			if a.Suppression != nil {
+				item.SuppressionFrom  = a.Suppression.TimeSuppressFrom.Format("2006-01-02T15:04:05Z")
+				item.SuppressionUntil = a.Suppression.TimeSuppressUntil.Format("2006-01-02T15:04:05Z")
+			}
+			alarms = append(alarms, item)
+		}
+		return response.OpcNextPage, nil
+	})
+	return alarms, err
+}
+
+// list the alarms of all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]alarm_json, error) {
+	client, err := monitoring.NewMonitoringClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return list_alarms(client, cpt_id, region)
+	})
+	alarms := make([]alarm_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		alarms = append(alarms, r.Value.([]alarm_json)...)
+	}
+	return alarms, nil
+}
+
+// display the table in text format, with the rows of firing alarms in red and suspended alarms in yellow
+func display_text(table ocihelpers.Table, statuses []string) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(statuses) { break }
+		switch statuses[i] {
+		case string(monitoring.AlarmStatusSummaryStatusFiring):    line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL
+		case string(monitoring.AlarmStatusSummaryStatusSuspended): line = ocihelpers.COLOR_YELLOW + line + ocihelpers.COLOR_NORMAL
+		}
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list alarms in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list alarms in all subscribed regions")
+	firing_only     := flag.Bool("firing-only", false, "only display the firing alarms")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the alarms in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("alarms", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	alarms := make([]alarm_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, a := range r.Value.([]alarm_json) {
+			if *firing_only && a.Status != string(monitoring.AlarmStatusSummaryStatusFiring) { continue }
+			a.CompartmentPath = paths[a.CompartmentId]
+			alarms = append(alarms, a)
+		}
+	}
+	sort.SliceStable(alarms, func(i, j int) bool {
+		if alarms[i].CompartmentPath != alarms[j].CompartmentPath { return alarms[i].CompartmentPath < alarms[j].CompartmentPath }
+		return alarms[i].Name < alarms[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(alarms, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "alarm", "severity", "status", "triggered", "namespace", "query", "destinations", "suppression", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		statuses := make([]string, 0)
+		nb_firing := 0
+		for _, a := range alarms {
+			if a.Status == string(monitoring.AlarmStatusSummaryStatusFiring) { nb_firing++ }
+			suppression := ""
+			if a.SuppressionFrom != "" { suppression = a.SuppressionFrom + " -> " + a.SuppressionUntil }
+			row := []string{ a.Name, a.Severity, a.Status, a.TimestampTriggered, a.Namespace, a.Query, strings.Join(a.Destinations, " "), suppression, a.CompartmentPath }
+			if all_regions { row = append(row, a.Region) }
+			table.AddRow(append(row, a.Id)...)
+			statuses = append(statuses, a.Status)
+		}
+		if format != "text" {
+			ocihelpers.FatalIfError(table.Print(format))
+		} else {
+			display_text(table, statuses)
+			if !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d alarms, %d firing\n", len(alarms), nb_firing)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,260 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the active OCI announcements of a OCI tenant (outages, maintenances, required actions...)
+// with their type, services, affected regions and time window, to feed them into an incident tool (JSON output)
+// Required actions and emergency announcements are displayed in red, recommended actions in yellow
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       announcements are global: the announcements of all regions are listed
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/announcementsservice"
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// announcement types
+var announcement_types = []string{
+	"ACTION_REQUIRED", "ACTION_RECOMMENDED", "EMERGENCY_MAINTENANCE", "EMERGENCY_MAINTENANCE_EXTENDED",
+	"EMERGENCY_MAINTENANCE_RESCHEDULED", "EMERGENCY_MAINTENANCE_COMPLETE", "EMERGENCY_CHANGE", "PRODUCTION_EVENT_NOTIFICATION",
+	"SCHEDULED_MAINTENANCE", "PLANNED_CHANGE", "PLANNED_CHANGE_EXTENDED", "PLANNED_CHANGE_RESCHEDULED", "PLANNED_CHANGE_COMPLETE",
+	"INFORMATION",
+}
+
+// -- types
+type announcement_json struct {
+	Id                    string   `json:"id"`
+	ReferenceTicketNumber string   `json:"reference_ticket_number"`
+	Type                  string   `json:"type"`
+	Summary               string   `json:"summary"`
+	Services              []string `json:"services"`
+	AffectedRegions       []string `json:"affected_regions"`
+	TimeOneTitle          string   `json:"time_one_title"`
+	TimeOne               string   `json:"time_one"`
+	TimeTwoTitle          string   `json:"time_two_title"`
+	TimeTwo               string   `json:"time_two"`
+	TimeCreated           string   `json:"time_created"`
+	TimeUpdated           string   `json:"time_updated"`
+	IsBanner              bool     `json:"is_banner"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    The active announcements are listed from the most recent to the oldest (start time of the event).")
+    fmt.Println("    Required actions and emergency announcements are displayed in red, recommended actions in yellow.")
+    fmt.Println("    If --type TYPE,... is provided, only the announcements with these types are listed. A type can be")
+    fmt.Println("    shortened to its first words (ex: --type ACTION_REQUIRED,EMERGENCY for all emergency announcements).")
+    fmt.Printf ("    Types: %s\n", strings.Join(announcement_types, ", "))
+    fmt.Println("    If --days N is provided, only the announcements of events starting in the last N days or later are listed.")
+    fmt.Println("    If --start TIMESTAMP and/or --end TIMESTAMP are provided (RFC3339 format), only the announcements of events")
+    fmt.Println("    starting in this time range are listed.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// parse the announcement types given by --type (nil if not provided): full types or their first words
+func parse_types(list string) (map[string]bool, error) {
+	if list == "" { return nil, nil }
+	types := make(map[string]bool)
+	for _, item := range strings.Split(list, ",") {
+		prefix := strings.ToUpper(strings.TrimSpace(item))
+		found := false
+		for _, t := range announcement_types {
+			if t == prefix || strings.HasPrefix(t, prefix + "_") {
+				types[t] = true
+				found = true
+			}
+		}
+		if !found { return nil, fmt.Errorf("invalid announcement type '%s' (%s expected)", item, strings.Join(announcement_types, ", ")) }
+	}
+	return types, nil
+}
+
+// time window of the events given by --days, --start and --end (nil = no limit)
+func time_window(days int, start string, end string) (*common.SDKTime, *common.SDKTime, error) {
+	var earliest, latest *common.SDKTime
+	if days > 0 { earliest = &common.SDKTime{ Time : time.Now().UTC().AddDate(0, 0, -days) } }
+	if start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil { return nil, nil, fmt.Errorf("invalid start timestamp '%s' (RFC3339 format expected)", start) }
+		earliest = &common.SDKTime{ Time : t.UTC() }
+	}
+	if end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil { return nil, nil, fmt.Errorf("invalid end timestamp '%s' (RFC3339 format expected)", end) }
+		latest = &common.SDKTime{ Time : t.UTC() }
+	}
+	if earliest != nil && latest != nil && !earliest.Time.Before(latest.Time) { return nil, nil, fmt.Errorf("start of the time range must be before its end") }
+	return earliest, latest, nil
+}
+
+// date of an announcement in UTC (empty if not set)
+func format_time(t *common.SDKTime) string {
+	if t == nil { return "" }
+	return t.Time.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// list the active announcements of the tenant (events starting in the time window)
+func list_announcements(client announcementsservice.AnnouncementClient, tenancy_id string, earliest *common.SDKTime, latest *common.SDKTime) ([]announcement_json, error) {
+	announcements := make([]announcement_json, 0)
+	request := announcementsservice.ListAnnouncementsRequest{ CompartmentId : common.String(tenancy_id), LifecycleState : announcementsservice.ListAnnouncementsLifecycleStateActive,
+		TimeOneEarliestTime : earliest, TimeOneLatestTime : latest, SortBy : announcementsservice.ListAnnouncementsSortByTimeonevalue,
+		SortOrder : announcementsservice.ListAnnouncementsSortOrderDesc, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListAnnouncements(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, a := range response.Items {
+			item := announcement_json{ Id : *a.Id, ReferenceTicketNumber : *a.ReferenceTicketNumber, Type : string(a.AnnouncementType), Summary : *a.Summary,
+				Services : a.Services, AffectedRegions : a.AffectedRegions, TimeOne : format_time(a.TimeOneValue), TimeTwo : format_time(a.TimeTwoValue),
+				TimeCreated : format_time(a.TimeCreated), TimeUpdated : format_time(a.TimeUpdated), IsBanner : a.IsBanner != nil && *a.IsBanner }
+			if a.TimeOneTitle != nil { item.TimeOneTitle = *a.TimeOneTitle }
+			if a.TimeTwoTitle != nil { item.TimeTwoTitle = *a.TimeTwoTitle }
+			if item.Services == nil        { item.Services = make([]string, 0) }
+			if item.AffectedRegions == nil { item.AffectedRegions = make([]string, 0) }
+			announcements = append(announcements, item)
+		}
+		return response.OpcNextPage, nil
+	})
+	return announcements, err
+}
+
+// display the table in text format, with the rows of required actions and emergency announcements in red
+// and recommended actions in yellow
+func display_text(table ocihelpers.Table, types []string) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(types) { break }
+		switch {
+		case types[i] == "ACTION_REQUIRED" || strings.HasPrefix(types[i], "EMERGENCY_") || types[i] == "PRODUCTION_EVENT_NOTIFICATION":
+			line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL
+		case types[i] == "ACTION_RECOMMENDED":
+			line = ocihelpers.COLOR_YELLOW + line + ocihelpers.COLOR_NORMAL
+		}
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	types_list      := flag.String("type", "", "only list the announcements with these types (comma separated)")
+	days            := flag.Int("days", 0, "only list the announcements of events starting in the last N days or later")
+	start           := flag.String("start", "", "start of the time range (RFC3339 format)")
+	end             := flag.String("end", "", "end of the time range (RFC3339 format)")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *days < 0 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	types, err := parse_types(*types_list)
+	ocihelpers.FatalIfError(err)
+	earliest, latest, err := time_window(*days, *start, *end)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	tenancy_id, err := config.TenancyOCID()
+	ocihelpers.FatalIfError(err)
+
+	// Get the active announcements of the tenant
+	client, err := announcementsservice.NewAnnouncementClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	all_announcements, err := list_announcements(client, tenancy_id, earliest, latest)
+	ocihelpers.FatalIfError(err)
+	announcements := make([]announcement_json, 0)
+	for _, a := range all_announcements {
+		if types != nil && !types[a.Type] { continue }
+		announcements = append(announcements, a)
+	}
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(announcements, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "type", "summary", "services", "affected_regions", "start", "end", "ticket", "ocid" } }
+	kinds := make([]string, 0)
+	nb_required := 0
+	for _, a := range announcements {
+		table.AddRow(a.Type, a.Summary, strings.Join(a.Services, ","), strings.Join(a.AffectedRegions, ","), a.TimeOne, a.TimeTwo, a.ReferenceTicketNumber, a.Id)
+		kinds = append(kinds, a.Type)
+		if a.Type == "ACTION_REQUIRED" { nb_required++ }
+	}
+	if format != "text" {
+		ocihelpers.FatalIfError(table.Print(format))
+		return
+	}
+	display_text(table, kinds)
+	if !ocihelpers.Quiet {
+		fmt.Println ("")
+		fmt.Printf ("%d active announcements (%d required actions)\n", len(announcements), nb_required)
+	}
+}
@@ -0,0 +1,363 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the API gateways and their deployments in all compartments of a OCI tenant using OCI Go SDK
+// For each route of each deployment, it displays path, HTTP methods, backend type and authentication, to audit
+// the APIs exposed publicly
+// Routes of public gateways which can be called without authentication are displayed in red
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       deleted gateways and deployments are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/apigateway"
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type route_json struct {
+	Path            string   `json:"path"`                // path prefix of the deployment + path of the route
+	Methods         []string `json:"methods"`
+	BackendType     string   `json:"backend_type"`        // http, function or stock_response
+	Backend         string   `json:"backend"`             // URL, function OCID or HTTP status
+	Authentication  string   `json:"authentication"`      // none, anonymous, jwt or custom
+	Unauthenticated bool     `json:"unauthenticated"`
+}
+
+type deployment_json struct {
+	Name            string       `json:"name"`
+	Id              string       `json:"id"`
+	PathPrefix      string       `json:"path_prefix"`
+	Endpoint        string       `json:"endpoint"`
+	Routes          []route_json `json:"routes"`
+	CompartmentId   string       `json:"compartment_id"`
+	CompartmentPath string       `json:"compartment_path"`
+}
+
+type gateway_json struct {
+	Name            string            `json:"name"`
+	Id              string            `json:"id"`
+	EndpointType    string            `json:"endpoint_type"`     // PUBLIC or PRIVATE
+	Hostname        string            `json:"hostname"`
+	LifecycleState  string            `json:"lifecycle_state"`
+	Deployments     []deployment_json `json:"deployments"`
+	Region          string            `json:"region"`
+	CompartmentId   string            `json:"compartment_id"`
+	CompartmentPath string            `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each route of each deployment. The routes of public gateways which can be called")
+    fmt.Println("    without authentication are displayed in red.")
+    fmt.Println("    If --public-only is provided, only the public gateways are displayed.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the gateways of a compartment
+func list_gateways(client apigateway.GatewayClient, cpt_id string) ([]apigateway.GatewaySummary, error) {
+	gateways := make([]apigateway.GatewaySummary, 0)
+	request := apigateway.ListGatewaysRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListGateways(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, gw := range response.Items {
+			if gw.LifecycleState != apigateway.GatewayLifecycleStateDeleted { gateways = append(gateways, gw) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return gateways, err
+}
+
+// list the deployments of a compartment (with their specification)
+func list_deployments(client apigateway.DeploymentClient, cpt_id string) ([]apigateway.Deployment, error) {
+	deployments := make([]apigateway.Deployment, 0)
+	request := apigateway.ListDeploymentsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListDeployments(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, d := range response.Items {
+			if d.LifecycleState == apigateway.DeploymentLifecycleStateDeleted { continue }
+
+			// the specification (routes) is only returned by GetDeployment
+			get_request := apigateway.GetDeploymentRequest{ DeploymentId : d.Id, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+			get_response, err := client.GetDeployment(context.Background(), get_request)
+			if err != nil { return nil, err }
+			deployments = append(deployments, get_response.Deployment)
+		}
+		return response.OpcNextPage, nil
+	})
+	return deployments, err
+}
+
+// summary of the routes of a deployment
+func summarize_routes(d apigateway.Deployment) []route_json {
+	routes := make([]route_json, 0)
+	if d.Specification == nil { return routes }
+
+	// authentication policy of the deployment (applies to all routes, except anonymous ones)
+	authentication := "none"
+	if d.Specification.RequestPolicies != nil {
+		switch d.Specification.RequestPolicies.Authentication.(type) {
+		case apigateway.JwtAuthenticationPolicy:    authentication = "jwt"
+		case apigateway.CustomAuthenticationPolicy: authentication = "custom"
+		}
+	}
+
+	for _, r := range d.Specification.Routes {
+		route := route_json{ Path : strings.TrimSuffix(*d.PathPrefix, "/") + *r.Path, Methods : make([]string, 0), Authentication : authentication }
+		for _, m := range r.Methods { route.Methods = append(route.Methods, string(m)) }
+		if len(route.Methods) == 0 { route.Methods = append(route.Methods, "GET") }     // default method
+		switch b := r.Backend.(type) {
+		case apigateway.HttpBackend:
+			route.BackendType = "http"
+			if b.Url != nil { route.Backend = *b.Url }
+		case apigateway.OracleFunctionBackend:
+			route.BackendType = "function"
+			if b.FunctionId != nil { route.Backend = *b.FunctionId }
+		case apigateway.StockResponseBackend:
+			route.BackendType = "stock_response"
+			if b.Status != nil { route.Backend = fmt.Sprintf("HTTP %d", *b.Status) }
+		}
+		if r.RequestPolicies != nil && authentication != "none" {
+			if _, anonymous := r.RequestPolicies.Authorization.(apigateway.AnonymousRouteAuthorizationPolicy); anonymous { route.Authentication = "anonymous" }
+		}
+		route.Unauthenticated = route.Authentication == "none" || route.Authentication == "anonymous"
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// list the gateways of all active compartments of a region, with their deployments
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]gateway_json, error) {
+	gw_client, err := apigateway.NewGatewayClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	gw_client.SetRegion(region)
+	dep_client, err := apigateway.NewDeploymentClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	dep_client.SetRegion(region)
+
+	type compartment_items struct {
+		gateways    []gateway_json
+		deployments []apigateway.Deployment
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		var cpt_items compartment_items
+		cpt_gateways, err := list_gateways(gw_client, cpt_id)
+		if err != nil { return nil, err }
+		for _, gw := range cpt_gateways {
+			item := gateway_json{ Name : *gw.DisplayName, Id : *gw.Id, EndpointType : string(gw.EndpointType), LifecycleState : string(gw.LifecycleState),
+				Deployments : make([]deployment_json, 0), Region : region, CompartmentId : *gw.CompartmentId }
+			if gw.Hostname != nil { item.Hostname = *gw.Hostname }
+			cpt_items.gateways = append(cpt_items.gateways, item)
+		}
+		cpt_items.deployments, err = list_deployments(dep_client, cpt_id)
+		return cpt_items, err
+	})
+	gateways := make([]gateway_json, 0)
+	deployments := make([]apigateway.Deployment, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		gateways = append(gateways, cpt_items.gateways...)
+		deployments = append(deployments, cpt_items.deployments...)
+	}
+
+	// deployments of each gateway (can be in another compartment than the gateway)
+	gateway_index := make(map[string]int)
+	for n, gw := range gateways { gateway_index[gw.Id] = n }
+	for _, d := range deployments {
+		n, found := gateway_index[*d.GatewayId]
+		if !found { continue }
+		item := deployment_json{ Name : *d.DisplayName, Id : *d.Id, PathPrefix : *d.PathPrefix, Endpoint : *d.Endpoint, Routes : summarize_routes(d), CompartmentId : *d.CompartmentId }
+		gateways[n].Deployments = append(gateways[n].Deployments, item)
+	}
+	for n := range gateways {
+		sort.Slice(gateways[n].Deployments, func(i, j int) bool { return gateways[n].Deployments[i].PathPrefix < gateways[n].Deployments[j].PathPrefix })
+	}
+	return gateways, nil
+}
+
+// display the table in text format, with the rows of public routes without authentication in red
+func display_text(table ocihelpers.Table, exposed []bool) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(exposed) { break }
+		if exposed[i] { line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL }
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list API gateways in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list API gateways in all subscribed regions")
+	public_only     := flag.Bool("public-only", false, "only display the public gateways")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the gateways in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("API gateways", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	gateways := make([]gateway_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, gw := range r.Value.([]gateway_json) {
+			if *public_only && gw.EndpointType != string(apigateway.GatewayEndpointTypePublic) { continue }
+			gw.CompartmentPath = paths[gw.CompartmentId]
+			for n := range gw.Deployments { gw.Deployments[n].CompartmentPath = paths[gw.Deployments[n].CompartmentId] }
+			gateways = append(gateways, gw)
+		}
+	}
+	sort.SliceStable(gateways, func(i, j int) bool {
+		if gateways[i].CompartmentPath != gateways[j].CompartmentPath { return gateways[i].CompartmentPath < gateways[j].CompartmentPath }
+		return gateways[i].Name < gateways[j].Name
+	})
+
+	// Display the results (a row per route)
+	if format == "json" {
+		output, err := json.MarshalIndent(gateways, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "gateway", "type", "hostname", "deployment", "path", "methods", "backend_type", "backend", "authentication", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		exposed := make([]bool, 0)
+		nb_deployments, nb_routes, nb_exposed := 0, 0, 0
+		for _, gw := range gateways {
+			end := []string{ gw.CompartmentPath }
+			if all_regions { end = append(end, gw.Region) }
+			if len(gw.Deployments) == 0 {
+				table.AddRow(append([]string{ gw.Name, gw.EndpointType, gw.Hostname, "", "", "", "", "", "" }, append(end, gw.Id)...)...)
+				exposed = append(exposed, false)
+			}
+			for _, d := range gw.Deployments {
+				nb_deployments++
+				routes := d.Routes
+				if len(routes) == 0 { routes = []route_json{ { Path : d.PathPrefix } } }
+				for _, r := range routes {
+					nb_routes++
+					is_exposed := gw.EndpointType == string(apigateway.GatewayEndpointTypePublic) && r.Unauthenticated
+					if is_exposed { nb_exposed++ }
+					row := []string{ gw.Name, gw.EndpointType, gw.Hostname, d.Name, r.Path, strings.Join(r.Methods, " "), r.BackendType, r.Backend, r.Authentication }
+					table.AddRow(append(row, append(end, d.Id)...)...)
+					exposed = append(exposed, is_exposed)
+				}
+			}
+		}
+		if format != "text" {
+			ocihelpers.FatalIfError(table.Print(format))
+		} else {
+			display_text(table, exposed)
+			if !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d gateways, %d deployments, %d routes (%d public routes without authentication)\n", len(gateways), nb_deployments, nb_routes, nb_exposed)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
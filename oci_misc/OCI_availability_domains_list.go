@@ -0,0 +1,172 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the availability domains and fault domains of the regions subscribed by a OCI tenant
+// using OCI Go SDK, with the availability domain names specific to the tenant (ex: Uocm:PHX-AD-1),
+// which are needed as inputs by many other automations (Terraform, OCI CLI...)
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type availability_domain_json struct {
+	Name         string   `json:"name"`
+	Id           string   `json:"id"`
+	FaultDomains []string `json:"fault_domains"`
+}
+
+type region_json struct {
+	Region              string                     `json:"region"`
+	AvailabilityDomains []availability_domain_json `json:"availability_domains"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, the availability domains and fault domains of all the subscribed regions are listed")
+    fmt.Println("    (home region first), one row per fault domain.")
+    fmt.Println("    If --region REGION is provided, only the availability domains and fault domains of this region are listed.")
+    fmt.Println("    If --ads-only is provided, only the availability domains are listed (one row per availability domain).")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format (fault domains nested in availability domains).")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the header rows are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the availability domains of a region with their fault domains
+func list_region(config common.ConfigurationProvider, tenancy_ocid string, region string) ([]availability_domain_json, error) {
+	client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	response, err := client.ListAvailabilityDomains(context.Background(), identity.ListAvailabilityDomainsRequest{ CompartmentId : common.String(tenancy_ocid), RequestMetadata : metadata })
+	if err != nil { return nil, err }
+	ads := make([]availability_domain_json, 0, len(response.Items))
+	for _, ad := range response.Items {
+		fd_response, err := client.ListFaultDomains(context.Background(), identity.ListFaultDomainsRequest{ CompartmentId : common.String(tenancy_ocid), AvailabilityDomain : ad.Name, RequestMetadata : metadata })
+		if err != nil { return nil, err }
+		item := availability_domain_json{ Name : *ad.Name, Id : *ad.Id, FaultDomains : make([]string, 0, len(fd_response.Items)) }
+		for _, fd := range fd_response.Items { item.FaultDomains = append(item.FaultDomains, *fd.Name) }
+		ads = append(ads, item)
+	}
+	return ads, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	region          := flag.String("region", "", "only list the availability domains of this region")
+	ads_only        := flag.Bool("ads-only", false, "only list the availability domains (no fault domains)")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	tenancy_ocid, err := config.TenancyOCID()
+	ocihelpers.FatalIfError(err)
+
+	// Regions: all subscribed regions (home region first) or region given by --region
+	regions := []string{ *region }
+	if *region == "" {
+		regions, err = ocihelpers.ListSubscribedRegions(config)
+		ocihelpers.FatalIfError(err)
+	}
+
+	// Get the availability domains and fault domains of each region (regions processed concurrently)
+	nb_failed := 0
+	items := make([]region_json, 0)
+	ocihelpers.StartProgress("availability domains", len(regions), 0)
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tenancy_ocid, region)
+	})
+	ocihelpers.StopProgress()
+	for _, r := range results {
+		if r.Err != nil {
+			if *region != "" { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		items = append(items, region_json{ Region : r.Region, AvailabilityDomains : r.Value.([]availability_domain_json) })
+	}
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(items, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "region", "availability_domain", "fault_domain", "availability_domain_ocid" } }
+		if *ads_only { table.Headers = []string{ "region", "availability_domain", "nb_fault_domains", "availability_domain_ocid" } }
+		for _, r := range items {
+			for _, ad := range r.AvailabilityDomains {
+				if *ads_only {
+					table.AddRow(r.Region, ad.Name, fmt.Sprintf("%d", len(ad.FaultDomains)), ad.Id)
+					continue
+				}
+				for _, fd := range ad.FaultDomains { table.AddRow(r.Region, ad.Name, fd, ad.Id) }
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+	}
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,255 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the budgets of a OCI tenant with their targets (compartments or tags), amount, actual spend,
+// forecasted spend and alert rules using OCI Go SDK
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/budget"
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type alert_rule_json struct {
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`            // ACTUAL or FORECAST
+	Threshold     float64 `json:"threshold"`
+	ThresholdType string  `json:"threshold_type"`  // PERCENTAGE or ABSOLUTE
+	Recipients    string  `json:"recipients"`
+}
+
+type budget_json struct {
+	Name            string            `json:"name"`
+	Id              string            `json:"id"`
+	TargetType      string            `json:"target_type"`
+	Targets         []string          `json:"targets"`     // compartment paths or tags
+	Amount          float64           `json:"amount"`
+	ResetPeriod     string            `json:"reset_period"`
+	ActualSpend     float64           `json:"actual_spend"`
+	ForecastedSpend float64           `json:"forecasted_spend"`
+	ActualPercent   float64           `json:"actual_percent"`
+	ForecastPercent float64           `json:"forecast_percent"`
+	LifecycleState  string            `json:"lifecycle_state"`
+	AlertRules      []alert_rule_json `json:"alert_rules"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    Budgets whose actual spend is above 80% of the amount are displayed in yellow, and in red above 100%.")
+    fmt.Println("    If --threshold PERCENT is provided, this percentage is used instead of 80%.")
+    fmt.Println("    If --over-threshold is provided, only the budgets whose actual spend is above the threshold are listed.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// float value of an optional float
+func float_value(f *float32) float64 {
+	if f == nil { return 0 }
+	return float64(*f)
+}
+
+// list the budgets of the tenancy (compartment and tag budgets)
+func list_budgets(client budget.BudgetClient, tenancy_ocid string) ([]budget.BudgetSummary, error) {
+	budgets := make([]budget.BudgetSummary, 0)
+	request := budget.ListBudgetsRequest{
+		CompartmentId   : common.String(tenancy_ocid),
+		TargetType      : budget.ListBudgetsTargetTypeAll,
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListBudgets(context.Background(), request)
+		if err != nil { return nil, err }
+		budgets = append(budgets, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	return budgets, err
+}
+
+// list the alert rules of a budget
+func list_alert_rules(client budget.BudgetClient, budget_id string) ([]alert_rule_json, error) {
+	rules := make([]alert_rule_json, 0)
+	request := budget.ListAlertRulesRequest{ BudgetId : common.String(budget_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListAlertRules(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, r := range response.Items {
+			rules = append(rules, alert_rule_json{ Name : *r.DisplayName, Type : string(r.Type), Threshold : float_value(r.Threshold), ThresholdType : string(r.ThresholdType), Recipients : *r.Recipients })
+		}
+		return response.OpcNextPage, nil
+	})
+	return rules, err
+}
+
+// short description of an alert rule (ex: ACTUAL>=80%)
+func describe_rule(r alert_rule_json) string {
+	if r.ThresholdType == string(budget.AlertRuleSummaryThresholdTypePercentage) { return fmt.Sprintf("%s>=%g%%", r.Type, r.Threshold) }
+	return fmt.Sprintf("%s>=%g", r.Type, r.Threshold)
+}
+
+// display the table in text format, with colors depending on the actual spend
+func display_text(table ocihelpers.Table, budgets []budget_json, threshold float64) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(budgets) { break }
+		switch {
+		case budgets[i].ActualPercent >= 100:       line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL
+		case budgets[i].ActualPercent >= threshold: line = ocihelpers.COLOR_YELLOW + line + ocihelpers.COLOR_NORMAL
+		}
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	threshold       := flag.Float64("threshold", 80, "warning threshold (percentage of the amount)")
+	over_threshold  := flag.Bool("over-threshold", false, "only list the budgets above the threshold")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *threshold <= 0 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of budgets (budgets are created in the root compartment, in the home region)
+	home_region, err := ocihelpers.GetHomeRegion(config)
+	ocihelpers.FatalIfError(err)
+	client, err := budget.NewBudgetClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	client.SetRegion(home_region)
+	summaries, err := list_budgets(client, tree.TenancyOCID)
+	ocihelpers.FatalIfError(err)
+
+	results := make([]budget_json, 0)
+	nb_over := 0
+	for _, b := range summaries {
+		item := budget_json{
+			Name            : *b.DisplayName,
+			Id              : *b.Id,
+			TargetType      : string(b.TargetType),
+			Targets         : make([]string, 0),
+			Amount          : float_value(b.Amount),
+			ResetPeriod     : string(b.ResetPeriod),
+			ActualSpend     : float_value(b.ActualSpend),
+			ForecastedSpend : float_value(b.ForecastedSpend),
+			LifecycleState  : string(b.LifecycleState),
+		}
+		targets := b.Targets
+		if len(targets) == 0 && b.TargetCompartmentId != nil { targets = []string{ *b.TargetCompartmentId } }
+		for _, t := range targets {
+			if path, found := paths[t]; found { t = path }
+			item.Targets = append(item.Targets, t)
+		}
+		if item.Amount > 0 {
+			item.ActualPercent = 100 * item.ActualSpend / item.Amount
+			item.ForecastPercent = 100 * item.ForecastedSpend / item.Amount
+		}
+		if item.ActualPercent >= *threshold { nb_over++ } else if *over_threshold { continue }
+		item.AlertRules, err = list_alert_rules(client, item.Id)
+		ocihelpers.FatalIfError(err)
+		results = append(results, item)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ActualPercent > results[j].ActualPercent })
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(results, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "budget", "targets", "amount", "actual", "actual_%", "forecast", "forecast_%", "alert_rules", "ocid" } }
+	for _, b := range results {
+		rules := make([]string, 0)
+		for _, r := range b.AlertRules { rules = append(rules, describe_rule(r)) }
+		table.AddRow(b.Name, strings.Join(b.Targets, ", "), fmt.Sprintf("%.2f", b.Amount), fmt.Sprintf("%.2f", b.ActualSpend), fmt.Sprintf("%.1f", b.ActualPercent),
+			fmt.Sprintf("%.2f", b.ForecastedSpend), fmt.Sprintf("%.1f", b.ForecastPercent), strings.Join(rules, " "), b.Id)
+	}
+	if format != "text" {
+		ocihelpers.FatalIfError(table.Print(format))
+		return
+	}
+	display_text(table, results, *threshold)
+	if !ocihelpers.Quiet {
+		fmt.Printf ("\n%d budgets, %d with actual spend above %g%% of the amount\n", len(summaries), nb_over, *threshold)
+	}
+}
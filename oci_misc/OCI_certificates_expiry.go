@@ -0,0 +1,342 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the certificates in all compartments of a OCI tenant using OCI Go SDK, with their expiry
+// date: certificates of the Certificates service and certificates stored on load balancers (with the
+// listeners using them)
+// Certificates expiring within N days (or already expired) are displayed in red
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the Certificates service is not available in the version of the OCI Go SDK used, so its REST API is
+//       used directly (signed REST requests, see internal/ocihelpers/rest.go)
+//       the expiry date of load balancer certificates is read from their public certificate (PEM)
+//       deleted certificates and load balancers are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/loadbalancer"
+)
+
+// -- constants
+const certificates_endpoint    = "https://certificatesmanagement.{region}.oci.{secondLevelDomain}"
+const certificates_api_version = "20210224"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// certificate returned by the Certificates service REST API
+type certificate_rest struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	CompartmentId  string `json:"compartmentId"`
+	LifecycleState string `json:"lifecycleState"`
+	Subject        struct {
+		CommonName string `json:"commonName"`
+	} `json:"subject"`
+	CurrentVersionSummary *struct {
+		Validity struct {
+			TimeOfValidityNotAfter time.Time `json:"timeOfValidityNotAfter"`
+		} `json:"validity"`
+	} `json:"currentVersionSummary"`
+}
+
+type certificate_json struct {
+	Source          string   `json:"source"`            // CERTIFICATES or LOAD_BALANCER
+	Name            string   `json:"name"`
+	Id              string   `json:"id"`                // OCID of the certificate or of the load balancer
+	LoadBalancer    string   `json:"load_balancer"`
+	Listeners       []string `json:"listeners"`
+	CommonName      string   `json:"common_name"`
+	Expiry          string   `json:"expiry"`
+	DaysLeft        int      `json:"days_left"`         // negative if expired
+	Expiring        bool     `json:"expiring"`          // expiring within N days or expired
+	Region          string   `json:"region"`
+	CompartmentId   string   `json:"compartment_id"`
+	CompartmentPath string   `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each certificate (Certificates service and load balancers). Certificates expiring")
+    fmt.Println("    within N days (or already expired) are displayed in red.")
+    fmt.Println("    If --days N is provided, N days is used as expiry window (default 30).")
+    fmt.Println("    If --expiring-only is provided, only the certificates expiring within N days (or expired) are displayed.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If --webhook URL is provided, a summary is posted to this Slack-compatible webhook when at least N certificates")
+    fmt.Println("    are expiring within N days or expired (--webhook-threshold N, default 1). Default URL: OCI_WEBHOOK_URL")
+    fmt.Println("    environment variable.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// number of days until a date (negative if in the past)
+func days_until(t time.Time) int {
+	return int(time.Until(t).Hours() / 24)
+}
+
+// list the certificates (not deleted) of the Certificates service in a compartment
+func list_certificates(client *ocihelpers.RestClient, cpt_id string, region string) ([]certificate_json, error) {
+	certs := make([]certificate_json, 0)
+	query := url.Values{ "compartmentId" : { cpt_id } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var collection struct {
+			Items []certificate_rest `json:"items"`
+		}
+		next_page, err := client.Get("/certificates", query, &collection)
+		if err != nil { return nil, err }
+		for _, c := range collection.Items {
+			if c.LifecycleState == "DELETED" || c.LifecycleState == "PENDING_DELETION" { continue }
+			item := certificate_json{ Source : "CERTIFICATES", Name : c.Name, Id : c.Id, Listeners : make([]string, 0), CommonName : c.Subject.CommonName, Region : region, CompartmentId : c.CompartmentId }
+			if c.CurrentVersionSummary != nil {
+				not_after := c.CurrentVersionSummary.Validity.TimeOfValidityNotAfter
+				item.Expiry, item.DaysLeft = not_after.Format("2006-01-02"), days_until(not_after)
+			}
+			certs = append(certs, item)
+		}
+		return next_page, nil
+	})
+	return certs, err
+}
+
+// list the certificates stored on the load balancers (not deleted) of a compartment, with the listeners using them
+func list_lb_certificates(client loadbalancer.LoadBalancerClient, cpt_id string, region string) ([]certificate_json, error) {
+	certs := make([]certificate_json, 0)
+	request := loadbalancer.ListLoadBalancersRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListLoadBalancers(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, lb := range response.Items {
+			if lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleted { continue }
+			for name, c := range lb.Certificates {
+				item := certificate_json{ Source : "LOAD_BALANCER", Name : name, Id : *lb.Id, LoadBalancer : *lb.DisplayName, Listeners : make([]string, 0), Region : region, CompartmentId : *lb.CompartmentId }
+				for _, l := range lb.Listeners {
+					if l.SslConfiguration != nil && l.SslConfiguration.CertificateName != nil && *l.SslConfiguration.CertificateName == name {
+						item.Listeners = append(item.Listeners, *l.Name)
+					}
+				}
+				sort.Strings(item.Listeners)
+				if c.PublicCertificate != nil {
+					if block, _ := pem.Decode([]byte(*c.PublicCertificate)); block != nil {
+						if x, err := x509.ParseCertificate(block.Bytes); err == nil {
+							item.CommonName, item.Expiry, item.DaysLeft = x.Subject.CommonName, x.NotAfter.Format("2006-01-02"), days_until(x.NotAfter)
+						}
+					}
+				}
+				certs = append(certs, item)
+			}
+		}
+		return response.OpcNextPage, nil
+	})
+	return certs, err
+}
+
+// list the certificates of all active compartments of a region (Certificates service and load balancers)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]certificate_json, error) {
+	rest_client, err := ocihelpers.NewRestClient(config, certificates_endpoint, certificates_api_version, region)
+	if err != nil { return nil, err }
+	lb_client, err := loadbalancer.NewLoadBalancerClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	lb_client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_certs, err := list_certificates(rest_client, cpt_id, region)
+		if err != nil { return nil, err }
+		lb_certs, err := list_lb_certificates(lb_client, cpt_id, region)
+		if err != nil { return nil, err }
+		return append(cpt_certs, lb_certs...), nil
+	})
+	certs := make([]certificate_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		certs = append(certs, r.Value.([]certificate_json)...)
+	}
+	return certs, nil
+}
+
+// display the table in text format, with the expiring certificates in red
+func display_text(table ocihelpers.Table, certs []certificate_json) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(certs) { break }
+		if certs[i].Expiring { line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL }
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list certificates in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list certificates in all subscribed regions")
+	days              := flag.Int("days", 30, "expiry window in days")
+	expiring_only     := flag.Bool("expiring-only", false, "only display the certificates expiring within the expiry window (or expired)")
+	no_color          := flag.Bool("no-color", false, "display output without colors")
+	webhook           := flag.String("webhook", ocihelpers.WebhookURL(), "post a summary to this Slack-compatible webhook")
+	webhook_threshold := flag.Int("webhook-threshold", 1, "minimum number of expiring certificates to post a summary")
+	json_output       := flag.Bool("json", false, "display output in JSON format")
+	csv_output        := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output   := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism       := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file       := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *days < 0 || *webhook_threshold < 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the certificates in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("certificates", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	certs := make([]certificate_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, c := range r.Value.([]certificate_json) {
+			c.CompartmentPath = paths[c.CompartmentId]
+			c.Expiring = c.Expiry != "" && c.DaysLeft <= *days
+			if *expiring_only && !c.Expiring { continue }
+			certs = append(certs, c)
+		}
+	}
+
+	// Certificates expiring first at the top (certificates with unknown expiry date at the end)
+	sort.SliceStable(certs, func(i, j int) bool {
+		if (certs[i].Expiry == "") != (certs[j].Expiry == "") { return certs[j].Expiry == "" }
+		if certs[i].DaysLeft != certs[j].DaysLeft { return certs[i].DaysLeft < certs[j].DaysLeft }
+		return certs[i].Name < certs[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(certs, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "source", "certificate", "load_balancer", "listeners", "common_name", "expiry", "days_left", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_expiring := 0
+		for _, c := range certs {
+			if c.Expiring { nb_expiring++ }
+			days_left := ""
+			if c.Expiry != "" { days_left = fmt.Sprintf("%d", c.DaysLeft) }
+			row := []string{ c.Source, c.Name, c.LoadBalancer, strings.Join(c.Listeners, " "), c.CommonName, c.Expiry, days_left, c.CompartmentPath }
+			if all_regions { row = append(row, c.Region) }
+			table.AddRow(append(row, c.Id)...)
+		}
+		if format != "text" {
+			ocihelpers.FatalIfError(table.Print(format))
+		} else {
+			display_text(table, certs)
+			if !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d certificates, %d expiring within %d days or expired\n", len(certs), nb_expiring, *days)
+			}
+		}
+	}
+
+	// Post a summary to the webhook if enough certificates are expiring
+	lines := make([]string, 0)
+	for _, c := range certs {
+		if !c.Expiring { continue }
+		name := c.Name
+		if c.LoadBalancer != "" { name += " (load balancer " + c.LoadBalancer + ")" }
+		lines = append(lines, fmt.Sprintf("%s: %s, %d days left (%s, %s)", name, c.Expiry, c.DaysLeft, c.Region, c.CompartmentPath))
+	}
+	_, err = ocihelpers.Notify(*webhook, *webhook_threshold, fmt.Sprintf("OCI certificates report: %d certificates expiring within %d days or expired", len(lines), *days), lines)
+	ocihelpers.FatalIfError(err)
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
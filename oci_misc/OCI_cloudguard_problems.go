@@ -0,0 +1,280 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the open problems detected by Cloud Guard in all compartments of a OCI tenant, with their
+// risk level, detector rule, resource and compartment, for a weekly security review
+// Critical and high risk problems are displayed in red, medium risk problems in yellow
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       the problems are read in the reporting region of Cloud Guard (they include the problems of all regions)
+//       Cloud Guard is not available in the version of the OCI Go SDK used, so its REST API is used directly
+//       (signed REST requests, see internal/ocihelpers/rest.go)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+//                 - Cloud Guard enabled in the tenant
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- constants
+const cloudguard_endpoint    = "https://cloudguard-cp-api.{region}.oci.{secondLevelDomain}"
+const cloudguard_api_version = "20200131"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// risk levels, from the highest to the lowest
+var risk_levels = []string{ "CRITICAL", "HIGH", "MEDIUM", "LOW", "MINOR" }
+
+// -- types
+
+// problem returned by the Cloud Guard REST API
+type problem_rest struct {
+	Id                string    `json:"id"`
+	CompartmentId     string    `json:"compartmentId"`
+	DetectorRuleId    string    `json:"detectorRuleId"`
+	DetectorId        string    `json:"detectorId"`
+	RiskLevel         string    `json:"riskLevel"`
+	ResourceId        string    `json:"resourceId"`
+	ResourceName      string    `json:"resourceName"`
+	ResourceType      string    `json:"resourceType"`
+	Region            string    `json:"region"`
+	Labels            []string  `json:"labels"`
+	TimeFirstDetected time.Time `json:"timeFirstDetected"`
+	TimeLastDetected  time.Time `json:"timeLastDetected"`
+}
+
+type problem_json struct {
+	Id                string   `json:"id"`
+	RiskLevel         string   `json:"risk_level"`
+	DetectorRule      string   `json:"detector_rule"`
+	Detector          string   `json:"detector"`
+	ResourceType      string   `json:"resource_type"`
+	ResourceName      string   `json:"resource_name"`
+	ResourceId        string   `json:"resource_id"`
+	Region            string   `json:"region"`
+	Labels            []string `json:"labels"`
+	TimeFirstDetected string   `json:"time_first_detected"`
+	TimeLastDetected  string   `json:"time_last_detected"`
+	CompartmentId     string   `json:"compartment_id"`
+	CompartmentPath   string   `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    The open problems are listed from the highest risk level to the lowest. Critical and high risk problems are")
+    fmt.Println("    displayed in red, medium risk problems in yellow.")
+    fmt.Println("    If --risk-level LEVEL,... is provided, only the problems with these risk levels are listed")
+    fmt.Println("    (CRITICAL, HIGH, MEDIUM, LOW, MINOR, ex: --risk-level CRITICAL,HIGH).")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// parse the risk levels given by --risk-level (nil if not provided)
+func parse_risk_levels(list string) (map[string]bool, error) {
+	if list == "" { return nil, nil }
+	levels := make(map[string]bool)
+	for _, item := range strings.Split(list, ",") {
+		level := strings.ToUpper(strings.TrimSpace(item))
+		if risk_rank(level) == len(risk_levels) { return nil, fmt.Errorf("invalid risk level '%s' (%s expected)", item, strings.Join(risk_levels, ", ")) }
+		levels[level] = true
+	}
+	return levels, nil
+}
+
+// rank of a risk level (0 for the highest), len(risk_levels) if unknown
+func risk_rank(level string) int {
+	for i, l := range risk_levels {
+		if l == level { return i }
+	}
+	return len(risk_levels)
+}
+
+// last part of an OCID or of a name (ex: the name of a detector rule)
+func short_name(id string) string {
+	fields := strings.Split(id, ".")
+	return fields[len(fields)-1]
+}
+
+// reporting region of Cloud Guard
+func reporting_region(client *ocihelpers.RestClient, tenancy_id string) (string, error) {
+	var configuration struct {
+		ReportingRegion string `json:"reportingRegion"`
+		Status          string `json:"status"`
+	}
+	if _, err := client.Get("/configuration", url.Values{ "compartmentId" : { tenancy_id } }, &configuration); err != nil { return "", err }
+	if configuration.Status != "ENABLED" { return "", fmt.Errorf("Cloud Guard is not enabled in the tenant (status %s)", configuration.Status) }
+	return configuration.ReportingRegion, nil
+}
+
+// list the open problems of the tenant (all compartments)
+func list_problems(client *ocihelpers.RestClient, tenancy_id string) ([]problem_json, error) {
+	problems := make([]problem_json, 0)
+	query := url.Values{ "compartmentId" : { tenancy_id }, "compartmentIdInSubtree" : { "true" }, "accessLevel" : { "ACCESSIBLE" }, "lifecycleDetail" : { "OPEN" } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var collection struct {
+			Items []problem_rest `json:"items"`
+		}
+		next_page, err := client.Get("/problems", query, &collection)
+		if err != nil { return nil, err }
+		for _, p := range collection.Items {
+			item := problem_json{ Id : p.Id, RiskLevel : p.RiskLevel, DetectorRule : p.DetectorRuleId, Detector : p.DetectorId, ResourceType : p.ResourceType,
+				ResourceName : p.ResourceName, ResourceId : p.ResourceId, Region : p.Region, Labels : p.Labels,
+				TimeFirstDetected : p.TimeFirstDetected.UTC().Format("2006-01-02T15:04:05Z"), TimeLastDetected : p.TimeLastDetected.UTC().Format("2006-01-02T15:04:05Z"),
+				CompartmentId : p.CompartmentId }
+			if item.Labels == nil { item.Labels = make([]string, 0) }
+			problems = append(problems, item)
+		}
+		return next_page, nil
+	})
+	return problems, err
+}
+
+// display the table in text format, with the rows of critical and high risk problems in red and medium risk problems in yellow
+func display_text(table ocihelpers.Table, levels []string) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(levels) { break }
+		switch levels[i] {
+		case "CRITICAL", "HIGH": line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL
+		case "MEDIUM":           line = ocihelpers.COLOR_YELLOW + line + ocihelpers.COLOR_NORMAL
+		}
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	risk_level      := flag.String("risk-level", "", "only list the problems with these risk levels (comma separated)")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	levels, err := parse_risk_levels(*risk_level)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the open problems in the reporting region of Cloud Guard
+	region, err := config.Region()
+	ocihelpers.FatalIfError(err)
+	client, err := ocihelpers.NewRestClient(config, cloudguard_endpoint, cloudguard_api_version, region)
+	ocihelpers.FatalIfError(err)
+	reporting, err := reporting_region(client, tree.TenancyOCID)
+	ocihelpers.FatalIfError(err)
+	if reporting != region {
+		client, err = ocihelpers.NewRestClient(config, cloudguard_endpoint, cloudguard_api_version, reporting)
+		ocihelpers.FatalIfError(err)
+	}
+	all_problems, err := list_problems(client, tree.TenancyOCID)
+	ocihelpers.FatalIfError(err)
+	problems := make([]problem_json, 0)
+	for _, p := range all_problems {
+		if levels != nil && !levels[p.RiskLevel] { continue }
+		p.CompartmentPath = paths[p.CompartmentId]
+		problems = append(problems, p)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].RiskLevel != problems[j].RiskLevel { return risk_rank(problems[i].RiskLevel) < risk_rank(problems[j].RiskLevel) }
+		return problems[i].TimeLastDetected > problems[j].TimeLastDetected
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(problems, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "risk_level", "detector_rule", "detector", "resource_type", "resource", "region", "first_detected", "last_detected", "compartment_path", "ocid" } }
+	risks := make([]string, 0)
+	counts := make(map[string]int)
+	for _, p := range problems {
+		table.AddRow(p.RiskLevel, short_name(p.DetectorRule), p.Detector, p.ResourceType, p.ResourceName, p.Region, p.TimeFirstDetected, p.TimeLastDetected, p.CompartmentPath, p.Id)
+		risks = append(risks, p.RiskLevel)
+		counts[p.RiskLevel]++
+	}
+	if format != "text" {
+		ocihelpers.FatalIfError(table.Print(format))
+		return
+	}
+	display_text(table, risks)
+	if !ocihelpers.Quiet {
+		summary := make([]string, 0)
+		for _, level := range risk_levels { summary = append(summary, fmt.Sprintf("%d %s", counts[level], strings.ToLower(level))) }
+		fmt.Println ("")
+		fmt.Printf ("%d open problems (%s), reporting region %s\n", len(problems), strings.Join(summary, ", "), reporting)
+	}
+}
@@ -0,0 +1,269 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script downloads the cost reports of a OCI tenant (CSV files generated by OCI in a special Object Storage
+// bucket) using OCI Go SDK, and displays the cost per service and per compartment for a month
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       the user needs a cross-tenancy policy to read the cost reports (see README.md)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/objectstorage"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type cost_json struct {
+	Name string  `json:"name"`
+	Cost float64 `json:"cost"`
+}
+
+type summary_json struct {
+	Month          string      `json:"month"`
+	Currency       string      `json:"currency"`
+	Total          float64     `json:"total"`
+	NbReports      int         `json:"nb_reports"`
+	ByService      []cost_json `json:"by_service,omitempty"`
+	ByCompartment  []cost_json `json:"by_compartment,omitempty"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    The cost per service and the cost per compartment are displayed for the current month.")
+    fmt.Println("    If --month YYYY-MM is provided, the costs of this month are displayed instead (reports are kept 1 year).")
+    fmt.Println("    If --by service or --by compartment is provided, only the cost per service or per compartment is displayed.")
+    fmt.Println("    If --download-dir DIR is provided, the cost reports are kept in DIR (and not downloaded again later).")
+    fmt.Println("    If -json is provided, the costs are displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the costs are displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the costs are displayed as Markdown tables (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the total lines are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the cost reports created between 2 dates
+func list_reports(client objectstorage.ObjectStorageClient, bucket string, start time.Time, end time.Time) ([]string, error) {
+	reports := make([]string, 0)
+	request := objectstorage.ListObjectsRequest{
+		NamespaceName   : common.String(ocihelpers.CostReportsNamespace),
+		BucketName      : common.String(bucket),
+		Prefix          : common.String(ocihelpers.CostReportsPrefix),
+		Fields          : common.String("name,timeCreated"),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	// object storage uses a start object name instead of a page token
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Start = page
+		response, err := client.ListObjects(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, o := range response.Objects {
+			if o.TimeCreated == nil || o.TimeCreated.Before(start) || !o.TimeCreated.Before(end) { continue }
+			reports = append(reports, *o.Name)
+		}
+		return response.NextStartWith, nil
+	})
+	sort.Strings(reports)
+	return reports, err
+}
+
+// open a cost report (gzip compressed CSV file), downloaded in download_dir if not empty
+func open_report(client objectstorage.ObjectStorageClient, bucket string, name string, download_dir string) (io.ReadCloser, error) {
+	local_path := ""
+	if download_dir != "" {
+		local_path = filepath.Join(download_dir, path.Base(name))
+		if f, err := os.Open(local_path); err == nil { return f, nil }
+	}
+	response, err := client.GetObject(context.Background(), objectstorage.GetObjectRequest{
+		NamespaceName   : common.String(ocihelpers.CostReportsNamespace),
+		BucketName      : common.String(bucket),
+		ObjectName      : common.String(name),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	})
+	if err != nil { return nil, err }
+	if local_path == "" { return response.Content, nil }
+
+	// written to a temporary file first, so that a failed download does not leave a truncated file
+	defer response.Content.Close()
+	f, err := os.Create(local_path + ".part")
+	if err != nil { return nil, err }
+	_, err = io.Copy(f, response.Content)
+	if close_err := f.Close(); err == nil { err = close_err }
+	if err == nil { err = os.Rename(local_path + ".part", local_path) }
+	if err != nil {
+		os.Remove(local_path + ".part")
+		return nil, err
+	}
+	return os.Open(local_path)
+}
+
+// read the line items of a cost report
+func read_report(client objectstorage.ObjectStorageClient, bucket string, name string, download_dir string) ([]ocihelpers.CostLine, error) {
+	f, err := open_report(client, bucket, name, download_dir)
+	if err != nil { return nil, err }
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil { return nil, fmt.Errorf("%s: %s", name, err) }
+	defer gz.Close()
+	lines, err := ocihelpers.ParseCostReport(gz)
+	if err != nil { return nil, fmt.Errorf("%s: %s", name, err) }
+	return lines, nil
+}
+
+// costs sorted by decreasing cost
+func sorted_costs(costs map[string]float64) []cost_json {
+	result := make([]cost_json, 0, len(costs))
+	for name, cost := range costs { result = append(result, cost_json{ name, cost }) }
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost { return result[i].Cost > result[j].Cost }
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// display the costs per service or per compartment
+func display_costs(header string, costs []cost_json, summary summary_json, format string) {
+	table := ocihelpers.Table{ Headers : []string{ header, "cost_" + summary.Currency, "percent" } }
+	for _, c := range costs {
+		percent := 0.0
+		if summary.Total != 0 { percent = 100 * c.Cost / summary.Total }
+		table.AddRow(c.Name, fmt.Sprintf("%.2f", c.Cost), fmt.Sprintf("%.1f", percent))
+	}
+	if format == "text" && !ocihelpers.Quiet { table.AddRow("TOTAL", fmt.Sprintf("%.2f", summary.Total), "100.0") }
+	ocihelpers.FatalIfError(table.Print(format))
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	month           := flag.String("month", time.Now().UTC().Format("2006-01"), "month (YYYY-MM)")
+	by              := flag.String("by", "", "display only the cost per service or per compartment")
+	download_dir    := flag.String("download-dir", "", "keep the cost reports in this directory")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as Markdown tables")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the total lines")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *by != "" && *by != "service" && *by != "compartment" { usage() }
+	// in CSV format, a single table can be displayed
+	if format == "csv" && *by == "" { ocihelpers.Fatal ("--by service or --by compartment is needed with -csv") }
+	month_start, err := time.Parse("2006-01", *month)
+	if err != nil { ocihelpers.Fatal ("invalid month %s (YYYY-MM expected)", *month) }
+	month_end := month_start.AddDate(0, 1, 0)
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	if *download_dir != "" { ocihelpers.FatalIfError(os.MkdirAll(ocihelpers.ExpandPath(*download_dir), 0755)) }
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	tenancy_ocid, err := config.TenancyOCID()
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments (including deleted ones)
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// The cost reports are stored in the home region, in a bucket named as the tenancy OCID
+	home_region, err := ocihelpers.GetHomeRegion(config)
+	ocihelpers.FatalIfError(err)
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	client.SetRegion(home_region)
+
+	// Get the cost reports created during the month (and a few days later, as late line items and corrections
+	// are added in later reports)
+	reports, err := list_reports(client, tenancy_ocid, month_start, month_end.AddDate(0, 0, 3))
+	ocihelpers.FatalIfError(err)
+	if len(reports) == 0 { ocihelpers.Fatal ("no cost report found for %s", *month) }
+	lines := make([]ocihelpers.CostLine, 0)
+	for _, name := range reports {
+		report_lines, err := read_report(client, tenancy_ocid, name, ocihelpers.ExpandPath(*download_dir))
+		ocihelpers.FatalIfError(err)
+		lines = append(lines, report_lines...)
+	}
+	lines = ocihelpers.ApplyCostCorrections(lines)
+
+	// Cost per service and per compartment
+	summary := summary_json{ Month : *month, NbReports : len(reports) }
+	by_service     := make(map[string]float64)
+	by_compartment := make(map[string]float64)
+	for _, l := range lines {
+		if l.IntervalStart.Before(month_start) || !l.IntervalStart.Before(month_end) { continue }
+		if summary.Currency == "" { summary.Currency = l.Currency }
+		cpt_path, found := paths[l.CompartmentId]
+		if !found && l.CompartmentName != "" { cpt_path = l.CompartmentName + " (deleted)" }
+		if !found && l.CompartmentName == "" { cpt_path = "-" }
+		summary.Total += l.Cost
+		by_service[l.Service] += l.Cost
+		by_compartment[cpt_path] += l.Cost
+	}
+	if *by != "compartment" { summary.ByService = sorted_costs(by_service) }
+	if *by != "service" { summary.ByCompartment = sorted_costs(by_compartment) }
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(summary, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	if format == "text" && !ocihelpers.Quiet { fmt.Printf ("Costs for %s (%d cost reports)\n\n", *month, len(reports)) }
+	if summary.ByService != nil { display_costs("service", summary.ByService, summary, format) }
+	if summary.ByService != nil && summary.ByCompartment != nil { fmt.Println ("") }
+	if summary.ByCompartment != nil { display_costs("compartment", summary.ByCompartment, summary, format) }
+}
@@ -0,0 +1,356 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the Data Science projects and notebook sessions in all compartments of a OCI tenant using
+// OCI Go SDK, with the time since the activation of the active notebook sessions
+// It can also deactivate the notebook sessions active for more than N hours outside working hours (sessions
+// forgotten by data scientists): in this case, it needs to be executed every hour by an external scheduler
+// (cron table on Linux for example)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the activation time of a notebook session is the end of its last successful create or activate work
+//       request (or its creation time if no such work request is found)
+//       working hours are in UTC time, week-ends (Saturday and Sunday) are outside working hours
+//       deleted projects and notebook sessions are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+//                 - OCI user with enough privileges to be able to read and deactivate notebook sessions
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/datascience"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type session_json struct {
+	Name            string  `json:"name"`
+	Id              string  `json:"id"`
+	ProjectName     string  `json:"project_name"`
+	ProjectId       string  `json:"project_id"`
+	Shape           string  `json:"shape"`
+	LifecycleState  string  `json:"lifecycle_state"`
+	CreatedBy       string  `json:"created_by"`
+	ActiveSince     string  `json:"active_since"`        // only for active sessions
+	RunningHours    float64 `json:"running_hours"`       // only for active sessions
+	Region          string  `json:"region"`
+	CompartmentId   string  `json:"compartment_id"`
+	CompartmentPath string  `json:"compartment_path"`
+}
+
+// projects and notebook sessions found in a region
+type region_items struct {
+	projects []datascience.ProjectSummary
+	sessions []session_json
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each notebook session (projects without notebook sessions are displayed with an empty")
+    fmt.Println("    notebook session), with the number of hours since its activation for the active sessions.")
+    fmt.Println("    If --deactivate-after N is provided, the notebook sessions active for more than N hours are deactivated if the")
+    fmt.Println("    current time is outside working hours (the list is not displayed).")
+    fmt.Println("    If --confirm is not provided, the notebook sessions to deactivate are listed but not actually deactivated.")
+    fmt.Println("    If --working-hours HH-HH is provided, these working hours (UTC) are used instead of 08-19. Week-ends are always")
+    fmt.Println("    outside working hours.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions")
+    fmt.Println("    or deactivations failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// parse working hours with format HH-HH (ex: 08-19)
+func parse_working_hours(str string) (int, int, error) {
+	var start, end int
+	if _, err := fmt.Sscanf(str, "%d-%d", &start, &end); err != nil || start < 0 || end > 24 || start >= end {
+		return 0, 0, fmt.Errorf("invalid working hours %s (format HH-HH, ex: 08-19)", str)
+	}
+	return start, end, nil
+}
+
+// true if a time (UTC) is outside working hours (or during a week-end)
+func outside_working_hours(t time.Time, start int, end int) bool {
+	t = t.UTC()
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday { return true }
+	return t.Hour() < start || t.Hour() >= end
+}
+
+// activation time of the notebook sessions of a compartment: end of their last successful create or activate work request
+func activation_times(client datascience.DataScienceClient, cpt_id string) (map[string]time.Time, error) {
+	times := make(map[string]time.Time)
+	request := datascience.ListWorkRequestsRequest{ CompartmentId : common.String(cpt_id), Status : datascience.ListWorkRequestsStatusSucceeded, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListWorkRequests(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, w := range response.Items {
+			if w.OperationType != datascience.WorkRequestOperationTypeNotebookSessionCreate && w.OperationType != datascience.WorkRequestOperationTypeNotebookSessionActivate { continue }
+			if w.TimeFinished == nil { continue }
+			for _, r := range w.Resources {
+				if t, found := times[*r.Identifier]; !found || w.TimeFinished.Time.After(t) { times[*r.Identifier] = w.TimeFinished.Time }
+			}
+		}
+		return response.OpcNextPage, nil
+	})
+	return times, err
+}
+
+// list the projects and notebook sessions (not deleted) of all active compartments of a region
+// (the notebook sessions can be in a compartment different from the one of their project)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) (region_items, error) {
+	var items region_items
+	client, err := datascience.NewDataScienceClientWithConfigurationProvider(config)
+	if err != nil { return items, err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		var items region_items
+		projects_request := datascience.ListProjectsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			projects_request.Page = page
+			response, err := client.ListProjects(context.Background(), projects_request)
+			if err != nil { return nil, err }
+			for _, p := range response.Items {
+				if p.LifecycleState != datascience.ProjectLifecycleStateDeleted { items.projects = append(items.projects, p) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		sessions := make([]datascience.NotebookSessionSummary, 0)
+		sessions_request := datascience.ListNotebookSessionsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			sessions_request.Page = page
+			response, err := client.ListNotebookSessions(context.Background(), sessions_request)
+			if err != nil { return nil, err }
+			for _, s := range response.Items {
+				if s.LifecycleState != datascience.NotebookSessionLifecycleStateDeleted { sessions = append(sessions, s) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		if len(sessions) == 0 { return items, nil }
+
+		times, err := activation_times(client, cpt_id)
+		if err != nil { return nil, err }
+		for _, s := range sessions {
+			item := session_json{ Name : *s.DisplayName, Id : *s.Id, ProjectId : *s.ProjectId, LifecycleState : string(s.LifecycleState), CreatedBy : *s.CreatedBy, Region : region, CompartmentId : *s.CompartmentId }
+			if s.NotebookSessionConfigurationDetails != nil { item.Shape = *s.NotebookSessionConfigurationDetails.Shape }
+			if s.LifecycleState == datascience.NotebookSessionLifecycleStateActive {
+				active_since, found := times[*s.Id]
+				if !found { active_since = s.TimeCreated.Time }
+				item.ActiveSince  = active_since.UTC().Format("2006-01-02T15:04:05Z")
+				item.RunningHours = float64(int(time.Since(active_since).Hours() * 10)) / 10
+			}
+			items.sessions = append(items.sessions, item)
+		}
+		return items, nil
+	})
+	for _, r := range results {
+		if r.Err != nil { return items, r.Err }
+		cpt_items := r.Value.(region_items)
+		items.projects = append(items.projects, cpt_items.projects...)
+		items.sessions = append(items.sessions, cpt_items.sessions...)
+	}
+	return items, nil
+}
+
+// deactivate a notebook session
+func deactivate_session(config common.ConfigurationProvider, s session_json) error {
+	client, err := datascience.NewDataScienceClientWithConfigurationProvider(config)
+	if err != nil { return err }
+	client.SetRegion(s.Region)
+	_, err = client.DeactivateNotebookSession(context.Background(), datascience.DeactivateNotebookSessionRequest{ NotebookSessionId : common.String(s.Id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+	return err
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	deactivate_after := flag.Float64("deactivate-after", 0, "deactivate the notebook sessions active for more than N hours outside working hours")
+	confirm          := flag.Bool("confirm", false, "actually deactivate the notebook sessions")
+	working_hours    := flag.String("working-hours", "08-19", "working hours (UTC)")
+	json_output      := flag.Bool("json", false, "display output in JSON format")
+	csv_output       := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output  := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism      := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file      := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *deactivate_after < 0 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	start_hour, end_hour, err := parse_working_hours(*working_hours)
+	ocihelpers.FatalIfError(err)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the projects and notebook sessions in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("notebook sessions", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	projects := make([]session_json, 0)     // projects (displayed with an empty notebook session)
+	sessions := make([]session_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		items := r.Value.(region_items)
+		project_names := make(map[string]string)
+		for _, p := range items.projects {
+			project_names[*p.Id] = *p.DisplayName
+			projects = append(projects, session_json{ ProjectName : *p.DisplayName, ProjectId : *p.Id, LifecycleState : string(p.LifecycleState), CreatedBy : *p.CreatedBy, Region : r.Region, CompartmentId : *p.CompartmentId, CompartmentPath : paths[*p.CompartmentId] })
+		}
+		for _, s := range items.sessions {
+			s.ProjectName, s.CompartmentPath = project_names[s.ProjectId], paths[s.CompartmentId]
+			sessions = append(sessions, s)
+		}
+	}
+
+	// Deactivate the notebook sessions active for too long outside working hours
+	if *deactivate_after > 0 {
+		now := time.Now().UTC()
+		if !outside_working_hours(now, start_hour, end_hour) {
+			fmt.Printf ("%s: within working hours (%s UTC): nothing to do\n", now.Format("2006/01/02 15:04:05"), *working_hours)
+		} else {
+			for _, s := range sessions {
+				if s.LifecycleState != string(datascience.NotebookSessionLifecycleStateActive) || s.RunningHours <= *deactivate_after { continue }
+				prefix := fmt.Sprintf("%s, %s, %s: ", now.Format("15:04:05"), s.Region, s.CompartmentPath)
+				if !*confirm {
+					fmt.Printf ("%sNotebook session %s (%s) active for %.1f hours SHOULD BE DEACTIVATED --> re-run script with --confirm to actually deactivate notebook sessions\n", prefix, s.Name, s.Id, s.RunningHours)
+					continue
+				}
+				fmt.Printf ("%sDEACTIVATING notebook session %s (%s) active for %.1f hours\n", prefix, s.Name, s.Id, s.RunningHours)
+				if err := deactivate_session(config, s); err != nil {
+					fmt.Printf ("%sERROR: %s\n", prefix, err)
+					nb_failed++
+				}
+			}
+		}
+		if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+		return
+	}
+
+	// Projects without notebook sessions are displayed with an empty notebook session
+	has_sessions := make(map[string]bool)
+	for _, s := range sessions { has_sessions[s.ProjectId] = true }
+	rows := make([]session_json, 0)
+	for _, p := range projects {
+		if !has_sessions[p.ProjectId] { rows = append(rows, p) }
+	}
+	rows = append(rows, sessions...)
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].CompartmentPath != rows[j].CompartmentPath { return rows[i].CompartmentPath < rows[j].CompartmentPath }
+		if rows[i].ProjectName != rows[j].ProjectName { return rows[i].ProjectName < rows[j].ProjectName }
+		return rows[i].Name < rows[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		sort.SliceStable(sessions, func(i, j int) bool {
+			if sessions[i].CompartmentPath != sessions[j].CompartmentPath { return sessions[i].CompartmentPath < sessions[j].CompartmentPath }
+			return sessions[i].Name < sessions[j].Name
+		})
+		output, err := json.MarshalIndent(sessions, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "compartment_path", "project", "notebook_session", "shape", "state", "created_by", "active_since", "running_hours" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_active := 0
+		for _, s := range rows {
+			running_hours := ""
+			if s.ActiveSince != "" {
+				nb_active++
+				running_hours = fmt.Sprintf("%.1f", s.RunningHours)
+			}
+			id := s.Id
+			if id == "" { id = s.ProjectId }
+			row := []string{ s.CompartmentPath, s.ProjectName, s.Name, s.Shape, s.LifecycleState, s.CreatedBy, s.ActiveSince, running_hours }
+			if all_regions { row = append(row, s.Region) }
+			table.AddRow(append(row, id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d projects, %d notebook sessions (%d active)\n", len(projects), len(sessions), nb_active)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,272 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the rules of the Events service in all compartments of a OCI tenant using OCI Go SDK,
+// including disabled rules, with their condition (JSON) and the targets of their actions (functions, streams
+// and notification topics), to audit the event-driven automation of the tenant
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       deleted rules are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/events"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type action_json struct {
+	Type           string `json:"type"`         // function, stream or topic
+	TargetId       string `json:"target_id"`
+	IsEnabled      bool   `json:"is_enabled"`
+	LifecycleState string `json:"lifecycle_state"`
+	Description    string `json:"description"`
+}
+
+type rule_json struct {
+	Name            string        `json:"name"`
+	Id              string        `json:"id"`
+	Description     string        `json:"description"`
+	IsEnabled       bool          `json:"is_enabled"`
+	LifecycleState  string        `json:"lifecycle_state"`
+	Condition       string        `json:"condition"`
+	Actions         []action_json `json:"actions"`
+	Region          string        `json:"region"`
+	CompartmentId   string        `json:"compartment_id"`
+	CompartmentPath string        `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each action of each rule.")
+    fmt.Println("    If --disabled-only is provided, only the disabled rules and the rules with disabled actions are displayed.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// condition of a rule on a single line
+func compact_condition(condition string) string {
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, []byte(condition)); err != nil { return condition }
+	return buffer.String()
+}
+
+// type and target of an action
+func action_target(a events.Action) (string, string) {
+	switch t := a.(type) {
+	case events.FaaSAction:
+		if t.FunctionId != nil { return "function", *t.FunctionId }
+		return "function", ""
+	case events.StreamingServiceAction:
+		if t.StreamId != nil { return "stream", *t.StreamId }
+		return "stream", ""
+	case events.NotificationServiceAction:
+		if t.TopicId != nil { return "topic", *t.TopicId }
+		return "topic", ""
+	}
+	return "unknown", ""
+}
+
+// list the rules of a compartment (with their actions, only returned by GetRule)
+func list_rules(client events.EventsClient, cpt_id string, region string) ([]rule_json, error) {
+	rules := make([]rule_json, 0)
+	request := events.ListRulesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListRules(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, r := range response.Items {
+			if r.LifecycleState == events.RuleLifecycleStateDeleted { continue }
+			get_request := events.GetRuleRequest{ RuleId : r.Id, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+			get_response, err := client.GetRule(context.Background(), get_request)
+			if err != nil { return nil, err }
+			rule := get_response.Rule
+			item := rule_json{ Name : *rule.DisplayName, Id : *rule.Id, IsEnabled : *rule.IsEnabled, LifecycleState : string(rule.LifecycleState),
+				Condition : compact_condition(*rule.Condition), Actions : make([]action_json, 0), Region : region, CompartmentId : cpt_id }
+			if rule.Description != nil { item.Description = *rule.Description }
+			if rule.Actions != nil {
+				for _, a := range rule.Actions.Actions {
+					action := action_json{ LifecycleState : string(a.GetLifecycleState()) }
+					action.Type, action.TargetId = action_target(a)
+					if a.GetIsEnabled() != nil   { action.IsEnabled = *a.GetIsEnabled() }
+					if a.GetDescription() != nil { action.Description = *a.GetDescription() }
+					item.Actions = append(item.Actions, action)
+				}
+			}
+			rules = append(rules, item)
+		}
+		return response.OpcNextPage, nil
+	})
+	return rules, err
+}
+
+// list the rules of all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]rule_json, error) {
+	client, err := events.NewEventsClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return list_rules(client, cpt_id, region)
+	})
+	rules := make([]rule_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		rules = append(rules, r.Value.([]rule_json)...)
+	}
+	return rules, nil
+}
+
+// true if the rule or one of its actions is disabled
+func is_disabled(r rule_json) bool {
+	if !r.IsEnabled { return true }
+	for _, a := range r.Actions {
+		if !a.IsEnabled { return true }
+	}
+	return false
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list rules in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list rules in all subscribed regions")
+	disabled_only   := flag.Bool("disabled-only", false, "only display the disabled rules and the rules with disabled actions")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the rules in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("events rules", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	rules := make([]rule_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, rule := range r.Value.([]rule_json) {
+			if *disabled_only && !is_disabled(rule) { continue }
+			rule.CompartmentPath = paths[rule.CompartmentId]
+			rules = append(rules, rule)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].CompartmentPath != rules[j].CompartmentPath { return rules[i].CompartmentPath < rules[j].CompartmentPath }
+		return rules[i].Name < rules[j].Name
+	})
+
+	// Display the results (a row per action)
+	if format == "json" {
+		output, err := json.MarshalIndent(rules, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "rule", "enabled", "state", "condition", "action_type", "action_target", "action_enabled", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_disabled, nb_actions := 0, 0
+		for _, r := range rules {
+			if !r.IsEnabled { nb_disabled++ }
+			end := []string{ r.CompartmentPath }
+			if all_regions { end = append(end, r.Region) }
+			end = append(end, r.Id)
+			if len(r.Actions) == 0 { table.AddRow(append([]string{ r.Name, fmt.Sprintf("%t", r.IsEnabled), r.LifecycleState, r.Condition, "", "", "" }, end...)...) }
+			for _, a := range r.Actions {
+				nb_actions++
+				row := []string{ r.Name, fmt.Sprintf("%t", r.IsEnabled), r.LifecycleState, r.Condition, a.Type, a.TargetId, fmt.Sprintf("%t", a.IsEnabled) }
+				table.AddRow(append(row, end...)...)
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d rules (%d disabled), %d actions\n", len(rules), nb_disabled, nb_actions)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
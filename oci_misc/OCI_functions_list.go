@@ -0,0 +1,295 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the applications and functions (Oracle Functions / Fn) in all compartments of a OCI tenant
+// using OCI Go SDK
+// For each function, it displays memory, timeout, image, last invocation and invoke endpoint
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the last invocation is found from the FunctionInvocationCount metric of the Monitoring service
+//       (namespace oci_faas, resolution 1 hour), so it is only found if it is less than --days days old
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/functions"
+	"github.com/oracle/oci-go-sdk/monitoring"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type function_json struct {
+	Name             string `json:"name"`
+	Id               string `json:"id"`
+	LifecycleState   string `json:"lifecycle_state"`
+	MemoryInMBs      int64  `json:"memory_in_mbs"`
+	TimeoutInSeconds int    `json:"timeout_in_seconds"`
+	Image            string `json:"image"`
+	LastInvocation   string `json:"last_invocation"`     // empty if no invocation found in the last --days days
+	InvokeEndpoint   string `json:"invoke_endpoint"`
+}
+
+type application_json struct {
+	Name            string          `json:"name"`
+	Id              string          `json:"id"`
+	LifecycleState  string          `json:"lifecycle_state"`
+	Functions       []function_json `json:"functions"`
+	Region          string          `json:"region"`
+	CompartmentId   string          `json:"compartment_id"`
+	CompartmentPath string          `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each function of each application.")
+    fmt.Println("    If --days N is provided, the last invocation of the functions is searched in the last N days (default 30, max 90).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the applications of a compartment
+func list_applications(client functions.FunctionsManagementClient, cpt_id string) ([]functions.ApplicationSummary, error) {
+	applications := make([]functions.ApplicationSummary, 0)
+	request := functions.ListApplicationsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListApplications(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, app := range response.Items {
+			if app.LifecycleState != functions.ApplicationLifecycleStateDeleted { applications = append(applications, app) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return applications, err
+}
+
+// list the functions of an application
+func list_functions(client functions.FunctionsManagementClient, app_id string) ([]functions.FunctionSummary, error) {
+	fns := make([]functions.FunctionSummary, 0)
+	request := functions.ListFunctionsRequest{ ApplicationId : common.String(app_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListFunctions(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, fn := range response.Items {
+			if fn.LifecycleState != functions.FunctionLifecycleStateDeleted { fns = append(fns, fn) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return fns, err
+}
+
+// get the last invocation (hour) of the functions of a compartment in the last days (map function OCID -> time)
+func last_invocations(client monitoring.MonitoringClient, cpt_id string, days int) (map[string]time.Time, error) {
+	end := time.Now().UTC()
+	details := monitoring.SummarizeMetricsDataDetails{
+		Namespace  : common.String("oci_faas"),
+		Query      : common.String("FunctionInvocationCount[1h].sum()"),
+		StartTime  : &common.SDKTime{ Time : end.AddDate(0, 0, -days) },
+		EndTime    : &common.SDKTime{ Time : end },
+		Resolution : common.String("1h"),
+	}
+	request := monitoring.SummarizeMetricsDataRequest{ CompartmentId : common.String(cpt_id), SummarizeMetricsDataDetails : details,
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	response, err := client.SummarizeMetricsData(context.Background(), request)
+	if err != nil { return nil, err }
+
+	last := make(map[string]time.Time)
+	for _, metric := range response.Items {
+		fn_id := metric.Dimensions["resourceId"]
+		for _, dp := range metric.AggregatedDatapoints {
+			if dp.Value == nil || *dp.Value == 0 || dp.Timestamp == nil { continue }
+			if dp.Timestamp.Time.After(last[fn_id]) { last[fn_id] = dp.Timestamp.Time }
+		}
+	}
+	return last, nil
+}
+
+// list the applications and functions of all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, days int, parallelism int) ([]application_json, error) {
+	client, err := functions.NewFunctionsManagementClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	mon_client, err := monitoring.NewMonitoringClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	mon_client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		applications := make([]application_json, 0)
+		apps, err := list_applications(client, cpt_id)
+		if err != nil { return nil, err }
+		if len(apps) == 0 { return applications, nil }
+
+		// functions are in the compartment of their application
+		last, err := last_invocations(mon_client, cpt_id, days)
+		if err != nil { return nil, err }
+		for _, app := range apps {
+			fns, err := list_functions(client, *app.Id)
+			if err != nil { return nil, err }
+			item := application_json{ Name : *app.DisplayName, Id : *app.Id, LifecycleState : string(app.LifecycleState), Functions : make([]function_json, 0),
+				Region : region, CompartmentId : cpt_id }
+			for _, fn := range fns {
+				f := function_json{ Name : *fn.DisplayName, Id : *fn.Id, LifecycleState : string(fn.LifecycleState) }
+				if fn.MemoryInMBs != nil      { f.MemoryInMBs = *fn.MemoryInMBs }
+				if fn.TimeoutInSeconds != nil { f.TimeoutInSeconds = *fn.TimeoutInSeconds }
+				if fn.Image != nil            { f.Image = *fn.Image }
+				if fn.InvokeEndpoint != nil   { f.InvokeEndpoint = *fn.InvokeEndpoint }
+				if t, found := last[*fn.Id]; found { f.LastInvocation = t.Format("2006-01-02T15:04:05Z") }
+				item.Functions = append(item.Functions, f)
+			}
+			sort.Slice(item.Functions, func(i, j int) bool { return item.Functions[i].Name < item.Functions[j].Name })
+			applications = append(applications, item)
+		}
+		return applications, nil
+	})
+	applications := make([]application_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		applications = append(applications, r.Value.([]application_json)...)
+	}
+	return applications, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list functions in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list functions in all subscribed regions")
+	days            := flag.Int("days", 30, "search the last invocation in the last N days")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if *days < 1 || *days > 90 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the applications in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("functions", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *days, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	applications := make([]application_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, app := range r.Value.([]application_json) {
+			app.CompartmentPath = paths[app.CompartmentId]
+			applications = append(applications, app)
+		}
+	}
+	sort.SliceStable(applications, func(i, j int) bool {
+		if applications[i].CompartmentPath != applications[j].CompartmentPath { return applications[i].CompartmentPath < applications[j].CompartmentPath }
+		return applications[i].Name < applications[j].Name
+	})
+
+	// Display the results (a row per function)
+	if format == "json" {
+		output, err := json.MarshalIndent(applications, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "application", "function", "state", "memory_mb", "timeout_s", "image", "last_invocation", "invoke_endpoint", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_functions, nb_unused := 0, 0
+		for _, app := range applications {
+			end := []string{ app.CompartmentPath }
+			if all_regions { end = append(end, app.Region) }
+			if len(app.Functions) == 0 { table.AddRow(append([]string{ app.Name, "", app.LifecycleState, "", "", "", "", "" }, append(end, app.Id)...)...) }
+			for _, fn := range app.Functions {
+				nb_functions++
+				last := fn.LastInvocation
+				if last == "" { last, nb_unused = "-", nb_unused+1 }
+				row := []string{ app.Name, fn.Name, fn.LifecycleState, fmt.Sprintf("%d", fn.MemoryInMBs), fmt.Sprintf("%d", fn.TimeoutInSeconds), fn.Image, last, fn.InvokeEndpoint }
+				table.AddRow(append(row, append(end, fn.Id)...)...)
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d applications, %d functions (%d not invoked in the last %d days)\n", len(applications), nb_functions, nb_unused, *days)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
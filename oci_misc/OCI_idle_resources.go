@@ -0,0 +1,499 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script looks for likely-idle resources in all compartments of a OCI tenant using OCI Go SDK, combining
+// the state of the resources and the metrics of the Monitoring service:
+// - stopped compute instances (their boot volume is still billed)
+// - running compute instances with a maximum CPU utilization below N percent over the last days
+// - load balancers without backends, or without accepted connections over the last days
+// - block volumes and boot volumes not attached to an instance
+// - reserved public IPs not assigned
+// - OKE node pools without nodes
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       CPU metrics are the ones of the Compute Instance Monitoring plugin of Oracle Cloud Agent (namespace
+//       oci_computeagent): running instances without this plugin are never reported as idle
+//       only load balancers are checked (not network load balancers)
+//       this is a report only: see OCI_volumes_orphaned.go to delete the unattached volumes
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/containerengine"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/loadbalancer"
+	"github.com/oracle/oci-go-sdk/monitoring"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// likely-idle resource
+type idle_json struct {
+	Type            string `json:"type"`
+	Name            string `json:"name"`
+	Id              string `json:"id"`
+	Reason          string `json:"reason"`
+	Details         string `json:"details"`
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+}
+
+// clients used to look for idle resources in a region
+type region_clients struct {
+	compute    core.ComputeClient
+	storage    core.BlockstorageClient
+	network    core.VirtualNetworkClient
+	lb         loadbalancer.LoadBalancerClient
+	oke        containerengine.ContainerEngineClient
+	monitoring monitoring.MonitoringClient
+	metadata   common.RequestMetadata
+	ads        []string
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each likely-idle resource, with the reason: stopped instance, idle instance (low CPU),")
+    fmt.Println("    load balancer without backends or without connections, unattached volume, unassigned reserved public IP,")
+    fmt.Println("    empty OKE node pool.")
+    fmt.Println("    If --days N is provided, the metrics are checked over the last N days (default 7, max 90).")
+    fmt.Println("    If --cpu PERCENT is provided, running instances whose maximum CPU utilization is below PERCENT are reported")
+    fmt.Println("    as idle (default 5).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If --webhook URL is provided, a summary is posted to this Slack-compatible webhook when at least N likely-idle")
+    fmt.Println("    resources are found (--webhook-threshold N, default 1). Default URL: OCI_WEBHOOK_URL environment variable.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// create the clients for a region
+func new_region_clients(config common.ConfigurationProvider, region string) (*region_clients, error) {
+	var err error
+	c := &region_clients{ metadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	if c.compute, err = core.NewComputeClientWithConfigurationProvider(config); err != nil { return nil, err }
+	if c.storage, err = core.NewBlockstorageClientWithConfigurationProvider(config); err != nil { return nil, err }
+	if c.network, err = core.NewVirtualNetworkClientWithConfigurationProvider(config); err != nil { return nil, err }
+	if c.lb, err = loadbalancer.NewLoadBalancerClientWithConfigurationProvider(config); err != nil { return nil, err }
+	if c.oke, err = containerengine.NewContainerEngineClientWithConfigurationProvider(config); err != nil { return nil, err }
+	if c.monitoring, err = monitoring.NewMonitoringClientWithConfigurationProvider(config); err != nil { return nil, err }
+	c.compute.SetRegion(region)
+	c.storage.SetRegion(region)
+	c.network.SetRegion(region)
+	c.lb.SetRegion(region)
+	c.oke.SetRegion(region)
+	c.monitoring.SetRegion(region)
+	if c.ads, err = ocihelpers.ListAvailabilityDomains(config, region); err != nil { return nil, err }
+	return c, nil
+}
+
+// run a query of the monitoring service (1 day intervals) on the resources of a compartment
+// and return the maximum of the aggregated datapoints per resource (resources without datapoints are absent)
+func query_max(c *region_clients, cpt_id string, namespace string, query string, days int) (map[string]float64, error) {
+	end := time.Now().UTC()
+	details := monitoring.SummarizeMetricsDataDetails{
+		Namespace  : common.String(namespace),
+		Query      : common.String(query),
+		StartTime  : &common.SDKTime{ Time : end.AddDate(0, 0, -days) },
+		EndTime    : &common.SDKTime{ Time : end },
+		Resolution : common.String("1d"),
+	}
+	response, err := c.monitoring.SummarizeMetricsData(context.Background(), monitoring.SummarizeMetricsDataRequest{ CompartmentId : common.String(cpt_id), SummarizeMetricsDataDetails : details, RequestMetadata : c.metadata })
+	if err != nil { return nil, err }
+
+	values := make(map[string]float64)
+	for _, metric := range response.Items {
+		id := metric.Dimensions["resourceId"]
+		for _, dp := range metric.AggregatedDatapoints {
+			if dp.Value == nil { continue }
+			if max, found := values[id]; !found || *dp.Value > max { values[id] = *dp.Value }
+		}
+	}
+	return values, nil
+}
+
+// stopped instances (with the size of their boot volume) and idle running instances of a compartment
+func idle_instances(c *region_clients, cpt_id string, days int, cpu float64) ([]idle_json, error) {
+	instances := make([]core.Instance, 0)
+	request := core.ListInstancesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : c.metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := c.compute.ListInstances(context.Background(), request)
+		if err != nil { return nil, err }
+		instances = append(instances, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	if err != nil || len(instances) == 0 { return nil, err }
+
+	cpu_max, err := query_max(c, cpt_id, "oci_computeagent", "CpuUtilization[1d].max()", days)
+	if err != nil { return nil, err }
+
+	items := make([]idle_json, 0)
+	for _, i := range instances {
+		item := idle_json{ Type : "instance", Name : *i.DisplayName, Id : *i.Id, CompartmentId : cpt_id }
+		switch i.LifecycleState {
+		case core.InstanceLifecycleStateStopped:
+			item.Reason, item.Details = "stopped", "no boot volume"
+			attachments, err := c.compute.ListBootVolumeAttachments(context.Background(), core.ListBootVolumeAttachmentsRequest{ AvailabilityDomain : i.AvailabilityDomain, CompartmentId : common.String(cpt_id), InstanceId : i.Id, RequestMetadata : c.metadata })
+			if err != nil { return nil, err }
+			for _, a := range attachments.Items {
+				if a.LifecycleState != core.BootVolumeAttachmentLifecycleStateAttached { continue }
+				response, err := c.storage.GetBootVolume(context.Background(), core.GetBootVolumeRequest{ BootVolumeId : a.BootVolumeId, RequestMetadata : c.metadata })
+				if err != nil { return nil, err }
+				item.Details = fmt.Sprintf("boot volume of %d GB still billed", *response.SizeInGBs)
+			}
+		case core.InstanceLifecycleStateRunning:
+			max, found := cpu_max[*i.Id]
+			if !found || max >= cpu { continue }
+			item.Reason, item.Details = "idle", fmt.Sprintf("max CPU %.1f%% over %d days", max, days)
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// load balancers of a compartment without backends, or without accepted connections
+func idle_load_balancers(c *region_clients, cpt_id string, days int) ([]idle_json, error) {
+	lbs := make([]loadbalancer.LoadBalancer, 0)
+	request := loadbalancer.ListLoadBalancersRequest{ CompartmentId : common.String(cpt_id), LifecycleState : loadbalancer.LoadBalancerLifecycleStateActive, RequestMetadata : c.metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := c.lb.ListLoadBalancers(context.Background(), request)
+		if err != nil { return nil, err }
+		lbs = append(lbs, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	if err != nil || len(lbs) == 0 { return nil, err }
+
+	connections, err := query_max(c, cpt_id, "oci_lbaas", "AcceptedConnections[1d].sum()", days)
+	if err != nil { return nil, err }
+
+	items := make([]idle_json, 0)
+	for _, lb := range lbs {
+		item := idle_json{ Type : "load_balancer", Name : *lb.DisplayName, Id : *lb.Id, CompartmentId : cpt_id }
+		nb_backends := 0
+		for _, bs := range lb.BackendSets { nb_backends += len(bs.Backends) }
+		max, found := connections[*lb.Id]
+		switch {
+		case nb_backends == 0:
+			item.Reason, item.Details = "no backends", fmt.Sprintf("%d backend sets without backends", len(lb.BackendSets))
+		case found && max == 0:
+			item.Reason, item.Details = "no connections", fmt.Sprintf("no accepted connections over %d days", days)
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// available block volumes and boot volumes of a compartment not attached to an instance
+// (the attachments are in the compartment of the instance, so all the attachments of the region are needed)
+func unattached_volumes(c *region_clients, cpt_id string, attached map[string]bool) ([]idle_json, error) {
+	items := make([]idle_json, 0)
+	volumes_request := core.ListVolumesRequest{ CompartmentId : common.String(cpt_id), LifecycleState : core.VolumeLifecycleStateAvailable, RequestMetadata : c.metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		volumes_request.Page = page
+		response, err := c.storage.ListVolumes(context.Background(), volumes_request)
+		if err != nil { return nil, err }
+		for _, v := range response.Items {
+			if attached[*v.Id] { continue }
+			items = append(items, idle_json{ Type : "block_volume", Name : *v.DisplayName, Id : *v.Id, Reason : "unattached", Details : fmt.Sprintf("%d GB", *v.SizeInGBs), CompartmentId : cpt_id })
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	for _, ad := range c.ads {
+		boot_volumes_request := core.ListBootVolumesRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : c.metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			boot_volumes_request.Page = page
+			response, err := c.storage.ListBootVolumes(context.Background(), boot_volumes_request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				if v.LifecycleState != core.BootVolumeLifecycleStateAvailable || attached[*v.Id] { continue }
+				items = append(items, idle_json{ Type : "boot_volume", Name : *v.DisplayName, Id : *v.Id, Reason : "unattached", Details : fmt.Sprintf("%d GB", *v.SizeInGBs), CompartmentId : cpt_id })
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return items, nil
+}
+
+// add the block volumes and boot volumes attached to the instances of a compartment
+func list_attachments(c *region_clients, cpt_id string, attached map[string]bool) error {
+	attachments_request := core.ListVolumeAttachmentsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : c.metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		attachments_request.Page = page
+		response, err := c.compute.ListVolumeAttachments(context.Background(), attachments_request)
+		if err != nil { return nil, err }
+		for _, a := range response.Items {
+			if a.GetLifecycleState() != core.VolumeAttachmentLifecycleStateDetached { attached[*a.GetVolumeId()] = true }
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return err }
+
+	for _, ad := range c.ads {
+		boot_attachments_request := core.ListBootVolumeAttachmentsRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : c.metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			boot_attachments_request.Page = page
+			response, err := c.compute.ListBootVolumeAttachments(context.Background(), boot_attachments_request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				if a.LifecycleState != core.BootVolumeAttachmentLifecycleStateDetached { attached[*a.BootVolumeId] = true }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return err }
+	}
+	return nil
+}
+
+// reserved public IPs of a compartment not assigned to a private IP
+func unassigned_public_ips(c *region_clients, cpt_id string) ([]idle_json, error) {
+	items := make([]idle_json, 0)
+	request := core.ListPublicIpsRequest{ Scope : core.ListPublicIpsScopeRegion, Lifetime : core.ListPublicIpsLifetimeReserved, CompartmentId : common.String(cpt_id), RequestMetadata : c.metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := c.network.ListPublicIps(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, p := range response.Items {
+			if p.LifecycleState != core.PublicIpLifecycleStateAvailable || p.AssignedEntityId != nil { continue }
+			items = append(items, idle_json{ Type : "public_ip", Name : *p.DisplayName, Id : *p.Id, Reason : "unassigned", Details : *p.IpAddress, CompartmentId : cpt_id })
+		}
+		return response.OpcNextPage, nil
+	})
+	return items, err
+}
+
+// OKE node pools of a compartment without nodes
+func empty_node_pools(c *region_clients, cpt_id string) ([]idle_json, error) {
+	clusters := make(map[string]string)
+	clusters_request := containerengine.ListClustersRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : c.metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		clusters_request.Page = page
+		response, err := c.oke.ListClusters(context.Background(), clusters_request)
+		if err != nil { return nil, err }
+		for _, cl := range response.Items { clusters[*cl.Id] = *cl.Name }
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	items := make([]idle_json, 0)
+	node_pools_request := containerengine.ListNodePoolsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : c.metadata }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		node_pools_request.Page = page
+		response, err := c.oke.ListNodePools(context.Background(), node_pools_request)
+		if err != nil { return nil, err }
+		for _, np := range response.Items {
+			nodes := 0
+			switch {
+			case np.NodeConfigDetails != nil && np.NodeConfigDetails.Size != nil: nodes = *np.NodeConfigDetails.Size
+			case np.QuantityPerSubnet != nil:                                     nodes = *np.QuantityPerSubnet * len(np.SubnetIds)
+			}
+			if nodes > 0 { continue }
+			cluster, found := clusters[*np.ClusterId]
+			if !found { cluster = *np.ClusterId }
+			items = append(items, idle_json{ Type : "node_pool", Name : *np.Name, Id : *np.Id, Reason : "no nodes", Details : "cluster " + cluster, CompartmentId : cpt_id })
+		}
+		return response.OpcNextPage, nil
+	})
+	return items, err
+}
+
+// look for likely-idle resources in all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, days int, cpu float64, parallelism int) ([]idle_json, error) {
+	c, err := new_region_clients(config, region)
+	if err != nil { return nil, err }
+
+	// volumes attached in all compartments (the attachments are in the compartment of the instance)
+	attachments := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_attached := make(map[string]bool)
+		err := list_attachments(c, cpt_id, cpt_attached)
+		return cpt_attached, err
+	})
+	attached := make(map[string]bool)
+	for _, r := range attachments {
+		if r.Err != nil { return nil, r.Err }
+		for id := range r.Value.(map[string]bool) { attached[id] = true }
+	}
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		idle := make([]idle_json, 0)
+		checks := []func() ([]idle_json, error){
+			func() ([]idle_json, error) { return idle_instances(c, cpt_id, days, cpu) },
+			func() ([]idle_json, error) { return idle_load_balancers(c, cpt_id, days) },
+			func() ([]idle_json, error) { return unattached_volumes(c, cpt_id, attached) },
+			func() ([]idle_json, error) { return unassigned_public_ips(c, cpt_id) },
+			func() ([]idle_json, error) { return empty_node_pools(c, cpt_id) },
+		}
+		for _, check := range checks {
+			items, err := check()
+			if err != nil { return nil, err }
+			for _, item := range items {
+				item.Region = region
+				idle = append(idle, item)
+			}
+		}
+		return idle, nil
+	})
+	idle := make([]idle_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		idle = append(idle, r.Value.([]idle_json)...)
+	}
+	return idle, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	days              := flag.Int("days", 7, "check the metrics over the last N days")
+	cpu               := flag.Float64("cpu", 5, "maximum CPU utilization (percent) below which running instances are idle")
+	webhook           := flag.String("webhook", ocihelpers.WebhookURL(), "post a summary to this Slack-compatible webhook")
+	webhook_threshold := flag.Int("webhook-threshold", 1, "minimum number of likely-idle resources to post a summary")
+	json_output       := flag.Bool("json", false, "display output in JSON format")
+	csv_output        := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output   := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism       := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file       := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if *days < 1 || *days > 90 || *cpu <= 0 || *cpu > 100 || *webhook_threshold < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Look for idle resources in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	// the compartments are processed twice in each region (attachments, then idle resources)
+	ocihelpers.StartProgress("idle resources", len(regions), 2 * len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *days, *cpu, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	items := make([]idle_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, item := range r.Value.([]idle_json) {
+			item.CompartmentPath = paths[item.CompartmentId]
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Type != items[j].Type { return items[i].Type < items[j].Type }
+		if items[i].CompartmentPath != items[j].CompartmentPath { return items[i].CompartmentPath < items[j].CompartmentPath }
+		return items[i].Name < items[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(items, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "type", "name", "reason", "details", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_per_type := make(map[string]int)
+		for _, item := range items {
+			nb_per_type[item.Type]++
+			row := []string{ item.Type, item.Name, item.Reason, item.Details, item.CompartmentPath }
+			if all_regions { row = append(row, item.Region) }
+			table.AddRow(append(row, item.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d likely-idle resources: %d instances, %d load balancers, %d block volumes, %d boot volumes, %d public IPs, %d node pools\n", len(items),
+				nb_per_type["instance"], nb_per_type["load_balancer"], nb_per_type["block_volume"], nb_per_type["boot_volume"], nb_per_type["public_ip"], nb_per_type["node_pool"])
+		}
+	}
+
+	// Post a summary to the webhook if enough likely-idle resources are found
+	lines := make([]string, 0, len(items))
+	for _, item := range items { lines = append(lines, fmt.Sprintf("%s %s (%s, %s): %s, %s", item.Type, item.Name, item.Region, item.CompartmentPath, item.Reason, item.Details)) }
+	_, err = ocihelpers.Notify(*webhook, *webhook_threshold, fmt.Sprintf("OCI idle resources report: %d likely-idle resources", len(items)), lines)
+	ocihelpers.FatalIfError(err)
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,298 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script compares the service limits of a OCI tenant with the current usage using OCI Go SDK, and displays
+// the limits whose usage is above a percentage of the limit (to request limit increases before being blocked)
+// Note: OCI tenant given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/limits"
+)
+
+// -- constants
+
+// number of limits whose usage is requested at the same time in a region
+const usage_parallelism = 8
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type limit_json struct {
+	Region             string  `json:"region"`
+	Service            string  `json:"service"`
+	Name               string  `json:"name"`
+	Description        string  `json:"description"`
+	ScopeType          string  `json:"scope_type"`           // GLOBAL, REGION or AD
+	AvailabilityDomain string  `json:"availability_domain"`  // for AD limits
+	Limit              int64   `json:"limit"`
+	Used               int64   `json:"used"`
+	Available          int64   `json:"available"`
+	Percent            float64 `json:"percent"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    The limits whose usage is above 80% of the limit are displayed.")
+    fmt.Println("    If --threshold PERCENT is provided, this percentage is used instead of 80%.")
+    fmt.Println("    If --all-limits is provided, all the limits with a non-zero value are displayed, whatever their usage.")
+    fmt.Println("    If --service NAME is provided, only the limits of this service are checked (ex: compute, vcn, database).")
+    fmt.Println("    If -a or --all-regions is provided, the limits are checked in all subscribed regions.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the names of the services having limits
+func list_services(client limits.LimitsClient, tenancy_ocid string) ([]string, error) {
+	services := make([]string, 0)
+	request := limits.ListServicesRequest{ CompartmentId : common.String(tenancy_ocid), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListServices(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, s := range response.Items { services = append(services, *s.Name) }
+		return response.OpcNextPage, nil
+	})
+	return services, err
+}
+
+// list the non-zero limits of a service whose usage can be requested (without usage)
+func list_limits(client limits.LimitsClient, tenancy_ocid string, service string, region string, include_global bool) ([]limit_json, error) {
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	// limit definitions: description and availability of the usage
+	definitions := make(map[string]limits.LimitDefinitionSummary)
+	definitions_request := limits.ListLimitDefinitionsRequest{ CompartmentId : common.String(tenancy_ocid), ServiceName : common.String(service), RequestMetadata : metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		definitions_request.Page = page
+		response, err := client.ListLimitDefinitions(context.Background(), definitions_request)
+		if err != nil { return nil, err }
+		for _, d := range response.Items { definitions[*d.Name] = d }
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	// limit values (one per AD for AD limits)
+	items := make([]limit_json, 0)
+	values_request := limits.ListLimitValuesRequest{ CompartmentId : common.String(tenancy_ocid), ServiceName : common.String(service), RequestMetadata : metadata }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		values_request.Page = page
+		response, err := client.ListLimitValues(context.Background(), values_request)
+		if err != nil { return nil, err }
+		for _, v := range response.Items {
+			if v.Value == nil || *v.Value == 0 { continue }
+			if v.ScopeType == limits.LimitValueSummaryScopeTypeGlobal && !include_global { continue }
+			definition, found := definitions[*v.Name]
+			if !found || definition.IsResourceAvailabilitySupported == nil || !*definition.IsResourceAvailabilitySupported { continue }
+			item := limit_json{ Region : region, Service : service, Name : *v.Name, ScopeType : string(v.ScopeType), Limit : *v.Value }
+			if definition.Description != nil { item.Description = *definition.Description }
+			if v.AvailabilityDomain != nil { item.AvailabilityDomain = *v.AvailabilityDomain }
+			items = append(items, item)
+		}
+		return response.OpcNextPage, nil
+	})
+	return items, err
+}
+
+// get the usage of a limit (limits whose usage cannot be found are ignored)
+func get_usage(client limits.LimitsClient, tenancy_ocid string, item *limit_json) (bool, error) {
+	request := limits.GetResourceAvailabilityRequest{
+		ServiceName     : common.String(item.Service),
+		LimitName       : common.String(item.Name),
+		CompartmentId   : common.String(tenancy_ocid),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	if item.AvailabilityDomain != "" { request.AvailabilityDomain = common.String(item.AvailabilityDomain) }
+	response, err := client.GetResourceAvailability(context.Background(), request)
+	if failure, ok := common.IsServiceError(err); ok {
+		if code := failure.GetHTTPStatusCode(); code == http.StatusBadRequest || code == http.StatusNotFound { return false, nil }
+	}
+	if err != nil { return false, err }
+	if response.Used != nil { item.Used = *response.Used }
+	if response.Available != nil { item.Available = *response.Available }
+	item.Percent = 100 * float64(item.Used) / float64(item.Limit)
+	return true, nil
+}
+
+// check the limits of a region (GLOBAL limits are only checked if include_global is true, so that they are
+// displayed only once when all regions are checked)
+func list_region(config common.ConfigurationProvider, tenancy_ocid string, region string, service_name string, include_global bool) ([]limit_json, error) {
+	client, err := limits.NewLimitsClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	services := []string{ service_name }
+	if service_name == "" {
+		services, err = list_services(client, tenancy_ocid)
+		if err != nil { return nil, err }
+	}
+	items := make([]limit_json, 0)
+	for _, service := range services {
+		service_items, err := list_limits(client, tenancy_ocid, service, region, include_global)
+		if err != nil { return nil, err }
+		items = append(items, service_items...)
+	}
+
+	// get the usage of the limits concurrently
+	found := make([]bool, len(items))
+	errors := make([]error, len(items))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, usage_parallelism)
+	for i := range items {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			found[i], errors[i] = get_usage(client, tenancy_ocid, &items[i])
+		}(i)
+	}
+	wg.Wait()
+	results := make([]limit_json, 0, len(items))
+	for i, item := range items {
+		if errors[i] != nil { return nil, errors[i] }
+		if found[i] { results = append(results, item) }
+	}
+	return results, nil
+}
+
+// short name of an availability domain (ex: AD-1 for xxxx:EU-FRANKFURT-1-AD-1)
+func short_ad(ad string) string {
+	if i := strings.LastIndex(ad, "-AD-"); i >= 0 { return ad[i+1:] }
+	return ad
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "check limits in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "check limits in all subscribed regions")
+	threshold       := flag.Float64("threshold", 80, "usage threshold (percentage of the limit)")
+	all_limits      := flag.Bool("all-limits", false, "display all the limits whatever their usage")
+	service_name    := flag.String("service", "", "only check the limits of this service")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *threshold < 0 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	tenancy_ocid, err := config.TenancyOCID()
+	ocihelpers.FatalIfError(err)
+
+	// Check the limits in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("limits", len(regions), 0)
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tenancy_ocid, region, *service_name, region == regions[0])
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	nb_checked := 0
+	items := make([]limit_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, item := range r.Value.([]limit_json) {
+			nb_checked++
+			if !*all_limits && item.Percent < *threshold { continue }
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Percent > items[j].Percent })
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(items, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "service", "limit_name", "scope" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "limit", "used", "available", "percent")
+		for _, item := range items {
+			scope := item.ScopeType
+			if item.AvailabilityDomain != "" { scope = short_ad(item.AvailabilityDomain) }
+			row := []string{ item.Service, item.Name, scope }
+			if all_regions { row = append(row, item.Region) }
+			table.AddRow(append(row, fmt.Sprintf("%d", item.Limit), fmt.Sprintf("%d", item.Used), fmt.Sprintf("%d", item.Available), fmt.Sprintf("%.1f", item.Percent))...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			if *all_limits {
+				fmt.Printf ("%d limits checked\n", nb_checked)
+			} else {
+				fmt.Printf ("%d limits checked, %d with usage above %g%% of the limit\n", nb_checked, len(items), *threshold)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,434 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the log groups and logs of the Logging service in all compartments of a OCI tenant using
+// OCI Go SDK, with their type (SERVICE, CUSTOM or AUDIT), source and retention
+// Optionally, it checks whether the key resources (subnets, buckets and load balancers) have logging enabled
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       audit logs are always enabled for the whole tenant: a single AUDIT row is displayed with the
+//       retention period configured for the tenant
+//       a resource is considered logged if an enabled service log exists for it (flow logs for subnets,
+//       read/write logs for buckets, access/error logs for load balancers)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/audit"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/loadbalancer"
+	"github.com/oracle/oci-go-sdk/logging"
+	"github.com/oracle/oci-go-sdk/objectstorage"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type log_json struct {
+	LogGroup        string `json:"log_group"`
+	LogGroupId      string `json:"log_group_id"`
+	Name            string `json:"name"`
+	Id              string `json:"id"`
+	Type            string `json:"type"`                 // SERVICE, CUSTOM or AUDIT
+	IsEnabled       bool   `json:"is_enabled"`
+	LifecycleState  string `json:"lifecycle_state"`
+	Service         string `json:"service"`              // service logs only
+	Resource        string `json:"resource"`             // service logs only
+	Category        string `json:"category"`             // service logs only
+	RetentionDays   int    `json:"retention_days"`
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+}
+
+type coverage_json struct {
+	ResourceType    string   `json:"resource_type"`      // subnet, bucket or load_balancer
+	Name            string   `json:"name"`
+	Id              string   `json:"id"`
+	Logs            []string `json:"logs"`               // categories of the enabled service logs
+	Region          string   `json:"region"`
+	CompartmentId   string   `json:"compartment_id"`
+	CompartmentPath string   `json:"compartment_path"`
+}
+
+type region_result struct {
+	Logs     []log_json
+	Coverage []coverage_json
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each log (log groups without logs are displayed with an empty log).")
+    fmt.Println("    If --coverage is provided, the subnets, buckets and load balancers are listed instead, with the categories")
+    fmt.Println("    of their enabled service logs (none if logging is not enabled).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the log groups of a compartment and their logs
+func list_logs(client logging.LoggingManagementClient, cpt_id string, region string) ([]log_json, error) {
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	groups := make([]logging.LogGroupSummary, 0)
+	request := logging.ListLogGroupsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListLogGroups(context.Background(), request)
+		if err != nil { return nil, err }
+		groups = append(groups, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	logs := make([]log_json, 0)
+	for _, g := range groups {
+		nb_logs := 0
+		logs_request := logging.ListLogsRequest{ LogGroupId : g.Id, RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			logs_request.Page = page
+			response, err := client.ListLogs(context.Background(), logs_request)
+			if err != nil { return nil, err }
+			for _, l := range response.Items {
+				if l.LifecycleState == logging.LogLifecycleStateDeleting { continue }
+				item := log_json{ LogGroup : *g.DisplayName, LogGroupId : *g.Id, Name : *l.DisplayName, Id : *l.Id, Type : string(l.LogType),
+					LifecycleState : string(l.LifecycleState), Region : region, CompartmentId : cpt_id }
+				if l.IsEnabled != nil         { item.IsEnabled = *l.IsEnabled }
+				if l.RetentionDuration != nil { item.RetentionDays = *l.RetentionDuration }
+				if l.Configuration != nil {
+					if s, ok := l.Configuration.Source.(logging.OciService); ok {
+						item.Service, item.Resource, item.Category = *s.Service, *s.Resource, *s.Category
+					}
+				}
+				logs = append(logs, item)
+				nb_logs++
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		if nb_logs == 0 {
+			logs = append(logs, log_json{ LogGroup : *g.DisplayName, LogGroupId : *g.Id, Region : region, CompartmentId : cpt_id })
+		}
+	}
+	return logs, nil
+}
+
+// list the subnets, buckets and load balancers of a compartment
+func list_resources(network_client core.VirtualNetworkClient, os_client objectstorage.ObjectStorageClient, lb_client loadbalancer.LoadBalancerClient,
+	namespace string, cpt_id string, region string) ([]coverage_json, error) {
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	resources := make([]coverage_json, 0)
+
+	subnets_request := core.ListSubnetsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		subnets_request.Page = page
+		response, err := network_client.ListSubnets(context.Background(), subnets_request)
+		if err != nil { return nil, err }
+		for _, s := range response.Items {
+			if s.LifecycleState != core.SubnetLifecycleStateAvailable { continue }
+			resources = append(resources, coverage_json{ ResourceType : "subnet", Name : *s.DisplayName, Id : *s.Id, Region : region, CompartmentId : cpt_id })
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	buckets_request := objectstorage.ListBucketsRequest{ NamespaceName : common.String(namespace), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		buckets_request.Page = page
+		response, err := os_client.ListBuckets(context.Background(), buckets_request)
+		if err != nil { return nil, err }
+		for _, b := range response.Items {
+			resources = append(resources, coverage_json{ ResourceType : "bucket", Name : *b.Name, Id : *b.Name, Region : region, CompartmentId : cpt_id })
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	lbs_request := loadbalancer.ListLoadBalancersRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		lbs_request.Page = page
+		response, err := lb_client.ListLoadBalancers(context.Background(), lbs_request)
+		if err != nil { return nil, err }
+		for _, lb := range response.Items {
+			if lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleted { continue }
+			resources = append(resources, coverage_json{ ResourceType : "load_balancer", Name : *lb.DisplayName, Id : *lb.Id, Region : region, CompartmentId : cpt_id })
+		}
+		return response.OpcNextPage, nil
+	})
+	return resources, err
+}
+
+// service of the logs of a resource type
+func log_service(resource_type string) string {
+	switch resource_type {
+	case "subnet": return "flowlogs"
+	case "bucket": return "objectstorage"
+	}
+	return "loadbalancer"
+}
+
+// list the resources of all active compartments of a region, with the categories of their enabled service logs
+// (at most parallelism compartments processed at the same time)
+func list_coverage(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, logs []log_json, parallelism int) ([]coverage_json, error) {
+	network_client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	network_client.SetRegion(region)
+	os_client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	os_client.SetRegion(region)
+	lb_client, err := loadbalancer.NewLoadBalancerClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	lb_client.SetRegion(region)
+
+	response, err := os_client.GetNamespace(context.Background(), objectstorage.GetNamespaceRequest{ RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+	if err != nil { return nil, err }
+	namespace := *response.Value
+
+	// categories of the enabled service logs, by service and resource
+	categories := make(map[string][]string)
+	for _, l := range logs {
+		if l.Type != string(logging.LogSummaryLogTypeService) || !l.IsEnabled { continue }
+		key := l.Service + "/" + l.Resource
+		categories[key] = append(categories[key], l.Category)
+	}
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return list_resources(network_client, os_client, lb_client, namespace, cpt_id, region)
+	})
+	coverage := make([]coverage_json, 0)
+	for _, result := range results {
+		if result.Err != nil { return nil, result.Err }
+		for _, r := range result.Value.([]coverage_json) {
+			r.Logs = categories[log_service(r.ResourceType) + "/" + r.Id]
+			if r.Logs == nil { r.Logs = make([]string, 0) }
+			sort.Strings(r.Logs)
+			coverage = append(coverage, r)
+		}
+	}
+	return coverage, nil
+}
+
+// list the logs of all active compartments of a region (and the logging coverage of the resources if requested)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, coverage bool, parallelism int) (region_result, error) {
+	client, err := logging.NewLoggingManagementClientWithConfigurationProvider(config)
+	if err != nil { return region_result{}, err }
+	client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return list_logs(client, cpt_id, region)
+	})
+	result := region_result{ Logs : make([]log_json, 0) }
+	for _, r := range results {
+		if r.Err != nil { return region_result{}, r.Err }
+		result.Logs = append(result.Logs, r.Value.([]log_json)...)
+	}
+	if coverage {
+		result.Coverage, err = list_coverage(config, tree, region, result.Logs, parallelism)
+		if err != nil { return region_result{}, err }
+	}
+	return result, nil
+}
+
+// audit logs of the tenant (always enabled, retention period configured at tenant level)
+func audit_log(config common.ConfigurationProvider, tenancy_id string, region string) (log_json, error) {
+	client, err := audit.NewAuditClientWithConfigurationProvider(config)
+	if err != nil { return log_json{}, err }
+	client.SetRegion(region)
+	request := audit.GetConfigurationRequest{ CompartmentId : common.String(tenancy_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	response, err := client.GetConfiguration(context.Background(), request)
+	if err != nil { return log_json{}, err }
+	item := log_json{ LogGroup : "_Audit", Name : "_Audit", Type : "AUDIT", IsEnabled : true, LifecycleState : string(logging.LogLifecycleStateActive),
+		Region : region, CompartmentId : tenancy_id }
+	if response.RetentionPeriodDays != nil { item.RetentionDays = *response.RetentionPeriodDays }
+	return item, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list logs in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list logs in all subscribed regions")
+	coverage        := flag.Bool("coverage", false, "list the subnets, buckets and load balancers with their enabled service logs")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the logs in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	// the compartments are processed twice with --coverage (logs, then resources covered)
+	nb_passes := 1
+	if *coverage { nb_passes = 2 }
+	ocihelpers.StartProgress("logs", len(regions), nb_passes * len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *coverage, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	logs := make([]log_json, 0)
+	resources := make([]coverage_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		result := r.Value.(region_result)
+		for _, l := range result.Logs {
+			l.CompartmentPath = paths[l.CompartmentId]
+			logs = append(logs, l)
+		}
+		for _, c := range result.Coverage {
+			c.CompartmentPath = paths[c.CompartmentId]
+			resources = append(resources, c)
+		}
+	}
+
+	// Audit logs are tenant-wide: retention period read once in the region of the profile
+	if !*coverage {
+		region, err := config.Region()
+		ocihelpers.FatalIfError(err)
+		item, err := audit_log(config, tree.TenancyOCID, region)
+		ocihelpers.FatalIfError(err)
+		item.CompartmentPath = paths[tree.TenancyOCID]
+		logs = append(logs, item)
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].CompartmentPath != logs[j].CompartmentPath { return logs[i].CompartmentPath < logs[j].CompartmentPath }
+		if logs[i].LogGroup != logs[j].LogGroup { return logs[i].LogGroup < logs[j].LogGroup }
+		return logs[i].Name < logs[j].Name
+	})
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].CompartmentPath != resources[j].CompartmentPath { return resources[i].CompartmentPath < resources[j].CompartmentPath }
+		if resources[i].ResourceType != resources[j].ResourceType { return resources[i].ResourceType < resources[j].ResourceType }
+		return resources[i].Name < resources[j].Name
+	})
+
+	// Display the results
+	if *coverage {
+		if format == "json" {
+			output, err := json.MarshalIndent(resources, "", "  ")
+			ocihelpers.FatalIfError(err)
+			fmt.Println(string(output))
+		} else {
+			table := ocihelpers.Table{ Headers : []string{ "resource_type", "name", "logs", "compartment_path" } }
+			if all_regions { table.Headers = append(table.Headers, "region") }
+			table.Headers = append(table.Headers, "ocid")
+			nb_not_logged := 0
+			for _, c := range resources {
+				enabled_logs := strings.Join(c.Logs, " ")
+				if enabled_logs == "" { enabled_logs = "none"; nb_not_logged++ }
+				row := []string{ c.ResourceType, c.Name, enabled_logs, c.CompartmentPath }
+				if all_regions { row = append(row, c.Region) }
+				table.AddRow(append(row, c.Id)...)
+			}
+			ocihelpers.FatalIfError(table.Print(format))
+			if format == "text" && !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d resources, %d without logging\n", len(resources), nb_not_logged)
+			}
+		}
+	} else {
+		if format == "json" {
+			output, err := json.MarshalIndent(logs, "", "  ")
+			ocihelpers.FatalIfError(err)
+			fmt.Println(string(output))
+		} else {
+			table := ocihelpers.Table{ Headers : []string{ "log_group", "log", "type", "enabled", "service", "resource", "category", "retention_days", "compartment_path" } }
+			if all_regions { table.Headers = append(table.Headers, "region") }
+			table.Headers = append(table.Headers, "ocid")
+			groups := make(map[string]bool)
+			nb_logs := 0
+			for _, l := range logs {
+				groups[l.LogGroupId] = true
+				row := []string{ l.LogGroup, l.Name, l.Type, "", l.Service, l.Resource, l.Category, "", l.CompartmentPath }
+				if l.Name != "" {
+					nb_logs++
+					row[3], row[7] = fmt.Sprintf("%t", l.IsEnabled), fmt.Sprintf("%d", l.RetentionDays)
+				}
+				if all_regions { row = append(row, l.Region) }
+				table.AddRow(append(row, l.Id)...)
+			}
+			ocihelpers.FatalIfError(table.Print(format))
+			if format == "text" && !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d log groups, %d logs\n", len(groups), nb_logs)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
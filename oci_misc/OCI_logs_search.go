@@ -0,0 +1,173 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script searches the logs of the Logging service of OCI (service, custom and audit logs) using the
+// Logging Search API of OCI Go SDK, and streams the matching log entries to stdout as JSON lines (one log
+// entry per line), so that logs can be filtered with grep or jq from the terminal
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the search query uses the query language of the Logging Search service
+//       (ex: search "<compartment_ocid>/<log_group_ocid>" | where level = 'ERROR')
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/loggingsearch"
+)
+
+// -- constants
+const page_size = 500     // log entries returned per request
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    The matching log entries are displayed on stdout as JSON lines (one log entry per line).")
+    fmt.Println("    If --query QUERY is provided, the log entries are filtered by this query. QUERY is either a full query")
+    fmt.Println("    of the Logging Search service (starting with search), or the filters following the search scope, examples:")
+    fmt.Println("       \"where level = 'ERROR'\"")
+    fmt.Println("       \"where data.message = '*timeout*' | sort by datetime desc\"")
+    fmt.Println("       'search \"ocid1.compartment.oc1..xxx/ocid1.loggroup.oc1.xxx\" | where type = \"com.oraclecloud.loadbalancer.access\"'")
+    fmt.Println("    If --compartment OCID is provided, the search scope is this compartment and its sub-compartments (by default,")
+    fmt.Println("    the root compartment). Ignored if QUERY is a full query.")
+    fmt.Println("    If --since DURATION is provided, the log entries of the last DURATION are searched (default: 1h, ex: 30m, 12h).")
+    fmt.Println("    If --start TIMESTAMP and/or --end TIMESTAMP are provided (RFC3339 format, ex: 2026-10-15T08:00:00Z), the log")
+    fmt.Println("    entries of this time range are searched instead (--end defaults to the current time).")
+    fmt.Println("    If --limit N is provided, at most N log entries are displayed.")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --quiet is provided, the summary line (displayed on stderr) is not displayed.")
+    fmt.Println("")
+    fmt.Println("    Example: OCI_logs_search --since 2h --query \"where level = 'ERROR'\" | jq -r .logContent.data.message")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// full search query from the query given by --query
+func full_query(query string, cpt_id string) string {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, "search ") { return query }
+	scope := fmt.Sprintf("search \"%s\"", cpt_id)
+	if query == "" { return scope }
+	return scope + " | " + strings.TrimPrefix(query, "| ")
+}
+
+// time range of the search from --since, --start and --end
+func time_range(since string, start string, end string) (time.Time, time.Time, error) {
+	time_end := time.Now().UTC()
+	if end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil { return time.Time{}, time.Time{}, fmt.Errorf("invalid end timestamp '%s' (RFC3339 format expected)", end) }
+		time_end = t.UTC()
+	}
+	var time_start time.Time
+	if start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil { return time.Time{}, time.Time{}, fmt.Errorf("invalid start timestamp '%s' (RFC3339 format expected)", start) }
+		time_start = t.UTC()
+	} else {
+		duration, err := time.ParseDuration(since)
+		if err != nil || duration <= 0 { return time.Time{}, time.Time{}, fmt.Errorf("invalid duration '%s' (ex: 30m, 12h)", since) }
+		time_start = time_end.Add(-duration)
+	}
+	if !time_start.Before(time_end) { return time.Time{}, time.Time{}, fmt.Errorf("start of the time range must be before its end") }
+	return time_start, time_end, nil
+}
+
+// search the logs and display the matching log entries as JSON lines as soon as they are received
+func search_logs(client loggingsearch.LogSearchClient, details loggingsearch.SearchLogsDetails, limit int) (int, error) {
+	nb_entries := 0
+	request := loggingsearch.SearchLogsRequest{ SearchLogsDetails : details, Limit : common.Int(page_size), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.SearchLogs(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, r := range response.Results {
+			if limit > 0 && nb_entries >= limit { return nil, nil }
+			if r.Data == nil { continue }
+			line, err := json.Marshal(*r.Data)
+			if err != nil { return nil, err }
+			fmt.Println(string(line))
+			nb_entries++
+		}
+		if limit > 0 && nb_entries >= limit { return nil, nil }
+		return response.OpcNextPage, nil
+	})
+	return nb_entries, err
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	query          := flag.String("query", "", "search query or filters of the search query")
+	compartment_id := flag.String("compartment", "", "OCID of the compartment searched (default: root compartment)")
+	since          := flag.String("since", "1h", "search the log entries of the last DURATION")
+	start          := flag.String("start", "", "start of the time range (RFC3339 format)")
+	end            := flag.String("end", "", "end of the time range (RFC3339 format, default: current time)")
+	limit          := flag.Int("limit", 0, "maximum number of log entries displayed (default: no limit)")
+	output_file    := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the summary line")
+	flag.Parse()
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	if *limit < 0 { usage() }
+	time_start, time_end, err := time_range(*since, *start, *end)
+	ocihelpers.FatalIfError(err)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	if *compartment_id == "" {
+		*compartment_id, err = config.TenancyOCID()
+		ocihelpers.FatalIfError(err)
+	}
+
+	// Search the logs in the region of the profile
+	client, err := loggingsearch.NewLogSearchClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	details := loggingsearch.SearchLogsDetails{
+		TimeStart   : &common.SDKTime{ Time : time_start },
+		TimeEnd     : &common.SDKTime{ Time : time_end },
+		SearchQuery : common.String(full_query(*query, *compartment_id)),
+	}
+	nb_entries, err := search_logs(client, details, *limit)
+	ocihelpers.FatalIfError(err)
+	if !ocihelpers.Quiet {
+		fmt.Fprintf (os.Stderr, "%d log entries from %s to %s\n", nb_entries, time_start.Format("2006-01-02T15:04:05Z"), time_end.Format("2006-01-02T15:04:05Z"))
+	}
+}
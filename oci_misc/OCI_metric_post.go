@@ -0,0 +1,178 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script posts custom metrics to the Monitoring service of OCI using OCI Go SDK, so that shell scripts
+// (ex: on compute instances with instance principal authentication) can emit business metrics
+// The value is given by --value or read from stdin (one datapoint per line: VALUE [TIMESTAMP])
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       metrics are posted to the telemetry ingestion endpoint of the region
+//       custom metrics namespaces cannot start with oci_ or oracle_
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/monitoring"
+)
+
+// -- constants
+const ingestion_endpoint = "https://telemetry-ingestion.{region}.{secondLevelDomain}"
+const max_datapoints     = 50     // datapoints posted per request
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] NAMESPACE METRIC [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip NAMESPACE METRIC\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If --value VALUE is provided, a single datapoint is posted with the current time. Otherwise, datapoints are")
+    fmt.Println("    read from stdin, one per line: VALUE [TIMESTAMP] (TIMESTAMP in RFC3339 format, ex: 2026-10-15T08:00:00Z).")
+    fmt.Println("    If --dimensions 'KEY=VALUE,...' is provided, the metric is posted with these dimensions (by default,")
+    fmt.Println("    hostname=HOSTNAME of the machine running the script).")
+    fmt.Println("    If --compartment OCID is provided, the metric is posted in this compartment (by default, root compartment).")
+    fmt.Println("    If --quiet is provided, nothing is displayed when the metrics are posted.")
+    fmt.Println("")
+    fmt.Println("    Example: df --output=pcent / | tail -1 | tr -d ' %' | OCI_metric_post -ip myapp disk_used_pct")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// parse the dimensions given by --dimensions 'KEY=VALUE,...'
+func parse_dimensions(list string) (map[string]string, error) {
+	dimensions := make(map[string]string)
+	if list == "" {
+		hostname, err := os.Hostname()
+		if err != nil { return nil, err }
+		dimensions["hostname"] = hostname
+		return dimensions, nil
+	}
+	for _, item := range strings.Split(list, ",") {
+		fields := strings.SplitN(item, "=", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[0]) == "" || strings.TrimSpace(fields[1]) == "" {
+			return nil, fmt.Errorf("invalid dimension '%s' (KEY=VALUE expected)", item)
+		}
+		dimensions[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
+	}
+	return dimensions, nil
+}
+
+// parse a datapoint: VALUE [TIMESTAMP]
+func parse_datapoint(line string) (monitoring.Datapoint, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 1 || len(fields) > 2 { return monitoring.Datapoint{}, fmt.Errorf("invalid datapoint '%s' (VALUE [TIMESTAMP] expected)", line) }
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil { return monitoring.Datapoint{}, fmt.Errorf("invalid value '%s'", fields[0]) }
+	timestamp := time.Now().UTC()
+	if len(fields) == 2 {
+		timestamp, err = time.Parse(time.RFC3339, fields[1])
+		if err != nil { return monitoring.Datapoint{}, fmt.Errorf("invalid timestamp '%s' (RFC3339 format expected)", fields[1]) }
+	}
+	return monitoring.Datapoint{ Timestamp : &common.SDKTime{ Time : timestamp }, Value : common.Float64(value) }, nil
+}
+
+// post datapoints of a metric
+func post_datapoints(client monitoring.MonitoringClient, details monitoring.MetricDataDetails, datapoints []monitoring.Datapoint) error {
+	details.Datapoints = datapoints
+	request := monitoring.PostMetricDataRequest{ PostMetricDataDetails : monitoring.PostMetricDataDetails{ MetricData : []monitoring.MetricDataDetails{ details } },
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	response, err := client.PostMetricData(context.Background(), request)
+	if err != nil { return err }
+	if response.FailedMetricsCount != nil && *response.FailedMetricsCount > 0 {
+		messages := make([]string, 0)
+		for _, f := range response.FailedMetrics { messages = append(messages, *f.Message) }
+		return fmt.Errorf("%d metrics not posted: %s", *response.FailedMetricsCount, strings.Join(messages, ", "))
+	}
+	return nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	value          := flag.String("value", "", "value of the datapoint (default: datapoints read from stdin)")
+	dimensions     := flag.String("dimensions", "", "dimensions of the metric: 'KEY=VALUE,...' (default: hostname=HOSTNAME)")
+	compartment_id := flag.String("compartment", "", "OCID of the compartment of the metric (default: root compartment)")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display anything when the metrics are posted")
+	flag.Parse()
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 2) { usage() }
+	} else {
+		if (flag.NArg() < 2 || flag.NArg() > 3) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args()[2:])
+	}
+	namespace, metric := flag.Arg(0), flag.Arg(1)
+	if strings.HasPrefix(namespace, "oci_") || strings.HasPrefix(namespace, "oracle_") { ocihelpers.Fatal ("namespace %s is reserved (oci_ and oracle_ prefixes)", namespace) }
+	dims, err := parse_dimensions(*dimensions)
+	ocihelpers.FatalIfError(err)
+
+	// Get the datapoints
+	datapoints := make([]monitoring.Datapoint, 0)
+	if *value != "" {
+		dp, err := parse_datapoint(*value)
+		ocihelpers.FatalIfError(err)
+		datapoints = append(datapoints, dp)
+	} else {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) == "" { continue }
+			dp, err := parse_datapoint(scanner.Text())
+			ocihelpers.FatalIfError(err)
+			datapoints = append(datapoints, dp)
+		}
+		ocihelpers.FatalIfError(scanner.Err())
+	}
+	if len(datapoints) == 0 { ocihelpers.Fatal ("no datapoint to post") }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	if *compartment_id == "" {
+		*compartment_id, err = config.TenancyOCID()
+		ocihelpers.FatalIfError(err)
+	}
+
+	// Metrics are posted to the telemetry ingestion endpoint (not the monitoring endpoint)
+	region, err := config.Region()
+	ocihelpers.FatalIfError(err)
+	client, err := monitoring.NewMonitoringClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	client.Host = common.StringToRegion(region).EndpointForTemplate("telemetry-ingestion", ingestion_endpoint)
+
+	// Post the datapoints by batches
+	details := monitoring.MetricDataDetails{ Namespace : common.String(namespace), CompartmentId : compartment_id, Name : common.String(metric), Dimensions : dims }
+	for start := 0; start < len(datapoints); start += max_datapoints {
+		end := start + max_datapoints
+		if end > len(datapoints) { end = len(datapoints) }
+		ocihelpers.FatalIfError(post_datapoints(client, details, datapoints[start:end]))
+	}
+	if !ocihelpers.Quiet {
+		fmt.Printf ("%d datapoints posted for metric %s.%s\n", len(datapoints), namespace, metric)
+	}
+}
@@ -0,0 +1,333 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the Kubernetes clusters (OKE) in all compartments of a OCI tenant using OCI Go SDK
+// For each cluster, it displays Kubernetes version, endpoint and its visibility (public or private), and
+// the node pools with their shape, number of nodes, image and version skew vs the control plane
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the endpoints of the clusters (public and private) are not available in the OCI Go SDK version used,
+//       so the clusters are listed with the container engine REST API
+//       deleted clusters are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/containerengine"
+)
+
+// -- constants
+const oke_endpoint    = "https://containerengine.{region}.oci.{secondLevelDomain}"
+const oke_api_version = "20180222"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// cluster returned by the container engine REST API
+type cluster_rest struct {
+	Id                          string   `json:"id"`
+	Name                        string   `json:"name"`
+	CompartmentId               string   `json:"compartmentId"`
+	KubernetesVersion           string   `json:"kubernetesVersion"`
+	LifecycleState              string   `json:"lifecycleState"`
+	AvailableKubernetesUpgrades []string `json:"availableKubernetesUpgrades"`
+	Endpoints                   *struct {
+		Kubernetes      string `json:"kubernetes"`     // public endpoint of the clusters not integrated in a VCN
+		PublicEndpoint  string `json:"publicEndpoint"`
+		PrivateEndpoint string `json:"privateEndpoint"`
+	} `json:"endpoints"`
+}
+
+type node_pool_json struct {
+	Name              string  `json:"name"`
+	Id                string  `json:"id"`
+	Shape             string  `json:"shape"`
+	Ocpus             float32 `json:"ocpus,omitempty"`
+	Nodes             int     `json:"nodes"`
+	Image             string  `json:"image"`
+	KubernetesVersion string  `json:"kubernetes_version"`
+	VersionSkew       int     `json:"version_skew"`     // number of minor versions behind the control plane
+}
+
+type cluster_json struct {
+	Name                        string           `json:"name"`
+	Id                          string           `json:"id"`
+	KubernetesVersion           string           `json:"kubernetes_version"`
+	LifecycleState              string           `json:"lifecycle_state"`
+	EndpointVisibility          string           `json:"endpoint_visibility"`     // public or private
+	PublicEndpoint              string           `json:"public_endpoint"`
+	PrivateEndpoint             string           `json:"private_endpoint"`
+	AvailableKubernetesUpgrades []string         `json:"available_kubernetes_upgrades"`
+	NodePools                   []node_pool_json `json:"node_pools"`
+	Region                      string           `json:"region"`
+	CompartmentId               string           `json:"compartment_id"`
+	CompartmentPath             string           `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each node pool of each cluster. The version skew is the number of minor Kubernetes")
+    fmt.Println("    versions of the node pool behind the control plane of the cluster.")
+    fmt.Println("    If --skew-only is provided, only the node pools with a version skew are displayed.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// minor number of a Kubernetes version (ex: 27 for v1.27.2), -1 if the version cannot be parsed
+func minor_version(version string) int {
+	fields := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(fields) < 2 { return -1 }
+	minor, err := strconv.Atoi(fields[1])
+	if err != nil { return -1 }
+	return minor
+}
+
+// list the clusters (not deleted) of a compartment
+func list_clusters(client *ocihelpers.RestClient, cpt_id string, region string) ([]cluster_json, error) {
+	clusters := make([]cluster_json, 0)
+	query := url.Values{ "compartmentId" : { cpt_id } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []cluster_rest
+		next_page, err := client.Get("/clusters", query, &items)
+		if err != nil { return nil, err }
+		for _, c := range items {
+			if c.LifecycleState == "DELETED" { continue }
+			item := cluster_json{ Name : c.Name, Id : c.Id, KubernetesVersion : c.KubernetesVersion, LifecycleState : c.LifecycleState, AvailableKubernetesUpgrades : c.AvailableKubernetesUpgrades,
+				NodePools : make([]node_pool_json, 0), Region : region, CompartmentId : c.CompartmentId }
+			if item.AvailableKubernetesUpgrades == nil { item.AvailableKubernetesUpgrades = make([]string, 0) }
+			if c.Endpoints != nil {
+				item.PublicEndpoint, item.PrivateEndpoint = c.Endpoints.PublicEndpoint, c.Endpoints.PrivateEndpoint
+				if item.PublicEndpoint == "" { item.PublicEndpoint = c.Endpoints.Kubernetes }
+			}
+			item.EndpointVisibility = "private"
+			if item.PublicEndpoint != "" { item.EndpointVisibility = "public" }
+			clusters = append(clusters, item)
+		}
+		return next_page, nil
+	})
+	return clusters, err
+}
+
+// list the node pools of a compartment
+func list_node_pools(client containerengine.ContainerEngineClient, cpt_id string) ([]containerengine.NodePoolSummary, error) {
+	node_pools := make([]containerengine.NodePoolSummary, 0)
+	request := containerengine.ListNodePoolsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListNodePools(context.Background(), request)
+		if err != nil { return nil, err }
+		node_pools = append(node_pools, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	return node_pools, err
+}
+
+// list the clusters of all active compartments of a region, with their node pools
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]cluster_json, error) {
+	rest_client, err := ocihelpers.NewRestClient(config, oke_endpoint, oke_api_version, region)
+	if err != nil { return nil, err }
+	client, err := containerengine.NewContainerEngineClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	type compartment_items struct {
+		clusters   []cluster_json
+		node_pools []containerengine.NodePoolSummary
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_clusters, err := list_clusters(rest_client, cpt_id, region)
+		if err != nil { return nil, err }
+		cpt_node_pools, err := list_node_pools(client, cpt_id)
+		if err != nil { return nil, err }
+		return compartment_items{ cpt_clusters, cpt_node_pools }, nil
+	})
+	clusters := make([]cluster_json, 0)
+	node_pools := make([]containerengine.NodePoolSummary, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		clusters = append(clusters, cpt_items.clusters...)
+		node_pools = append(node_pools, cpt_items.node_pools...)
+	}
+
+	// node pools of each cluster (can be in another compartment than the cluster)
+	cluster_index := make(map[string]int)
+	for n, c := range clusters { cluster_index[c.Id] = n }
+	for _, np := range node_pools {
+		n, found := cluster_index[*np.ClusterId]
+		if !found { continue }
+		item := node_pool_json{ Name : *np.Name, Id : *np.Id, Shape : *np.NodeShape }
+		if np.KubernetesVersion != nil { item.KubernetesVersion = *np.KubernetesVersion }
+		if np.NodeImageName != nil { item.Image = *np.NodeImageName }
+		if np.NodeSource != nil && np.NodeSource.GetSourceName() != nil { item.Image = *np.NodeSource.GetSourceName() }
+		if np.NodeShapeConfig != nil && np.NodeShapeConfig.Ocpus != nil { item.Ocpus = *np.NodeShapeConfig.Ocpus }
+		switch {
+		case np.NodeConfigDetails != nil && np.NodeConfigDetails.Size != nil: item.Nodes = *np.NodeConfigDetails.Size
+		case np.QuantityPerSubnet != nil:                                     item.Nodes = *np.QuantityPerSubnet * len(np.SubnetIds)
+		}
+		cp_minor, np_minor := minor_version(clusters[n].KubernetesVersion), minor_version(item.KubernetesVersion)
+		if cp_minor >= 0 && np_minor >= 0 { item.VersionSkew = cp_minor - np_minor }
+		clusters[n].NodePools = append(clusters[n].NodePools, item)
+	}
+	for n := range clusters {
+		sort.Slice(clusters[n].NodePools, func(i, j int) bool { return clusters[n].NodePools[i].Name < clusters[n].NodePools[j].Name })
+	}
+	return clusters, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list clusters in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list clusters in all subscribed regions")
+	skew_only       := flag.Bool("skew-only", false, "only display the node pools with a version skew")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the clusters in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("OKE clusters", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	clusters := make([]cluster_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, c := range r.Value.([]cluster_json) {
+			c.CompartmentPath = paths[c.CompartmentId]
+			if *skew_only {
+				node_pools := make([]node_pool_json, 0)
+				for _, np := range c.NodePools {
+					if np.VersionSkew > 0 { node_pools = append(node_pools, np) }
+				}
+				if len(node_pools) == 0 { continue }
+				c.NodePools = node_pools
+			}
+			clusters = append(clusters, c)
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].CompartmentPath != clusters[j].CompartmentPath { return clusters[i].CompartmentPath < clusters[j].CompartmentPath }
+		return clusters[i].Name < clusters[j].Name
+	})
+
+	// Display the results (a row per node pool)
+	if format == "json" {
+		output, err := json.MarshalIndent(clusters, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "cluster", "version", "state", "visibility", "endpoint", "upgrades", "node_pool", "shape", "nodes", "image", "node_version", "skew", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_node_pools, nb_nodes := 0, 0
+		for _, c := range clusters {
+			endpoint := c.PublicEndpoint
+			if endpoint == "" { endpoint = c.PrivateEndpoint }
+			cluster := []string{ c.Name, c.KubernetesVersion, c.LifecycleState, c.EndpointVisibility, endpoint, strings.Join(c.AvailableKubernetesUpgrades, " ") }
+			end := []string{ c.CompartmentPath }
+			if all_regions { end = append(end, c.Region) }
+			end = append(end, c.Id)
+			if len(c.NodePools) == 0 { table.AddRow(append(append(cluster, "", "", "", "", "", ""), end...)...) }
+			for _, np := range c.NodePools {
+				nb_node_pools++
+				nb_nodes += np.Nodes
+				node_pool := []string{ np.Name, np.Shape, fmt.Sprintf("%d", np.Nodes), np.Image, np.KubernetesVersion, fmt.Sprintf("%d", np.VersionSkew) }
+				table.AddRow(append(append(append([]string{}, cluster...), node_pool...), end...)...)
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d clusters, %d node pools, %d nodes\n", len(clusters), nb_node_pools, nb_nodes)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
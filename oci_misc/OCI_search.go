@@ -0,0 +1,206 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script runs a query of the structured query language of the Resource Search service of OCI
+// (ex: query instance resources where lifecycleState = 'STOPPED') or a free text search using OCI Go SDK,
+// and displays the resources found with the full path of their compartment instead of its OCID
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/resourcesearch"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type resource_json struct {
+	Name               string `json:"name"`
+	Id                 string `json:"id"`
+	ResourceType       string `json:"resource_type"`
+	LifecycleState     string `json:"lifecycle_state"`
+	AvailabilityDomain string `json:"availability_domain,omitempty"`
+	TimeCreated        string `json:"time_created"`
+	Region             string `json:"region"`
+	CompartmentId      string `json:"compartment_id"`
+	CompartmentPath    string `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] --query QUERY [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --text TEXT [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --query QUERY -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    QUERY is a query of the structured query language of Resource Search, examples:")
+    fmt.Println("       'query all resources where timeCreated >= \"2026-01-01T00:00:00Z\"'")
+    fmt.Println("       \"query instance resources where lifecycleState = 'STOPPED'\"")
+    fmt.Println("       \"query vcn, subnet resources where displayName =~ 'prod'\"")
+    fmt.Println("    TEXT is a free text searched in all the fields of the resources (ex: an IP address or a tag value).")
+    fmt.Println("")
+    fmt.Println("    If -a or --all-regions is provided, the search is done in all subscribed regions instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// run the search in a region
+func search_region(config common.ConfigurationProvider, region string, details resourcesearch.SearchDetails) ([]resource_json, error) {
+	client, err := resourcesearch.NewResourceSearchClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	resources := make([]resource_json, 0)
+	request := resourcesearch.SearchResourcesRequest{
+		SearchDetails   : details,
+		Limit           : common.Int(1000),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.SearchResources(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, r := range response.Items {
+			item := resource_json{ Id : *r.Identifier, ResourceType : *r.ResourceType, Region : region, CompartmentId : *r.CompartmentId }
+			if r.DisplayName != nil        { item.Name = *r.DisplayName }
+			if r.LifecycleState != nil     { item.LifecycleState = *r.LifecycleState }
+			if r.AvailabilityDomain != nil { item.AvailabilityDomain = *r.AvailabilityDomain }
+			if r.TimeCreated != nil        { item.TimeCreated = r.TimeCreated.Format("2006-01-02 15:04:05") }
+			resources = append(resources, item)
+		}
+		return response.OpcNextPage, nil
+	})
+	return resources, err
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "search in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "search in all subscribed regions")
+	query           := flag.String("query", "", "query of the structured query language of Resource Search")
+	text            := flag.String("text", "", "free text search")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if (*query == "") == (*text == "") { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	var details resourcesearch.SearchDetails = resourcesearch.StructuredSearchDetails{ Query : common.String(*query) }
+	if *text != "" { details = resourcesearch.FreeTextSearchDetails{ Text : common.String(*text) } }
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Run the search in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("search", len(regions), 0)
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return search_region(config, region, details)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	resources := make([]resource_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		resources = append(resources, r.Value.([]resource_json)...)
+	}
+	for i := range resources {
+		resources[i].CompartmentPath = resources[i].CompartmentId
+		if path, found := paths[resources[i].CompartmentId]; found { resources[i].CompartmentPath = path }
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].ResourceType != resources[j].ResourceType { return resources[i].ResourceType < resources[j].ResourceType }
+		if resources[i].CompartmentPath != resources[j].CompartmentPath { return resources[i].CompartmentPath < resources[j].CompartmentPath }
+		return resources[i].Name < resources[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(resources, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "resource_type", "name", "state", "time_created", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		for _, r := range resources {
+			row := []string{ r.ResourceType, r.Name, r.LifecycleState, r.TimeCreated, r.CompartmentPath }
+			if all_regions { row = append(row, r.Region) }
+			table.AddRow(append(row, r.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d resources found\n", len(resources))
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
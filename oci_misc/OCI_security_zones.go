@@ -0,0 +1,276 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the security zones (Maximum Security Zones) of a OCI tenant using OCI Go SDK, with their
+// recipe (security policies enforced), and displays for each compartment the security zone covering it
+// (its own zone or the zone of a parent compartment), to find the compartments not covered by any security zone
+// Compartments not covered by any security zone are displayed in red
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       security zones are read in the reporting region of Cloud Guard
+//       security zones are not available in the version of the OCI Go SDK used, so the Cloud Guard REST API is
+//       used directly (signed REST requests, see internal/ocihelpers/rest.go)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+//                 - Cloud Guard enabled in the tenant
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- constants
+const cloudguard_endpoint    = "https://cloudguard-cp-api.{region}.oci.{secondLevelDomain}"
+const cloudguard_api_version = "20200131"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// security zone and recipe returned by the Cloud Guard REST API
+type zone_rest struct {
+	Id                   string `json:"id"`
+	DisplayName          string `json:"displayName"`
+	CompartmentId        string `json:"compartmentId"`
+	SecurityZoneRecipeId string `json:"securityZoneRecipeId"`
+	LifecycleState       string `json:"lifecycleState"`
+}
+
+type recipe_rest struct {
+	Id               string   `json:"id"`
+	DisplayName      string   `json:"displayName"`
+	Owner            string   `json:"owner"`            // ORACLE or CUSTOMER
+	SecurityPolicies []string `json:"securityPolicies"`
+}
+
+type compartment_json struct {
+	CompartmentPath string `json:"compartment_path"`
+	CompartmentId   string `json:"compartment_id"`
+	Covered         bool   `json:"covered"`
+	SecurityZone    string `json:"security_zone"`
+	SecurityZoneId  string `json:"security_zone_id"`
+	Inherited       bool   `json:"inherited"`        // zone of a parent compartment
+	Recipe          string `json:"recipe"`
+	RecipeOwner     string `json:"recipe_owner"`
+	NbPolicies      int    `json:"nb_policies"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each active compartment, with the security zone covering it (its own zone or the zone")
+    fmt.Println("    of a parent compartment). Compartments not covered by any security zone are displayed in red.")
+    fmt.Println("    If --uncovered-only is provided, only the compartments not covered by any security zone are displayed.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// reporting region of Cloud Guard
+func reporting_region(client *ocihelpers.RestClient, tenancy_id string) (string, error) {
+	var configuration struct {
+		ReportingRegion string `json:"reportingRegion"`
+		Status          string `json:"status"`
+	}
+	if _, err := client.Get("/configuration", url.Values{ "compartmentId" : { tenancy_id } }, &configuration); err != nil { return "", err }
+	if configuration.Status != "ENABLED" { return "", fmt.Errorf("Cloud Guard is not enabled in the tenant (status %s)", configuration.Status) }
+	return configuration.ReportingRegion, nil
+}
+
+// list the active security zones of a compartment
+func list_zones(client *ocihelpers.RestClient, cpt_id string) ([]zone_rest, error) {
+	zones := make([]zone_rest, 0)
+	query := url.Values{ "compartmentId" : { cpt_id }, "lifecycleState" : { "ACTIVE" } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var collection struct {
+			Items []zone_rest `json:"items"`
+		}
+		next_page, err := client.Get("/securityZones", query, &collection)
+		if err != nil { return nil, err }
+		zones = append(zones, collection.Items...)
+		return next_page, nil
+	})
+	return zones, err
+}
+
+// security zone covering a compartment: zone of the compartment or of its closest parent (nil if none)
+func covering_zone(tree *ocihelpers.CompartmentTree, zones map[string]zone_rest, cpt_id string) (*zone_rest, bool) {
+	inherited := false
+	for {
+		if z, found := zones[cpt_id]; found { return &z, inherited }
+		if cpt_id == tree.TenancyOCID { return nil, false }
+		c, found := tree.Get(cpt_id)
+		if !found { return nil, false }
+		cpt_id = *c.CompartmentId
+		inherited = true
+	}
+}
+
+// display the table in text format, with the compartments not covered by any security zone in red
+func display_text(table ocihelpers.Table, cpts []compartment_json) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(cpts) { break }
+		if !cpts[i].Covered { line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL }
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	uncovered_only  := flag.Bool("uncovered-only", false, "only display the compartments not covered by any security zone")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Cloud Guard REST API in the reporting region
+	region, err := config.Region()
+	ocihelpers.FatalIfError(err)
+	client, err := ocihelpers.NewRestClient(config, cloudguard_endpoint, cloudguard_api_version, region)
+	ocihelpers.FatalIfError(err)
+	reporting, err := reporting_region(client, tree.TenancyOCID)
+	ocihelpers.FatalIfError(err)
+	if reporting != region {
+		client, err = ocihelpers.NewRestClient(config, cloudguard_endpoint, cloudguard_api_version, reporting)
+		ocihelpers.FatalIfError(err)
+	}
+
+	// Get the security zones (a compartment has at most one security zone) and their recipes
+	// (at most parallelism compartments processed at the same time)
+	ocihelpers.StartProgress("security zones", 1, len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), *parallelism, func(cpt_id string) (interface{}, error) {
+		return list_zones(client, cpt_id)
+	})
+	ocihelpers.StopProgress()
+	zones := make(map[string]zone_rest)
+	recipes := make(map[string]recipe_rest)
+	for _, r := range results {
+		ocihelpers.FatalIfError(r.Err)
+		for _, z := range r.Value.([]zone_rest) {
+			zones[z.CompartmentId] = z
+			if _, found := recipes[z.SecurityZoneRecipeId]; found { continue }
+			var recipe recipe_rest
+			_, err := client.Get("/securityRecipes/"+z.SecurityZoneRecipeId, nil, &recipe)
+			ocihelpers.FatalIfError(err)
+			recipes[z.SecurityZoneRecipeId] = recipe
+		}
+	}
+
+	// Security zone covering each compartment
+	cpts := make([]compartment_json, 0)
+	nb_uncovered := 0
+	for _, cpt_id := range tree.ActiveCompartmentIds() {
+		item := compartment_json{ CompartmentPath : paths[cpt_id], CompartmentId : cpt_id }
+		if z, inherited := covering_zone(tree, zones, cpt_id); z != nil {
+			recipe := recipes[z.SecurityZoneRecipeId]
+			item.Covered, item.SecurityZone, item.SecurityZoneId, item.Inherited = true, z.DisplayName, z.Id, inherited
+			item.Recipe, item.RecipeOwner, item.NbPolicies = recipe.DisplayName, recipe.Owner, len(recipe.SecurityPolicies)
+		} else {
+			nb_uncovered++
+		}
+		if *uncovered_only && item.Covered { continue }
+		cpts = append(cpts, item)
+	}
+	sort.SliceStable(cpts, func(i, j int) bool { return cpts[i].CompartmentPath < cpts[j].CompartmentPath })
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(cpts, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	table := ocihelpers.Table{ Headers : []string{ "compartment_path", "covered", "security_zone", "inherited", "recipe", "recipe_owner", "policies", "compartment_id" } }
+	for _, c := range cpts {
+		if !c.Covered {
+			table.AddRow(c.CompartmentPath, "false", "", "", "", "", "", c.CompartmentId)
+			continue
+		}
+		table.AddRow(c.CompartmentPath, "true", c.SecurityZone, fmt.Sprintf("%t", c.Inherited), c.Recipe, c.RecipeOwner, fmt.Sprintf("%d", c.NbPolicies), c.CompartmentId)
+	}
+	if format != "text" {
+		ocihelpers.FatalIfError(table.Print(format))
+		return
+	}
+	display_text(table, cpts)
+	if !ocihelpers.Quiet {
+		fmt.Println ("")
+		fmt.Printf ("%d security zones, %d compartments not covered by any security zone\n", len(zones), nb_uncovered)
+	}
+}
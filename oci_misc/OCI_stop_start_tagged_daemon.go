@@ -0,0 +1,545 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script runs as a long-running daemon that stops (or starts) resources whose schedule tag value matches
+//     the current UTC time, like OCI_instances_stop_start_tagged.go, but without an external scheduler:
+//     the schedule tags are evaluated every N minutes, and everything done is logged.
+// Supported resources: compute instances, Autonomous Databases, MySQL DB systems and Analytics instances
+// This script looks in all compartments in a OCI tenant in a region (or in all subscribed regions) using OCI Go SDK
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       tag values must have the format HH:00_UTC (ex: 19:00_UTC), other values (ex: off) are ignored
+//       each scheduled action is applied only once per hour: a resource started manually after its scheduled
+//       stop is not stopped again at the next evaluation (failed actions are retried at the next evaluations)
+//       an error on a service (ex: missing policy) does not prevent the other services from being processed
+//       the compartments and the regions are refreshed at each evaluation
+//       stop the daemon with Ctrl-C or kill (SIGINT or SIGTERM)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+//                 - OCI user with enough privileges to be able to read, stop and start the resources (policy example below)
+//                       allow group osc_stop_and_start to read all-resources in tenancy
+//                       allow group osc_stop_and_start to manage instances in tenancy where request.operation = 'InstanceAction'
+//                       allow group osc_stop_and_start to use autonomous-databases in tenancy
+//                       allow group osc_stop_and_start to use mysql-instances in tenancy
+//                       allow group osc_stop_and_start to use analytics-instances in tenancy
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Count the failed actions separately from the scheduled actions in the webhook summary
+//    2026-10-15: Process the other services when one fails, retry the failed actions at the next evaluation
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/analytics"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/database"
+	"github.com/oracle/oci-go-sdk/mysql"
+)
+
+// -- constants
+
+// Tag namespace and keys of the schedule (same as OCI_instances_stop_start_tagged.go)
+// Update these to match your tags (or use the --tag-ns, --tag-key-stop and --tag-key-start options)
+const default_tag_ns        = "osc"
+const default_tag_key_stop  = "automatic_shutdown"
+const default_tag_key_start = "automatic_startup"
+
+const default_services = "compute,adb,mysql,analytics"
+
+// -- types
+
+// resource that can be stopped and started by the daemon
+type resource struct {
+	name           string
+	id             string
+	compartment_id string
+	state          string                              // running, stopped or the lifecycle state if neither
+	defined_tags   map[string]map[string]interface{}
+}
+
+// service whose resources can be stopped and started
+type service struct {
+	description string                                                                                       // ex: compute instance
+	list        func(config common.ConfigurationProvider, region string, cpt_ids []string) ([]resource, error)
+	action      func(config common.ConfigurationProvider, region string, r resource, action string) error       // action = start or stop
+}
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// options
+var tag_ns            string
+var tag_key_stop      string
+var tag_key_start     string
+var confirm_stop      bool
+var confirm_start     bool
+var webhook           string
+var webhook_threshold int
+var parallelism       int                   // number of compartments processed at the same time
+
+// scheduled actions already applied (or reported) during the current hour: key = ocid/action, value = hour
+var done      = make(map[string]string)
+var done_lock sync.Mutex
+
+var services = map[string]service{
+	"compute"   : { "compute instance", list_instances, instance_action },
+	"adb"       : { "Autonomous Database", list_adbs, adb_action },
+	"mysql"     : { "MySQL DB system", list_mysql_db_systems, mysql_db_system_action },
+	"analytics" : { "Analytics instance", list_analytics_instances, analytics_instance_action },
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [-a] [--confirm_stop] [--confirm_start] [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [-a] [--confirm_stop] [--confirm_start] [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    The schedule tags are evaluated every 10 minutes (--interval N for every N minutes) until the daemon is stopped.")
+    fmt.Println("    If -a or --all-regions is provided, the daemon processes all subscribed regions instead of the region of the profile.")
+    fmt.Println("    If --confirm_stop  is not provided, the resources to stop are logged but not actually stopped.")
+    fmt.Println("    If --confirm_start is not provided, the resources to start are logged but not actually started.")
+    fmt.Printf ("    If --services LIST is provided, only these services are processed (default: %s).\n", default_services)
+    fmt.Printf ("    If --tag-ns NS is provided, this tag namespace is used instead of %s.\n", default_tag_ns)
+    fmt.Printf ("    If --tag-key-stop KEY is provided, this tag key is used instead of %s.\n", default_tag_key_stop)
+    fmt.Printf ("    If --tag-key-start KEY is provided, this tag key is used instead of %s.\n", default_tag_key_start)
+    fmt.Println("    If --webhook URL is provided, a summary is posted to this Slack-compatible webhook after each evaluation with at")
+    fmt.Println("    least N resources stopped, started or to stop/start (--webhook-threshold N, default 1). Default URL:")
+    fmt.Println("    OCI_WEBHOOK_URL environment variable. The actions that failed are counted separately and always posted.")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is appended to this file instead of stdout (log file).")
+    fmt.Println("")
+    fmt.Println("    Tag values must have the format HH:00_UTC (ex: 19:00_UTC), other values (ex: off) are ignored.")
+    fmt.Println("    Each scheduled action is applied only once per hour (failed actions are retried at the next evaluations).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = stopped by SIGINT or SIGTERM, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// log a line with the date and time
+func log(format string, args ...interface{}) {
+	fmt.Printf ("%s: %s\n", time.Now().UTC().Format("2006/01/02 15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// get the value of a defined tag (empty string if not set)
+func get_tag_value(r resource, key string) string {
+	if value, ok := r.defined_tags[tag_ns][key]; ok {
+		return fmt.Sprintf("%v", value)
+	}
+	return ""
+}
+
+// true if the scheduled action on a resource was already applied during this hour
+func already_done(id string, action string, hour string) bool {
+	done_lock.Lock()
+	defer done_lock.Unlock()
+	return done[id + "/" + action] == hour
+}
+
+// record the scheduled action on a resource as applied during this hour
+// (only when it succeeded, so that failed actions are retried at the next evaluation)
+func mark_done(id string, action string, hour string) {
+	done_lock.Lock()
+	defer done_lock.Unlock()
+	done[id + "/" + action] = hour
+}
+
+// forget the scheduled actions of the previous hours
+func forget_previous_hours(hour string) {
+	done_lock.Lock()
+	defer done_lock.Unlock()
+	for key, h := range done {
+		if h != hour { delete(done, key) }
+	}
+}
+
+// resources of all the compartments processed by ForEachCompartment (error of the first compartment that failed)
+func merge_resources(results []ocihelpers.CompartmentResult) ([]resource, error) {
+	resources := make([]resource, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		resources = append(resources, r.Value.([]resource)...)
+	}
+	return resources, nil
+}
+
+// list the compute instances of some compartments of a region (at most parallelism compartments at the same time)
+func list_instances(config common.ConfigurationProvider, region string, cpt_ids []string) ([]resource, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(cpt_ids, parallelism, func(cpt_id string) (interface{}, error) {
+		resources := make([]resource, 0)
+		request := core.ListInstancesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListInstances(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, i := range response.Items {
+				state := string(i.LifecycleState)
+				switch i.LifecycleState {
+				case core.InstanceLifecycleStateRunning: state = "running"
+				case core.InstanceLifecycleStateStopped: state = "stopped"
+				}
+				resources = append(resources, resource{ *i.DisplayName, *i.Id, cpt_id, state, i.DefinedTags })
+			}
+			return response.OpcNextPage, nil
+		})
+		return resources, err
+	})
+	return merge_resources(results)
+}
+
+// start or stop (soft stop) a compute instance
+func instance_action(config common.ConfigurationProvider, region string, r resource, action string) error {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return err }
+	client.SetRegion(region)
+	request := core.InstanceActionRequest{ InstanceId : common.String(r.id), Action : core.InstanceActionActionStart, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	if action == "stop" { request.Action = core.InstanceActionActionSoftstop }
+	_, err = client.InstanceAction(context.Background(), request)
+	return err
+}
+
+// list the Autonomous Databases of some compartments of a region (at most parallelism compartments at the same time)
+func list_adbs(config common.ConfigurationProvider, region string, cpt_ids []string) ([]resource, error) {
+	client, err := database.NewDatabaseClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(cpt_ids, parallelism, func(cpt_id string) (interface{}, error) {
+		resources := make([]resource, 0)
+		request := database.ListAutonomousDatabasesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListAutonomousDatabases(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				state := string(a.LifecycleState)
+				switch a.LifecycleState {
+				case database.AutonomousDatabaseSummaryLifecycleStateAvailable: state = "running"
+				case database.AutonomousDatabaseSummaryLifecycleStateStopped:   state = "stopped"
+				}
+				resources = append(resources, resource{ *a.DisplayName, *a.Id, cpt_id, state, a.DefinedTags })
+			}
+			return response.OpcNextPage, nil
+		})
+		return resources, err
+	})
+	return merge_resources(results)
+}
+
+// start or stop an Autonomous Database
+func adb_action(config common.ConfigurationProvider, region string, r resource, action string) error {
+	client, err := database.NewDatabaseClientWithConfigurationProvider(config)
+	if err != nil { return err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	if action == "start" {
+		_, err = client.StartAutonomousDatabase(context.Background(), database.StartAutonomousDatabaseRequest{ AutonomousDatabaseId : common.String(r.id), RequestMetadata : metadata })
+	} else {
+		_, err = client.StopAutonomousDatabase(context.Background(), database.StopAutonomousDatabaseRequest{ AutonomousDatabaseId : common.String(r.id), RequestMetadata : metadata })
+	}
+	return err
+}
+
+// list the MySQL DB systems of some compartments of a region (at most parallelism compartments at the same time)
+func list_mysql_db_systems(config common.ConfigurationProvider, region string, cpt_ids []string) ([]resource, error) {
+	client, err := mysql.NewDbSystemClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(cpt_ids, parallelism, func(cpt_id string) (interface{}, error) {
+		resources := make([]resource, 0)
+		request := mysql.ListDbSystemsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListDbSystems(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, s := range response.Items {
+				state := string(s.LifecycleState)
+				switch s.LifecycleState {
+				case mysql.DbSystemLifecycleStateActive:   state = "running"
+				case mysql.DbSystemLifecycleStateInactive: state = "stopped"
+				}
+				resources = append(resources, resource{ *s.DisplayName, *s.Id, cpt_id, state, s.DefinedTags })
+			}
+			return response.OpcNextPage, nil
+		})
+		return resources, err
+	})
+	return merge_resources(results)
+}
+
+// start or stop (fast shutdown) a MySQL DB system
+func mysql_db_system_action(config common.ConfigurationProvider, region string, r resource, action string) error {
+	client, err := mysql.NewDbSystemClientWithConfigurationProvider(config)
+	if err != nil { return err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	if action == "start" {
+		_, err = client.StartDbSystem(context.Background(), mysql.StartDbSystemRequest{ DbSystemId : common.String(r.id), RequestMetadata : metadata })
+	} else {
+		details := mysql.StopDbSystemDetails{ ShutdownType : mysql.InnoDbShutdownModeFast }
+		_, err = client.StopDbSystem(context.Background(), mysql.StopDbSystemRequest{ DbSystemId : common.String(r.id), StopDbSystemDetails : details, RequestMetadata : metadata })
+	}
+	return err
+}
+
+// list the Analytics instances of some compartments of a region (at most parallelism compartments at the same time)
+// (the tags are not returned by the list, so they are read for the active and inactive instances only)
+func list_analytics_instances(config common.ConfigurationProvider, region string, cpt_ids []string) ([]resource, error) {
+	client, err := analytics.NewAnalyticsClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	results := ocihelpers.ForEachCompartment(cpt_ids, parallelism, func(cpt_id string) (interface{}, error) {
+		resources := make([]resource, 0)
+		request := analytics.ListAnalyticsInstancesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListAnalyticsInstances(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				r := resource{ name : *a.Name, id : *a.Id, compartment_id : cpt_id, state : string(a.LifecycleState) }
+				switch a.LifecycleState {
+				case analytics.AnalyticsInstanceLifecycleStateActive:   r.state = "running"
+				case analytics.AnalyticsInstanceLifecycleStateInactive: r.state = "stopped"
+				default:
+					resources = append(resources, r)
+					continue
+				}
+				instance, err := client.GetAnalyticsInstance(context.Background(), analytics.GetAnalyticsInstanceRequest{ AnalyticsInstanceId : a.Id, RequestMetadata : metadata })
+				if err != nil { return nil, err }
+				r.defined_tags = instance.DefinedTags
+				resources = append(resources, r)
+			}
+			return response.OpcNextPage, nil
+		})
+		return resources, err
+	})
+	return merge_resources(results)
+}
+
+// start or stop an Analytics instance
+func analytics_instance_action(config common.ConfigurationProvider, region string, r resource, action string) error {
+	client, err := analytics.NewAnalyticsClientWithConfigurationProvider(config)
+	if err != nil { return err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	if action == "start" {
+		_, err = client.StartAnalyticsInstance(context.Background(), analytics.StartAnalyticsInstanceRequest{ AnalyticsInstanceId : common.String(r.id), RequestMetadata : metadata })
+	} else {
+		_, err = client.StopAnalyticsInstance(context.Background(), analytics.StopAnalyticsInstanceRequest{ AnalyticsInstanceId : common.String(r.id), RequestMetadata : metadata })
+	}
+	return err
+}
+
+// check the resources of the selected services in all active compartments of a region and stop or start them if needed
+// returns the log lines (the regions are processed concurrently, so the lines are displayed at the end),
+// and for the webhook summary, the scheduled actions and the actions that failed (lines without date and time)
+func process_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, service_names []string, current_utc_time string, hour string) ([]string, []string, []string, error) {
+	lines := make([]string, 0)
+	actions := make([]string, 0)
+	failures := make([]string, 0)
+	log_line := func(summary *[]string, cpt_id string, format string, args ...interface{}) {
+		line := fmt.Sprintf("%s, %s: %s", region, tree.FullPath(cpt_id), fmt.Sprintf(format, args...))
+		lines = append(lines, time.Now().UTC().Format("2006/01/02 15:04:05") + ": " + line)
+		*summary = append(*summary, line)
+	}
+
+	// an error on a service does not prevent the other services from being processed
+	service_errors := make([]string, 0)
+	for _, name := range service_names {
+		s := services[name]
+		resources, err := s.list(config, region, tree.ActiveCompartmentIds())
+		if err != nil {
+			service_errors = append(service_errors, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+
+		// for each resource, check if it needs to be stopped or started
+		for _, r := range resources {
+			action, confirm := "", false
+			switch {
+			case r.state == "stopped" && get_tag_value(r, tag_key_start) == current_utc_time:
+				action, confirm = "start", confirm_start
+			case r.state == "running" && get_tag_value(r, tag_key_stop) == current_utc_time:
+				action, confirm = "stop", confirm_stop
+			default:
+				continue
+			}
+			if already_done(r.id, action, hour) { continue }
+
+			if !confirm {
+				if action == "start" {
+					log_line(&actions, r.compartment_id, "%s %s (%s) SHOULD BE STARTED --> re-run daemon with --confirm_start to actually start resources", s.description, r.name, r.id)
+				} else {
+					log_line(&actions, r.compartment_id, "%s %s (%s) SHOULD BE STOPPED --> re-run daemon with --confirm_stop to actually stop resources", s.description, r.name, r.id)
+				}
+				mark_done(r.id, action, hour)
+				continue
+			}
+
+			if action == "start" {
+				log_line(&actions, r.compartment_id, "STARTING %s %s (%s)", s.description, r.name, r.id)
+			} else {
+				log_line(&actions, r.compartment_id, "STOPPING %s %s (%s)", s.description, r.name, r.id)
+			}
+			if err := s.action(config, region, r, action); err != nil {
+				log_line(&failures, r.compartment_id, "ERROR: cannot %s %s %s (%s): %s", action, s.description, r.name, r.id, err)
+				continue
+			}
+			mark_done(r.id, action, hour)
+		}
+	}
+
+	// the region failed only if all the services failed
+	if len(service_errors) == len(service_names) { return lines, actions, failures, fmt.Errorf("%s", strings.Join(service_errors, ", ")) }
+	for _, e := range service_errors {
+		line := fmt.Sprintf("%s: ERROR: %s", region, e)
+		lines = append(lines, time.Now().UTC().Format("2006/01/02 15:04:05") + ": " + line)
+		failures = append(failures, line)
+	}
+	return lines, actions, failures, nil
+}
+
+// evaluate the schedule tags once in all regions
+func evaluate(config common.ConfigurationProvider, all_regions bool, service_names []string) {
+	// current UTC time (format 10:00_UTC, 11:00_UTC ...) and hour (to apply each action once per hour)
+	now := time.Now().UTC()
+	current_utc_time := now.Format("15") + ":00_UTC"
+	hour := now.Format("2006-01-02T15")
+	forget_previous_hours(hour)
+
+	// compartments and regions (refreshed at each evaluation, compartments never read from the cache file)
+	ocihelpers.RefreshCompartmentCache = true
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	if err != nil {
+		log("ERROR: cannot get the compartments: %s", err)
+		return
+	}
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	if err != nil {
+		log("ERROR: cannot get the regions: %s", err)
+		return
+	}
+
+	// Do the job (regions processed concurrently)
+	type region_output struct {
+		lines    []string
+		actions  []string
+		failures []string
+	}
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		lines, actions, failures, err := process_region(config, tree, region, service_names, current_utc_time, hour)
+		return region_output{ lines, actions, failures }, err
+	})
+	actions := make([]string, 0)
+	failures := make([]string, 0)
+	for _, r := range results {
+		output, _ := r.Value.(region_output)
+		for _, line := range output.lines { fmt.Println (line) }
+		actions = append(actions, output.actions...)
+		failures = append(failures, output.failures...)
+		if r.Err != nil {
+			log("ERROR: region %s: %s", r.Region, r.Err)
+			failures = append(failures, fmt.Sprintf("%s: ERROR: %s", r.Region, r.Err))
+		}
+	}
+	log("evaluation of %s done (%d regions, %d actions, %d errors)", current_utc_time, len(regions), len(actions), len(failures))
+
+	// Post a summary to the webhook: scheduled actions, then failures (errors are logged, the daemon keeps running)
+	// the failures are always posted, even if the number of actions is below the threshold
+	title := fmt.Sprintf("OCI stop/start daemon: %d scheduled actions, %d failures at %s", len(actions), len(failures), current_utc_time)
+	threshold := webhook_threshold
+	if len(failures) > 0 { threshold = 1 }
+	if _, err := ocihelpers.Notify(webhook, threshold, title, append(actions, failures...)); err != nil { log("ERROR: %s", err) }
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	flag.BoolVar(&confirm_stop, "confirm_stop", false, "actually stop the resources")
+	flag.BoolVar(&confirm_start, "confirm_start", false, "actually start the resources")
+	flag.StringVar(&tag_ns, "tag-ns", default_tag_ns, "tag namespace")
+	flag.StringVar(&tag_key_stop, "tag-key-stop", default_tag_key_stop, "tag key for the stop time")
+	flag.StringVar(&tag_key_start, "tag-key-start", default_tag_key_start, "tag key for the start time")
+	flag.StringVar(&webhook, "webhook", ocihelpers.WebhookURL(), "post a summary to this Slack-compatible webhook")
+	flag.IntVar(&webhook_threshold, "webhook-threshold", 1, "minimum number of scheduled actions to post a summary")
+	flag.IntVar(&parallelism, "parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	interval     := flag.Int("interval", 10, "evaluate the schedule tags every N minutes")
+	service_list := flag.String("services", default_services, "comma separated list of services to process")
+	output_file  := flag.String("output-file", "", "append the output to this file instead of stdout")
+	flag.Parse()
+	if *interval < 1 || *interval > 60 || webhook_threshold < 1 || parallelism < 1 { usage() }
+	service_names := strings.Split(*service_list, ",")
+	for _, name := range service_names {
+		if _, ok := services[name]; !ok { usage() }
+	}
+	sort.Strings(service_names)
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Append the output to a log file instead of stdout
+	if *output_file != "" {
+		f, err := os.OpenFile(ocihelpers.ExpandPath(*output_file), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		ocihelpers.FatalIfError(err)
+		os.Stdout = f
+	}
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Starting
+	pid := os.Getpid()
+	log("BEGIN DAEMON PID=%d (services %s, every %d minutes, confirm_stop=%t, confirm_start=%t)", pid, strings.Join(service_names, ","), *interval, confirm_stop, confirm_start)
+
+	// Evaluate the schedule tags now, then every N minutes until SIGINT or SIGTERM
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	ticker := time.NewTicker(time.Duration(*interval) * time.Minute)
+	defer ticker.Stop()
+	evaluate(config, all_regions, service_names)
+	for {
+		select {
+		case <-ticker.C:
+			evaluate(config, all_regions, service_names)
+		case s := <-signals:
+			log("END DAEMON PID=%d (signal %s)", pid, s)
+			return
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script looks for the resources of a OCI tenant that do not have some required tags (ex: CostCenter, Owner)
+// using the Resource Search service of OCI Go SDK, and reports them per compartment
+// It can optionally add the missing defined tags with a default value (--fix --yes)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       terminated and deleted resources are ignored
+//       only defined tags can be added by --fix (missing freeform tags are only reported). The tags are added by
+//       a work request of OCI, so some resources types may not support it (see the work requests in the console)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/resourcesearch"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type resource_json struct {
+	Name            string   `json:"name"`
+	Id              string   `json:"id"`
+	ResourceType    string   `json:"resource_type"`
+	Region          string   `json:"region"`
+	CompartmentId   string   `json:"compartment_id"`
+	CompartmentPath string   `json:"compartment_path"`
+	MissingTags     []string `json:"missing_tags"`
+}
+
+type compartment_json struct {
+	CompartmentPath string  `json:"compartment_path"`
+	CompartmentId   string  `json:"compartment_id"`
+	Resources       int     `json:"resources"`
+	NonCompliant    int     `json:"non_compliant"`
+	CompliantPct    float64 `json:"compliant_percent"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] --required TAGS [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --required TAGS -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    TAGS is a comma separated list of required tags: NAMESPACE.KEY for defined tags, KEY for freeform tags.")
+    fmt.Println("    A default value can be given for the defined tags (NAMESPACE.KEY=VALUE): it is used by --fix.")
+    fmt.Println("    Example: --required 'Finance.CostCenter=unknown,Operations.Owner=unknown'")
+    fmt.Println("")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If --summary is provided, the number of resources and non compliant resources is displayed per compartment.")
+    fmt.Println("    If --fix is provided without --yes, the tags that would be added are only listed (dry run).")
+    fmt.Println("    If --fix --yes is provided, the missing defined tags are added to the resources with their default value.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions or tag updates failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// parse the list of required tags (comma separated)
+func parse_required_tags(str string) ([]ocihelpers.TagFilter, error) {
+	tags := make([]ocihelpers.TagFilter, 0)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" { continue }
+		tag, err := ocihelpers.ParseTagFilter(item)
+		if err != nil { return nil, err }
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 { return nil, fmt.Errorf("no required tags") }
+	return tags, nil
+}
+
+// list the resources of a region with missing required tags, and count the resources per compartment
+func check_region(config common.ConfigurationProvider, region string, required []ocihelpers.TagFilter) ([]resource_json, map[string]int, error) {
+	client, err := resourcesearch.NewResourceSearchClientWithConfigurationProvider(config)
+	if err != nil { return nil, nil, err }
+	client.SetRegion(region)
+
+	resources := make([]resource_json, 0)
+	counts := make(map[string]int)
+	request := resourcesearch.SearchResourcesRequest{
+		SearchDetails   : resourcesearch.StructuredSearchDetails{ Query : common.String("query all resources") },
+		Limit           : common.Int(1000),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.SearchResources(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, r := range response.Items {
+			if r.LifecycleState != nil {
+				state := strings.ToUpper(*r.LifecycleState)
+				if state == "TERMINATED" || state == "DELETED" { continue }
+			}
+			counts[*r.CompartmentId]++
+			missing := make([]string, 0)
+			for _, tag := range required {
+				// only the presence of the tag is checked, not its value
+				presence := tag
+				presence.AnyValue = true
+				if !presence.Match(r.FreeformTags, r.DefinedTags) { missing = append(missing, presence.String()) }
+			}
+			if len(missing) == 0 { continue }
+			item := resource_json{ Id : *r.Identifier, ResourceType : *r.ResourceType, Region : region, CompartmentId : *r.CompartmentId, MissingTags : missing }
+			if r.DisplayName != nil { item.Name = *r.DisplayName }
+			resources = append(resources, item)
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, nil, err }
+	return resources, counts, nil
+}
+
+// defined tags (with their default value) to add to a resource: the missing required defined tags
+func tags_to_add(r resource_json, required []ocihelpers.TagFilter) map[string]map[string]interface{} {
+	tags := make(map[string]map[string]interface{})
+	for _, tag := range required {
+		if tag.Namespace == "" || tag.AnyValue { continue }
+		for _, m := range r.MissingTags {
+			if m != tag.Namespace + "." + tag.Key { continue }
+			if tags[tag.Namespace] == nil { tags[tag.Namespace] = make(map[string]interface{}) }
+			tags[tag.Namespace][tag.Key] = tag.Value
+		}
+	}
+	return tags
+}
+
+// add the missing defined tags to the resources, with bulk edit requests per compartment
+// (all the required defined tags are sent: the tags already set are not modified). Returns the number of failed requests
+func fix_resources(config common.ConfigurationProvider, resources []resource_json, required []ocihelpers.TagFilter) int {
+	defined_tags := make(map[string]map[string]interface{})
+	for _, tag := range required {
+		if tag.Namespace == "" || tag.AnyValue { continue }
+		if defined_tags[tag.Namespace] == nil { defined_tags[tag.Namespace] = make(map[string]interface{}) }
+		defined_tags[tag.Namespace][tag.Key] = tag.Value
+	}
+	by_compartment := make(map[string][]ocihelpers.BulkEditResource)
+	compartment_ids := make([]string, 0)
+	for _, r := range resources {
+		if len(tags_to_add(r, required)) == 0 { continue }
+		if by_compartment[r.CompartmentId] == nil { compartment_ids = append(compartment_ids, r.CompartmentId) }
+		by_compartment[r.CompartmentId] = append(by_compartment[r.CompartmentId], ocihelpers.BulkEditResource{ Id : r.Id, ResourceType : r.ResourceType })
+	}
+	nb_failed := 0
+	for _, cpt_id := range compartment_ids {
+		items := by_compartment[cpt_id]
+		for start := 0; start < len(items); start += ocihelpers.BulkEditMaxResources {
+			end := start + ocihelpers.BulkEditMaxResources
+			if end > len(items) { end = len(items) }
+			if err := ocihelpers.BulkAddDefinedTags(config, cpt_id, items[start:end], defined_tags); err != nil {
+				fmt.Fprintf (os.Stderr, "ERROR: compartment %s: %s\n", cpt_id, err)
+				nb_failed++
+				continue
+			}
+			fmt.Printf ("Tags update requested for %d resources in compartment %s\n", end - start, cpt_id)
+		}
+	}
+	return nb_failed
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "check resources in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "check resources in all subscribed regions")
+	required_list   := flag.String("required", "", "comma separated list of required tags")
+	summary         := flag.Bool("summary", false, "display the number of non compliant resources per compartment")
+	fix             := flag.Bool("fix", false, "add the missing defined tags with their default value (with --yes)")
+	yes             := flag.Bool("yes", false, "confirm the update of the tags")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 { usage() }
+	if *yes && !*fix { usage() }
+	required, err := parse_required_tags(*required_list)
+	if err != nil {
+		fmt.Fprintf (os.Stderr, "ERROR: --required: %s\n", err)
+		usage()
+	}
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Check the resources in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	type region_result struct {
+		resources []resource_json
+		counts    map[string]int
+	}
+	ocihelpers.StartProgress("tags compliance", len(regions), 0)
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		resources, counts, err := check_region(config, region, required)
+		return region_result{ resources, counts }, err
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	resources := make([]resource_json, 0)
+	counts := make(map[string]int)
+	nb_resources := 0
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		value := r.Value.(region_result)
+		resources = append(resources, value.resources...)
+		for cpt_id, n := range value.counts {
+			counts[cpt_id] += n
+			nb_resources += n
+		}
+	}
+	for i := range resources {
+		resources[i].CompartmentPath = resources[i].CompartmentId
+		if path, found := paths[resources[i].CompartmentId]; found { resources[i].CompartmentPath = path }
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].CompartmentPath != resources[j].CompartmentPath { return resources[i].CompartmentPath < resources[j].CompartmentPath }
+		if resources[i].ResourceType != resources[j].ResourceType { return resources[i].ResourceType < resources[j].ResourceType }
+		return resources[i].Name < resources[j].Name
+	})
+
+	// Display the number of non compliant resources per compartment
+	if *summary {
+		non_compliant := make(map[string]int)
+		for _, r := range resources { non_compliant[r.CompartmentId]++ }
+		compartments := make([]compartment_json, 0, len(counts))
+		for cpt_id, n := range counts {
+			item := compartment_json{ CompartmentPath : cpt_id, CompartmentId : cpt_id, Resources : n, NonCompliant : non_compliant[cpt_id] }
+			if path, found := paths[cpt_id]; found { item.CompartmentPath = path }
+			item.CompliantPct = 100 * float64(n - item.NonCompliant) / float64(n)
+			compartments = append(compartments, item)
+		}
+		sort.Slice(compartments, func(i, j int) bool { return compartments[i].CompartmentPath < compartments[j].CompartmentPath })
+		if format == "json" {
+			output, err := json.MarshalIndent(compartments, "", "  ")
+			ocihelpers.FatalIfError(err)
+			fmt.Println(string(output))
+		} else {
+			table := ocihelpers.Table{ Headers : []string{ "compartment_path", "resources", "non_compliant", "compliant_%", "ocid" } }
+			for _, c := range compartments {
+				table.AddRow(c.CompartmentPath, fmt.Sprintf("%d", c.Resources), fmt.Sprintf("%d", c.NonCompliant), fmt.Sprintf("%.1f", c.CompliantPct), c.CompartmentId)
+			}
+			ocihelpers.FatalIfError(table.Print(format))
+		}
+	} else if format == "json" {
+		output, err := json.MarshalIndent(resources, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "compartment_path", "resource_type", "name", "missing_tags" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		for _, r := range resources {
+			row := []string{ r.CompartmentPath, r.ResourceType, r.Name, strings.Join(r.MissingTags, ", ") }
+			if all_regions { row = append(row, r.Region) }
+			table.AddRow(append(row, r.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+	}
+	if format == "text" && !ocihelpers.Quiet {
+		fmt.Println ("")
+		fmt.Printf ("%d resources checked, %d with missing required tags\n", nb_resources, len(resources))
+	}
+
+	// Add the missing defined tags
+	if *fix && len(resources) > 0 {
+		nb_fixable := 0
+		for _, r := range resources {
+			if len(tags_to_add(r, required)) > 0 { nb_fixable++ }
+		}
+		fmt.Println ("")
+		switch {
+		case nb_fixable == 0:
+			fmt.Println ("No defined tags with a default value to add (use NAMESPACE.KEY=VALUE in --required)")
+		case !*yes:
+			fmt.Printf ("Re-run the script with --fix --yes to add the missing defined tags to %d resources\n", nb_fixable)
+		default:
+			nb_failed += fix_resources(config, resources, required)
+		}
+	}
+
+	// Partial results if some regions could not be processed or some tags could not be updated
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
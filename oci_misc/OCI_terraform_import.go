@@ -0,0 +1,309 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script generates Terraform import blocks (or terraform import commands) and skeleton resource blocks
+// for existing resources of a OCI tenant using OCI Go SDK, to bring resources created manually (console, CLI)
+// under Terraform management: compartments, VCNs and compute instances
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       VCNs and instances are the ones of the region of the profile (1 Terraform OCI provider per region)
+//       the resource blocks are skeletons with the main arguments only: run terraform plan after the import and
+//       complete them until the plan shows no changes
+//       import blocks need Terraform 1.5 or later (use --commands for older versions)
+//       terminated or deleted resources are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/identity"
+)
+
+// -- constants
+const default_types = "compartments,vcns,instances"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// Terraform address (ex: oci_core_vcn.my_vcn) of the generated resources by OCID, to reference them in other resources
+var addresses = make(map[string]string)
+
+// Terraform names already used per resource type
+var names_used = make(map[string]map[string]bool)
+
+// -- types
+
+// generated resource: import block (or command) and skeleton resource block
+type tf_resource struct {
+	address string        // ex: oci_core_vcn.my_vcn
+	id      string
+	body    []string      // arguments of the resource block (already indented)
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    Terraform import blocks and skeleton resource blocks are generated for the compartments of the tenant and for")
+    fmt.Println("    the VCNs and compute instances of the region of the profile.")
+    fmt.Printf ("    If --types LIST is provided, only these resource types are generated (default: %s).\n", default_types)
+    fmt.Println("    If --compartment PATH is provided, only the resources of this compartment and its sub-compartments are generated")
+    fmt.Println("    (ex: --compartment root/project1).")
+    fmt.Println("    If --commands is provided, terraform import commands are generated instead of import blocks (Terraform < 1.5),")
+    fmt.Println("    as comments before each resource block (run them with: grep '^# terraform import' FILE | cut -c3- | sh).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout (ex: imports.tf).")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// Terraform name from a display name (letters, digits and underscores, unique per resource type)
+func tf_name(resource_type string, display_name string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' { return r }
+		return '_'
+	}, strings.ToLower(display_name))
+	if name == "" || (name[0] >= '0' && name[0] <= '9') { name = "r_" + name }
+
+	if names_used[resource_type] == nil { names_used[resource_type] = make(map[string]bool) }
+	unique := name
+	for n := 2; names_used[resource_type][unique]; n++ { unique = fmt.Sprintf("%s_%d", name, n) }
+	names_used[resource_type][unique] = true
+	return unique
+}
+
+// new generated resource (its address can then be referenced by the next resources)
+func new_tf_resource(resource_type string, display_name string, id string) *tf_resource {
+	r := &tf_resource{ address : resource_type + "." + tf_name(resource_type, display_name), id : id }
+	addresses[id] = r.address
+	return r
+}
+
+// add an argument to the resource block
+func (r *tf_resource) add(format string, args ...interface{}) {
+	r.body = append(r.body, "  " + fmt.Sprintf(format, args...))
+}
+
+// reference to a generated resource (ex: oci_identity_compartment.project1.id), or its OCID if not generated
+func ref(id string) string {
+	if address, found := addresses[id]; found { return address + ".id" }
+	return fmt.Sprintf("%q", id)
+}
+
+// active compartments (not root) of the selected compartment and its sub-compartments, parents first
+func compartments_resources(tree *ocihelpers.CompartmentTree, cpt_ids []string) []*tf_resource {
+	resources := make([]*tf_resource, 0)
+	for _, cpt_id := range cpt_ids {
+		c, found := tree.Get(cpt_id)
+		if !found || c.LifecycleState != identity.CompartmentLifecycleStateActive { continue }
+		r := new_tf_resource("oci_identity_compartment", *c.Name, *c.Id)
+		r.add("compartment_id = %s", ref(*c.CompartmentId))
+		r.add("name           = %q", *c.Name)
+		r.add("description    = %q", *c.Description)
+		resources = append(resources, r)
+	}
+	return resources
+}
+
+// VCNs (available) of some compartments
+func vcns_resources(config common.ConfigurationProvider, cpt_ids []string) ([]*tf_resource, error) {
+	client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+
+	resources := make([]*tf_resource, 0)
+	for _, cpt_id := range cpt_ids {
+		request := core.ListVcnsRequest{ CompartmentId : common.String(cpt_id), LifecycleState : core.VcnLifecycleStateAvailable, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListVcns(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				r := new_tf_resource("oci_core_vcn", *v.DisplayName, *v.Id)
+				r.add("compartment_id = %s", ref(*v.CompartmentId))
+				r.add("display_name   = %q", *v.DisplayName)
+				r.add("cidr_blocks    = [%q]", *v.CidrBlock)
+				if v.DnsLabel != nil { r.add("dns_label      = %q", *v.DnsLabel) }
+				resources = append(resources, r)
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return resources, nil
+}
+
+// compute instances (not terminated) of some compartments
+func instances_resources(config common.ConfigurationProvider, cpt_ids []string) ([]*tf_resource, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	resources := make([]*tf_resource, 0)
+	for _, cpt_id := range cpt_ids {
+		// subnet of the primary VNIC of each instance
+		subnets := make(map[string]string)
+		attachments_request := core.ListVnicAttachmentsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			attachments_request.Page = page
+			response, err := client.ListVnicAttachments(context.Background(), attachments_request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				if a.LifecycleState == core.VnicAttachmentLifecycleStateAttached && a.NicIndex != nil && *a.NicIndex == 0 && a.SubnetId != nil { subnets[*a.InstanceId] = *a.SubnetId }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		request := core.ListInstancesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListInstances(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, i := range response.Items {
+				if i.LifecycleState == core.InstanceLifecycleStateTerminating || i.LifecycleState == core.InstanceLifecycleStateTerminated { continue }
+				r := new_tf_resource("oci_core_instance", *i.DisplayName, *i.Id)
+				r.add("compartment_id      = %s", ref(*i.CompartmentId))
+				r.add("availability_domain = %q", *i.AvailabilityDomain)
+				r.add("display_name        = %q", *i.DisplayName)
+				r.add("shape               = %q", *i.Shape)
+				if strings.HasSuffix(*i.Shape, ".Flex") && i.ShapeConfig != nil && i.ShapeConfig.Ocpus != nil && i.ShapeConfig.MemoryInGBs != nil {
+					r.add("shape_config {")
+					r.add("  ocpus         = %g", *i.ShapeConfig.Ocpus)
+					r.add("  memory_in_gbs = %g", *i.ShapeConfig.MemoryInGBs)
+					r.add("}")
+				}
+				switch source := i.SourceDetails.(type) {
+				case core.InstanceSourceViaImageDetails:
+					r.add("source_details {")
+					r.add("  source_type = \"image\"")
+					r.add("  source_id   = %q", *source.ImageId)
+					r.add("}")
+				case core.InstanceSourceViaBootVolumeDetails:
+					r.add("source_details {")
+					r.add("  source_type = \"bootVolume\"")
+					r.add("  source_id   = %q", *source.BootVolumeId)
+					r.add("}")
+				}
+				if subnet_id, found := subnets[*i.Id]; found {
+					r.add("create_vnic_details {")
+					r.add("  subnet_id = %q", subnet_id)
+					r.add("}")
+				}
+				resources = append(resources, r)
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return resources, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	type_list   := flag.String("types", default_types, "comma separated list of resource types to generate")
+	compartment := flag.String("compartment", "", "only generate the resources of this compartment (full path) and its sub-compartments")
+	commands    := flag.Bool("commands", false, "generate terraform import commands instead of import blocks")
+	output_file := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.Parse()
+	types := make(map[string]bool)
+	for _, t := range strings.Split(*type_list, ",") {
+		types[t] = true
+	}
+	for t := range types {
+		if !strings.Contains(","+default_types+",", ","+t+",") { usage() }
+	}
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+	region, err := config.Region()
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments, and keep the selected ones
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+	top_path := "root"
+	if *compartment != "" {
+		top_id, found := tree.FindByPath(*compartment)
+		if !found { ocihelpers.Fatal("compartment %s not found", *compartment) }
+		top_path = paths[top_id]
+	}
+	cpt_ids := make([]string, 0)
+	for _, cpt_id := range tree.ActiveCompartmentIds() {
+		if paths[cpt_id] == top_path || strings.HasPrefix(paths[cpt_id], top_path + "/") { cpt_ids = append(cpt_ids, cpt_id) }
+	}
+
+	// Generate the resources (compartments first, so that the next resources can reference them)
+	resources := make([]*tf_resource, 0)
+	if types["compartments"] {
+		resources = append(resources, compartments_resources(tree, cpt_ids)...)
+	}
+	if types["vcns"] {
+		vcns, err := vcns_resources(config, cpt_ids)
+		ocihelpers.FatalIfError(err)
+		resources = append(resources, vcns...)
+	}
+	if types["instances"] {
+		instances, err := instances_resources(config, cpt_ids)
+		ocihelpers.FatalIfError(err)
+		resources = append(resources, instances...)
+	}
+
+	// Display the import blocks (or commands) and the skeleton resource blocks
+	fmt.Printf ("# Generated by %s on %s (region %s, compartment %s): %d resources\n", os.Args[0], time.Now().UTC().Format("2006-01-02 15:04:05 UTC"), region, top_path, len(resources))
+	fmt.Println("# Run terraform plan after the import and complete the resource blocks until the plan shows no changes")
+	for _, r := range resources {
+		fmt.Println ("")
+		if *commands {
+			fmt.Printf ("# terraform import %s %s\n", r.address, r.id)
+		} else {
+			fmt.Println ("import {")
+			fmt.Printf ("  to = %s\n", r.address)
+			fmt.Printf ("  id = %q\n", r.id)
+			fmt.Println ("}")
+			fmt.Println ("")
+		}
+		parts := strings.SplitN(r.address, ".", 2)
+		fmt.Printf ("resource %q %q {\n", parts[0], parts[1])
+		for _, line := range r.body { fmt.Println (line) }
+		fmt.Println ("}")
+	}
+}
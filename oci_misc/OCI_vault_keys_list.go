@@ -0,0 +1,340 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the vaults and master encryption keys of the Vault (KMS) service in all compartments of
+// a OCI tenant using OCI Go SDK, with the protection mode (HSM or SOFTWARE) and algorithm of the keys, their
+// creation date and the time since their last rotation (creation of their current key version)
+// Enabled keys not rotated within the rotation window are displayed in red (key rotation compliance)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       keys are listed with the management endpoint of their vault, so only active vaults are processed
+//       deleted keys are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/keymanagement"
+)
+
+// -- constants
+const kms_api_version = "20180608"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// key and key version returned by the KMS management REST API (protection mode and algorithm of the keys
+// are not available in the version of the OCI Go SDK used)
+type key_rest struct {
+	Id             string    `json:"id"`
+	DisplayName    string    `json:"displayName"`
+	CompartmentId  string    `json:"compartmentId"`
+	LifecycleState string    `json:"lifecycleState"`
+	ProtectionMode string    `json:"protectionMode"`
+	Algorithm      string    `json:"algorithm"`
+	TimeCreated    time.Time `json:"timeCreated"`
+}
+
+type key_version_rest struct {
+	Id          string    `json:"id"`
+	TimeCreated time.Time `json:"timeCreated"`
+}
+
+type key_json struct {
+	Name            string `json:"name"`
+	Id              string `json:"id"`
+	LifecycleState  string `json:"lifecycle_state"`
+	ProtectionMode  string `json:"protection_mode"`     // HSM or SOFTWARE
+	Algorithm       string `json:"algorithm"`
+	TimeCreated     string `json:"time_created"`
+	LastRotation    string `json:"last_rotation"`       // creation date of the current key version
+	RotationAgeDays int    `json:"rotation_age_days"`
+	Overdue         bool   `json:"overdue"`             // enabled key not rotated within the rotation window
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+}
+
+type vault_json struct {
+	Name            string     `json:"name"`
+	Id              string     `json:"id"`
+	VaultType       string     `json:"vault_type"`      // DEFAULT or VIRTUAL_PRIVATE
+	LifecycleState  string     `json:"lifecycle_state"`
+	Keys            []key_json `json:"keys"`
+	Region          string     `json:"region"`
+	CompartmentId   string     `json:"compartment_id"`
+	CompartmentPath string     `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each key (vaults without keys are displayed with an empty key). Enabled keys not")
+    fmt.Println("    rotated within the rotation window are displayed in red.")
+    fmt.Println("    If --max-age N is provided, N days is used as rotation window (default 365).")
+    fmt.Println("    If --overdue-only is provided, only the keys not rotated within the rotation window are displayed.")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --no-color is provided, the output is displayed without colors.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the vaults (not deleted) of a compartment
+func list_vaults(client keymanagement.KmsVaultClient, cpt_id string, region string) ([]keymanagement.VaultSummary, error) {
+	vaults := make([]keymanagement.VaultSummary, 0)
+	request := keymanagement.ListVaultsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListVaults(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, v := range response.Items {
+			if v.LifecycleState == keymanagement.VaultSummaryLifecycleStateDeleted { continue }
+			vaults = append(vaults, v)
+		}
+		return response.OpcNextPage, nil
+	})
+	return vaults, err
+}
+
+// list the keys (not deleted) of a vault in a compartment, with the date of their last rotation
+func list_keys(client *ocihelpers.RestClient, cpt_id string) ([]key_json, error) {
+	keys := make([]key_json, 0)
+	query := url.Values{ "compartmentId" : { cpt_id } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []key_rest
+		next_page, err := client.Get("/keys", query, &items)
+		if err != nil { return nil, err }
+		for _, k := range items {
+			if k.LifecycleState == "DELETED" { continue }
+			item := key_json{ Name : k.DisplayName, Id : k.Id, LifecycleState : k.LifecycleState, ProtectionMode : k.ProtectionMode, Algorithm : k.Algorithm,
+				TimeCreated : k.TimeCreated.Format("2006-01-02"), CompartmentId : k.CompartmentId }
+
+			// the most recent key version is the current one
+			last_rotation := k.TimeCreated
+			var versions []key_version_rest
+			versions_query := url.Values{ "sortBy" : { "TIMECREATED" }, "sortOrder" : { "DESC" }, "limit" : { "1" } }
+			if _, err := client.Get("/keys/"+k.Id+"/keyVersions", versions_query, &versions); err != nil { return nil, err }
+			if len(versions) > 0 { last_rotation = versions[0].TimeCreated }
+			item.LastRotation    = last_rotation.Format("2006-01-02")
+			item.RotationAgeDays = ocihelpers.DaysSince(last_rotation)
+			keys = append(keys, item)
+		}
+		return next_page, nil
+	})
+	return keys, err
+}
+
+// list the vaults of all active compartments of a region, with their keys
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]vault_json, error) {
+	client, err := keymanagement.NewKmsVaultClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return list_vaults(client, cpt_id, region)
+	})
+	vaults := make([]vault_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		for _, v := range r.Value.([]keymanagement.VaultSummary) {
+			vault := vault_json{ Name : *v.DisplayName, Id : *v.Id, VaultType : string(v.VaultType), LifecycleState : string(v.LifecycleState),
+				Keys : make([]key_json, 0), Region : region, CompartmentId : *v.CompartmentId }
+			if v.LifecycleState == keymanagement.VaultSummaryLifecycleStateActive {
+				// the keys of a vault can be in any compartment
+				rest_client, err := ocihelpers.NewRestClient(config, *v.ManagementEndpoint, kms_api_version, region)
+				if err != nil { return nil, err }
+				key_results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(key_cpt_id string) (interface{}, error) {
+					return list_keys(rest_client, key_cpt_id)
+				})
+				for _, k := range key_results {
+					if k.Err != nil { return nil, k.Err }
+					vault.Keys = append(vault.Keys, k.Value.([]key_json)...)
+				}
+			}
+			vaults = append(vaults, vault)
+		}
+	}
+	return vaults, nil
+}
+
+// display the table in text format, with the overdue keys in red
+func display_text(table ocihelpers.Table, overdue []bool) {
+	var buffer bytes.Buffer
+	ocihelpers.FatalIfError(table.Fprint(&buffer, "text"))
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if !ocihelpers.Quiet {
+		fmt.Println (lines[0])
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if i >= len(overdue) { break }
+		if overdue[i] { line = ocihelpers.COLOR_RED + line + ocihelpers.COLOR_NORMAL }
+		fmt.Println (line)
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list vaults in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list vaults in all subscribed regions")
+	max_age         := flag.Int("max-age", 365, "rotation window in days")
+	overdue_only    := flag.Bool("overdue-only", false, "only display the keys not rotated within the rotation window")
+	no_color        := flag.Bool("no-color", false, "display output without colors")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *max_age <= 0 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+	ocihelpers.SetupColors(*no_color)
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the vaults and keys in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	// only the regions are counted (the keys of each vault are looked for in all the compartments)
+	ocihelpers.StartProgress("vaults and keys", len(regions), 0)
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	vaults := make([]vault_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, v := range r.Value.([]vault_json) {
+			v.CompartmentPath = paths[v.CompartmentId]
+			keys := make([]key_json, 0)
+			for _, k := range v.Keys {
+				k.CompartmentPath = paths[k.CompartmentId]
+				k.Overdue = k.LifecycleState == string(keymanagement.KeyLifecycleStateEnabled) && k.RotationAgeDays > *max_age
+				if *overdue_only && !k.Overdue { continue }
+				keys = append(keys, k)
+			}
+			if *overdue_only && len(keys) == 0 { continue }
+			sort.SliceStable(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+			v.Keys = keys
+			vaults = append(vaults, v)
+		}
+	}
+	sort.SliceStable(vaults, func(i, j int) bool {
+		if vaults[i].CompartmentPath != vaults[j].CompartmentPath { return vaults[i].CompartmentPath < vaults[j].CompartmentPath }
+		return vaults[i].Name < vaults[j].Name
+	})
+
+	// Display the results (a row per key)
+	if format == "json" {
+		output, err := json.MarshalIndent(vaults, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "vault", "vault_type", "key", "state", "protection", "algorithm", "created", "last_rotation", "rotation_age_days", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		overdue := make([]bool, 0)
+		nb_keys, nb_overdue := 0, 0
+		for _, v := range vaults {
+			if len(v.Keys) == 0 {
+				row := []string{ v.Name, v.VaultType, "", v.LifecycleState, "", "", "", "", "", v.CompartmentPath }
+				if all_regions { row = append(row, v.Region) }
+				table.AddRow(append(row, v.Id)...)
+				overdue = append(overdue, false)
+			}
+			for _, k := range v.Keys {
+				nb_keys++
+				if k.Overdue { nb_overdue++ }
+				row := []string{ v.Name, v.VaultType, k.Name, k.LifecycleState, k.ProtectionMode, k.Algorithm, k.TimeCreated, k.LastRotation, fmt.Sprintf("%d", k.RotationAgeDays), k.CompartmentPath }
+				if all_regions { row = append(row, v.Region) }
+				table.AddRow(append(row, k.Id)...)
+				overdue = append(overdue, k.Overdue)
+			}
+		}
+		if format != "text" {
+			ocihelpers.FatalIfError(table.Print(format))
+		} else {
+			display_text(table, overdue)
+			if !ocihelpers.Quiet {
+				fmt.Println ("")
+				fmt.Printf ("%d vaults, %d keys, %d keys not rotated within %d days\n", len(vaults), nb_keys, nb_overdue, *max_age)
+			}
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,414 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the bastions of the Bastion service and their active sessions in all compartments of a
+// OCI tenant, or creates a session (managed SSH or port forwarding) to a target instance and displays the
+// ssh command to run to connect through the bastion
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the Bastion service is not available in the version of the OCI Go SDK used, so its REST API is
+//       used directly (signed REST requests, see internal/ocihelpers/rest.go)
+//       managed SSH sessions require the Bastion plugin of the Oracle Cloud Agent enabled on the instance
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+)
+
+// -- constants
+const bastion_endpoint    = "https://bastion.{region}.oci.{secondLevelDomain}"
+const bastion_api_version = "20210331"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// bastion and session returned by the Bastion REST API
+type bastion_rest struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	BastionType    string `json:"bastionType"`
+	CompartmentId  string `json:"compartmentId"`
+	TargetVcnId    string `json:"targetVcnId"`
+	TargetSubnetId string `json:"targetSubnetId"`
+	LifecycleState string `json:"lifecycleState"`
+}
+
+type target_rest struct {
+	SessionType                           string `json:"sessionType"`     // MANAGED_SSH or PORT_FORWARDING
+	TargetResourceId                      string `json:"targetResourceId,omitempty"`
+	TargetResourceDisplayName             string `json:"targetResourceDisplayName,omitempty"`
+	TargetResourcePrivateIpAddress        string `json:"targetResourcePrivateIpAddress,omitempty"`
+	TargetResourcePort                    int    `json:"targetResourcePort,omitempty"`
+	TargetResourceOperatingSystemUserName string `json:"targetResourceOperatingSystemUserName,omitempty"`
+}
+
+type session_rest struct {
+	Id                    string            `json:"id"`
+	DisplayName           string            `json:"displayName"`
+	BastionId             string            `json:"bastionId"`
+	LifecycleState        string            `json:"lifecycleState"`
+	LifecycleDetails      string            `json:"lifecycleDetails"`
+	TimeCreated           time.Time         `json:"timeCreated"`
+	SessionTtlInSeconds   int               `json:"sessionTtlInSeconds"`
+	TargetResourceDetails target_rest       `json:"targetResourceDetails"`
+	SshMetadata           map[string]string `json:"sshMetadata"`      // only returned by GET /sessions/{id}
+}
+
+type create_session_rest struct {
+	BastionId             string      `json:"bastionId"`
+	DisplayName           string      `json:"displayName"`
+	KeyType               string      `json:"keyType"`
+	KeyDetails            struct {
+		PublicKeyContent string `json:"publicKeyContent"`
+	} `json:"keyDetails"`
+	SessionTtlInSeconds   int         `json:"sessionTtlInSeconds"`
+	TargetResourceDetails target_rest `json:"targetResourceDetails"`
+}
+
+type session_json struct {
+	Name           string `json:"name"`
+	Id             string `json:"id"`
+	SessionType    string `json:"session_type"`
+	Target         string `json:"target"`             // display name or OCID of the target instance
+	TargetIp       string `json:"target_ip"`
+	TargetPort     int    `json:"target_port"`
+	TargetUser     string `json:"target_user"`        // managed SSH sessions only
+	LifecycleState string `json:"lifecycle_state"`
+	TimeExpiry     string `json:"time_expiry"`
+}
+
+type bastion_json struct {
+	Name            string         `json:"name"`
+	Id              string         `json:"id"`
+	BastionType     string         `json:"bastion_type"`
+	LifecycleState  string         `json:"lifecycle_state"`
+	TargetVcnId     string         `json:"target_vcn_id"`
+	TargetSubnetId  string         `json:"target_subnet_id"`
+	Sessions        []session_json `json:"sessions"`
+	Region          string         `json:"region"`
+	CompartmentId   string         `json:"compartment_id"`
+	CompartmentPath string         `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --bastion BASTION --target TARGET [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    Without --target, the bastions and their active sessions are listed (a row per session).")
+    fmt.Println("    With --target, a session is created on the bastion BASTION (name or OCID) to the target TARGET (OCID of")
+    fmt.Println("    an instance, or private IP address for port forwarding sessions), and the ssh command is displayed when the")
+    fmt.Println("    session is active.")
+    fmt.Println("")
+    fmt.Println("    Options for session creation:")
+    fmt.Println("    If --port-forwarding is provided, a port forwarding session is created instead of a managed SSH session.")
+    fmt.Println("    If --port N is provided, port N of the target is used (default 22).")
+    fmt.Println("    If --local-port N is provided, port N is used on the local machine for port forwarding (default: same as --port).")
+    fmt.Println("    If --user USER is provided, USER is used to connect to the target with managed SSH (default opc).")
+    fmt.Println("    If --public-key FILE is provided, this SSH public key is used (default ~/.ssh/id_rsa.pub). The private key")
+    fmt.Println("    used in the ssh command is the same file without the .pub extension.")
+    fmt.Println("    If --ttl DURATION is provided, the session expires after DURATION (default 3h, max 3h, ex: 30m).")
+    fmt.Println("")
+    fmt.Println("    Options for listing:")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows, the summary line and the progress messages are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the bastions (not deleted) of a compartment
+func list_bastions(client *ocihelpers.RestClient, cpt_id string) ([]bastion_rest, error) {
+	bastions := make([]bastion_rest, 0)
+	query := url.Values{ "compartmentId" : { cpt_id } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []bastion_rest
+		next_page, err := client.Get("/bastions", query, &items)
+		if err != nil { return nil, err }
+		for _, b := range items {
+			if b.LifecycleState != "DELETED" { bastions = append(bastions, b) }
+		}
+		return next_page, nil
+	})
+	return bastions, err
+}
+
+// list the active sessions of a bastion
+func list_sessions(client *ocihelpers.RestClient, bastion_id string) ([]session_json, error) {
+	sessions := make([]session_json, 0)
+	query := url.Values{ "bastionId" : { bastion_id }, "sessionLifecycleState" : { "ACTIVE" } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []session_rest
+		next_page, err := client.Get("/sessions", query, &items)
+		if err != nil { return nil, err }
+		for _, s := range items {
+			t := s.TargetResourceDetails
+			item := session_json{ Name : s.DisplayName, Id : s.Id, SessionType : t.SessionType, Target : t.TargetResourceDisplayName, TargetIp : t.TargetResourcePrivateIpAddress,
+				TargetPort : t.TargetResourcePort, TargetUser : t.TargetResourceOperatingSystemUserName, LifecycleState : s.LifecycleState,
+				TimeExpiry : s.TimeCreated.Add(time.Duration(s.SessionTtlInSeconds) * time.Second).UTC().Format("2006-01-02T15:04:05Z") }
+			if item.Target == "" { item.Target = t.TargetResourceId }
+			sessions = append(sessions, item)
+		}
+		return next_page, nil
+	})
+	return sessions, err
+}
+
+// list the bastions of all active compartments of a region, with their active sessions
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]bastion_json, error) {
+	client, err := ocihelpers.NewRestClient(config, bastion_endpoint, bastion_api_version, region)
+	if err != nil { return nil, err }
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		bastions := make([]bastion_json, 0)
+		cpt_bastions, err := list_bastions(client, cpt_id)
+		if err != nil { return nil, err }
+		for _, b := range cpt_bastions {
+			item := bastion_json{ Name : b.Name, Id : b.Id, BastionType : b.BastionType, LifecycleState : b.LifecycleState, TargetVcnId : b.TargetVcnId,
+				TargetSubnetId : b.TargetSubnetId, Sessions : make([]session_json, 0), Region : region, CompartmentId : b.CompartmentId }
+			if b.LifecycleState == "ACTIVE" {
+				item.Sessions, err = list_sessions(client, b.Id)
+				if err != nil { return nil, err }
+			}
+			bastions = append(bastions, item)
+		}
+		return bastions, nil
+	})
+	bastions := make([]bastion_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		bastions = append(bastions, r.Value.([]bastion_json)...)
+	}
+	return bastions, nil
+}
+
+// find the OCID of a bastion from its name in all active compartments (error if not found or several bastions found)
+// (at most parallelism compartments processed at the same time)
+func find_bastion(client *ocihelpers.RestClient, tree *ocihelpers.CompartmentTree, name string, parallelism int) (string, error) {
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return list_bastions(client, cpt_id)
+	})
+	found := make([]string, 0)
+	for _, r := range results {
+		if r.Err != nil { return "", r.Err }
+		for _, b := range r.Value.([]bastion_rest) {
+			if b.Name == name { found = append(found, b.Id) }
+		}
+	}
+	switch len(found) {
+	case 0:  return "", fmt.Errorf("bastion %s not found", name)
+	case 1:  return found[0], nil
+	default: return "", fmt.Errorf("%d bastions named %s found, use the OCID of the bastion: %s", len(found), name, strings.Join(found, " "))
+	}
+}
+
+// create a session on a bastion and wait for it to be active, then return the ssh command to connect
+func create_session(client *ocihelpers.RestClient, details create_session_rest, private_key string, local_port int) (string, error) {
+	var session session_rest
+	if err := client.Post("/sessions", details, &session); err != nil { return "", err }
+	if !ocihelpers.Quiet { fmt.Printf ("Session %s created, waiting for it to be active...\n", session.Id) }
+	err := ocihelpers.WaitFor(5*time.Second, 5*time.Minute, func() (bool, error) {
+		if _, err := client.Get("/sessions/"+session.Id, nil, &session); err != nil { return false, err }
+		if session.LifecycleState == "FAILED" || session.LifecycleState == "DELETED" {
+			return false, fmt.Errorf("session %s is %s: %s", session.Id, session.LifecycleState, session.LifecycleDetails)
+		}
+		return session.LifecycleState == "ACTIVE", nil
+	})
+	if err != nil { return "", err }
+	command := session.SshMetadata["command"]
+	if command == "" { return "", fmt.Errorf("no ssh command returned for session %s", session.Id) }
+	command = strings.Replace(command, "<privateKey>", private_key, -1)
+	command = strings.Replace(command, "<localPort>", fmt.Sprintf("%d", local_port), -1)
+	return command, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list bastions in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list bastions in all subscribed regions")
+	bastion         := flag.String("bastion", "", "name or OCID of the bastion used to create a session")
+	target          := flag.String("target", "", "OCID of the target instance (or private IP address for port forwarding)")
+	port_forwarding := flag.Bool("port-forwarding", false, "create a port forwarding session instead of a managed SSH session")
+	port            := flag.Int("port", 22, "port of the target")
+	local_port      := flag.Int("local-port", 0, "local port for port forwarding (default: same as --port)")
+	user            := flag.String("user", "opc", "user to connect to the target with managed SSH")
+	home, _         := os.UserHomeDir()
+	public_key      := flag.String("public-key", filepath.Join(home, ".ssh", "id_rsa.pub"), "SSH public key file")
+	ttl             := flag.Duration("ttl", 3*time.Hour, "duration of the session (max 3h)")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if (*bastion == "") != (*target == "") { usage() }
+	if *ttl < 30*time.Minute || *ttl > 3*time.Hour { ocihelpers.Fatal ("invalid session duration %s (between 30m and 3h)", *ttl) }
+	if !*port_forwarding && !strings.HasPrefix(*target, "ocid1.") && *target != "" { ocihelpers.Fatal ("the target of a managed SSH session must be the OCID of an instance") }
+	if *local_port == 0 { *local_port = *port }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Create a session on a bastion (region of the bastion OCID or of the profile)
+	if *target != "" {
+		region, err := config.Region()
+		ocihelpers.FatalIfError(err)
+		if fields := strings.Split(*bastion, "."); strings.HasPrefix(*bastion, "ocid1.bastion.") && len(fields) == 5 && fields[3] != "" {
+			region = string(common.StringToRegion(fields[3]))
+		}
+		client, err := ocihelpers.NewRestClient(config, bastion_endpoint, bastion_api_version, region)
+		ocihelpers.FatalIfError(err)
+		bastion_id := *bastion
+		if !strings.HasPrefix(*bastion, "ocid1.bastion.") {
+			bastion_id, err = find_bastion(client, tree, *bastion, *parallelism)
+			ocihelpers.FatalIfError(err)
+		}
+		key, err := ioutil.ReadFile(*public_key)
+		ocihelpers.FatalIfError(err)
+
+		details := create_session_rest{ BastionId : bastion_id, DisplayName : "session-" + time.Now().UTC().Format("20060102-150405"), KeyType : "PUB",
+			SessionTtlInSeconds : int(ttl.Seconds()) }
+		details.KeyDetails.PublicKeyContent = strings.TrimSpace(string(key))
+		details.TargetResourceDetails = target_rest{ SessionType : "MANAGED_SSH", TargetResourceId : *target, TargetResourcePort : *port, TargetResourceOperatingSystemUserName : *user }
+		if *port_forwarding {
+			details.TargetResourceDetails = target_rest{ SessionType : "PORT_FORWARDING", TargetResourcePort : *port }
+			if strings.HasPrefix(*target, "ocid1.") {
+				details.TargetResourceDetails.TargetResourceId = *target
+			} else {
+				details.TargetResourceDetails.TargetResourcePrivateIpAddress = *target
+			}
+		}
+		command, err := create_session(client, details, strings.TrimSuffix(*public_key, ".pub"), *local_port)
+		ocihelpers.FatalIfError(err)
+		fmt.Println (command)
+		return
+	}
+
+	// Get the bastions in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("bastions", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	bastions := make([]bastion_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, b := range r.Value.([]bastion_json) {
+			b.CompartmentPath = paths[b.CompartmentId]
+			bastions = append(bastions, b)
+		}
+	}
+	sort.SliceStable(bastions, func(i, j int) bool {
+		if bastions[i].CompartmentPath != bastions[j].CompartmentPath { return bastions[i].CompartmentPath < bastions[j].CompartmentPath }
+		return bastions[i].Name < bastions[j].Name
+	})
+
+	// Display the results (a row per session)
+	if format == "json" {
+		output, err := json.MarshalIndent(bastions, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "bastion", "state", "session", "session_type", "target", "target_ip", "port", "user", "expiry", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_sessions := 0
+		for _, b := range bastions {
+			if len(b.Sessions) == 0 {
+				row := []string{ b.Name, b.LifecycleState, "", "", "", "", "", "", "", b.CompartmentPath }
+				if all_regions { row = append(row, b.Region) }
+				table.AddRow(append(row, b.Id)...)
+			}
+			for _, s := range b.Sessions {
+				nb_sessions++
+				row := []string{ b.Name, b.LifecycleState, s.Name, s.SessionType, s.Target, s.TargetIp, fmt.Sprintf("%d", s.TargetPort), s.TargetUser, s.TimeExpiry, b.CompartmentPath }
+				if all_regions { row = append(row, b.Region) }
+				table.AddRow(append(row, s.Id)...)
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d bastions, %d active sessions\n", len(bastions), nb_sessions)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
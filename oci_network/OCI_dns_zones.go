@@ -0,0 +1,350 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the DNS zones in all compartments of a OCI tenant using OCI Go SDK, and can export
+// the records of a zone (or of all zones) in BIND zone file or CSV format (backup, migration)
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       public zones (GLOBAL scope) and private zones (PRIVATE scope, region of the profile) are processed
+//       the DNS REST API is used directly (the zone scope is not supported by the OCI Go SDK version used)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: List the private zones too (scope column), export all zones in a single JSON object
+//    2026-10-15: Display a progress indicator on stderr during the scan
+//    2026-10-15: Export all zones in a single CSV table with a zone column (valid CSV on stdout)
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- constants
+const dns_endpoint    = "https://dns.{region}.oci.{secondLevelDomain}"
+const dns_api_version = "20180115"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// zone and record returned by the DNS REST API
+type zone_rest struct {
+	Name           string `json:"name"`
+	Id             string `json:"id"`
+	ZoneType       string `json:"zoneType"`
+	Scope          string `json:"scope"`
+	Serial         int64  `json:"serial"`
+	LifecycleState string `json:"lifecycleState"`
+	CompartmentId  string `json:"compartmentId"`
+}
+
+type record_rest struct {
+	Domain string `json:"domain"`
+	Ttl    int    `json:"ttl"`
+	Rtype  string `json:"rtype"`
+	Rdata  string `json:"rdata"`
+}
+
+type zone_json struct {
+	Name            string `json:"name"`
+	Id              string `json:"id"`
+	ZoneType        string `json:"zone_type"`
+	Scope           string `json:"scope"`         // GLOBAL (public zone) or PRIVATE
+	Serial          int64  `json:"serial"`
+	LifecycleState  string `json:"lifecycle_state"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+}
+
+type record_json struct {
+	Domain string `json:"domain"`
+	Ttl    int    `json:"ttl"`
+	Rtype  string `json:"rtype"`
+	Rdata  string `json:"rdata"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --export ZONE [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] --export-all [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip ...\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, the DNS zones are listed: public zones (GLOBAL scope) and private zones (PRIVATE scope) of the")
+    fmt.Println("    region of the profile.")
+    fmt.Println("    If --export ZONE is provided, the records of the zone (name or OCID) are exported in BIND zone file format.")
+    fmt.Println("    If --export-all is provided, the records of all the zones are exported.")
+    fmt.Println("    If --output-dir DIR is provided with --export or --export-all, the records of each zone are written")
+    fmt.Println("    to a file in DIR (ZONE.zone, or ZONE.csv with -csv, ZONE.private.zone for private zones) instead of stdout.")
+    fmt.Println("    If -json is provided, the zones or records are displayed in JSON format (with --export-all, a single JSON")
+    fmt.Println("    object with the records of each zone, keyed by zone name, ZONE.private for private zones).")
+    fmt.Println("    If -csv is provided, the zones or records are displayed in CSV format (with a header row; with --export-all,")
+    fmt.Println("    a single table with a zone column, ZONE.private for private zones).")
+    fmt.Println("    If --markdown is provided, the zones are displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the comments of the zone files are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the DNS zones (not deleted) of a scope (GLOBAL or PRIVATE) in a compartment
+func list_zones(client *ocihelpers.RestClient, cpt_id string, scope string) ([]zone_json, error) {
+	zones := make([]zone_json, 0)
+	query := url.Values{ "compartmentId" : { cpt_id }, "scope" : { scope } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var items []zone_rest
+		next_page, err := client.Get("/zones", query, &items)
+		if err != nil { return nil, err }
+		for _, z := range items {
+			if z.LifecycleState == "DELETED" { continue }
+			zones = append(zones, zone_json{ Name : z.Name, Id : z.Id, ZoneType : z.ZoneType, Scope : scope, Serial : z.Serial,
+				LifecycleState : z.LifecycleState, CompartmentId : z.CompartmentId })
+		}
+		return next_page, nil
+	})
+	return zones, err
+}
+
+// list the public and private DNS zones in all active compartments (compartments processed concurrently)
+func list_all_zones(client *ocihelpers.RestClient, tree *ocihelpers.CompartmentTree, parallelism int) ([]zone_json, error) {
+	zones := make([]zone_json, 0)
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_zones := make([]zone_json, 0)
+		for _, scope := range []string{ "GLOBAL", "PRIVATE" } {
+			scope_zones, err := list_zones(client, cpt_id, scope)
+			if err != nil { return nil, err }
+			cpt_zones = append(cpt_zones, scope_zones...)
+		}
+		return cpt_zones, nil
+	})
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		zones = append(zones, r.Value.([]zone_json)...)
+	}
+	sort.SliceStable(zones, func(i, j int) bool {
+		if zones[i].Name != zones[j].Name { return zones[i].Name < zones[j].Name }
+		return zones[i].Scope < zones[j].Scope
+	})
+	return zones, nil
+}
+
+// get the records of a zone (sorted by domain and type, like the OCI console)
+func get_records(client *ocihelpers.RestClient, zone zone_json) ([]record_json, error) {
+	records := make([]record_json, 0)
+	query := url.Values{ "compartmentId" : { zone.CompartmentId }, "scope" : { zone.Scope }, "sortBy" : { "domain" } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var collection struct { Items []record_rest `json:"items"` }
+		next_page, err := client.Get("/zones/"+url.PathEscape(zone.Id)+"/records", query, &collection)
+		if err != nil { return nil, err }
+		for _, r := range collection.Items {
+			records = append(records, record_json{ Domain : r.Domain, Ttl : r.Ttl, Rtype : r.Rtype, Rdata : r.Rdata })
+		}
+		return next_page, nil
+	})
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Domain != records[j].Domain { return records[i].Domain < records[j].Domain }
+		return records[i].Rtype == "SOA" && records[j].Rtype != "SOA"
+	})
+	return records, err
+}
+
+// key of a zone in the JSON export of all zones and name of its export file (without extension): the name of
+// the zone, with a .private suffix for private zones (a public and a private zone can have the same name)
+func zone_key(zone zone_json) string {
+	if zone.Scope == "PRIVATE" { return zone.Name + ".private" }
+	return zone.Name
+}
+
+// fully qualified domain name (with a trailing dot)
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") { return name }
+	return name + "."
+}
+
+// write the records of a zone in BIND zone file format (SOA record first)
+func write_zone_file(w io.Writer, zone zone_json, records []record_json) {
+	if !ocihelpers.Quiet {
+		fmt.Fprintf (w, "; zone %s (%s) exported from OCI on %s (serial %d)\n", zone.Name, zone.Scope, time.Now().UTC().Format("2006-01-02 15:04:05 UTC"), zone.Serial)
+		fmt.Fprintf (w, "; compartment %s\n", zone.CompartmentPath)
+	}
+	fmt.Fprintf (w, "$ORIGIN %s\n", fqdn(zone.Name))
+	for _, soa := range []bool{ true, false } {
+		for _, r := range records {
+			if (r.Rtype == "SOA") != soa { continue }
+			fmt.Fprintf (w, "%s\t%d\tIN\t%s\t%s\n", fqdn(r.Domain), r.Ttl, r.Rtype, r.Rdata)
+		}
+	}
+}
+
+// write the records of a zone in the selected format
+func write_records(w io.Writer, zone zone_json, records []record_json, format string) error {
+	switch format {
+	case "zone":
+		write_zone_file(w, zone, records)
+		return nil
+	case "json":
+		output, err := json.MarshalIndent(records, "", "  ")
+		if err != nil { return err }
+		fmt.Fprintln(w, string(output))
+		return nil
+	}
+	table := ocihelpers.Table{ Headers : []string{ "domain", "ttl", "rtype", "rdata" } }
+	for _, r := range records { table.AddRow(r.Domain, fmt.Sprintf("%d", r.Ttl), r.Rtype, r.Rdata) }
+	return table.Fprint(w, format)
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	export_zone     := flag.String("export", "", "export the records of this zone (name or OCID)")
+	export_all      := flag.Bool("export-all", false, "export the records of all the zones")
+	output_dir      := flag.String("output-dir", "", "write the records of each zone to a file in this directory")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and comments")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	export := *export_zone != "" || *export_all
+	if *export_zone != "" && *export_all { usage() }
+	if *output_dir != "" && !export { usage() }
+	if export {
+		if format == "markdown" { usage() }
+		if format == "text" { format = "zone" }
+	}
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of DNS zones (public zones are global resources, private zones are listed in the region of the profile)
+	region, err := config.Region()
+	ocihelpers.FatalIfError(err)
+	client, err := ocihelpers.NewRestClient(config, dns_endpoint, dns_api_version, region)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("DNS zones", 1, len(tree.ActiveCompartmentIds()))
+	zones, err := list_all_zones(client, tree, *parallelism)
+	ocihelpers.StopProgress()
+	ocihelpers.FatalIfError(err)
+	for i := range zones { zones[i].CompartmentPath = paths[zones[i].CompartmentId] }
+
+	// List the zones
+	if !export {
+		if format == "json" {
+			output, err := json.MarshalIndent(zones, "", "  ")
+			ocihelpers.FatalIfError(err)
+			fmt.Println(string(output))
+			return
+		}
+		table := ocihelpers.Table{ Headers : []string{ "name", "scope", "zone_type", "serial", "state", "compartment_path", "ocid" } }
+		for _, z := range zones { table.AddRow(z.Name, z.Scope, z.ZoneType, fmt.Sprintf("%d", z.Serial), z.LifecycleState, z.CompartmentPath, z.Id) }
+		ocihelpers.FatalIfError(table.Print(format))
+		return
+	}
+
+	// Export the records of a zone or of all zones
+	if *export_zone != "" {
+		selected := make([]zone_json, 0)
+		for _, z := range zones {
+			if z.Name == strings.TrimSuffix(*export_zone, ".") || z.Id == *export_zone { selected = append(selected, z) }
+		}
+		if len(selected) == 0 { ocihelpers.Fatal("zone %s not found", *export_zone) }
+		zones = selected
+	}
+	if *output_dir != "" { ocihelpers.FatalIfError(os.MkdirAll(ocihelpers.ExpandPath(*output_dir), 0755)) }
+
+	// JSON export of several zones to stdout: a single JSON object (records of each zone keyed by zone name)
+	if format == "json" && *output_dir == "" && len(zones) > 1 {
+		all_records := make(map[string][]record_json)
+		for _, zone := range zones {
+			records, err := get_records(client, zone)
+			ocihelpers.FatalIfError(err)
+			all_records[zone_key(zone)] = records
+		}
+		output, err := json.MarshalIndent(all_records, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+
+	// CSV export of several zones to stdout: a single table with a zone column (zone name, ZONE.private for private zones)
+	if format == "csv" && *output_dir == "" && len(zones) > 1 {
+		table := ocihelpers.Table{ Headers : []string{ "zone", "domain", "ttl", "rtype", "rdata" } }
+		for _, zone := range zones {
+			records, err := get_records(client, zone)
+			ocihelpers.FatalIfError(err)
+			for _, r := range records { table.AddRow(zone_key(zone), r.Domain, fmt.Sprintf("%d", r.Ttl), r.Rtype, r.Rdata) }
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		return
+	}
+	for i, zone := range zones {
+		records, err := get_records(client, zone)
+		ocihelpers.FatalIfError(err)
+		if *output_dir == "" {
+			if i > 0 { fmt.Println ("") }
+			ocihelpers.FatalIfError(write_records(os.Stdout, zone, records, format))
+			continue
+		}
+		extension := map[string]string{ "zone" : ".zone", "csv" : ".csv", "json" : ".json" }[format]
+		file_name := filepath.Join(ocihelpers.ExpandPath(*output_dir), zone_key(zone)+extension)
+		f, err := os.Create(file_name)
+		ocihelpers.FatalIfError(err)
+		ocihelpers.FatalIfError(write_records(f, zone, records, format))
+		ocihelpers.FatalIfError(f.Close())
+		if !ocihelpers.Quiet { fmt.Printf ("%s: %d records exported to %s\n", zone.Name, len(records), file_name) }
+	}
+}
@@ -0,0 +1,389 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the load balancers (LBs) and network load balancers (NLBs) in all compartments of a OCI tenant
+// using OCI Go SDK, with their shape, IP addresses, listeners, backend sets and the health status of each backend
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the network load balancer API is not available in the OCI Go SDK version used, so it is called directly
+//       (signed REST requests)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/loadbalancer"
+)
+
+// -- constants
+const nlb_endpoint    = "https://network-load-balancer-api.{region}.oci.{secondLevelDomain}"
+const nlb_api_version = "20200501"
+const health_ok       = "OK"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type backend_json struct {
+	Name   string `json:"name"`
+	Health string `json:"health"`
+}
+
+type backend_set_json struct {
+	Name     string         `json:"name"`
+	Policy   string         `json:"policy"`
+	Health   string         `json:"health"`
+	Backends []backend_json `json:"backends"`
+}
+
+type listener_json struct {
+	Name       string `json:"name"`
+	Protocol   string `json:"protocol"`
+	Port       int    `json:"port"`
+	BackendSet string `json:"backend_set"`
+}
+
+type lb_json struct {
+	Type            string             `json:"type"`
+	Name            string             `json:"name"`
+	Id              string             `json:"id"`
+	Shape           string             `json:"shape"`
+	LifecycleState  string             `json:"lifecycle_state"`
+	IpAddresses     []string           `json:"ip_addresses"`
+	Listeners       []listener_json    `json:"listeners"`
+	BackendSets     []backend_set_json `json:"backend_sets"`
+	Region          string             `json:"region"`
+	CompartmentId   string             `json:"compartment_id"`
+	CompartmentPath string             `json:"compartment_path"`
+}
+
+// network load balancer returned by the REST API
+type nlb_rest struct {
+	Id             string `json:"id"`
+	DisplayName    string `json:"displayName"`
+	CompartmentId  string `json:"compartmentId"`
+	LifecycleState string `json:"lifecycleState"`
+	IpAddresses    []struct {
+		IpAddress string `json:"ipAddress"`
+		IsPublic  bool   `json:"isPublic"`
+	} `json:"ipAddresses"`
+	Listeners map[string]struct {
+		Name                  string `json:"name"`
+		DefaultBackendSetName string `json:"defaultBackendSetName"`
+		Port                  int    `json:"port"`
+		Protocol              string `json:"protocol"`
+	} `json:"listeners"`
+	BackendSets map[string]struct {
+		Name     string `json:"name"`
+		Policy   string `json:"policy"`
+		Backends []struct {
+			Name string `json:"name"`
+		} `json:"backends"`
+	} `json:"backendSets"`
+}
+
+// health of a backend set returned by the APIs of LBs and NLBs
+type backend_set_health struct {
+	Status                    string   `json:"status"`
+	WarningStateBackendNames  []string `json:"warningStateBackendNames"`
+	CriticalStateBackendNames []string `json:"criticalStateBackendNames"`
+	UnknownStateBackendNames  []string `json:"unknownStateBackendNames"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    One row is displayed per backend, with its health status (OK, WARNING, CRITICAL or UNKNOWN).")
+    fmt.Println("")
+    fmt.Println("    If --unhealthy is provided, only the backends whose health status is not OK are listed.")
+    fmt.Println("    If -a or --all-regions is provided, the load balancers in all subscribed regions are listed.")
+    fmt.Println("    If -json is provided, the load balancers are displayed in JSON format (with listeners and backend sets).")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// health of a backend from the health of its backend set
+func backend_health(name string, health backend_set_health) string {
+	for status, names := range map[string][]string{ "WARNING" : health.WarningStateBackendNames, "CRITICAL" : health.CriticalStateBackendNames, "UNKNOWN" : health.UnknownStateBackendNames } {
+		for _, n := range names {
+			if n == name { return status }
+		}
+	}
+	return health_ok
+}
+
+// sort the listeners and backend sets of a load balancer (maps in the API responses)
+func sort_lb(lb *lb_json) {
+	sort.Slice(lb.Listeners, func(i, j int) bool { return lb.Listeners[i].Name < lb.Listeners[j].Name })
+	sort.Slice(lb.BackendSets, func(i, j int) bool { return lb.BackendSets[i].Name < lb.BackendSets[j].Name })
+}
+
+// list the load balancers in a compartment, with the health of their backends
+func list_lbs(client loadbalancer.LoadBalancerClient, cpt_id string, region string) ([]lb_json, error) {
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	lbs := make([]loadbalancer.LoadBalancer, 0)
+	request := loadbalancer.ListLoadBalancersRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListLoadBalancers(context.Background(), request)
+		if err != nil { return nil, err }
+		lbs = append(lbs, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	items := make([]lb_json, 0)
+	for _, lb := range lbs {
+		if lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleted { continue }
+		item := lb_json{ Type : "LB", Name : *lb.DisplayName, Id : *lb.Id, Shape : *lb.ShapeName, LifecycleState : string(lb.LifecycleState), IpAddresses : make([]string, 0), Listeners : make([]listener_json, 0), BackendSets : make([]backend_set_json, 0), Region : region, CompartmentId : *lb.CompartmentId }
+		for _, ip := range lb.IpAddresses { item.IpAddresses = append(item.IpAddresses, *ip.IpAddress) }
+		for _, l := range lb.Listeners {
+			item.Listeners = append(item.Listeners, listener_json{ *l.Name, *l.Protocol, *l.Port, *l.DefaultBackendSetName })
+		}
+		for _, bs := range lb.BackendSets {
+			response, err := client.GetBackendSetHealth(context.Background(), loadbalancer.GetBackendSetHealthRequest{ LoadBalancerId : lb.Id, BackendSetName : bs.Name, RequestMetadata : metadata })
+			if err != nil { return nil, err }
+			health := backend_set_health{ string(response.Status), response.WarningStateBackendNames, response.CriticalStateBackendNames, response.UnknownStateBackendNames }
+			backend_set := backend_set_json{ Name : *bs.Name, Policy : *bs.Policy, Health : health.Status, Backends : make([]backend_json, 0) }
+			for _, b := range bs.Backends { backend_set.Backends = append(backend_set.Backends, backend_json{ *b.Name, backend_health(*b.Name, health) }) }
+			item.BackendSets = append(item.BackendSets, backend_set)
+		}
+		sort_lb(&item)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// list the network load balancers in a compartment, with the health of their backends
+func list_nlbs(client *ocihelpers.RestClient, cpt_id string, region string) ([]lb_json, error) {
+	type nlb_collection struct {
+		Items []struct {
+			Id             string `json:"id"`
+			LifecycleState string `json:"lifecycleState"`
+		} `json:"items"`
+	}
+	ids := make([]string, 0)
+	query := url.Values{ "compartmentId" : { cpt_id } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var collection nlb_collection
+		next_page, err := client.Get("/networkLoadBalancers", query, &collection)
+		if err != nil { return nil, err }
+		for _, nlb := range collection.Items {
+			if nlb.LifecycleState != "DELETED" { ids = append(ids, nlb.Id) }
+		}
+		return next_page, nil
+	})
+	if err != nil { return nil, err }
+
+	items := make([]lb_json, 0)
+	for _, id := range ids {
+		var nlb nlb_rest
+		if _, err := client.Get("/networkLoadBalancers/"+id, nil, &nlb); err != nil { return nil, err }
+		item := lb_json{ Type : "NLB", Name : nlb.DisplayName, Id : nlb.Id, Shape : "network", LifecycleState : nlb.LifecycleState, IpAddresses : make([]string, 0), Listeners : make([]listener_json, 0), BackendSets : make([]backend_set_json, 0), Region : region, CompartmentId : nlb.CompartmentId }
+		for _, ip := range nlb.IpAddresses { item.IpAddresses = append(item.IpAddresses, ip.IpAddress) }
+		for _, l := range nlb.Listeners {
+			item.Listeners = append(item.Listeners, listener_json{ l.Name, l.Protocol, l.Port, l.DefaultBackendSetName })
+		}
+		for _, bs := range nlb.BackendSets {
+			var health backend_set_health
+			if _, err := client.Get("/networkLoadBalancers/"+id+"/backendSets/"+url.PathEscape(bs.Name)+"/health", nil, &health); err != nil { return nil, err }
+			backend_set := backend_set_json{ Name : bs.Name, Policy : bs.Policy, Health : health.Status, Backends : make([]backend_json, 0) }
+			for _, b := range bs.Backends { backend_set.Backends = append(backend_set.Backends, backend_json{ b.Name, backend_health(b.Name, health) }) }
+			item.BackendSets = append(item.BackendSets, backend_set)
+		}
+		sort_lb(&item)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// list the LBs and NLBs in all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]lb_json, error) {
+	lb_client, err := loadbalancer.NewLoadBalancerClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	lb_client.SetRegion(region)
+	nlb_client, err := ocihelpers.NewRestClient(config, nlb_endpoint, nlb_api_version, region)
+	if err != nil { return nil, err }
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		lbs, err := list_lbs(lb_client, cpt_id, region)
+		if err != nil { return nil, err }
+		nlbs, err := list_nlbs(nlb_client, cpt_id, region)
+		if err != nil { return nil, err }
+		return append(lbs, nlbs...), nil
+	})
+	items := make([]lb_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		items = append(items, r.Value.([]lb_json)...)
+	}
+	return items, nil
+}
+
+// listeners using a backend set (name:protocol/port)
+func backend_set_listeners(lb lb_json, backend_set string) string {
+	listeners := make([]string, 0)
+	for _, l := range lb.Listeners {
+		if l.BackendSet == backend_set { listeners = append(listeners, fmt.Sprintf("%s:%s/%d", l.Name, l.Protocol, l.Port)) }
+	}
+	return strings.Join(listeners, " ")
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list load balancers in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list load balancers in all subscribed regions")
+	unhealthy_only  := flag.Bool("unhealthy", false, "only list the backends whose health status is not OK")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of load balancers in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("load balancers", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	lbs := make([]lb_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, lb := range r.Value.([]lb_json) {
+			lb.CompartmentPath = paths[lb.CompartmentId]
+			lbs = append(lbs, lb)
+		}
+	}
+	sort.SliceStable(lbs, func(i, j int) bool {
+		if lbs[i].CompartmentPath != lbs[j].CompartmentPath { return lbs[i].CompartmentPath < lbs[j].CompartmentPath }
+		return lbs[i].Name < lbs[j].Name
+	})
+
+	// Only keep the unhealthy backends (and the load balancers having some)
+	if *unhealthy_only {
+		unhealthy_lbs := make([]lb_json, 0)
+		for _, lb := range lbs {
+			backend_sets := make([]backend_set_json, 0)
+			for _, bs := range lb.BackendSets {
+				backends := make([]backend_json, 0)
+				for _, b := range bs.Backends {
+					if b.Health != health_ok { backends = append(backends, b) }
+				}
+				if len(backends) > 0 { bs.Backends = backends; backend_sets = append(backend_sets, bs) }
+			}
+			if len(backend_sets) > 0 { lb.BackendSets = backend_sets; unhealthy_lbs = append(unhealthy_lbs, lb) }
+		}
+		lbs = unhealthy_lbs
+	}
+
+	// Display the results (one row per backend)
+	if format == "json" {
+		output, err := json.MarshalIndent(lbs, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "compartment_path", "type", "name", "shape", "ip_addresses", "backend_set", "listeners", "backend", "health" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		nb_backends, nb_unhealthy := 0, 0
+		for _, lb := range lbs {
+			add_row := func(backend_set, listeners, backend, health string) {
+				row := []string{ lb.CompartmentPath, lb.Type, lb.Name, lb.Shape, strings.Join(lb.IpAddresses, " "), backend_set, listeners, backend, health }
+				if all_regions { row = append(row, lb.Region) }
+				table.AddRow(row...)
+			}
+			if len(lb.BackendSets) == 0 { add_row("", "", "", "") }
+			for _, bs := range lb.BackendSets {
+				if len(bs.Backends) == 0 { add_row(bs.Name, backend_set_listeners(lb, bs.Name), "", bs.Health) }
+				for _, b := range bs.Backends {
+					add_row(bs.Name, backend_set_listeners(lb, bs.Name), b.Name, b.Health)
+					nb_backends++
+					if b.Health != health_ok { nb_unhealthy++ }
+				}
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d load balancers, %d backends (%d not OK)\n", len(lbs), nb_backends, nb_unhealthy)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
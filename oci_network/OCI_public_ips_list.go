@@ -0,0 +1,356 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists all the public IP addresses (ephemeral and reserved) in all compartments of a OCI tenant
+// using OCI Go SDK, with the resource they are bound to (compute instance, load balancer, NAT gateway, VNIC)
+// The reserved public IPs not assigned to any resource are flagged (they are charged when unassigned)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the public IPs of load balancers and NAT gateways are also listed when they are not returned by the
+//       public IP API (ephemeral IPs)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/loadbalancer"
+)
+
+// -- constants
+const status_unassigned = "UNASSIGNED"
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type public_ip_json struct {
+	IpAddress       string `json:"ip_address"`
+	Id              string `json:"id"`
+	Lifetime        string `json:"lifetime"`
+	Scope           string `json:"scope"`
+	LifecycleState  string `json:"lifecycle_state"`
+	BoundToType     string `json:"bound_to_type"`
+	BoundToName     string `json:"bound_to_name"`
+	BoundToId       string `json:"bound_to_id"`
+	Status          string `json:"status"`
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+}
+
+// resource a public IP can be bound to
+type resource struct {
+	kind           string
+	name           string
+	id             string
+	compartment_id string
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If --unassigned is provided, only the reserved public IPs not assigned to any resource are listed.")
+    fmt.Println("    If -a or --all-regions is provided, the public IPs in all subscribed regions are listed.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the public IPs in a compartment (regional ones, and ephemeral ones in each availability domain)
+func list_public_ips(client core.VirtualNetworkClient, cpt_id string, ads []string) ([]core.PublicIp, error) {
+	public_ips := make([]core.PublicIp, 0)
+	requests := []core.ListPublicIpsRequest{ { Scope : core.ListPublicIpsScopeRegion } }
+	for _, ad := range ads {
+		requests = append(requests, core.ListPublicIpsRequest{ Scope : core.ListPublicIpsScopeAvailabilityDomain, AvailabilityDomain : common.String(ad), Lifetime : core.ListPublicIpsLifetimeEphemeral })
+	}
+	for _, request := range requests {
+		request.CompartmentId = common.String(cpt_id)
+		request.RequestMetadata = common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListPublicIps(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, p := range response.Items {
+				if p.LifecycleState != core.PublicIpLifecycleStateTerminated { public_ips = append(public_ips, p) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return public_ips, nil
+}
+
+// list the public IPs in all active compartments of a region, with the resources they are bound to
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]public_ip_json, error) {
+	network_client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	network_client.SetRegion(region)
+	compute_client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	compute_client.SetRegion(region)
+	lb_client, err := loadbalancer.NewLoadBalancerClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	lb_client.SetRegion(region)
+	ads, err := ocihelpers.ListAvailabilityDomains(config, region)
+	if err != nil { return nil, err }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	type compartment_items struct {
+		public_ips []core.PublicIp
+		instances  map[string]resource
+		nat_gws    map[string]resource
+		by_address map[string]resource
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		instances  := make(map[string]resource)
+		nat_gws    := make(map[string]resource)
+		by_address := make(map[string]resource)
+		public_ips, err := list_public_ips(network_client, cpt_id, ads)
+		if err != nil { return nil, err }
+
+		// compute instances and their VNICs
+		names := make(map[string]string)
+		instances_request := core.ListInstancesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			instances_request.Page = page
+			response, err := compute_client.ListInstances(context.Background(), instances_request)
+			if err != nil { return nil, err }
+			for _, i := range response.Items { names[*i.Id] = *i.DisplayName }
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		attachments_request := core.ListVnicAttachmentsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			attachments_request.Page = page
+			response, err := compute_client.ListVnicAttachments(context.Background(), attachments_request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				if a.VnicId == nil || a.LifecycleState != core.VnicAttachmentLifecycleStateAttached { continue }
+				instances[*a.VnicId] = resource{ "instance", names[*a.InstanceId], *a.InstanceId, cpt_id }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		// load balancers
+		lb_request := loadbalancer.ListLoadBalancersRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			lb_request.Page = page
+			response, err := lb_client.ListLoadBalancers(context.Background(), lb_request)
+			if err != nil { return nil, err }
+			for _, lb := range response.Items {
+				if lb.LifecycleState == loadbalancer.LoadBalancerLifecycleStateDeleted { continue }
+				for _, ip := range lb.IpAddresses {
+					if ip.IsPublic != nil && *ip.IsPublic { by_address[*ip.IpAddress] = resource{ "load_balancer", *lb.DisplayName, *lb.Id, cpt_id } }
+				}
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		// NAT gateways
+		nat_request := core.ListNatGatewaysRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			nat_request.Page = page
+			response, err := network_client.ListNatGateways(context.Background(), nat_request)
+			if err != nil { return nil, err }
+			for _, n := range response.Items {
+				if n.LifecycleState == core.NatGatewayLifecycleStateTerminated { continue }
+				nat_gws[*n.Id] = resource{ "nat_gateway", *n.DisplayName, *n.Id, cpt_id }
+				if n.NatIp != nil { by_address[*n.NatIp] = nat_gws[*n.Id] }
+			}
+			return response.OpcNextPage, nil
+		})
+		return compartment_items{ public_ips, instances, nat_gws, by_address }, err
+	})
+	public_ips := make([]core.PublicIp, 0)
+	instances  := make(map[string]resource)      // VNIC OCID -> instance
+	nat_gws    := make(map[string]resource)      // NAT gateway OCID -> NAT gateway
+	by_address := make(map[string]resource)      // public IP address -> load balancer or NAT gateway
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		public_ips = append(public_ips, cpt_items.public_ips...)
+		for id, res := range cpt_items.instances { instances[id] = res }
+		for id, res := range cpt_items.nat_gws { nat_gws[id] = res }
+		for address, res := range cpt_items.by_address { by_address[address] = res }
+	}
+
+	// find the resource each public IP is bound to
+	items := make([]public_ip_json, 0)
+	listed := make(map[string]bool)
+	for _, p := range public_ips {
+		item := public_ip_json{
+			IpAddress      : *p.IpAddress,
+			Id             : *p.Id,
+			Lifetime       : string(p.Lifetime),
+			Scope          : "regional",
+			LifecycleState : string(p.LifecycleState),
+			Region         : region,
+			CompartmentId  : *p.CompartmentId,
+		}
+		if p.AvailabilityDomain != nil { item.Scope = *p.AvailabilityDomain }
+		var bound_to resource
+		found := false
+		switch {
+		case p.AssignedEntityType == core.PublicIpAssignedEntityTypeNatGateway && p.AssignedEntityId != nil:
+			bound_to, found = nat_gws[*p.AssignedEntityId]
+		case by_address[*p.IpAddress].id != "":
+			bound_to, found = by_address[*p.IpAddress], true
+		case p.AssignedEntityType == core.PublicIpAssignedEntityTypePrivateIp && p.AssignedEntityId != nil:
+			response, err := network_client.GetPrivateIp(context.Background(), core.GetPrivateIpRequest{ PrivateIpId : p.AssignedEntityId, RequestMetadata : metadata })
+			if err != nil { return nil, err }
+			if response.VnicId != nil {
+				bound_to, found = instances[*response.VnicId]
+				if !found { bound_to, found = resource{ "vnic", "", *response.VnicId, "" }, true }
+			}
+		}
+		if found {
+			item.BoundToType, item.BoundToName, item.BoundToId = bound_to.kind, bound_to.name, bound_to.id
+		} else if p.Lifetime == core.PublicIpLifetimeReserved {
+			item.Status = status_unassigned
+		}
+		items = append(items, item)
+		listed[*p.IpAddress] = true
+	}
+
+	// public IPs of load balancers and NAT gateways not returned by the public IP API
+	for address, r := range by_address {
+		if listed[address] { continue }
+		items = append(items, public_ip_json{ IpAddress : address, Lifetime : string(core.PublicIpLifetimeEphemeral), Scope : "regional", BoundToType : r.kind, BoundToName : r.name, BoundToId : r.id, Region : region, CompartmentId : r.compartment_id })
+	}
+	return items, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list public IPs in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list public IPs in all subscribed regions")
+	unassigned_only := flag.Bool("unassigned", false, "only list the unassigned reserved public IPs")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of public IPs in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("public IPs", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	items := make([]public_ip_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, item := range r.Value.([]public_ip_json) {
+			if *unassigned_only && item.Status != status_unassigned { continue }
+			item.CompartmentPath = paths[item.CompartmentId]
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].CompartmentPath != items[j].CompartmentPath { return items[i].CompartmentPath < items[j].CompartmentPath }
+		return items[i].IpAddress < items[j].IpAddress
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(items, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "ip_address", "lifetime", "scope", "bound_to_type", "bound_to_name", "status", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_reserved, nb_unassigned := 0, 0
+		for _, item := range items {
+			row := []string{ item.IpAddress, item.Lifetime, item.Scope, item.BoundToType, item.BoundToName, item.Status, item.CompartmentPath }
+			if all_regions { row = append(row, item.Region) }
+			table.AddRow(append(row, item.Id)...)
+			if item.Lifetime == string(core.PublicIpLifetimeReserved) { nb_reserved++ }
+			if item.Status == status_unassigned { nb_unassigned++ }
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d public IPs (%d reserved, including %d unassigned)\n", len(items), nb_reserved, nb_unassigned)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
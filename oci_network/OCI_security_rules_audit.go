@@ -0,0 +1,359 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script scans the security lists and network security groups (NSGs) in all compartments of a OCI tenant
+// using OCI Go SDK and reports the ingress rules that allow access from anywhere (0.0.0.0/0) to sensitive
+// ports (SSH, RDP, databases...)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- constants
+
+// sensitive ports checked by default: SSH, RDP, Oracle DB, MySQL, PostgreSQL, SQL Server, MongoDB, Redis, Elasticsearch
+const default_ports = "22,3389,1521,1522,3306,5432,1433,27017,6379,9200"
+
+// sources considered as anywhere
+var anywhere_sources = map[string]bool{ "0.0.0.0/0" : true, "::/0" : true }
+
+// protocol names (OCI uses IANA protocol numbers)
+var protocol_names = map[string]string{ "all" : "all", "1" : "ICMP", "6" : "TCP", "17" : "UDP", "58" : "ICMPv6" }
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// ingress rule of a security list or of a NSG
+type ingress_rule struct {
+	protocol    string
+	source      string
+	tcp_options *core.TcpOptions
+	udp_options *core.UdpOptions
+	description *string
+}
+
+type finding_json struct {
+	Type            string `json:"type"`
+	Name            string `json:"name"`
+	Id              string `json:"id"`
+	VcnName         string `json:"vcn_name"`
+	Protocol        string `json:"protocol"`
+	Source          string `json:"source"`
+	PortRange       string `json:"port_range"`
+	ExposedPorts    []int  `json:"exposed_ports"`
+	Description     string `json:"description"`
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    Reports the ingress rules of security lists and NSGs allowing access from 0.0.0.0/0 to sensitive ports.")
+    fmt.Println("")
+    fmt.Printf ("    If --ports LIST is provided, these ports are checked instead of %s.\n", default_ports)
+    fmt.Println("    If --all-ports is provided, all the ingress rules from 0.0.0.0/0 are reported (including ICMP).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// parse a comma separated list of ports
+func parse_ports(list string) ([]int, error) {
+	ports := make([]int, 0)
+	for _, s := range strings.Split(list, ",") {
+		port, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || port < 1 || port > 65535 { return nil, fmt.Errorf("invalid port %q", s) }
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// destination port range of a rule (nil if all ports)
+func port_range(rule ingress_rule) *core.PortRange {
+	if rule.tcp_options != nil { return rule.tcp_options.DestinationPortRange }
+	if rule.udp_options != nil { return rule.udp_options.DestinationPortRange }
+	return nil
+}
+
+// description of the destination port range of a rule
+func port_range_string(rule ingress_rule) string {
+	switch rule.protocol {
+	case "6", "17", "all":
+		r := port_range(rule)
+		if r == nil { return "all" }
+		if *r.Min == *r.Max { return fmt.Sprintf("%d", *r.Min) }
+		return fmt.Sprintf("%d-%d", *r.Min, *r.Max)
+	}
+	return ""
+}
+
+// ports in the list reachable through a rule (TCP, UDP or all protocols only)
+func exposed_ports(rule ingress_rule, ports []int) []int {
+	exposed := make([]int, 0)
+	if rule.protocol != "all" && rule.protocol != "6" && rule.protocol != "17" { return exposed }
+	r := port_range(rule)
+	for _, port := range ports {
+		if r == nil || (port >= *r.Min && port <= *r.Max) { exposed = append(exposed, port) }
+	}
+	return exposed
+}
+
+// check the ingress rules of a security list or NSG and return the findings
+func check_rules(rules []ingress_rule, ports []int, all_ports bool, finding finding_json) []finding_json {
+	findings := make([]finding_json, 0)
+	for _, rule := range rules {
+		if !anywhere_sources[rule.source] { continue }
+		exposed := exposed_ports(rule, ports)
+		if len(exposed) == 0 && !all_ports { continue }
+		f := finding
+		f.Protocol = protocol_names[rule.protocol]
+		if f.Protocol == "" { f.Protocol = rule.protocol }
+		f.Source = rule.source
+		f.PortRange = port_range_string(rule)
+		f.ExposedPorts = exposed
+		if rule.description != nil { f.Description = *rule.description }
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// check the security lists and NSGs in all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func audit_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, ports []int, all_ports bool, parallelism int) ([]finding_json, error) {
+	client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	// names of the VCNs (security lists and NSGs can be in another compartment than their VCN)
+	vcns := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_vcn_names := make(map[string]string)
+		request := core.ListVcnsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListVcns(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items { cpt_vcn_names[*v.Id] = *v.DisplayName }
+			return response.OpcNextPage, nil
+		})
+		return cpt_vcn_names, err
+	})
+	vcn_names := make(map[string]string)
+	for _, r := range vcns {
+		if r.Err != nil { return nil, r.Err }
+		for id, name := range r.Value.(map[string]string) { vcn_names[id] = name }
+	}
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		findings := make([]finding_json, 0)
+
+		// security lists
+		sl_request := core.ListSecurityListsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			sl_request.Page = page
+			response, err := client.ListSecurityLists(context.Background(), sl_request)
+			if err != nil { return nil, err }
+			for _, sl := range response.Items {
+				if sl.LifecycleState != core.SecurityListLifecycleStateAvailable { continue }
+				rules := make([]ingress_rule, 0, len(sl.IngressSecurityRules))
+				for _, r := range sl.IngressSecurityRules {
+					rules = append(rules, ingress_rule{ *r.Protocol, *r.Source, r.TcpOptions, r.UdpOptions, r.Description })
+				}
+				finding := finding_json{ Type : "security_list", Name : *sl.DisplayName, Id : *sl.Id, VcnName : vcn_names[*sl.VcnId], Region : region, CompartmentId : cpt_id }
+				findings = append(findings, check_rules(rules, ports, all_ports, finding)...)
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		// network security groups
+		nsgs := make([]core.NetworkSecurityGroup, 0)
+		nsg_request := core.ListNetworkSecurityGroupsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			nsg_request.Page = page
+			response, err := client.ListNetworkSecurityGroups(context.Background(), nsg_request)
+			if err != nil { return nil, err }
+			nsgs = append(nsgs, response.Items...)
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+		for _, nsg := range nsgs {
+			if nsg.LifecycleState != core.NetworkSecurityGroupLifecycleStateAvailable { continue }
+			rules := make([]ingress_rule, 0)
+			rules_request := core.ListNetworkSecurityGroupSecurityRulesRequest{
+				NetworkSecurityGroupId : nsg.Id,
+				Direction              : core.ListNetworkSecurityGroupSecurityRulesDirectionIngress,
+				RequestMetadata        : metadata,
+			}
+			err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				rules_request.Page = page
+				response, err := client.ListNetworkSecurityGroupSecurityRules(context.Background(), rules_request)
+				if err != nil { return nil, err }
+				for _, r := range response.Items {
+					if r.SourceType != core.SecurityRuleSourceTypeCidrBlock || r.Source == nil { continue }
+					rules = append(rules, ingress_rule{ *r.Protocol, *r.Source, r.TcpOptions, r.UdpOptions, r.Description })
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+			finding := finding_json{ Type : "nsg", Name : *nsg.DisplayName, Id : *nsg.Id, VcnName : vcn_names[*nsg.VcnId], Region : region, CompartmentId : cpt_id }
+			findings = append(findings, check_rules(rules, ports, all_ports, finding)...)
+		}
+		return findings, nil
+	})
+	findings := make([]finding_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		findings = append(findings, r.Value.([]finding_json)...)
+	}
+	return findings, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "process all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "process all subscribed regions")
+	ports_list      := flag.String("ports", default_ports, "comma separated list of sensitive ports")
+	all_ports       := flag.Bool("all-ports", false, "report all the ingress rules from 0.0.0.0/0")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	ports, err := parse_ports(*ports_list)
+	if err != nil {
+		fmt.Fprintf (os.Stderr, "ERROR: --ports: %s\n", err)
+		usage()
+	}
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Check the security rules in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	// the compartments are processed twice in each region (VCN names, then security rules)
+	ocihelpers.StartProgress("security rules", len(regions), 2 * len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return audit_region(config, tree, region, ports, *all_ports, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	findings := make([]finding_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, f := range r.Value.([]finding_json) {
+			f.CompartmentPath = paths[f.CompartmentId]
+			findings = append(findings, f)
+		}
+	}
+
+	// Display the findings grouped by compartment
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].CompartmentPath != findings[j].CompartmentPath { return findings[i].CompartmentPath < findings[j].CompartmentPath }
+		if findings[i].Region != findings[j].Region { return findings[i].Region < findings[j].Region }
+		return findings[i].Name < findings[j].Name
+	})
+	if format == "json" {
+		output, err := json.MarshalIndent(findings, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "compartment_path", "type", "name", "vcn", "protocol", "source", "port_range", "exposed_ports", "description" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		for _, f := range findings {
+			exposed := make([]string, 0, len(f.ExposedPorts))
+			for _, port := range f.ExposedPorts { exposed = append(exposed, strconv.Itoa(port)) }
+			row := []string{ f.CompartmentPath, f.Type, f.Name, f.VcnName, f.Protocol, f.Source, f.PortRange, strings.Join(exposed, " "), f.Description }
+			if all_regions { row = append(row, f.Region) }
+			table.AddRow(append(row, f.Id)...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d ingress rules from 0.0.0.0/0 found\n", len(findings))
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
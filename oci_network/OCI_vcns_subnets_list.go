@@ -0,0 +1,295 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the VCNs and subnets in all compartments of a OCI tenant using OCI Go SDK
+// (CIDR blocks, DNS labels, public or private subnets, number of used and available IP addresses)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the number of used IP addresses in a subnet is the number of private IPs in this subnet
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+)
+
+// -- constants
+
+// number of IP addresses reserved by OCI in each subnet (first 2 and last IP addresses of the CIDR block)
+const nb_reserved_ips = 3
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type subnet_json struct {
+	Name               string `json:"name"`
+	Id                 string `json:"id"`
+	CidrBlock          string `json:"cidr_block"`
+	DnsLabel           string `json:"dns_label"`
+	Access             string `json:"access"`
+	AvailabilityDomain string `json:"availability_domain"`
+	TotalIps           int64  `json:"total_ips"`
+	UsedIps            int64  `json:"used_ips"`
+	AvailableIps       int64  `json:"available_ips"`
+	CompartmentId      string `json:"compartment_id"`
+	CompartmentPath    string `json:"compartment_path"`
+}
+
+type vcn_json struct {
+	Name            string        `json:"name"`
+	Id              string        `json:"id"`
+	CidrBlock       string        `json:"cidr_block"`
+	DnsLabel        string        `json:"dns_label"`
+	Region          string        `json:"region"`
+	CompartmentId   string        `json:"compartment_id"`
+	CompartmentPath string        `json:"compartment_path"`
+	Subnets         []subnet_json `json:"subnets"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    By default, the subnets are listed (one row per subnet, with the name and CIDR block of its VCN).")
+    fmt.Println("    If --vcns is provided, the VCNs are listed instead, with their number of subnets and IP addresses.")
+    fmt.Println("    If -a or --all-regions is provided, the VCNs and subnets in all subscribed regions are listed.")
+    fmt.Println("    If -json is provided, the VCNs and their subnets are displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows are not displayed.")
+    fmt.Println("")
+    fmt.Println("    Note: 3 IP addresses are reserved by OCI in each subnet, they are not included in the total number of IPs.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// number of usable IP addresses in a CIDR block
+func usable_ips(cidr_block string) int64 {
+	_, ipnet, err := net.ParseCIDR(cidr_block)
+	if err != nil { return 0 }
+	ones, bits := ipnet.Mask.Size()
+	if bits-ones > 62 { return 0 }     // IPv6
+	total := int64(1) << uint(bits-ones)
+	if total <= nb_reserved_ips { return 0 }
+	return total - nb_reserved_ips
+}
+
+// get a string from a pointer (empty string if nil)
+func str(s *string) string {
+	if s == nil { return "" }
+	return *s
+}
+
+// list the VCNs and subnets in all active compartments of a region
+// (the subnets of a VCN can be in other compartments than the VCN)
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]vcn_json, error) {
+	client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	type compartment_items struct {
+		vcns    []vcn_json
+		subnets map[string][]subnet_json
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		vcns := make([]vcn_json, 0)
+		subnets := make(map[string][]subnet_json)
+		vcns_request := core.ListVcnsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			vcns_request.Page = page
+			response, err := client.ListVcns(context.Background(), vcns_request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				if v.LifecycleState != core.VcnLifecycleStateAvailable { continue }
+				vcns = append(vcns, vcn_json{ Name : *v.DisplayName, Id : *v.Id, CidrBlock : *v.CidrBlock, DnsLabel : str(v.DnsLabel), Region : region, CompartmentId : *v.CompartmentId })
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		subnets_request := core.ListSubnetsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			subnets_request.Page = page
+			response, err := client.ListSubnets(context.Background(), subnets_request)
+			if err != nil { return nil, err }
+			for _, s := range response.Items {
+				if s.LifecycleState != core.SubnetLifecycleStateAvailable { continue }
+				subnet := subnet_json{ Name : *s.DisplayName, Id : *s.Id, CidrBlock : *s.CidrBlock, DnsLabel : str(s.DnsLabel), Access : "public", AvailabilityDomain : "regional", CompartmentId : *s.CompartmentId }
+				if s.ProhibitPublicIpOnVnic != nil && *s.ProhibitPublicIpOnVnic { subnet.Access = "private" }
+				if s.AvailabilityDomain != nil { subnet.AvailabilityDomain = *s.AvailabilityDomain }
+				subnets[*s.VcnId] = append(subnets[*s.VcnId], subnet)
+			}
+			return response.OpcNextPage, nil
+		})
+		return compartment_items{ vcns, subnets }, err
+	})
+	vcns := make([]vcn_json, 0)
+	subnets := make(map[string][]subnet_json)     // VCN OCID -> subnets
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		vcns = append(vcns, cpt_items.vcns...)
+		for vcn_id, vcn_subnets := range cpt_items.subnets { subnets[vcn_id] = append(subnets[vcn_id], vcn_subnets...) }
+	}
+
+	// number of used IP addresses in each subnet
+	for i := range vcns {
+		vcns[i].Subnets = make([]subnet_json, 0)
+		for _, subnet := range subnets[vcns[i].Id] {
+			request := core.ListPrivateIpsRequest{ SubnetId : common.String(subnet.Id), RequestMetadata : metadata }
+			err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				request.Page = page
+				response, err := client.ListPrivateIps(context.Background(), request)
+				if err != nil { return nil, err }
+				subnet.UsedIps += int64(len(response.Items))
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+			subnet.TotalIps = usable_ips(subnet.CidrBlock)
+			subnet.AvailableIps = subnet.TotalIps - subnet.UsedIps
+			vcns[i].Subnets = append(vcns[i].Subnets, subnet)
+		}
+	}
+	return vcns, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list VCNs and subnets in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list VCNs and subnets in all subscribed regions")
+	vcns_only       := flag.Bool("vcns", false, "list the VCNs instead of the subnets")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of VCNs and subnets in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("VCNs and subnets", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	vcns := make([]vcn_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, v := range r.Value.([]vcn_json) {
+			v.CompartmentPath = paths[v.CompartmentId]
+			for i := range v.Subnets { v.Subnets[i].CompartmentPath = paths[v.Subnets[i].CompartmentId] }
+			sort.SliceStable(v.Subnets, func(i, j int) bool { return v.Subnets[i].Name < v.Subnets[j].Name })
+			vcns = append(vcns, v)
+		}
+	}
+	sort.SliceStable(vcns, func(i, j int) bool {
+		if vcns[i].CompartmentPath != vcns[j].CompartmentPath { return vcns[i].CompartmentPath < vcns[j].CompartmentPath }
+		return vcns[i].Name < vcns[j].Name
+	})
+
+	// Display the results
+	switch {
+	case format == "json":
+		output, err := json.MarshalIndent(vcns, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+
+	case *vcns_only:
+		table := ocihelpers.Table{ Headers : []string{ "compartment_path", "vcn", "cidr_block", "dns_label", "subnets", "total_ips", "used_ips", "available_ips" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		for _, v := range vcns {
+			var total, used int64
+			for _, s := range v.Subnets { total += s.TotalIps; used += s.UsedIps }
+			row := []string{ v.CompartmentPath, v.Name, v.CidrBlock, v.DnsLabel, fmt.Sprintf("%d", len(v.Subnets)), fmt.Sprintf("%d", total), fmt.Sprintf("%d", used), fmt.Sprintf("%d", total-used) }
+			if all_regions { row = append(row, v.Region) }
+			table.AddRow(row...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+
+	default:
+		table := ocihelpers.Table{ Headers : []string{ "compartment_path", "vcn", "vcn_cidr_block", "subnet", "cidr_block", "dns_label", "access", "availability_domain", "total_ips", "used_ips", "available_ips" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		for _, v := range vcns {
+			for _, s := range v.Subnets {
+				row := []string{ s.CompartmentPath, v.Name, v.CidrBlock, s.Name, s.CidrBlock, s.DnsLabel, s.Access, s.AvailabilityDomain, fmt.Sprintf("%d", s.TotalIps), fmt.Sprintf("%d", s.UsedIps), fmt.Sprintf("%d", s.AvailableIps) }
+				if all_regions { row = append(row, v.Region) }
+				table.AddRow(row...)
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,241 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the Web Application Firewall (WAF) policies in all compartments of a OCI tenant using
+// OCI Go SDK, with the endpoints they protect (domains and origins) and the number of protection rules in each
+// state (OFF, DETECT or BLOCK)
+// Note: OCI tenant given by an OCI CLI PROFILE
+//       only the edge WAF policies (WAAS) are listed: the regional WAF policies (attached to load balancers)
+//       are not available in the version of the OCI Go SDK used
+//       deleted policies are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/waas"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type rule_json struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Action string `json:"action"`     // OFF, DETECT or BLOCK
+}
+
+type policy_json struct {
+	Name            string      `json:"name"`
+	Id              string      `json:"id"`
+	LifecycleState  string      `json:"lifecycle_state"`
+	Domains         []string    `json:"domains"`
+	Cname           string      `json:"cname"`
+	Origins         []string    `json:"origins"`
+	NbRulesOff      int         `json:"nb_rules_off"`
+	NbRulesDetect   int         `json:"nb_rules_detect"`
+	NbRulesBlock    int         `json:"nb_rules_block"`
+	Rules           []rule_json `json:"rules"`
+	CompartmentId   string      `json:"compartment_id"`
+	CompartmentPath string      `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each policy, with the number of protection rules in each state (OFF, DETECT, BLOCK).")
+    fmt.Println("    If --rules is provided, a row is displayed for each protection rule not OFF instead.")
+    fmt.Println("    If --no-block-only is provided, only the policies without any protection rule in BLOCK state are displayed.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format (with all the protection rules).")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// list the protection rules of a policy
+func list_rules(client waas.WaasClient, policy_id *string) ([]rule_json, error) {
+	rules := make([]rule_json, 0)
+	request := waas.ListProtectionRulesRequest{ WaasPolicyId : policy_id, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListProtectionRules(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, r := range response.Items {
+			rule := rule_json{ Action : string(r.Action) }
+			if r.Key != nil  { rule.Key = *r.Key }
+			if r.Name != nil { rule.Name = *r.Name }
+			rules = append(rules, rule)
+		}
+		return response.OpcNextPage, nil
+	})
+	return rules, err
+}
+
+// list the policies (not deleted) of a compartment, with their domains, origins and protection rules
+func list_policies(client waas.WaasClient, cpt_id string) ([]policy_json, error) {
+	summaries := make([]waas.WaasPolicySummary, 0)
+	request := waas.ListWaasPoliciesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListWaasPolicies(context.Background(), request)
+		if err != nil { return nil, err }
+		summaries = append(summaries, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return nil, err }
+
+	policies := make([]policy_json, 0)
+	for _, s := range summaries {
+		if s.LifecycleState == waas.LifecycleStatesDeleted { continue }
+		response, err := client.GetWaasPolicy(context.Background(), waas.GetWaasPolicyRequest{ WaasPolicyId : s.Id, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+		if err != nil { return nil, err }
+		p := response.WaasPolicy
+		item := policy_json{ Name : *p.DisplayName, Id : *p.Id, LifecycleState : string(p.LifecycleState), Domains : []string{ *p.Domain }, Origins : make([]string, 0), CompartmentId : cpt_id }
+		item.Domains = append(item.Domains, p.AdditionalDomains...)
+		if p.Cname != nil { item.Cname = *p.Cname }
+		for _, o := range p.Origins { item.Origins = append(item.Origins, *o.Uri) }
+		sort.Strings(item.Origins)
+		item.Rules, err = list_rules(client, p.Id)
+		if err != nil { return nil, err }
+		for _, r := range item.Rules {
+			switch r.Action {
+			case string(waas.ProtectionRuleActionOff):    item.NbRulesOff++
+			case string(waas.ProtectionRuleActionDetect): item.NbRulesDetect++
+			case string(waas.ProtectionRuleActionBlock):  item.NbRulesBlock++
+			}
+		}
+		policies = append(policies, item)
+	}
+	return policies, nil
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	rules_output    := flag.Bool("rules", false, "display a row for each protection rule not OFF")
+	no_block_only   := flag.Bool("no-block-only", false, "only display the policies without any protection rule in BLOCK state")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the policies (edge policies are global resources, at most parallelism compartments processed at the same time)
+	client, err := waas.NewWaasClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("WAF policies", 1, len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), *parallelism, func(cpt_id string) (interface{}, error) {
+		return list_policies(client, cpt_id)
+	})
+	ocihelpers.StopProgress()
+	policies := make([]policy_json, 0)
+	for _, r := range results {
+		ocihelpers.FatalIfError(r.Err)
+		for _, p := range r.Value.([]policy_json) {
+			if *no_block_only && p.NbRulesBlock > 0 { continue }
+			p.CompartmentPath = paths[p.CompartmentId]
+			policies = append(policies, p)
+		}
+	}
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].CompartmentPath != policies[j].CompartmentPath { return policies[i].CompartmentPath < policies[j].CompartmentPath }
+		return policies[i].Name < policies[j].Name
+	})
+
+	// Display the results
+	if format == "json" {
+		output, err := json.MarshalIndent(policies, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+		return
+	}
+	var table ocihelpers.Table
+	if *rules_output {
+		table = ocihelpers.Table{ Headers : []string{ "policy", "domains", "rule_key", "rule", "action", "compartment_path", "ocid" } }
+		for _, p := range policies {
+			for _, r := range p.Rules {
+				if r.Action == string(waas.ProtectionRuleActionOff) { continue }
+				table.AddRow(p.Name, strings.Join(p.Domains, " "), r.Key, r.Name, r.Action, p.CompartmentPath, p.Id)
+			}
+		}
+	} else {
+		table = ocihelpers.Table{ Headers : []string{ "policy", "state", "domains", "cname", "origins", "rules_off", "rules_detect", "rules_block", "compartment_path", "ocid" } }
+		for _, p := range policies {
+			table.AddRow(p.Name, p.LifecycleState, strings.Join(p.Domains, " "), p.Cname, strings.Join(p.Origins, " "),
+				fmt.Sprintf("%d", p.NbRulesOff), fmt.Sprintf("%d", p.NbRulesDetect), fmt.Sprintf("%d", p.NbRulesBlock), p.CompartmentPath, p.Id)
+		}
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet {
+		nb_no_block := 0
+		for _, p := range policies {
+			if p.NbRulesBlock == 0 { nb_no_block++ }
+		}
+		fmt.Println ("")
+		fmt.Printf ("%d policies, %d without any protection rule in BLOCK state\n", len(policies), nb_no_block)
+	}
+}
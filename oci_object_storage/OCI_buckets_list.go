@@ -0,0 +1,284 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the object storage buckets in all compartments of a OCI tenant using OCI Go SDK
+// (storage tier, approximate size, approximate number of objects, versioning, public access)
+// or the object storage consumption per compartment (--summary)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       the size and number of objects are approximate values computed asynchronously by OCI
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/objectstorage"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type bucket_json struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	StorageTier     string `json:"storage_tier"`
+	SizeInBytes     int64  `json:"approximate_size_in_bytes"`
+	NbObjects       int64  `json:"approximate_count"`
+	Versioning      string `json:"versioning"`
+	PublicAccess    string `json:"public_access_type"`
+	Region          string `json:"region"`
+	CompartmentId   string `json:"compartment_id"`
+	CompartmentPath string `json:"compartment_path"`
+	TimeCreated     string `json:"time_created"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    If -a or --all-regions is provided, the buckets in all subscribed regions are listed.")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --summary is provided, the object storage consumption (number of buckets and objects, size, public buckets)")
+    fmt.Println("    is displayed for each compartment instead of the list of buckets.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the total line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Note: the size and number of objects are approximate values (computed asynchronously by OCI).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// convert a size in bytes to GB (string with 2 decimals)
+func size_gb(bytes int64) string {
+	return fmt.Sprintf("%.2f", float64(bytes)/(1024*1024*1024))
+}
+
+// list the buckets in all active compartments of a region
+// (ListBuckets only returns a summary, so GetBucket is needed to get the details of each bucket)
+// at most parallelism compartments are processed at the same time
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]bucket_json, error) {
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	response, err := client.GetNamespace(context.Background(), objectstorage.GetNamespaceRequest{ RequestMetadata : metadata })
+	if err != nil { return nil, err }
+	namespace := *response.Value
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		summaries := make([]objectstorage.BucketSummary, 0)
+		request := objectstorage.ListBucketsRequest{ NamespaceName : common.String(namespace), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListBuckets(context.Background(), request)
+			if err != nil { return nil, err }
+			summaries = append(summaries, response.Items...)
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		buckets := make([]bucket_json, 0, len(summaries))
+		for _, s := range summaries {
+			response, err := client.GetBucket(context.Background(), objectstorage.GetBucketRequest{
+				NamespaceName   : common.String(namespace),
+				BucketName      : s.Name,
+				Fields          : []objectstorage.GetBucketFieldsEnum{ objectstorage.GetBucketFieldsApproximatecount, objectstorage.GetBucketFieldsApproximatesize },
+				RequestMetadata : metadata,
+			})
+			if err != nil { return nil, err }
+			b := response.Bucket
+			bucket := bucket_json{
+				Name          : *b.Name,
+				Namespace     : namespace,
+				StorageTier   : string(b.StorageTier),
+				Versioning    : string(b.Versioning),
+				PublicAccess  : string(b.PublicAccessType),
+				Region        : region,
+				CompartmentId : *b.CompartmentId,
+				TimeCreated   : b.TimeCreated.Format("2006-01-02T15:04:05Z"),
+			}
+			if b.ApproximateSize  != nil { bucket.SizeInBytes = *b.ApproximateSize }
+			if b.ApproximateCount != nil { bucket.NbObjects = *b.ApproximateCount }
+			buckets = append(buckets, bucket)
+		}
+		return buckets, nil
+	})
+	buckets := make([]bucket_json, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		buckets = append(buckets, r.Value.([]bucket_json)...)
+	}
+	return buckets, nil
+}
+
+// display the object storage consumption per compartment (and per region if all_regions is true)
+func display_summary(buckets []bucket_json, all_regions bool, format string) {
+	type consumption struct {
+		nb_buckets int
+		nb_public  int
+		nb_objects int64
+		size       int64
+	}
+	consumptions := make(map[string]*consumption)
+	keys := make([]string, 0)
+	for _, b := range buckets {
+		key := b.CompartmentPath
+		if all_regions { key += "\t" + b.Region }
+		if consumptions[key] == nil {
+			consumptions[key] = &consumption{}
+			keys = append(keys, key)
+		}
+		consumptions[key].nb_buckets++
+		consumptions[key].nb_objects += b.NbObjects
+		consumptions[key].size += b.SizeInBytes
+		if b.PublicAccess != string(objectstorage.BucketPublicAccessTypeNopublicaccess) { consumptions[key].nb_public++ }
+	}
+	sort.Strings(keys)
+
+	table := ocihelpers.Table{ Headers : []string{ "compartment_path" } }
+	if all_regions { table.Headers = append(table.Headers, "region") }
+	table.Headers = append(table.Headers, "buckets", "public_buckets", "objects", "size_gb")
+	var total consumption
+	for _, key := range keys {
+		c := consumptions[key]
+		row := strings.Split(key, "\t")
+		row = append(row, fmt.Sprintf("%d", c.nb_buckets), fmt.Sprintf("%d", c.nb_public), fmt.Sprintf("%d", c.nb_objects), size_gb(c.size))
+		table.AddRow(row...)
+		total.nb_buckets += c.nb_buckets
+		total.nb_public  += c.nb_public
+		total.nb_objects += c.nb_objects
+		total.size       += c.size
+	}
+	ocihelpers.FatalIfError(table.Print(format))
+	if format == "text" && !ocihelpers.Quiet {
+		fmt.Println ("")
+		fmt.Printf ("%d buckets (%d public), %d objects, %s GB\n", total.nb_buckets, total.nb_public, total.nb_objects, size_gb(total.size))
+	}
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list buckets in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list buckets in all subscribed regions")
+	summary         := flag.Bool("summary", false, "display the object storage consumption per compartment")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the list of buckets in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("buckets", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	buckets := make([]bucket_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, b := range r.Value.([]bucket_json) {
+			b.CompartmentPath = paths[b.CompartmentId]
+			buckets = append(buckets, b)
+		}
+	}
+	sort.SliceStable(buckets, func(i, j int) bool {
+		if buckets[i].CompartmentPath != buckets[j].CompartmentPath { return buckets[i].CompartmentPath < buckets[j].CompartmentPath }
+		return buckets[i].Name < buckets[j].Name
+	})
+
+	// Display the results
+	switch {
+	case *summary:
+		display_summary(buckets, all_regions, format)
+
+	case format == "json":
+		output, err := json.MarshalIndent(buckets, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+
+	default:
+		table := ocihelpers.Table{ Headers : []string{ "name", "storage_tier", "size_gb", "objects", "versioning", "public_access", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		for _, b := range buckets {
+			row := []string{ b.Name, b.StorageTier, size_gb(b.SizeInBytes), fmt.Sprintf("%d", b.NbObjects), b.Versioning, b.PublicAccess, b.CompartmentPath }
+			if all_regions { row = append(row, b.Region) }
+			table.AddRow(row...)
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
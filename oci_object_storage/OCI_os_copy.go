@@ -0,0 +1,314 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script uploads a local directory tree to an object storage bucket, or downloads the objects of a bucket
+// to a local directory, using OCI Go SDK (lightweight alternative to "oci os object bulk-upload/bulk-download")
+// Several files are transferred concurrently, and big files are uploaded with the multipart upload manager
+// of the SDK (parts uploaded in parallel)
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       files/objects already present with the same size are skipped (unless --overwrite is provided)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/objectstorage"
+	"github.com/oracle/oci-go-sdk/objectstorage/transfer"
+)
+
+// -- constants
+const default_parallelism       = 4      // number of files transferred concurrently
+const default_part_size_mb      = 128    // part size of multipart uploads
+const default_parts_parallelism = 5      // number of parts of a multipart upload uploaded concurrently
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+
+// a file to transfer
+type copy_item struct {
+	object_name string
+	local_path  string
+	size        int64
+}
+
+// result of the transfers
+type copy_stats struct {
+	lock          sync.Mutex
+	nb_copied     int
+	nb_skipped    int
+	nb_failed     int
+	bytes_copied  int64
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] upload   LOCAL_DIR BUCKET [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] download BUCKET LOCAL_DIR [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip upload|download ...\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    upload  : upload all the files of LOCAL_DIR (and sub-directories) to the bucket.")
+    fmt.Println("              The object names are the paths of the files relative to LOCAL_DIR.")
+    fmt.Println("    download: download all the objects of the bucket to LOCAL_DIR (sub-directories are created).")
+    fmt.Println("")
+    fmt.Println("    If --prefix PREFIX is provided, PREFIX is added to the object names (upload), or only the objects whose name")
+    fmt.Println("    starts with PREFIX are downloaded, PREFIX being removed from the local file names (download).")
+    fmt.Printf ("    If --parallel N is provided, N files are transferred concurrently instead of %d.\n", default_parallelism)
+    fmt.Printf ("    If --part-size MB is provided, the files are uploaded in parts of MB MiB instead of %d MiB (multipart upload).\n", default_part_size_mb)
+    fmt.Printf ("    If --parts-parallel N is provided, N parts of a file are uploaded concurrently instead of %d.\n", default_parts_parallelism)
+    fmt.Println("    If --overwrite is provided, files or objects already present with the same size are copied again")
+    fmt.Println("    (by default, they are skipped).")
+    fmt.Println("    If --dry-run is provided, the files or objects to copy are listed but not copied.")
+    fmt.Println("    If --quiet is provided, only the errors and the summary line are displayed.")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some files failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// convert a size in bytes to MB (string with 1 decimal)
+func size_mb(bytes int64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+}
+
+// list the objects of a bucket whose name starts with prefix (name -> size)
+func list_objects(client objectstorage.ObjectStorageClient, namespace string, bucket string, prefix string) (map[string]int64, error) {
+	objects := make(map[string]int64)
+	request := objectstorage.ListObjectsRequest{
+		NamespaceName   : common.String(namespace),
+		BucketName      : common.String(bucket),
+		Fields          : common.String("name,size"),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	if prefix != "" { request.Prefix = common.String(prefix) }
+	// object storage uses a start object name instead of a page token
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Start = page
+		response, err := client.ListObjects(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, o := range response.Objects {
+			size := int64(0)
+			if o.Size != nil { size = *o.Size }
+			objects[*o.Name] = size
+		}
+		return response.NextStartWith, nil
+	})
+	return objects, err
+}
+
+// list the files in a local directory tree to upload
+func list_files(local_dir string, prefix string) ([]copy_item, error) {
+	items := make([]copy_item, 0)
+	err := filepath.Walk(local_dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil { return err }
+		if !info.Mode().IsRegular() { return nil }
+		relative_path, err := filepath.Rel(local_dir, path)
+		if err != nil { return err }
+		items = append(items, copy_item{ object_name : prefix + filepath.ToSlash(relative_path), local_path : path, size : info.Size() })
+		return nil
+	})
+	return items, err
+}
+
+// upload a file using the upload manager of the SDK (multipart upload for big files)
+func upload_file(client *objectstorage.ObjectStorageClient, namespace string, bucket string, item copy_item, part_size_mb int64, parts_parallelism int) error {
+	upload_manager := transfer.NewUploadManager()
+	request := transfer.UploadFileRequest{
+		UploadRequest : transfer.UploadRequest{
+			NamespaceName         : common.String(namespace),
+			BucketName            : common.String(bucket),
+			ObjectName            : common.String(item.object_name),
+			PartSize              : common.Int64(part_size_mb*1024*1024),
+			AllowMultipartUploads : common.Bool(true),
+			AllowParrallelUploads : common.Bool(true),
+			NumberOfGoroutines    : common.Int(parts_parallelism),
+			ObjectStorageClient   : client,
+			RequestMetadata       : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+		},
+		FilePath : item.local_path,
+	}
+	_, err := upload_manager.UploadFile(context.Background(), request)
+	return err
+}
+
+// download an object to a local file (written to a temporary file first, so that a failed download
+// does not leave a truncated file)
+func download_object(client *objectstorage.ObjectStorageClient, namespace string, bucket string, item copy_item) error {
+	response, err := client.GetObject(context.Background(), objectstorage.GetObjectRequest{
+		NamespaceName   : common.String(namespace),
+		BucketName      : common.String(bucket),
+		ObjectName      : common.String(item.object_name),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	})
+	if err != nil { return err }
+	defer response.Content.Close()
+
+	if err := os.MkdirAll(filepath.Dir(item.local_path), 0755); err != nil { return err }
+	tmp_path := item.local_path + ".part"
+	f, err := os.Create(tmp_path)
+	if err != nil { return err }
+	_, err = io.Copy(f, response.Content)
+	if close_err := f.Close(); err == nil { err = close_err }
+	if err != nil {
+		os.Remove(tmp_path)
+		return err
+	}
+	return os.Rename(tmp_path, item.local_path)
+}
+
+// description of a transfer (source -> destination)
+func describe(action string, item copy_item) string {
+	if action == "upload" { return fmt.Sprintf("%s -> %s (%s)", item.local_path, item.object_name, size_mb(item.size)) }
+	return fmt.Sprintf("%s -> %s (%s)", item.object_name, item.local_path, size_mb(item.size))
+}
+
+// transfer the items, with at most parallelism transfers at the same time
+func copy_items(action string, items []copy_item, parallelism int, stats *copy_stats, transfer_item func(item copy_item) error) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+	for _, item := range items {
+		wg.Add(1)
+		go func(item copy_item) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			err := transfer_item(item)
+
+			stats.lock.Lock()
+			defer stats.lock.Unlock()
+			if err != nil {
+				fmt.Fprintf (os.Stderr, "ERROR: %s: %s\n", item.object_name, err)
+				stats.nb_failed++
+				return
+			}
+			stats.nb_copied++
+			stats.bytes_copied += item.size
+			if !ocihelpers.Quiet { fmt.Println (describe(action, item)) }
+		}(item)
+	}
+	wg.Wait()
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	prefix            := flag.String("prefix", "", "prefix of the object names")
+	parallelism       := flag.Int("parallel", default_parallelism, "number of files transferred concurrently")
+	part_size_mb      := flag.Int64("part-size", default_part_size_mb, "part size in MiB for multipart uploads")
+	parts_parallelism := flag.Int("parts-parallel", default_parts_parallelism, "number of parts uploaded concurrently")
+	overwrite         := flag.Bool("overwrite", false, "copy files or objects already present with the same size")
+	dry_run           := flag.Bool("dry-run", false, "list the files or objects to copy without copying them")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "only display the errors and the summary line")
+	flag.Parse()
+	if *parallelism < 1 || *part_size_mb < 1 || *parts_parallelism < 1 { usage() }
+	if instance_principal {
+		if (flag.NArg() != 3) { usage() }
+	} else {
+		if (flag.NArg() < 3 || flag.NArg() > 4) { usage() }
+	}
+	action := flag.Arg(0)
+	var local_dir, bucket string
+	switch action {
+	case "upload":   local_dir, bucket = flag.Arg(1), flag.Arg(2)
+	case "download": bucket, local_dir = flag.Arg(1), flag.Arg(2)
+	default:         usage()
+	}
+	local_dir = ocihelpers.ExpandPath(local_dir)
+	profile := ""
+	if !instance_principal { profile = ocihelpers.GetProfile(flag.Args()[3:]) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Object storage client without timeout (default timeout of 60s is too short for big files)
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	client.HTTPClient = &http.Client{}
+	response, err := client.GetNamespace(context.Background(), objectstorage.GetNamespaceRequest{ RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+	ocihelpers.FatalIfError(err)
+	namespace := *response.Value
+
+	// Get the list of files or objects to copy (skip the ones already present with the same size)
+	objects, err := list_objects(client, namespace, bucket, *prefix)
+	ocihelpers.FatalIfError(err)
+	stats := &copy_stats{}
+	items := make([]copy_item, 0)
+	if action == "upload" {
+		files, err := list_files(local_dir, *prefix)
+		ocihelpers.FatalIfError(err)
+		for _, item := range files {
+			if size, found := objects[item.object_name]; found && size == item.size && !*overwrite {
+				stats.nb_skipped++
+				continue
+			}
+			items = append(items, item)
+		}
+	} else {
+		for name, size := range objects {
+			if strings.HasSuffix(name, "/") { continue }     // folder markers
+			relative_path := filepath.FromSlash(strings.TrimPrefix(name, *prefix))
+			local_path := filepath.Join(local_dir, relative_path)
+			if !strings.HasPrefix(local_path, filepath.Clean(local_dir)+string(os.PathSeparator)) {
+				fmt.Fprintf (os.Stderr, "ERROR: %s: object name not usable as a local file name\n", name)
+				stats.nb_failed++
+				continue
+			}
+			if info, err := os.Stat(local_path); err == nil && info.Size() == size && !*overwrite {
+				stats.nb_skipped++
+				continue
+			}
+			items = append(items, copy_item{ object_name : name, local_path : local_path, size : size })
+		}
+	}
+
+	// Copy the files or objects (several at the same time)
+	start := time.Now()
+	switch {
+	case *dry_run:
+		for _, item := range items { fmt.Println (describe(action, item)) }
+	case action == "upload":
+		copy_items(action, items, *parallelism, stats, func(item copy_item) error {
+			return upload_file(&client, namespace, bucket, item, *part_size_mb, *parts_parallelism)
+		})
+	default:
+		copy_items(action, items, *parallelism, stats, func(item copy_item) error {
+			return download_object(&client, namespace, bucket, item)
+		})
+	}
+
+	// Summary
+	if *dry_run {
+		fmt.Printf ("%d files to %s, %d skipped (already present with the same size)\n", len(items), action, stats.nb_skipped)
+	} else {
+		fmt.Printf ("%d files copied (%s) in %s, %d skipped (already present with the same size), %d failed\n",
+			stats.nb_copied, size_mb(stats.bytes_copied), time.Since(start).Round(time.Second), stats.nb_skipped, stats.nb_failed)
+	}
+	if stats.nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,345 @@
+// --------------------------------------------------------------------------------------------------------------
+// This script lists the stream pools and streams (Streaming service) in all compartments of a OCI tenant using
+// OCI Go SDK, with number of partitions, retention and messages endpoint of each stream
+// Optionally, it publishes a test message in a stream and reads it back to verify the connectivity to the
+// messages endpoint of the stream
+// Note: OCI tenant and region given by an OCI CLI PROFILE
+//       deleted stream pools and streams are ignored
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during the scan
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/streaming"
+)
+
+// -- global variables
+var config_file = ocihelpers.GetConfigFile()
+
+// -- types
+type stream_json struct {
+	Name             string `json:"name"`
+	Id               string `json:"id"`
+	LifecycleState   string `json:"lifecycle_state"`
+	Partitions       int    `json:"partitions"`
+	RetentionInHours int    `json:"retention_in_hours"`
+	MessagesEndpoint string `json:"messages_endpoint"`
+	CompartmentId    string `json:"compartment_id"`
+	CompartmentPath  string `json:"compartment_path"`
+}
+
+type stream_pool_json struct {
+	Name            string        `json:"name"`
+	Id              string        `json:"id"`
+	LifecycleState  string        `json:"lifecycle_state"`
+	IsPrivate       bool          `json:"is_private"`
+	Streams         []stream_json `json:"streams"`
+	Region          string        `json:"region"`
+	CompartmentId   string        `json:"compartment_id"`
+	CompartmentPath string        `json:"compartment_path"`
+}
+
+// -- functions
+func usage() {
+    fmt.Printf ("Usage: %s [options] [OCI_PROFILE]\n",os.Args[0])
+    fmt.Printf ("   or: %s [options] -ip\n",os.Args[0])
+    fmt.Println("")
+    fmt.Println("    A row is displayed for each stream of each stream pool.")
+    fmt.Println("    If --test STREAM_OCID is provided, a test message is published in the stream and read back to verify the")
+    fmt.Println("    connectivity to the messages endpoint of the stream (streams are not listed).")
+    fmt.Println("    If -a or --all-regions is provided, all subscribed regions are processed instead of the region of the profile.")
+    fmt.Println("    If -json is provided, the list is displayed in JSON format.")
+    fmt.Println("    If -csv is provided, the list is displayed in CSV format (with a header row).")
+    fmt.Println("    If --markdown is provided, the list is displayed as a Markdown table (to paste in a wiki or a pull request).")
+    fmt.Printf ("    If --parallelism N is provided, N compartments are processed at the same time (default %d).\n", ocihelpers.DefaultCompartmentParallelism)
+    fmt.Println("    If --output-file FILE is provided, the output is written to this file instead of stdout.")
+    fmt.Println("    If --refresh is provided, the compartments are listed again instead of being read from the local cache file.")
+    fmt.Println("    If --quiet is provided, the header rows and the summary line are not displayed.")
+    fmt.Println("    A progress indicator is displayed on stderr during the scan (unless stderr is redirected or --quiet is provided).")
+    fmt.Println("")
+    fmt.Println("    Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results (some regions failed)")
+    ocihelpers.PrintAuthUsage(config_file)
+	os.Exit (1)
+}
+
+// region of a resource, given by its OCID (ex: ocid1.stream.oc1.eu-frankfurt-1.xxx or ocid1.stream.oc1.fra.xxx)
+func ocid_region(ocid string) string {
+	fields := strings.Split(ocid, ".")
+	if len(fields) < 5 || fields[3] == "" { return "" }
+	return string(common.StringToRegion(fields[3]))
+}
+
+// list the stream pools of a compartment
+func list_stream_pools(client streaming.StreamAdminClient, cpt_id string) ([]streaming.StreamPoolSummary, error) {
+	pools := make([]streaming.StreamPoolSummary, 0)
+	request := streaming.ListStreamPoolsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListStreamPools(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, p := range response.Items {
+			if p.LifecycleState != streaming.StreamPoolSummaryLifecycleStateDeleted { pools = append(pools, p) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return pools, err
+}
+
+// list the streams of a compartment (with their retention, only returned by GetStream)
+func list_streams(client streaming.StreamAdminClient, cpt_id string) ([]streaming.Stream, error) {
+	streams := make([]streaming.Stream, 0)
+	request := streaming.ListStreamsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListStreams(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, s := range response.Items {
+			if s.LifecycleState == streaming.StreamSummaryLifecycleStateDeleted { continue }
+			get_request := streaming.GetStreamRequest{ StreamId : s.Id, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+			get_response, err := client.GetStream(context.Background(), get_request)
+			if err != nil { return nil, err }
+			streams = append(streams, get_response.Stream)
+		}
+		return response.OpcNextPage, nil
+	})
+	return streams, err
+}
+
+// list the stream pools of all active compartments of a region, with their streams
+// (at most parallelism compartments processed at the same time)
+func list_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) ([]stream_pool_json, error) {
+	client, err := streaming.NewStreamAdminClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+
+	type compartment_items struct {
+		pools   []stream_pool_json
+		streams []streaming.Stream
+	}
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		var cpt_items compartment_items
+		cpt_pools, err := list_stream_pools(client, cpt_id)
+		if err != nil { return nil, err }
+		for _, p := range cpt_pools {
+			item := stream_pool_json{ Name : *p.Name, Id : *p.Id, LifecycleState : string(p.LifecycleState), Streams : make([]stream_json, 0), Region : region, CompartmentId : *p.CompartmentId }
+			if p.IsPrivate != nil { item.IsPrivate = *p.IsPrivate }
+			cpt_items.pools = append(cpt_items.pools, item)
+		}
+		cpt_items.streams, err = list_streams(client, cpt_id)
+		return cpt_items, err
+	})
+	pools := make([]stream_pool_json, 0)
+	streams := make([]streaming.Stream, 0)
+	for _, r := range results {
+		if r.Err != nil { return nil, r.Err }
+		cpt_items := r.Value.(compartment_items)
+		pools = append(pools, cpt_items.pools...)
+		streams = append(streams, cpt_items.streams...)
+	}
+
+	// streams of each stream pool (can be in another compartment than the stream pool)
+	pool_index := make(map[string]int)
+	for n, p := range pools { pool_index[p.Id] = n }
+	for _, s := range streams {
+		n, found := pool_index[*s.StreamPoolId]
+		if !found { continue }
+		item := stream_json{ Name : *s.Name, Id : *s.Id, LifecycleState : string(s.LifecycleState), Partitions : *s.Partitions, RetentionInHours : *s.RetentionInHours,
+			MessagesEndpoint : *s.MessagesEndpoint, CompartmentId : *s.CompartmentId }
+		pools[n].Streams = append(pools[n].Streams, item)
+	}
+	for n := range pools {
+		sort.Slice(pools[n].Streams, func(i, j int) bool { return pools[n].Streams[i].Name < pools[n].Streams[j].Name })
+	}
+	return pools, nil
+}
+
+// publish a test message in a stream and read it back from the messages endpoint of the stream
+func test_stream(config common.ConfigurationProvider, stream_id string) {
+	region := ocid_region(stream_id)
+	if !strings.HasPrefix(stream_id, "ocid1.stream.") || region == "" { ocihelpers.Fatal ("invalid stream OCID %s", stream_id) }
+	admin_client, err := streaming.NewStreamAdminClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	admin_client.SetRegion(region)
+	response, err := admin_client.GetStream(context.Background(), streaming.GetStreamRequest{ StreamId : common.String(stream_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+	ocihelpers.FatalIfError(err)
+	fmt.Printf ("Stream %s: messages endpoint %s\n", *response.Name, *response.MessagesEndpoint)
+
+	// publish the test message
+	client, err := streaming.NewStreamClientWithConfigurationProvider(config, *response.MessagesEndpoint)
+	ocihelpers.FatalIfError(err)
+	hostname, _ := os.Hostname()
+	value := fmt.Sprintf("test message from %s at %s", hostname, time.Now().UTC().Format("2006-01-02T15:04:05.000Z"))
+	start := time.Now()
+	put_request := streaming.PutMessagesRequest{ StreamId : common.String(stream_id),
+		PutMessagesDetails : streaming.PutMessagesDetails{ Messages : []streaming.PutMessagesDetailsEntry{ { Key : []byte("OCI_streams_list"), Value : []byte(value) } } } }
+	put_response, err := client.PutMessages(context.Background(), put_request)
+	ocihelpers.FatalIfError(err)
+	entry := put_response.Entries[0]
+	if entry.Error != nil {
+		message := *entry.Error
+		if entry.ErrorMessage != nil { message += ": "+*entry.ErrorMessage }
+		ocihelpers.Fatal ("cannot publish the test message: %s", message)
+	}
+	fmt.Printf ("Test message published in partition %s at offset %d (%s)\n", *entry.Partition, *entry.Offset, time.Since(start).Round(time.Millisecond))
+
+	// read it back from its offset
+	start = time.Now()
+	cursor_request := streaming.CreateCursorRequest{ StreamId : common.String(stream_id),
+		CreateCursorDetails : streaming.CreateCursorDetails{ Partition : entry.Partition, Type : streaming.CreateCursorDetailsTypeAtOffset, Offset : entry.Offset } }
+	cursor_response, err := client.CreateCursor(context.Background(), cursor_request)
+	ocihelpers.FatalIfError(err)
+	cursor := cursor_response.Value
+	err = ocihelpers.WaitFor(time.Second, 30*time.Second, func() (bool, error) {
+		get_response, err := client.GetMessages(context.Background(), streaming.GetMessagesRequest{ StreamId : common.String(stream_id), Cursor : cursor, Limit : common.Int(10) })
+		if err != nil { return false, err }
+		for _, m := range get_response.Items {
+			if *m.Offset == *entry.Offset && bytes.Equal(m.Value, []byte(value)) { return true, nil }
+		}
+		cursor = get_response.OpcNextCursor
+		return false, nil
+	})
+	if err != nil { ocihelpers.Fatal ("cannot read the test message: %s", err) }
+	fmt.Printf ("Test message read back from partition %s at offset %d (%s)\n", *entry.Partition, *entry.Offset, time.Since(start).Round(time.Millisecond))
+	fmt.Println ("Connectivity OK")
+}
+
+// -- main
+func main() {
+
+	// Check arguments passed
+	flag.Usage = usage
+	flag.StringVar(&config_file, "config-file", config_file, "OCI config file")
+	flag.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the cache file")
+	var instance_principal bool
+	flag.BoolVar(&instance_principal, "ip", false, "use instance principal authentication")
+	flag.BoolVar(&instance_principal, "instance-principal", false, "use instance principal authentication")
+	var all_regions bool
+	flag.BoolVar(&all_regions, "a", false, "list streams in all subscribed regions")
+	flag.BoolVar(&all_regions, "all-regions", false, "list streams in all subscribed regions")
+	test            := flag.String("test", "", "publish a test message in this stream and read it back")
+	json_output     := flag.Bool("json", false, "display output in JSON format")
+	csv_output      := flag.Bool("csv", false, "display output in CSV format")
+	markdown_output := flag.Bool("markdown", false, "display output as a Markdown table")
+	parallelism     := flag.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	output_file     := flag.String("output-file", "", "write the output to this file instead of stdout")
+	flag.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows and the summary line")
+	flag.Parse()
+	format := "text"
+	nb_formats := 0
+	for f, selected := range map[string]bool{ "json" : *json_output, "csv" : *csv_output, "markdown" : *markdown_output } {
+		if selected { format = f; nb_formats++ }
+	}
+	if nb_formats > 1 || *parallelism < 1 { usage() }
+	if *test != "" && (nb_formats > 0 || all_regions) { usage() }
+	profile := ""
+	if instance_principal {
+		if (flag.NArg() != 0) { usage() }
+	} else {
+		if (flag.NArg() > 1) { usage() }
+		profile = ocihelpers.GetProfile(flag.Args())
+	}
+
+	// Write the output to a file instead of stdout
+	if *output_file != "" { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(*output_file)) }
+
+	// Try to load OCI config from profile (or instance principal)
+	config, err := ocihelpers.GetConfigProvider(config_file, profile, instance_principal)
+	ocihelpers.FatalIfError(err)
+
+	// Test of a stream
+	if *test != "" {
+		test_stream(config, *test)
+		return
+	}
+
+	// Get the list of all compartments and sub-comparments
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// Get the stream pools in the region of the profile or in all subscribed regions (concurrently)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+	ocihelpers.StartProgress("stream pools", len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return list_region(config, tree, region, *parallelism)
+	})
+	ocihelpers.StopProgress()
+	nb_failed := 0
+	pools := make([]stream_pool_json, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			if !all_regions { ocihelpers.FatalIfError(r.Err) }
+			fmt.Fprintf (os.Stderr, "ERROR: region %s: %s\n", r.Region, r.Err)
+			nb_failed++
+			continue
+		}
+		for _, p := range r.Value.([]stream_pool_json) {
+			p.CompartmentPath = paths[p.CompartmentId]
+			for n := range p.Streams { p.Streams[n].CompartmentPath = paths[p.Streams[n].CompartmentId] }
+			pools = append(pools, p)
+		}
+	}
+	sort.SliceStable(pools, func(i, j int) bool {
+		if pools[i].CompartmentPath != pools[j].CompartmentPath { return pools[i].CompartmentPath < pools[j].CompartmentPath }
+		return pools[i].Name < pools[j].Name
+	})
+
+	// Display the results (a row per stream)
+	if format == "json" {
+		output, err := json.MarshalIndent(pools, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "stream_pool", "endpoint", "stream", "state", "partitions", "retention_h", "messages_endpoint", "compartment_path" } }
+		if all_regions { table.Headers = append(table.Headers, "region") }
+		table.Headers = append(table.Headers, "ocid")
+		nb_streams := 0
+		for _, p := range pools {
+			endpoint := "public"
+			if p.IsPrivate { endpoint = "private" }
+			if len(p.Streams) == 0 {
+				row := []string{ p.Name, endpoint, "", p.LifecycleState, "", "", "", p.CompartmentPath }
+				if all_regions { row = append(row, p.Region) }
+				table.AddRow(append(row, p.Id)...)
+			}
+			for _, s := range p.Streams {
+				nb_streams++
+				row := []string{ p.Name, endpoint, s.Name, s.LifecycleState, fmt.Sprintf("%d", s.Partitions), fmt.Sprintf("%d", s.RetentionInHours), s.MessagesEndpoint, s.CompartmentPath }
+				if all_regions { row = append(row, p.Region) }
+				table.AddRow(append(row, s.Id)...)
+			}
+		}
+		ocihelpers.FatalIfError(table.Print(format))
+		if format == "text" && !ocihelpers.Quiet {
+			fmt.Println ("")
+			fmt.Printf ("%d stream pools, %d streams\n", len(pools), nb_streams)
+		}
+	}
+
+	// Partial results if some regions could not be processed
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,369 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: browse sub-command
+//    browse : interactive terminal UI to navigate the tree of compartments (arrow keys, expand/collapse) and
+//             display the resources of the selected compartment (Search service)
+// Note: no external TUI library is used (not available in all environments): the terminal is put in raw mode
+//       with stty and the screen is drawn with ANSI escape sequences (MacOS and Linux terminals)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+	"github.com/oracle/oci-go-sdk/resourcesearch"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- constants
+const browse_help_tree      = "↑↓ move  → expand  ← collapse  Enter resources  q quit"
+const browse_help_resources = "↑↓ move  ← back  r reload  q quit"
+
+// -- types
+
+// compartment displayed in the tree (visible when all its parents are expanded)
+type browse_node struct {
+	id           string
+	name         string
+	depth        int
+	has_children bool
+}
+
+// resource of a compartment (result of the Search service)
+type browse_resource struct {
+	resource_type string
+	name          string
+	state         string
+	created       string
+	id            string
+}
+
+// state of the browser
+type browser struct {
+	tree        *ocihelpers.CompartmentTree
+	client      resourcesearch.ResourceSearchClient
+	region      string
+	expanded    map[string]bool
+	nodes       []browse_node                    // visible compartments of the tree
+	cursor      int                              // selected compartment
+	top         int                              // first compartment displayed
+	resources   map[string][]browse_resource     // resources per compartment (loaded once, r to reload)
+	compartment string                           // compartment whose resources are displayed ("" = tree)
+	res_cursor  int
+	res_top     int
+	message     string                           // error or information displayed in the status line
+	rows        int
+	cols        int
+}
+
+// -- functions
+func init() {
+	register("browse", "interactive terminal UI to browse the compartments and their resources", browse)
+}
+
+// run stty on the terminal of the browser and return its output
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// size of the terminal (24x80 if unknown)
+func terminal_size() (int, int) {
+	rows, cols := 24, 80
+	if size, err := stty("size"); err == nil { fmt.Sscanf(size, "%d %d", &rows, &cols) }
+	return rows, cols
+}
+
+// read a key in raw mode: up, down, left, right, enter, esc, pgup, pgdown, home, end or the character typed
+func read_key(reader *bufio.Reader) (string, error) {
+	b, err := reader.ReadByte()
+	if err != nil { return "", err }
+	switch b {
+	case '\r', '\n': return "enter", nil
+	case 3:          return "q", nil             // Ctrl-C
+	case 127, 8:     return "left", nil          // Backspace
+	case 27:
+		// escape sequence of a special key (the 2 bytes are received at the same time), else Esc alone
+		if reader.Buffered() < 2 { return "esc", nil }
+		seq := make([]byte, 2)
+		reader.Read(seq)
+		if seq[0] != '[' && seq[0] != 'O' { return "esc", nil }
+		switch seq[1] {
+		case 'A': return "up", nil
+		case 'B': return "down", nil
+		case 'C': return "right", nil
+		case 'D': return "left", nil
+		case 'H': return "home", nil
+		case 'F': return "end", nil
+		case '5', '6':
+			reader.ReadByte()                     // trailing ~
+			if seq[1] == '5' { return "pgup", nil }
+			return "pgdown", nil
+		}
+		return "esc", nil
+	}
+	return string(b), nil
+}
+
+// truncate a string to n characters (runes)
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n < 0 { n = 0 }
+	if len(r) <= n { return s }
+	return string(r[:n])
+}
+
+// active sub-compartments of a compartment, sorted by name
+func (b *browser) children(cpt_id string) []identity.Compartment {
+	active := make([]identity.Compartment, 0)
+	for _, c := range b.tree.Children(cpt_id) {
+		if c.LifecycleState == identity.CompartmentLifecycleStateActive { active = append(active, c) }
+	}
+	return active
+}
+
+// build the list of visible compartments (root, then the sub-compartments of the expanded compartments)
+func (b *browser) build_nodes() {
+	b.nodes = make([]browse_node, 0)
+	var add func(id string, name string, depth int)
+	add = func(id string, name string, depth int) {
+		children := b.children(id)
+		b.nodes = append(b.nodes, browse_node{ id, name, depth, len(children) > 0 })
+		if !b.expanded[id] { return }
+		for _, c := range children { add(*c.Id, *c.Name, depth + 1) }
+	}
+	add(b.tree.TenancyOCID, "root", 0)
+	if b.cursor >= len(b.nodes) { b.cursor = len(b.nodes) - 1 }
+}
+
+// list the resources of a compartment in the region of the browser (terminated and deleted resources ignored)
+func (b *browser) list_resources(cpt_id string) ([]browse_resource, error) {
+	request := resourcesearch.SearchResourcesRequest{
+		SearchDetails   : resourcesearch.StructuredSearchDetails{ Query : common.String("query all resources where compartmentId = '" + cpt_id + "'") },
+		Limit           : common.Int(1000),
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	resources := make([]browse_resource, 0)
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := b.client.SearchResources(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, r := range response.Items {
+			state := ""
+			if r.LifecycleState != nil { state = strings.ToUpper(*r.LifecycleState) }
+			if state == "TERMINATED" || state == "DELETED" { continue }
+			res := browse_resource{ resource_type : *r.ResourceType, state : state, id : *r.Identifier }
+			if r.DisplayName != nil { res.name = *r.DisplayName }
+			if r.TimeCreated != nil { res.created = r.TimeCreated.Format("2006-01-02") }
+			resources = append(resources, res)
+		}
+		return response.OpcNextPage, nil
+	})
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].resource_type != resources[j].resource_type { return resources[i].resource_type < resources[j].resource_type }
+		return strings.ToLower(resources[i].name) < strings.ToLower(resources[j].name)
+	})
+	return resources, err
+}
+
+// display the resources of the selected compartment (loaded once, unless reload is true)
+func (b *browser) open_resources(reload bool) {
+	cpt_id := b.nodes[b.cursor].id
+	if _, found := b.resources[cpt_id]; !found || reload {
+		b.message = "loading the resources of " + b.tree.FullPath(cpt_id) + " ..."
+		b.draw()
+		resources, err := b.list_resources(cpt_id)
+		if err != nil {
+			b.message = "ERROR: " + err.Error()
+			return
+		}
+		b.resources[cpt_id] = resources
+	}
+	b.compartment, b.res_cursor, b.res_top, b.message = cpt_id, 0, 0, ""
+}
+
+// keep the cursor in the list and in the displayed window of height lines
+func scroll(cursor *int, top *int, nb int, height int) {
+	if *cursor >= nb { *cursor = nb - 1 }
+	if *cursor < 0 { *cursor = 0 }
+	if *cursor < *top { *top = *cursor }
+	if *cursor >= *top + height { *top = *cursor - height + 1 }
+}
+
+// process a key, return false to quit
+func (b *browser) handle_key(key string) bool {
+	height := b.rows - 3
+	if key == "q" { return false }
+
+	// resources view
+	if b.compartment != "" {
+		switch key {
+		case "up", "k":          b.res_cursor--
+		case "down", "j":        b.res_cursor++
+		case "pgup":             b.res_cursor -= height
+		case "pgdown":           b.res_cursor += height
+		case "home":             b.res_cursor = 0
+		case "end":              b.res_cursor = len(b.resources[b.compartment]) - 1
+		case "left", "h", "esc": b.compartment, b.message = "", ""
+		case "r":                b.open_resources(true)
+		}
+		return true
+	}
+
+	// tree view
+	node := b.nodes[b.cursor]
+	switch key {
+	case "up", "k":   b.cursor--
+	case "down", "j": b.cursor++
+	case "pgup":      b.cursor -= height
+	case "pgdown":    b.cursor += height
+	case "home":      b.cursor = 0
+	case "end":       b.cursor = len(b.nodes) - 1
+	case "right", "l", "+":
+		if node.has_children { b.expanded[node.id] = true }
+	case "left", "h", "-":
+		// collapse the selected compartment, or go to its parent if already collapsed
+		if b.expanded[node.id] {
+			b.expanded[node.id] = false
+		} else {
+			for i := b.cursor - 1; i >= 0; i-- {
+				if b.nodes[i].depth < node.depth { b.cursor = i; break }
+			}
+		}
+	case " ":
+		if node.has_children { b.expanded[node.id] = !b.expanded[node.id] }
+	case "enter":
+		b.open_resources(false)
+	}
+	b.build_nodes()
+	return true
+}
+
+// draw the screen: title line, tree or resources, status line (selected OCID or message) and help line
+func (b *browser) draw() {
+	b.rows, b.cols = terminal_size()
+	height := b.rows - 3
+	lines := make([]string, 0, height)
+	title, selected, help := "", "", browse_help_tree
+
+	if b.compartment == "" {
+		scroll(&b.cursor, &b.top, len(b.nodes), height)
+		title = fmt.Sprintf("Compartments (%d) - region %s", len(b.tree.ActiveCompartmentIds()), b.region)
+		for i := b.top; i < len(b.nodes) && i < b.top + height; i++ {
+			n := b.nodes[i]
+			marker := "  "
+			if n.has_children && b.expanded[n.id] { marker = "▾ " }
+			if n.has_children && !b.expanded[n.id] { marker = "▸ " }
+			line := strings.Repeat("  ", n.depth) + marker + n.name
+			if nb, found := b.resources[n.id]; found { line += fmt.Sprintf(" (%d resources)", len(nb)) }
+			lines = append(lines, line)
+		}
+		selected = b.nodes[b.cursor].id
+	} else {
+		resources := b.resources[b.compartment]
+		scroll(&b.res_cursor, &b.res_top, len(resources), height)
+		title = fmt.Sprintf("%s - %d resources - region %s", b.tree.FullPath(b.compartment), len(resources), b.region)
+		help = browse_help_resources
+		widths := []int{ 0, 0, 0 }
+		for _, r := range resources {
+			for i, v := range []string{ r.resource_type, r.name, r.state } {
+				if len([]rune(v)) > widths[i] { widths[i] = len([]rune(v)) }
+			}
+		}
+		for i := b.res_top; i < len(resources) && i < b.res_top + height; i++ {
+			r := resources[i]
+			lines = append(lines, fmt.Sprintf("%-*s  %-*s  %-*s  %s", widths[0], r.resource_type, widths[1], r.name, widths[2], r.state, r.created))
+		}
+		if len(resources) == 0 { lines = append(lines, "(no resources in this compartment)") }
+		if b.res_cursor < len(resources) { selected = resources[b.res_cursor].id }
+	}
+
+	// build the whole screen, then write it at once to avoid flickering
+	var screen strings.Builder
+	screen.WriteString("\033[H")
+	screen.WriteString(ocihelpers.COLOR_CYAN + truncate(title, b.cols) + ocihelpers.COLOR_NORMAL + "\033[K\r\n")
+	for i := 0; i < height; i++ {
+		line := ""
+		if i < len(lines) { line = truncate(lines[i], b.cols) }
+		current := b.cursor - b.top
+		if b.compartment != "" { current = b.res_cursor - b.res_top }
+		if i == current && i < len(lines) {
+			line = "\033[7m" + line + strings.Repeat(" ", b.cols - len([]rune(line))) + "\033[27m"
+		}
+		screen.WriteString(line + "\033[K\r\n")
+	}
+	status := selected
+	if b.message != "" { status = b.message }
+	screen.WriteString(ocihelpers.COLOR_YELLOW + truncate(status, b.cols) + ocihelpers.COLOR_NORMAL + "\033[K\r\n")
+	screen.WriteString(ocihelpers.COLOR_GREY + truncate(help, b.cols) + ocihelpers.COLOR_NORMAL + "\033[K")
+	fmt.Print (screen.String())
+}
+
+// ---- browse
+func browse(args []string) {
+	formats := []string{ "text" }
+	fs, opts := new_flag_set("browse", "", formats)
+	region := fs.String("region", "", "region of the resources (default: region of the profile)")
+	fs.Parse(args)
+	if fs.NArg() != 0 || opts.output_file != "" { fs.Usage() }
+	opts.check(fs, formats)
+	stdin, err := os.Stdin.Stat()
+	if err != nil || (stdin.Mode() & os.ModeCharDevice) == 0 || !ocihelpers.StdoutIsTerminal() {
+		ocihelpers.Fatal ("browse needs a terminal (stdin and stdout not redirected)")
+	}
+
+	// compartments and Search service client
+	config := opts.config_provider()
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	client, err := resourcesearch.NewResourceSearchClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	if *region != "" {
+		client.SetRegion(*region)
+	} else {
+		*region, err = config.Region()
+		ocihelpers.FatalIfError(err)
+	}
+	b := &browser{
+		tree      : tree,
+		client    : client,
+		region    : *region,
+		expanded  : map[string]bool{ tree.TenancyOCID : true },
+		resources : make(map[string][]browse_resource),
+	}
+	b.build_nodes()
+
+	// raw mode and alternate screen, restored when leaving
+	saved, err := stty("-g")
+	ocihelpers.FatalIfError(err)
+	_, err = stty("raw", "-echo")
+	ocihelpers.FatalIfError(err)
+	fmt.Print ("\033[?1049h\033[?25l")
+	defer func() {
+		fmt.Print ("\033[?25h\033[?1049l")
+		stty(saved)
+	}()
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		b.draw()
+		key, err := read_key(reader)
+		if err != nil || !b.handle_key(key) { return }
+	}
+}
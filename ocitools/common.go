@@ -0,0 +1,90 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: options, authentication, colors and output formats shared by all sub-commands
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Use shared code from internal/ocihelpers
+//    2026-10-15: Add --output-file option
+//    2026-10-15: Add --quiet option and exit codes (ocihelpers.FatalIfError)
+//    2026-10-15: Add --refresh option (local cache file of the compartments)
+//    2026-10-15: Output file only readable by its owner for sensitive outputs (private_output)
+//    2026-10-15: --quiet also removes the header rows of the markdown format
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- types
+
+// options shared by all sub-commands
+type global_options struct {
+	profile            string
+	config_file        string
+	instance_principal bool
+	output             string
+	no_color           bool
+	output_file        string
+	private_output     bool           // output file only readable by its owner (sensitive output)
+}
+
+// -- functions
+
+// create the flag set of a sub-command with the shared options
+// output_formats is the list of output formats supported by the sub-command (the first one is the default)
+func new_flag_set(name string, args_usage string, output_formats []string) (*flag.FlagSet, *global_options) {
+	opts := &global_options{}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+
+	fs.StringVar(&opts.profile, "profile", ocihelpers.GetProfile(nil), "OCI profile (default: OCI_CLI_PROFILE environment variable or DEFAULT)")
+	fs.StringVar(&opts.config_file, "config-file", ocihelpers.GetConfigFile(), "OCI config file (default: OCI_CONFIG_FILE environment variable or ~/.oci/config)")
+	fs.BoolVar(&opts.instance_principal, "ip", false, "use instance principal authentication")
+	fs.BoolVar(&opts.instance_principal, "instance-principal", false, "use instance principal authentication")
+	fs.StringVar(&opts.output, "output", output_formats[0], "output format: "+strings.Join(output_formats, ", "))
+	fs.BoolVar(&opts.no_color, "no-color", false, "display output without colors")
+	fs.StringVar(&opts.output_file, "output-file", "", "write the output to this file instead of stdout (without colors)")
+	fs.BoolVar(&ocihelpers.Quiet, "quiet", false, "do not display the header rows (text, csv and markdown formats)")
+	fs.BoolVar(&ocihelpers.RefreshCompartmentCache, "refresh", false, "list the compartments again instead of reading the local cache file")
+
+	fs.Usage = func() {
+		fmt.Printf ("Usage: %s %s [options] %s\n", os.Args[0], name, args_usage)
+		fmt.Println("")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println("")
+		fmt.Println("Exit codes: 0 = OK, 1 = error, 2 = authentication error, 3 = partial results due to API errors")
+		os.Exit (1)
+	}
+	return fs, opts
+}
+
+// check the output format, disable colors if needed and open the output file if --output-file is provided
+// (call after parsing the options and checking the arguments of the sub-command)
+func (opts *global_options) check(fs *flag.FlagSet, output_formats []string) {
+	valid := false
+	for _, f := range output_formats {
+		if opts.output == f { valid = true }
+	}
+	if !valid { fs.Usage() }
+	ocihelpers.SetupColors(opts.no_color || opts.output != "text")
+	if opts.output_file != "" && opts.private_output { ocihelpers.FatalIfError(ocihelpers.SetPrivateOutputFile(opts.output_file)) }
+	if opts.output_file != "" && !opts.private_output { ocihelpers.FatalIfError(ocihelpers.SetOutputFile(opts.output_file)) }
+}
+
+// get the configuration provider: OCI profile from config file (API key or session token) or instance principal
+func (opts *global_options) config_provider() common.ConfigurationProvider {
+	config, err := ocihelpers.GetConfigProvider(opts.config_file, opts.profile, opts.instance_principal)
+	ocihelpers.FatalIfError(err)
+	return config
+}
@@ -0,0 +1,101 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: compartments sub-commands
+//    compartments list : list of compartments (text, JSON, CSV or Markdown)
+//    compartments tree : tree of compartments (text with colors, Graphviz DOT or HTML)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Use shared code from internal/ocihelpers
+//    2026-10-15: Add markdown output format to compartments list
+//    2026-10-15: compartments tree: --max-depth 0 only displays the root compartment (same rule for all formats)
+//    2026-10-15: Use the tree renderers (text, DOT, HTML) shared with OCI_compartments_list_formatted.go
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- functions
+func init() {
+	register("compartments list", "list compartments (text, JSON, CSV or Markdown)", compartments_list)
+	register("compartments tree", "display the tree of compartments (text, Graphviz DOT or HTML)", compartments_tree)
+}
+
+// compile the regular expression of --grep option (nil if not provided)
+func compile_grep(grep string) *regexp.Regexp {
+	if grep == "" { return nil }
+	re, err := regexp.Compile(grep)
+	if err != nil {
+		fmt.Fprintf (os.Stderr, "ERROR: invalid regular expression '%s': %s\n", grep, err)
+		os.Exit (1)
+	}
+	return re
+}
+
+// ---- compartments list
+func compartments_list(args []string) {
+	formats := []string{ "text", "json", "csv", "markdown" }
+	fs, opts := new_flag_set("compartments list", "", formats)
+	long      := fs.Bool("long", false, "display description and creation date")
+	show_tags := fs.Bool("show-tags", false, "display freeform and defined tags")
+	grep      := fs.String("grep", "", "only display compartments whose name or OCID matches this regular expression")
+	fs.Parse(args)
+	if fs.NArg() != 0 { fs.Usage() }
+	re := compile_grep(*grep)
+	opts.check(fs, formats)
+
+	t, err := ocihelpers.GetCompartmentTree(opts.config_provider())
+	ocihelpers.FatalIfError(err)
+
+	results := ocihelpers.Table{ Headers : []string{ "name", "id", "parent_id", "full_path", "lifecycle_state" } }
+	if *long      { results.Headers = append(results.Headers, "description", "time_created") }
+	if *show_tags { results.Headers = append(results.Headers, "tags") }
+	for _, c := range t.Compartments {
+		if re != nil && !re.MatchString(*c.Name) && !re.MatchString(*c.Id) { continue }
+		row := []string{ *c.Name, *c.Id, *c.CompartmentId, t.FullPath(*c.Id), string(c.LifecycleState) }
+		if *long      { row = append(row, *c.Description, c.TimeCreated.Format("2006-01-02T15:04:05Z")) }
+		if *show_tags { row = append(row, ocihelpers.FormatTags(c.FreeformTags, c.DefinedTags)) }
+		results.Rows = append(results.Rows, row)
+	}
+	sort.Slice(results.Rows, func(i, j int) bool { return results.Rows[i][3] < results.Rows[j][3] })
+	ocihelpers.FatalIfError(results.Print(opts.output))
+}
+
+// ---- compartments tree
+func compartments_tree(args []string) {
+	formats := []string{ "text", "dot", "html" }
+	fs, opts := new_flag_set("compartments tree", "", formats)
+	show_tags := fs.Bool("show-tags", false, "display freeform and defined tags")
+	max_depth := fs.Int("max-depth", -1, "maximum depth of the tree (no limit by default)")
+	sort_by   := fs.String("sort", "name", "sort sub-compartments by name or by creation date (name|created)")
+	grep      := fs.String("grep", "", "only display compartments whose name or OCID matches this regular expression (and their parents)")
+	fs.Parse(args)
+	if fs.NArg() != 0 { fs.Usage() }
+	if *sort_by != "name" && *sort_by != "created" { fs.Usage() }
+	re := compile_grep(*grep)
+	opts.check(fs, formats)
+
+	t, err := ocihelpers.GetCompartmentTree(opts.config_provider())
+	ocihelpers.FatalIfError(err)
+	t.SortBy = *sort_by
+	if re != nil { t.SetVisible(re) }
+
+	switch opts.output {
+	case "dot":
+		t.FprintDot(os.Stdout, *max_depth, *show_tags)
+	case "html":
+		t.FprintHTML(os.Stdout, *max_depth, *show_tags)
+	default:
+		t.FprintText(os.Stdout, *max_depth, *show_tags)
+	}
+}
@@ -0,0 +1,77 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: single Go program with sub-commands for OCI (Oracle Cloud Infrastructure) using OCI Go SDK
+// ex: ocitools compartments list --profile EMEAOSCf
+//     ocitools compartments tree --profile EMEAOSCf --max-depth 2
+// Authentication options (profile, config file, instance principal) and output formats are shared
+// by all sub-commands. To add a new sub-command, create a new file and call register() in its init()
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// prerequisites : - Go with OCI SDK installed
+//                 - OCI config file configured with profiles
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// -- types
+type command struct {
+	name        string                  // ex: "compartments list"
+	description string
+	run         func(args []string)     // args = arguments after the command name
+}
+
+// -- global variables
+var commands []command
+
+// -- functions
+
+// register a sub-command (called from the init() function of each file)
+func register(name string, description string, run func(args []string)) {
+	commands = append(commands, command{ name, description, run })
+}
+
+func usage() {
+	fmt.Printf ("Usage: %s COMMAND [options]\n", os.Args[0])
+	fmt.Println("")
+	fmt.Println("Commands:")
+	sort.Slice(commands, func(i, j int) bool { return commands[i].name < commands[j].name })
+	for _, c := range commands {
+		fmt.Printf ("    %-30s %s\n", c.name, c.description)
+	}
+	fmt.Println("")
+	fmt.Printf ("Use \"%s COMMAND -h\" for the options of a command.\n", os.Args[0])
+	os.Exit (1)
+}
+
+// find the command matching the first arguments (the longest command name wins)
+func find_command(args []string) (*command, []string) {
+	var found *command
+	nb_words := 0
+	for i := range commands {
+		words := strings.Fields(commands[i].name)
+		if len(words) > len(args) || len(words) <= nb_words { continue }
+		if strings.Join(args[:len(words)], " ") == commands[i].name {
+			found = &commands[i]
+			nb_words = len(words)
+		}
+	}
+	if found == nil { return nil, nil }
+	return found, args[nb_words:]
+}
+
+// -- main
+func main() {
+	cmd, args := find_command(os.Args[1:])
+	if cmd == nil { usage() }
+	cmd.run(args)
+}
@@ -0,0 +1,137 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: ocid sub-command
+//    ocid : decode and validate OCIDs (resource type, realm, region) and, unless --offline is provided, get the
+//           name, lifecycle state and compartment path of the resources (Search service)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/identity"
+	"github.com/oracle/oci-go-sdk/resourcesearch"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- functions
+func init() {
+	register("ocid", "decode and validate OCIDs, and get the name and compartment of the resources (text, JSON, CSV or Markdown)", ocid_decode)
+}
+
+// read the OCIDs from stdin (separated by spaces or new lines), ex: grep -o 'ocid1[.a-z0-9-]*' app.log | ocitools ocid -
+func read_ocids(args []string) []string {
+	if len(args) != 1 || args[0] != "-" { return args }
+	ocids := make([]string, 0)
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() { ocids = append(ocids, scanner.Text()) }
+	ocihelpers.FatalIfError(scanner.Err())
+	return ocids
+}
+
+// find a resource with the Search service: name, lifecycle state and compartment OCID (found = false if not found)
+func search_resource(client resourcesearch.ResourceSearchClient, ocid string) (name string, state string, cpt_id string, found bool, err error) {
+	request := resourcesearch.SearchResourcesRequest{
+		SearchDetails   : resourcesearch.StructuredSearchDetails{ Query : common.String("query all resources where identifier = '" + ocid + "'") },
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() },
+	}
+	response, err := client.SearchResources(context.Background(), request)
+	if err != nil || len(response.Items) == 0 { return "", "", "", false, err }
+	r := response.Items[0]
+	if r.DisplayName != nil    { name = *r.DisplayName }
+	if r.LifecycleState != nil { state = *r.LifecycleState }
+	if r.CompartmentId != nil  { cpt_id = *r.CompartmentId }
+	return name, state, cpt_id, true, nil
+}
+
+// ---- ocid
+func ocid_decode(args []string) {
+	formats := []string{ "text", "json", "csv", "markdown" }
+	fs, opts := new_flag_set("ocid", "OCID [OCID ...] (or - to read the OCIDs from stdin)", formats)
+	offline := fs.Bool("offline", false, "only decode the OCIDs (no OCI API calls, no credentials needed)")
+	fs.Parse(args)
+	ocids := read_ocids(fs.Args())
+	if len(ocids) == 0 { fs.Usage() }
+	opts.check(fs, formats)
+
+	// compartments (for the compartment paths) and Search service clients per region, unless --offline is provided
+	var config common.ConfigurationProvider
+	var tree *ocihelpers.CompartmentTree
+	var tenancy_name string
+	clients := make(map[string]resourcesearch.ResourceSearchClient)
+	if !*offline {
+		config = opts.config_provider()
+		var err error
+		tree, err = ocihelpers.GetCompartmentTree(config)
+		ocihelpers.FatalIfError(err)
+		identity_client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+		ocihelpers.FatalIfError(err)
+		response, err := identity_client.GetTenancy(context.Background(), identity.GetTenancyRequest{ TenancyId : common.String(tree.TenancyOCID), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+		ocihelpers.FatalIfError(err)
+		tenancy_name = *response.Name
+	}
+
+	results := ocihelpers.Table{ Headers : []string{ "ocid", "resource_type", "realm", "region", "name", "lifecycle_state", "compartment_path", "status" } }
+	nb_invalid, nb_failed := 0, 0
+	for _, ocid := range ocids {
+		o, err := ocihelpers.ParseOCID(ocid)
+		if err != nil {
+			nb_invalid++
+			results.AddRow(ocid, "", "", "", "", "", "", "INVALID: " + strings.TrimPrefix(err.Error(), "invalid OCID " + ocid + ": "))
+			continue
+		}
+		row := []string{ ocid, o.ResourceType, o.Realm, o.Region }
+		switch {
+		case *offline:
+			results.AddRow(append(row, "", "", "", "VALID")...)
+
+		// tenancy and compartments: found in the tree of compartments
+		case ocid == tree.TenancyOCID:
+			results.AddRow(append(row, tenancy_name, "ACTIVE", "root", "FOUND")...)
+		case o.ResourceType == "compartment":
+			if c, found := tree.Get(ocid); found {
+				results.AddRow(append(row, *c.Name, string(c.LifecycleState), tree.FullPath(*c.CompartmentId), "FOUND")...)
+			} else {
+				results.AddRow(append(row, "", "", "", "NOT_FOUND")...)
+			}
+
+		// other resources: Search service in the region of the resource (region of the profile for global resources)
+		default:
+			client, exists := clients[o.Region]
+			if !exists {
+				client, err = resourcesearch.NewResourceSearchClientWithConfigurationProvider(config)
+				ocihelpers.FatalIfError(err)
+				if o.Region != "" { client.SetRegion(o.Region) }
+				clients[o.Region] = client
+			}
+			name, state, cpt_id, found, err := search_resource(client, ocid)
+			switch {
+			case err != nil:
+				nb_failed++
+				fmt.Fprintf (os.Stderr, "ERROR: %s: %s\n", ocid, err)
+				results.AddRow(append(row, "", "", "", "ERROR")...)
+			case !found:
+				results.AddRow(append(row, "", "", "", "NOT_FOUND")...)
+			default:
+				results.AddRow(append(row, name, strings.ToUpper(state), tree.FullPath(cpt_id), "FOUND")...)
+			}
+		}
+	}
+	ocihelpers.FatalIfError(results.Print(opts.output))
+
+	// Exit code 1 if some OCIDs are invalid, 3 if some resources could not be searched
+	if nb_invalid > 0 { os.Exit (ocihelpers.ExitError) }
+	if nb_failed > 0  { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,133 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: OKE (Kubernetes clusters) sub-commands
+//    oke kubeconfig : generate the kubeconfig of a cluster and merge it in a kubeconfig file
+//                     (same as "oci ce cluster create-kubeconfig" of OCI CLI)
+// Note: the kubeconfig generated uses the OCI CLI (oci ce cluster generate-token) to get authentication tokens
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Look for the cluster by name in the compartments concurrently (--parallelism option)
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/containerengine"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- functions
+func init() {
+	register("oke kubeconfig", "generate the kubeconfig of an OKE cluster and merge it in a kubeconfig file", oke_kubeconfig)
+}
+
+// default kubeconfig file: first file of KUBECONFIG environment variable or ~/.kube/config
+func default_kubeconfig_file() string {
+	if files := filepath.SplitList(os.Getenv("KUBECONFIG")); len(files) > 0 && files[0] != "" { return files[0] }
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".kube", "config")
+}
+
+// find the OCID of a cluster from its name in all active compartments (error if not found or several clusters found)
+// (at most parallelism compartments processed at the same time)
+func find_cluster(client containerengine.ContainerEngineClient, tree *ocihelpers.CompartmentTree, name string, parallelism int) (string, error) {
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		found := make([]string, 0)
+		request := containerengine.ListClustersRequest{ CompartmentId : common.String(cpt_id), Name : common.String(name), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListClusters(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, c := range response.Items {
+				if c.LifecycleState != containerengine.ClusterLifecycleStateDeleted { found = append(found, *c.Id) }
+			}
+			return response.OpcNextPage, nil
+		})
+		return found, err
+	})
+	found := make([]string, 0)
+	for _, r := range results {
+		if r.Err != nil { return "", r.Err }
+		found = append(found, r.Value.([]string)...)
+	}
+	switch len(found) {
+	case 0:  return "", fmt.Errorf("cluster %s not found", name)
+	case 1:  return found[0], nil
+	default: return "", fmt.Errorf("%d clusters named %s found, use the OCID of the cluster: %s", len(found), name, strings.Join(found, " "))
+	}
+}
+
+// ---- oke kubeconfig
+func oke_kubeconfig(args []string) {
+	formats := []string{ "text" }
+	fs, opts := new_flag_set("oke kubeconfig", "CLUSTER_NAME_OR_OCID", formats)
+	region        := fs.String("region", "", "region of the cluster (default: region of the cluster OCID or of the profile)")
+	file          := fs.String("file", default_kubeconfig_file(), "kubeconfig file, - for stdout (default: KUBECONFIG environment variable or ~/.kube/config)")
+	overwrite     := fs.Bool("overwrite", false, "overwrite the kubeconfig file instead of merging the cluster in it")
+	token_version := fs.String("token-version", "2.0.0", "version of the kubeconfig token")
+	parallelism   := fs.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time to find the cluster by name")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *parallelism < 1 { fs.Usage() }
+	cluster := fs.Arg(0)
+	opts.check(fs, formats)
+
+	config := opts.config_provider()
+	client, err := containerengine.NewContainerEngineClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+
+	// region of the cluster (4th field of the OCID, ex: ocid1.cluster.oc1.eu-frankfurt-1.xxxx)
+	fields := strings.Split(cluster, ".")
+	if *region == "" && strings.HasPrefix(cluster, "ocid1.cluster.") && len(fields) == 5 && fields[3] != "" {
+		*region = string(common.StringToRegion(fields[3]))
+	}
+	if *region != "" { client.SetRegion(*region) }
+
+	// OCID of the cluster if the name is given
+	cluster_id := cluster
+	if !strings.HasPrefix(cluster, "ocid1.cluster.") {
+		tree, err := ocihelpers.GetCompartmentTree(config)
+		ocihelpers.FatalIfError(err)
+		cluster_id, err = find_cluster(client, tree, cluster, *parallelism)
+		ocihelpers.FatalIfError(err)
+	}
+
+	// generate the kubeconfig
+	request := containerengine.CreateKubeconfigRequest{ ClusterId : common.String(cluster_id),
+		CreateClusterKubeconfigContentDetails : containerengine.CreateClusterKubeconfigContentDetails{ TokenVersion : token_version },
+		RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	response, err := client.CreateKubeconfig(context.Background(), request)
+	ocihelpers.FatalIfError(err)
+	defer response.Content.Close()
+	content, err := ioutil.ReadAll(response.Content)
+	ocihelpers.FatalIfError(err)
+
+	if *file == "-" {
+		fmt.Print (string(content))
+		return
+	}
+
+	// merge it in the kubeconfig file (created if needed)
+	kubeconfig := string(content)
+	existing, err := ioutil.ReadFile(*file)
+	if err != nil && !os.IsNotExist(err) { ocihelpers.FatalIfError(err) }
+	if err == nil && !*overwrite {
+		kubeconfig, err = ocihelpers.MergeKubeconfig(string(existing), kubeconfig)
+		ocihelpers.FatalIfError(err)
+	}
+	ocihelpers.FatalIfError(os.MkdirAll(filepath.Dir(*file), 0700))
+	ocihelpers.FatalIfError(ioutil.WriteFile(*file, []byte(kubeconfig), 0600))
+	if !ocihelpers.Quiet {
+		fmt.Printf ("Kubeconfig of cluster %s written to file %s (current context)\n", cluster_id, *file)
+	}
+}
@@ -0,0 +1,382 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: overview sub-command
+//    overview : one-page summary of one or several tenancies (compartments, subscribed regions, instances per
+//               state, block and object storage, autonomous databases, open Cloud Guard problems, firing alarms),
+//               for a daily morning report
+// Note: Cloud Guard is not available in the version of the OCI Go SDK used, so its REST API is used directly
+//       (signed REST requests, see internal/ocihelpers/rest.go)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/database"
+	"github.com/oracle/oci-go-sdk/identity"
+	"github.com/oracle/oci-go-sdk/monitoring"
+	"github.com/oracle/oci-go-sdk/objectstorage"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- constants
+const overview_cloudguard_endpoint    = "https://cloudguard-cp-api.{region}.oci.{secondLevelDomain}"
+const overview_cloudguard_api_version = "20200131"
+
+// -- global variables
+
+// risk levels of Cloud Guard problems, from the highest to the lowest
+var overview_risk_levels = []string{ "CRITICAL", "HIGH", "MEDIUM", "LOW", "MINOR" }
+
+// -- types
+
+// summary of a tenancy
+type overview_json struct {
+	Profile              string         `json:"profile"`
+	Tenancy              string         `json:"tenancy"`
+	TenancyId            string         `json:"tenancy_id"`
+	HomeRegion           string         `json:"home_region"`
+	SubscribedRegions    []string       `json:"subscribed_regions"`
+	RegionsScanned       []string       `json:"regions_scanned"`
+	Compartments         int            `json:"compartments"`
+	Instances            map[string]int `json:"instances"`               // number of instances per lifecycle state
+	BlockVolumes         int            `json:"block_volumes"`
+	BootVolumes          int            `json:"boot_volumes"`
+	BlockStorageGBs      int64          `json:"block_storage_gbs"`       // block and boot volumes
+	Buckets              int            `json:"buckets"`
+	ObjectStorageBytes   int64          `json:"object_storage_bytes"`    // approximate size of the buckets
+	AutonomousDatabases  int            `json:"autonomous_databases"`
+	CloudGuardProblems   map[string]int `json:"cloud_guard_problems"`    // open problems per risk level (null if Cloud Guard is not enabled)
+	FiringAlarms         int            `json:"firing_alarms"`
+	Errors               []string       `json:"errors"`
+}
+
+// counts of a compartment or of a region
+type overview_counts struct {
+	instances            map[string]int
+	block_volumes        int
+	boot_volumes         int
+	block_storage_gbs    int64
+	buckets              int
+	object_storage_bytes int64
+	adbs                 int
+	firing_alarms        int
+}
+
+// clients of a region
+type overview_clients struct {
+	compute    core.ComputeClient
+	bs         core.BlockstorageClient
+	os         objectstorage.ObjectStorageClient
+	db         database.DatabaseClient
+	monitoring monitoring.MonitoringClient
+	namespace  string
+	ads        []string
+}
+
+// -- functions
+func init() {
+	register("overview", "one-page summary of tenancies for a daily report (text, JSON or Markdown)", overview)
+}
+
+// add the counts of a compartment to the counts of a region (or of a region to the counts of a tenancy)
+func (c *overview_counts) add(other overview_counts) {
+	if c.instances == nil { c.instances = make(map[string]int) }
+	for state, n := range other.instances { c.instances[state] += n }
+	c.block_volumes        += other.block_volumes
+	c.boot_volumes         += other.boot_volumes
+	c.block_storage_gbs    += other.block_storage_gbs
+	c.buckets              += other.buckets
+	c.object_storage_bytes += other.object_storage_bytes
+	c.adbs                 += other.adbs
+	c.firing_alarms        += other.firing_alarms
+}
+
+// create the clients of a region
+func new_overview_clients(config common.ConfigurationProvider, region string) (*overview_clients, error) {
+	var c overview_clients
+	var err error
+	if c.compute, err = core.NewComputeClientWithConfigurationProvider(config); err != nil { return nil, err }
+	c.compute.SetRegion(region)
+	if c.bs, err = core.NewBlockstorageClientWithConfigurationProvider(config); err != nil { return nil, err }
+	c.bs.SetRegion(region)
+	if c.os, err = objectstorage.NewObjectStorageClientWithConfigurationProvider(config); err != nil { return nil, err }
+	c.os.SetRegion(region)
+	if c.db, err = database.NewDatabaseClientWithConfigurationProvider(config); err != nil { return nil, err }
+	c.db.SetRegion(region)
+	if c.monitoring, err = monitoring.NewMonitoringClientWithConfigurationProvider(config); err != nil { return nil, err }
+	c.monitoring.SetRegion(region)
+	response, err := c.os.GetNamespace(context.Background(), objectstorage.GetNamespaceRequest{ RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+	if err != nil { return nil, err }
+	c.namespace = *response.Value
+	if c.ads, err = ocihelpers.ListAvailabilityDomains(config, region); err != nil { return nil, err }
+	return &c, nil
+}
+
+// count the resources of a compartment in a region (terminated resources are ignored)
+func overview_compartment(c *overview_clients, cpt_id string) (overview_counts, error) {
+	counts := overview_counts{ instances : make(map[string]int) }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	instances_request := core.ListInstancesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		instances_request.Page = page
+		response, err := c.compute.ListInstances(context.Background(), instances_request)
+		if err != nil { return nil, err }
+		for _, i := range response.Items {
+			if i.LifecycleState != core.InstanceLifecycleStateTerminated { counts.instances[string(i.LifecycleState)]++ }
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return counts, err }
+
+	volumes_request := core.ListVolumesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		volumes_request.Page = page
+		response, err := c.bs.ListVolumes(context.Background(), volumes_request)
+		if err != nil { return nil, err }
+		for _, v := range response.Items {
+			if v.LifecycleState == core.VolumeLifecycleStateTerminated { continue }
+			counts.block_volumes++
+			if v.SizeInGBs != nil { counts.block_storage_gbs += *v.SizeInGBs }
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return counts, err }
+
+	for _, ad := range c.ads {
+		boot_volumes_request := core.ListBootVolumesRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			boot_volumes_request.Page = page
+			response, err := c.bs.ListBootVolumes(context.Background(), boot_volumes_request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				if v.LifecycleState == core.BootVolumeLifecycleStateTerminated { continue }
+				counts.boot_volumes++
+				if v.SizeInGBs != nil { counts.block_storage_gbs += *v.SizeInGBs }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return counts, err }
+	}
+
+	// ListBuckets only returns a summary, so GetBucket is needed to get the approximate size of each bucket
+	bucket_names := make([]string, 0)
+	buckets_request := objectstorage.ListBucketsRequest{ NamespaceName : common.String(c.namespace), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		buckets_request.Page = page
+		response, err := c.os.ListBuckets(context.Background(), buckets_request)
+		if err != nil { return nil, err }
+		for _, b := range response.Items { bucket_names = append(bucket_names, *b.Name) }
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return counts, err }
+	for _, name := range bucket_names {
+		response, err := c.os.GetBucket(context.Background(), objectstorage.GetBucketRequest{ NamespaceName : common.String(c.namespace), BucketName : common.String(name),
+			Fields : []objectstorage.GetBucketFieldsEnum{ objectstorage.GetBucketFieldsApproximatesize }, RequestMetadata : metadata })
+		if err != nil { return counts, err }
+		counts.buckets++
+		if response.ApproximateSize != nil { counts.object_storage_bytes += *response.ApproximateSize }
+	}
+
+	adbs_request := database.ListAutonomousDatabasesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		adbs_request.Page = page
+		response, err := c.db.ListAutonomousDatabases(context.Background(), adbs_request)
+		if err != nil { return nil, err }
+		for _, a := range response.Items {
+			if a.LifecycleState != database.AutonomousDatabaseSummaryLifecycleStateTerminated { counts.adbs++ }
+		}
+		return response.OpcNextPage, nil
+	})
+	if err != nil { return counts, err }
+
+	alarms_request := monitoring.ListAlarmsStatusRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		alarms_request.Page = page
+		response, err := c.monitoring.ListAlarmsStatus(context.Background(), alarms_request)
+		if err != nil { return nil, err }
+		for _, a := range response.Items {
+			if a.Status == monitoring.AlarmStatusSummaryStatusFiring { counts.firing_alarms++ }
+		}
+		return response.OpcNextPage, nil
+	})
+	return counts, err
+}
+
+// count the resources of all active compartments of a region
+func overview_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) (overview_counts, error) {
+	var counts overview_counts
+	clients, err := new_overview_clients(config, region)
+	if err != nil { return counts, err }
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return overview_compartment(clients, cpt_id)
+	})
+	for _, r := range results {
+		if r.Err != nil { return counts, r.Err }
+		counts.add(r.Value.(overview_counts))
+	}
+	return counts, nil
+}
+
+// count the open Cloud Guard problems of the tenancy per risk level, in the reporting region of Cloud Guard
+// (nil if Cloud Guard is not enabled)
+func cloud_guard_problems(config common.ConfigurationProvider, tenancy_id string, region string) (map[string]int, error) {
+	client, err := ocihelpers.NewRestClient(config, overview_cloudguard_endpoint, overview_cloudguard_api_version, region)
+	if err != nil { return nil, err }
+	var configuration struct {
+		ReportingRegion string `json:"reportingRegion"`
+		Status          string `json:"status"`
+	}
+	if _, err := client.Get("/configuration", url.Values{ "compartmentId" : { tenancy_id } }, &configuration); err != nil { return nil, err }
+	if configuration.Status != "ENABLED" { return nil, nil }
+	if configuration.ReportingRegion != region {
+		client, err = ocihelpers.NewRestClient(config, overview_cloudguard_endpoint, overview_cloudguard_api_version, configuration.ReportingRegion)
+		if err != nil { return nil, err }
+	}
+
+	problems := make(map[string]int)
+	query := url.Values{ "compartmentId" : { tenancy_id }, "compartmentIdInSubtree" : { "true" }, "accessLevel" : { "ACCESSIBLE" }, "lifecycleDetail" : { "OPEN" } }
+	err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		if page != nil { query.Set("page", *page) }
+		var collection struct {
+			Items []struct {
+				RiskLevel string `json:"riskLevel"`
+			} `json:"items"`
+		}
+		next_page, err := client.Get("/problems", query, &collection)
+		if err != nil { return nil, err }
+		for _, p := range collection.Items { problems[p.RiskLevel]++ }
+		return next_page, nil
+	})
+	return problems, err
+}
+
+// summary of the tenancy of a configuration provider (API errors of regions or Cloud Guard are returned in the
+// Errors field, other errors are fatal)
+func overview_tenancy(config common.ConfigurationProvider, profile string, all_regions bool, parallelism int) overview_json {
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	identity_client, err := identity.NewIdentityClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	response, err := identity_client.GetTenancy(context.Background(), identity.GetTenancyRequest{ TenancyId : common.String(tree.TenancyOCID), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } })
+	ocihelpers.FatalIfError(err)
+	subscribed, err := ocihelpers.ListSubscribedRegions(config)
+	ocihelpers.FatalIfError(err)
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	ocihelpers.FatalIfError(err)
+
+	o := overview_json{ Profile : profile, Tenancy : *response.Name, TenancyId : tree.TenancyOCID, HomeRegion : subscribed[0], SubscribedRegions : subscribed,
+		RegionsScanned : regions, Compartments : len(tree.ActiveCompartmentIds()) - 1, Errors : make([]string, 0) }
+
+	// resources of the compartments in each region
+	ocihelpers.StartProgress("overview " + o.Tenancy, len(regions), len(tree.ActiveCompartmentIds()))
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return overview_region(config, tree, region, parallelism)
+	})
+	ocihelpers.StopProgress()
+	var counts overview_counts
+	for _, r := range results {
+		if r.Err != nil {
+			o.Errors = append(o.Errors, fmt.Sprintf("region %s: %s", r.Region, r.Err))
+			continue
+		}
+		counts.add(r.Value.(overview_counts))
+	}
+	o.Instances, o.BlockVolumes, o.BootVolumes, o.BlockStorageGBs = counts.instances, counts.block_volumes, counts.boot_volumes, counts.block_storage_gbs
+	o.Buckets, o.ObjectStorageBytes, o.AutonomousDatabases, o.FiringAlarms = counts.buckets, counts.object_storage_bytes, counts.adbs, counts.firing_alarms
+	if o.Instances == nil { o.Instances = make(map[string]int) }
+
+	// open problems of Cloud Guard (all regions)
+	o.CloudGuardProblems, err = cloud_guard_problems(config, tree.TenancyOCID, regions[0])
+	if err != nil { o.Errors = append(o.Errors, fmt.Sprintf("Cloud Guard: %s", err)) }
+	return o
+}
+
+// total of a map of counts and details (ex: 12 (10 RUNNING, 2 STOPPED)), keys in the given order then sorted
+func overview_details(counts map[string]int, order []string) string {
+	keys, ordered := make([]string, 0), make(map[string]bool)
+	for _, k := range order {
+		ordered[k] = true
+		if _, found := counts[k]; found { keys = append(keys, k) }
+	}
+	others := make([]string, 0)
+	for k := range counts {
+		if !ordered[k] { others = append(others, k) }
+	}
+	sort.Strings(others)
+	total, details := 0, make([]string, 0)
+	for _, k := range append(keys, others...) {
+		total += counts[k]
+		details = append(details, fmt.Sprintf("%d %s", counts[k], k))
+	}
+	if len(details) == 0 { return "0" }
+	return fmt.Sprintf("%d (%s)", total, strings.Join(details, ", "))
+}
+
+// ---- overview
+func overview(args []string) {
+	formats := []string{ "text", "json", "markdown" }
+	fs, opts := new_flag_set("overview", "[PROFILE ...] (default: --profile)", formats)
+	all_regions := fs.Bool("all-regions", false, "scan all subscribed regions (default: region of the profile)")
+	parallelism := fs.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed concurrently in each region")
+	fs.Parse(args)
+	if *parallelism < 1 || (opts.instance_principal && fs.NArg() > 0) { fs.Usage() }
+	opts.check(fs, formats)
+
+	// one summary per tenancy (profiles given as arguments, or profile of --profile or instance principal)
+	profiles := fs.Args()
+	if len(profiles) == 0 { profiles = []string{ opts.profile } }
+	if opts.instance_principal { profiles = []string{ "instance_principal" } }
+	overviews := make([]overview_json, 0)
+	nb_failed := 0
+	for _, profile := range profiles {
+		opts.profile = profile
+		o := overview_tenancy(opts.config_provider(), profile, *all_regions, *parallelism)
+		for _, e := range o.Errors { fmt.Fprintf (os.Stderr, "ERROR: %s: %s\n", o.Tenancy, e) }
+		nb_failed += len(o.Errors)
+		overviews = append(overviews, o)
+	}
+
+	// Display the results: one column per tenancy
+	if opts.output == "json" {
+		output, err := json.MarshalIndent(overviews, "", "  ")
+		ocihelpers.FatalIfError(err)
+		fmt.Println(string(output))
+	} else {
+		table := ocihelpers.Table{ Headers : []string{ "" } }
+		rows := [][]string{ { "tenancy" }, { "home region" }, { "subscribed regions" }, { "regions scanned" }, { "compartments" }, { "compute instances" },
+			{ "block and boot volumes" }, { "block storage" }, { "buckets" }, { "object storage" }, { "autonomous databases" }, { "open Cloud Guard problems" },
+			{ "firing alarms" }, { "errors" } }
+		for _, o := range overviews {
+			table.Headers = append(table.Headers, o.Profile)
+			problems := "Cloud Guard not enabled"
+			if o.CloudGuardProblems != nil { problems = overview_details(o.CloudGuardProblems, overview_risk_levels) }
+			values := []string{ o.Tenancy, o.HomeRegion, fmt.Sprintf("%d (%s)", len(o.SubscribedRegions), strings.Join(o.SubscribedRegions, ", ")),
+				strings.Join(o.RegionsScanned, ", "), fmt.Sprintf("%d", o.Compartments), overview_details(o.Instances, []string{ "RUNNING", "STOPPED" }),
+				fmt.Sprintf("%d block, %d boot", o.BlockVolumes, o.BootVolumes), fmt.Sprintf("%.1f TB", float64(o.BlockStorageGBs) / 1024),
+				fmt.Sprintf("%d", o.Buckets), fmt.Sprintf("%.1f TB (approximate)", float64(o.ObjectStorageBytes) / (1 << 40)), fmt.Sprintf("%d", o.AutonomousDatabases),
+				problems, fmt.Sprintf("%d", o.FiringAlarms), fmt.Sprintf("%d", len(o.Errors)) }
+			for i := range rows { rows[i] = append(rows[i], values[i]) }
+		}
+		for _, row := range rows { table.AddRow(row...) }
+		ocihelpers.FatalIfError(table.Print(opts.output))
+	}
+
+	// Exit code 3 if some regions (or Cloud Guard) could not be scanned
+	if nb_failed > 0 { os.Exit (ocihelpers.ExitPartialResults) }
+}
@@ -0,0 +1,229 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: secrets (Vault service) sub-commands
+//    secrets list : list of secrets with their versions and expiry (text, JSON, CSV or Markdown)
+//    secrets get  : get the content of a secret (decoded) for deployment scripts (requires --reveal)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Process the compartments concurrently in secrets list (--parallelism option)
+//    2026-10-15: Display a progress indicator on stderr during secrets list
+//    2026-10-15: secrets get: metadata read without the content and displayed on stderr without --reveal (exit code 1), output file created with mode 0600
+//    2026-10-15: Look for the secret by name in the compartments concurrently in secrets get (--parallelism option)
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/secrets"
+	"github.com/oracle/oci-go-sdk/vault"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- functions
+func init() {
+	register("secrets list", "list secrets with their versions and expiry (text, JSON, CSV or Markdown)", secrets_list)
+	register("secrets get", "get the content of a secret by name or OCID (requires --reveal)", secrets_get)
+}
+
+// list the secrets (not deleted) of a compartment, optionally only the secrets of a vault or with a name
+func list_secrets(client vault.VaultsClient, cpt_id string, vault_id string, name string) ([]vault.SecretSummary, error) {
+	found := make([]vault.SecretSummary, 0)
+	request := vault.ListSecretsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	if vault_id != "" { request.VaultId = common.String(vault_id) }
+	if name != ""     { request.Name = common.String(name) }
+	err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		response, err := client.ListSecrets(context.Background(), request)
+		if err != nil { return nil, err }
+		for _, s := range response.Items {
+			if s.LifecycleState != vault.SecretSummaryLifecycleStateDeleted { found = append(found, s) }
+		}
+		return response.OpcNextPage, nil
+	})
+	return found, err
+}
+
+// find the OCID of a secret from its name in all active compartments (error if not found or several secrets found)
+// (at most parallelism compartments processed at the same time)
+func find_secret(client vault.VaultsClient, tree *ocihelpers.CompartmentTree, vault_id string, name string, parallelism int) (string, error) {
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		return list_secrets(client, cpt_id, vault_id, name)
+	})
+	found := make([]string, 0)
+	for _, r := range results {
+		if r.Err != nil { return "", r.Err }
+		for _, s := range r.Value.([]vault.SecretSummary) { found = append(found, *s.Id) }
+	}
+	switch len(found) {
+	case 0:  return "", fmt.Errorf("secret %s not found", name)
+	case 1:  return found[0], nil
+	default: return "", fmt.Errorf("%d secrets named %s found, use --vault or the OCID of the secret: %s", len(found), name, strings.Join(found, " "))
+	}
+}
+
+// region of a resource from its OCID (4th field, ex: ocid1.vaultsecret.oc1.eu-frankfurt-1.xxxx), empty if not found
+func region_from_ocid(ocid string) string {
+	o, err := ocihelpers.ParseOCID(ocid)
+	if err != nil { return "" }
+	return o.Region
+}
+
+// display on stderr the metadata of a version of a secret (current version if version is 0), without its content
+func print_secret_version(config common.ConfigurationProvider, region string, secret_id string, version int64) {
+	client, err := vault.NewVaultsClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	if region != "" { client.SetRegion(region) }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+	if version == 0 {
+		response, err := client.GetSecret(context.Background(), vault.GetSecretRequest{ SecretId : common.String(secret_id), RequestMetadata : metadata })
+		ocihelpers.FatalIfError(err)
+		if response.CurrentVersionNumber == nil { ocihelpers.Fatal ("no current version for secret %s", secret_id) }
+		version = *response.CurrentVersionNumber
+	}
+	response, err := client.GetSecretVersion(context.Background(), vault.GetSecretVersionRequest{ SecretId : common.String(secret_id), SecretVersionNumber : common.Int64(version), RequestMetadata : metadata })
+	ocihelpers.FatalIfError(err)
+	v := response.SecretVersion
+
+	stages := make([]string, 0)
+	for _, s := range v.Stages { stages = append(stages, string(s)) }
+	fmt.Fprintf (os.Stderr, "secret_id    : %s\n", secret_id)
+	fmt.Fprintf (os.Stderr, "version      : %d\n", version)
+	fmt.Fprintf (os.Stderr, "stages       : %s\n", strings.Join(stages, " "))
+	if v.TimeCreated != nil                { fmt.Fprintf (os.Stderr, "time_created : %s\n", v.TimeCreated.Format("2006-01-02T15:04:05Z")) }
+	if v.TimeOfCurrentVersionExpiry != nil { fmt.Fprintf (os.Stderr, "time_expiry  : %s\n", v.TimeOfCurrentVersionExpiry.Format("2006-01-02T15:04:05Z")) }
+}
+
+// ---- secrets list
+func secrets_list(args []string) {
+	formats := []string{ "text", "json", "csv", "markdown" }
+	fs, opts := new_flag_set("secrets list", "", formats)
+	region      := fs.String("region", "", "region of the secrets (default: region of the profile)")
+	vault_id    := fs.String("vault", "", "only list the secrets of the vault with this OCID")
+	parallelism := fs.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time")
+	fs.Parse(args)
+	if fs.NArg() != 0 || *parallelism < 1 { fs.Usage() }
+	opts.check(fs, formats)
+
+	config := opts.config_provider()
+	client, err := vault.NewVaultsClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	if *region == "" && *vault_id != "" { *region = region_from_ocid(*vault_id) }
+	if *region != "" { client.SetRegion(*region) }
+
+	t, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := t.FullPaths()
+	paths[t.TenancyOCID] = "root"
+
+	results := ocihelpers.Table{ Headers : []string{ "name", "lifecycle_state", "versions", "current_version", "time_created", "current_version_expiry", "vault_id", "compartment_path", "id" } }
+	// compartments processed concurrently (at most --parallelism compartments at the same time)
+	ocihelpers.StartProgress("secrets", 1, len(t.ActiveCompartmentIds()))
+	cpt_results := ocihelpers.ForEachCompartment(t.ActiveCompartmentIds(), *parallelism, func(cpt_id string) (interface{}, error) {
+		cpt_secrets, err := list_secrets(client, cpt_id, *vault_id, "")
+		if err != nil { return nil, err }
+		rows := make([][]string, 0, len(cpt_secrets))
+		for _, s := range cpt_secrets {
+			// versions of the secret (the current one has the CURRENT stage)
+			nb_versions, current := 0, ""
+			request := vault.ListSecretVersionsRequest{ SecretId : s.Id, RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+			err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				request.Page = page
+				response, err := client.ListSecretVersions(context.Background(), request)
+				if err != nil { return nil, err }
+				for _, v := range response.Items {
+					nb_versions++
+					for _, stage := range v.Stages {
+						if stage == vault.SecretVersionSummaryStagesCurrent { current = fmt.Sprintf("%d", *v.VersionNumber) }
+					}
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+			expiry := ""
+			if s.TimeOfCurrentVersionExpiry != nil { expiry = s.TimeOfCurrentVersionExpiry.Format("2006-01-02T15:04:05Z") }
+			rows = append(rows, []string{ *s.SecretName, string(s.LifecycleState), fmt.Sprintf("%d", nb_versions), current, s.TimeCreated.Format("2006-01-02T15:04:05Z"), expiry, *s.VaultId, paths[*s.CompartmentId], *s.Id })
+		}
+		return rows, nil
+	})
+	ocihelpers.StopProgress()
+	for _, r := range cpt_results {
+		ocihelpers.FatalIfError(r.Err)
+		for _, row := range r.Value.([][]string) { results.AddRow(row...) }
+	}
+	sort.SliceStable(results.Rows, func(i, j int) bool {
+		if results.Rows[i][7] != results.Rows[j][7] { return results.Rows[i][7] < results.Rows[j][7] }
+		return results.Rows[i][0] < results.Rows[j][0]
+	})
+	ocihelpers.FatalIfError(results.Print(opts.output))
+}
+
+// ---- secrets get
+func secrets_get(args []string) {
+	formats := []string{ "text" }
+	fs, opts := new_flag_set("secrets get", "SECRET_NAME_OR_OCID", formats)
+	region      := fs.String("region", "", "region of the secret (default: region of the secret OCID or of the profile)")
+	vault_id    := fs.String("vault", "", "OCID of the vault of the secret (needed if several secrets have the same name)")
+	version     := fs.Int64("version", 0, "version number of the secret (default: current version)")
+	reveal      := fs.Bool("reveal", false, "display the content of the secret (only the metadata are displayed on stderr otherwise)")
+	raw         := fs.Bool("base64", false, "display the content of the secret in base64 (not decoded)")
+	parallelism := fs.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time to find the secret by name")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *version < 0 || *parallelism < 1 { fs.Usage() }
+	secret := fs.Arg(0)
+	opts.private_output = true
+	opts.check(fs, formats)
+
+	config := opts.config_provider()
+	if *region == "" { *region = region_from_ocid(secret) }
+	if *region == "" && *vault_id != "" { *region = region_from_ocid(*vault_id) }
+
+	// OCID of the secret if the name is given
+	secret_id := secret
+	if !strings.HasPrefix(secret, "ocid1.vaultsecret.") {
+		vaults_client, err := vault.NewVaultsClientWithConfigurationProvider(config)
+		ocihelpers.FatalIfError(err)
+		if *region != "" { vaults_client.SetRegion(*region) }
+		tree, err := ocihelpers.GetCompartmentTree(config)
+		ocihelpers.FatalIfError(err)
+		secret_id, err = find_secret(vaults_client, tree, *vault_id, secret, *parallelism)
+		ocihelpers.FatalIfError(err)
+	}
+
+	// without --reveal, only the metadata of the secret version are displayed on stderr (the content is not read)
+	// and the exit code is 1, so that a deployment script cannot use them as the content by mistake
+	if !*reveal {
+		print_secret_version(config, *region, secret_id, *version)
+		ocihelpers.Fatal ("content of the secret not displayed: use --reveal to display it")
+	}
+
+	// get the secret bundle
+	client, err := secrets.NewSecretsClientWithConfigurationProvider(config)
+	ocihelpers.FatalIfError(err)
+	if *region != "" { client.SetRegion(*region) }
+	request := secrets.GetSecretBundleRequest{ SecretId : common.String(secret_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+	if *version > 0 { request.VersionNumber = version }
+	response, err := client.GetSecretBundle(context.Background(), request)
+	ocihelpers.FatalIfError(err)
+	bundle := response.SecretBundle
+
+	content, ok := bundle.SecretBundleContent.(secrets.Base64SecretBundleContentDetails)
+	if !ok || content.Content == nil { ocihelpers.Fatal ("unsupported content for secret %s", secret_id) }
+	if *raw {
+		fmt.Println (*content.Content)
+		return
+	}
+	decoded, err := base64.StdEncoding.DecodeString(*content.Content)
+	ocihelpers.FatalIfError(err)
+	fmt.Print (string(decoded))
+}
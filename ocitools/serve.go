@@ -0,0 +1,352 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: serve sub-command
+//    serve : HTTP server exposing the inventory of the tenant (compartments, instances, volumes) as JSON REST
+//            endpoints, refreshed periodically, so that dashboards can query it without OCI credentials
+// Note: the server has no TLS: use it behind a reverse proxy, or on localhost (default), and use --token-file
+//       (or the OCITOOLS_TOKEN environment variable) to require a bearer token
+//       the token is never given on the command line (visible by all local users with ps)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+//    2026-10-15: Compare the bearer token in constant time
+//    2026-10-15: Read the bearer token from --token-file or OCITOOLS_TOKEN instead of the command line
+//    2026-10-15: Collect the compartments concurrently (--parallelism option)
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/identity"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- types
+type inventory_compartment struct {
+	Name           string `json:"name"`
+	Id             string `json:"id"`
+	Path           string `json:"path"`
+	Description    string `json:"description"`
+	LifecycleState string `json:"lifecycle_state"`
+	TimeCreated    string `json:"time_created"`
+}
+
+type inventory_instance struct {
+	Name               string  `json:"name"`
+	Id                 string  `json:"id"`
+	Shape              string  `json:"shape"`
+	Ocpus              float32 `json:"ocpus"`
+	MemoryInGBs        float32 `json:"memory_in_gbs"`
+	LifecycleState     string  `json:"lifecycle_state"`
+	AvailabilityDomain string  `json:"availability_domain"`
+	Region             string  `json:"region"`
+	CompartmentId      string  `json:"compartment_id"`
+	CompartmentPath    string  `json:"compartment_path"`
+	TimeCreated        string  `json:"time_created"`
+}
+
+type inventory_volume struct {
+	Type               string `json:"type"`            // block or boot
+	Name               string `json:"name"`
+	Id                 string `json:"id"`
+	SizeInGBs          int64  `json:"size_in_gbs"`
+	VpusPerGB          int64  `json:"vpus_per_gb"`
+	LifecycleState     string `json:"lifecycle_state"`
+	AvailabilityDomain string `json:"availability_domain"`
+	Region             string `json:"region"`
+	CompartmentId      string `json:"compartment_id"`
+	CompartmentPath    string `json:"compartment_path"`
+	TimeCreated        string `json:"time_created"`
+}
+
+// instances and volumes of a region
+type inventory_region struct {
+	instances []inventory_instance
+	volumes   []inventory_volume
+}
+
+// inventory served (replaced at each refresh)
+type inventory struct {
+	lock         sync.RWMutex
+	compartments []inventory_compartment
+	instances    []inventory_instance
+	volumes      []inventory_volume
+	last_refresh time.Time
+	errors       []string          // errors of the last refresh (the data of the failed regions are the previous ones)
+}
+
+// -- functions
+func init() {
+	register("serve", "serve the inventory (compartments, instances, volumes) as JSON REST endpoints", serve)
+}
+
+// log a line with the date and time
+func serve_log(format string, args ...interface{}) {
+	fmt.Printf ("%s: %s\n", time.Now().UTC().Format("2006/01/02 15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// collect the instances and volumes (not terminated) of all active compartments of a region
+// (at most parallelism compartments processed at the same time)
+func collect_region(config common.ConfigurationProvider, tree *ocihelpers.CompartmentTree, region string, parallelism int) (inventory_region, error) {
+	var inv inventory_region
+	compute_client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return inv, err }
+	compute_client.SetRegion(region)
+	bs_client, err := core.NewBlockstorageClientWithConfigurationProvider(config)
+	if err != nil { return inv, err }
+	bs_client.SetRegion(region)
+	ads, err := ocihelpers.ListAvailabilityDomains(config, region)
+	if err != nil { return inv, err }
+	metadata := common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() }
+
+	results := ocihelpers.ForEachCompartment(tree.ActiveCompartmentIds(), parallelism, func(cpt_id string) (interface{}, error) {
+		var cpt inventory_region
+		instances_request := core.ListInstancesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			instances_request.Page = page
+			response, err := compute_client.ListInstances(context.Background(), instances_request)
+			if err != nil { return nil, err }
+			for _, i := range response.Items {
+				if i.LifecycleState == core.InstanceLifecycleStateTerminated { continue }
+				item := inventory_instance{ Name : *i.DisplayName, Id : *i.Id, Shape : *i.Shape, LifecycleState : string(i.LifecycleState), AvailabilityDomain : *i.AvailabilityDomain,
+					Region : region, CompartmentId : cpt_id, TimeCreated : i.TimeCreated.Format("2006-01-02T15:04:05Z") }
+				if i.ShapeConfig != nil && i.ShapeConfig.Ocpus != nil       { item.Ocpus = *i.ShapeConfig.Ocpus }
+				if i.ShapeConfig != nil && i.ShapeConfig.MemoryInGBs != nil { item.MemoryInGBs = *i.ShapeConfig.MemoryInGBs }
+				cpt.instances = append(cpt.instances, item)
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		volumes_request := core.ListVolumesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+		err = ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			volumes_request.Page = page
+			response, err := bs_client.ListVolumes(context.Background(), volumes_request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				if v.LifecycleState == core.VolumeLifecycleStateTerminated { continue }
+				item := inventory_volume{ Type : "block", Name : *v.DisplayName, Id : *v.Id, SizeInGBs : *v.SizeInGBs, LifecycleState : string(v.LifecycleState), AvailabilityDomain : *v.AvailabilityDomain,
+					Region : region, CompartmentId : cpt_id, TimeCreated : v.TimeCreated.Format("2006-01-02T15:04:05Z") }
+				if v.VpusPerGB != nil { item.VpusPerGB = *v.VpusPerGB }
+				cpt.volumes = append(cpt.volumes, item)
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+
+		for _, ad := range ads {
+			boot_volumes_request := core.ListBootVolumesRequest{ AvailabilityDomain : common.String(ad), CompartmentId : common.String(cpt_id), RequestMetadata : metadata }
+			err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+				boot_volumes_request.Page = page
+				response, err := bs_client.ListBootVolumes(context.Background(), boot_volumes_request)
+				if err != nil { return nil, err }
+				for _, v := range response.Items {
+					if v.LifecycleState == core.BootVolumeLifecycleStateTerminated { continue }
+					item := inventory_volume{ Type : "boot", Name : *v.DisplayName, Id : *v.Id, SizeInGBs : *v.SizeInGBs, LifecycleState : string(v.LifecycleState), AvailabilityDomain : *v.AvailabilityDomain,
+						Region : region, CompartmentId : cpt_id, TimeCreated : v.TimeCreated.Format("2006-01-02T15:04:05Z") }
+					if v.VpusPerGB != nil { item.VpusPerGB = *v.VpusPerGB }
+					cpt.volumes = append(cpt.volumes, item)
+				}
+				return response.OpcNextPage, nil
+			})
+			if err != nil { return nil, err }
+		}
+		return cpt, nil
+	})
+	for _, r := range results {
+		if r.Err != nil { return inv, r.Err }
+		inv.instances = append(inv.instances, r.Value.(inventory_region).instances...)
+		inv.volumes = append(inv.volumes, r.Value.(inventory_region).volumes...)
+	}
+	return inv, nil
+}
+
+// refresh the inventory (the previous instances and volumes of a region are kept if the region fails)
+func (inv *inventory) refresh(config common.ConfigurationProvider, all_regions bool, parallelism int) error {
+	start := time.Now()
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	if err != nil { return err }
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+	regions, err := ocihelpers.GetRegions(config, all_regions)
+	if err != nil { return err }
+
+	compartments := make([]inventory_compartment, 0)
+	for _, c := range tree.Compartments {
+		if c.LifecycleState == identity.CompartmentLifecycleStateDeleted { continue }
+		compartments = append(compartments, inventory_compartment{ Name : *c.Name, Id : *c.Id, Path : paths[*c.Id], Description : *c.Description,
+			LifecycleState : string(c.LifecycleState), TimeCreated : c.TimeCreated.Format("2006-01-02T15:04:05Z") })
+	}
+	sort.Slice(compartments, func(i, j int) bool { return compartments[i].Path < compartments[j].Path })
+
+	results := ocihelpers.ForEachRegion(regions, ocihelpers.DefaultRegionParallelism, func(region string) (interface{}, error) {
+		return collect_region(config, tree, region, parallelism)
+	})
+
+	inv.lock.Lock()
+	defer inv.lock.Unlock()
+	failed := make(map[string]bool)
+	errors := make([]string, 0)
+	instances, volumes := make([]inventory_instance, 0), make([]inventory_volume, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			failed[r.Region] = true
+			errors = append(errors, fmt.Sprintf("region %s: %s", r.Region, r.Err))
+			continue
+		}
+		for _, i := range r.Value.(inventory_region).instances {
+			i.CompartmentPath = paths[i.CompartmentId]
+			instances = append(instances, i)
+		}
+		for _, v := range r.Value.(inventory_region).volumes {
+			v.CompartmentPath = paths[v.CompartmentId]
+			volumes = append(volumes, v)
+		}
+	}
+	for _, i := range inv.instances {
+		if failed[i.Region] { instances = append(instances, i) }
+	}
+	for _, v := range inv.volumes {
+		if failed[v.Region] { volumes = append(volumes, v) }
+	}
+	sort.SliceStable(instances, func(i, j int) bool { return instances[i].CompartmentPath + "/" + instances[i].Name < instances[j].CompartmentPath + "/" + instances[j].Name })
+	sort.SliceStable(volumes, func(i, j int) bool { return volumes[i].CompartmentPath + "/" + volumes[i].Name < volumes[j].CompartmentPath + "/" + volumes[j].Name })
+	inv.compartments, inv.instances, inv.volumes = compartments, instances, volumes
+	inv.last_refresh, inv.errors = time.Now(), errors
+
+	serve_log("inventory refreshed in %.0f seconds: %d compartments, %d instances, %d volumes, %d regions failed", time.Since(start).Seconds(), len(compartments), len(instances), len(volumes), len(errors))
+	for _, e := range errors { serve_log("ERROR: %s", e) }
+	return nil
+}
+
+// true if an item matches the region and compartment filters of the request (?region=REGION&compartment=PATH)
+// the compartment filter includes the sub-compartments, the region filter is ignored for global resources (empty region)
+func match_filters(r *http.Request, region string, path string) bool {
+	if want := r.URL.Query().Get("region"); want != "" && region != "" && want != region { return false }
+	if want := strings.TrimSuffix(r.URL.Query().Get("compartment"), "/"); want != "" && path != want && !strings.HasPrefix(path, want + "/") { return false }
+	return true
+}
+
+// write a JSON response
+func write_json(w http.ResponseWriter, status int, value interface{}) {
+	output, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(output, '\n'))
+}
+
+// HTTP handler of the inventory: GET only, bearer token checked if needed, access logged
+func (inv *inventory) handler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		inv.lock.RLock()
+		defer inv.lock.RUnlock()
+		write_json(w, http.StatusOK, map[string]interface{}{ "last_refresh" : inv.last_refresh.UTC().Format("2006-01-02T15:04:05Z"), "errors" : inv.errors })
+	})
+	mux.HandleFunc("/compartments", func(w http.ResponseWriter, r *http.Request) {
+		inv.lock.RLock()
+		defer inv.lock.RUnlock()
+		items := make([]inventory_compartment, 0)
+		for _, c := range inv.compartments {
+			if match_filters(r, "", c.Path) { items = append(items, c) }
+		}
+		write_json(w, http.StatusOK, items)
+	})
+	mux.HandleFunc("/instances", func(w http.ResponseWriter, r *http.Request) {
+		inv.lock.RLock()
+		defer inv.lock.RUnlock()
+		items := make([]inventory_instance, 0)
+		for _, i := range inv.instances {
+			if match_filters(r, i.Region, i.CompartmentPath) { items = append(items, i) }
+		}
+		write_json(w, http.StatusOK, items)
+	})
+	mux.HandleFunc("/volumes", func(w http.ResponseWriter, r *http.Request) {
+		inv.lock.RLock()
+		defer inv.lock.RUnlock()
+		items := make([]inventory_volume, 0)
+		for _, v := range inv.volumes {
+			if match_filters(r, v.Region, v.CompartmentPath) { items = append(items, v) }
+		}
+		write_json(w, http.StatusOK, items)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer " + token)) != 1:
+			write_json(w, http.StatusUnauthorized, map[string]string{ "error" : "missing or invalid bearer token" })
+			serve_log("%s %s %s: 401", r.RemoteAddr, r.Method, r.URL.Path)
+		case r.Method != http.MethodGet:
+			write_json(w, http.StatusMethodNotAllowed, map[string]string{ "error" : "only GET is supported" })
+			serve_log("%s %s %s: 405", r.RemoteAddr, r.Method, r.URL.Path)
+		default:
+			mux.ServeHTTP(w, r)
+			serve_log("%s %s %s", r.RemoteAddr, r.Method, r.URL.RequestURI())
+		}
+	})
+}
+
+// bearer token required in the requests: content of the token file (if given) or OCITOOLS_TOKEN environment
+// variable (empty if none)
+func serve_token(token_file string) (string, error) {
+	if token_file == "" { return strings.TrimSpace(os.Getenv("OCITOOLS_TOKEN")), nil }
+	data, err := ioutil.ReadFile(ocihelpers.ExpandPath(token_file))
+	if err != nil { return "", err }
+	token := strings.TrimSpace(string(data))
+	if token == "" { return "", fmt.Errorf("empty token file %s", token_file) }
+	return token, nil
+}
+
+// ---- serve
+func serve(args []string) {
+	formats := []string{ "json" }
+	fs, opts := new_flag_set("serve", "", formats)
+	listen      := fs.String("listen", "127.0.0.1:8080", "address and port to listen on (ex: :8080 for all interfaces)")
+	interval    := fs.Int("interval", 15, "refresh the inventory every N minutes")
+	all_regions := fs.Bool("all-regions", false, "collect the instances and volumes of all subscribed regions (default: region of the profile)")
+	parallelism := fs.Int("parallelism", ocihelpers.DefaultCompartmentParallelism, "number of compartments processed at the same time in each region")
+	token_file  := fs.String("token-file", "", "require the bearer token read from this file (default: OCITOOLS_TOKEN environment variable) in the requests")
+	fs.Parse(args)
+	if fs.NArg() != 0 || *interval < 1 || *parallelism < 1 { fs.Usage() }
+	opts.check(fs, formats)
+	token, err := serve_token(*token_file)
+	ocihelpers.FatalIfError(err)
+
+	// the compartments are listed at each refresh of the inventory (never read from the cache file)
+	ocihelpers.RefreshCompartmentCache = true
+
+	// first collection of the inventory before serving it (errors are fatal, ex: authentication)
+	config := opts.config_provider()
+	inv := &inventory{}
+	ocihelpers.FatalIfError(inv.refresh(config, *all_regions, *parallelism))
+
+	// refresh the inventory every N minutes (the previous inventory is served if the refresh fails)
+	go func() {
+		for range time.Tick(time.Duration(*interval) * time.Minute) {
+			if err := inv.refresh(config, *all_regions, *parallelism); err != nil { serve_log("ERROR: refresh failed: %s", err) }
+		}
+	}()
+
+	serve_log("serving the inventory on http://%s (endpoints /compartments, /instances, /volumes, /health)", *listen)
+	ocihelpers.FatalIfError(http.ListenAndServe(*listen, inv.handler(token)))
+}
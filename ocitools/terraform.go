@@ -0,0 +1,324 @@
+// --------------------------------------------------------------------------------------------------------------
+// ocitools: Terraform sub-commands
+//    terraform drift : compare a Terraform state file with the live resources of the tenant, per compartment
+//                      (resources in the tenant but not in the state, and resources in the state but not in the tenant)
+// Note: only some resource types are supported (see terraform_types)
+// Author        : Christophe Pauliat
+// Platforms     : MacOS / Linux
+// Versions
+//    2026-10-15: Initial Version
+// --------------------------------------------------------------------------------------------------------------
+
+
+package main
+
+// -- import
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/common"
+	"github.com/oracle/oci-go-sdk/core"
+	"github.com/oracle/oci-go-sdk/database"
+	"github.com/oracle/oci-go-sdk/identity"
+	"github.com/cpauliat/my-oci-scripts/internal/ocihelpers"
+)
+
+// -- types
+
+// Terraform state file (format version 4, Terraform 0.12 and later): only the fields used
+type tf_state struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   interface{}            `json:"index_key"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// resource of the state or of the tenant
+type drift_resource struct {
+	tf_type        string
+	address        string      // Terraform address (resources of the state only)
+	id             string
+	name           string
+	compartment_id string      // parent compartment for compartments
+}
+
+// list the live resources of a Terraform resource type in some compartments of a region
+type live_lister func(config common.ConfigurationProvider, region string, tree *ocihelpers.CompartmentTree, cpt_ids []string) ([]drift_resource, error)
+
+// -- global variables
+
+// Terraform resource types supported by terraform drift
+var terraform_types = map[string]live_lister{
+	"oci_identity_compartment"         : live_compartments,
+	"oci_core_vcn"                     : live_vcns,
+	"oci_core_subnet"                  : live_subnets,
+	"oci_core_instance"                : live_instances,
+	"oci_core_volume"                  : live_volumes,
+	"oci_database_autonomous_database" : live_adbs,
+}
+
+// -- functions
+func init() {
+	register("terraform drift", "compare a Terraform state file with the live resources (text, JSON, CSV or Markdown)", terraform_drift)
+}
+
+// read the resources of a Terraform state file (- for stdin, ex: terraform state pull | ocitools terraform drift -)
+func read_state(file string) ([]drift_resource, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(ocihelpers.ExpandPath(file))
+	}
+	if err != nil { return nil, err }
+	var state tf_state
+	if err := json.Unmarshal(data, &state); err != nil { return nil, fmt.Errorf("%s: %s", file, err) }
+	if state.Version != 4 { return nil, fmt.Errorf("%s: unsupported state format version %d (Terraform 0.12 or later needed)", file, state.Version) }
+
+	resources := make([]drift_resource, 0)
+	for _, r := range state.Resources {
+		if r.Mode != "managed" { continue }
+		for _, i := range r.Instances {
+			address := r.Type + "." + r.Name
+			if r.Module != "" { address = r.Module + "." + address }
+			switch key := i.IndexKey.(type) {
+			case string:  address += fmt.Sprintf("[%q]", key)
+			case float64: address += fmt.Sprintf("[%d]", int(key))
+			}
+			item := drift_resource{ tf_type : r.Type, address : address }
+			item.id, _             = i.Attributes["id"].(string)
+			item.compartment_id, _ = i.Attributes["compartment_id"].(string)
+			if item.name, _ = i.Attributes["display_name"].(string); item.name == "" { item.name, _ = i.Attributes["name"].(string) }
+			resources = append(resources, item)
+		}
+	}
+	return resources, nil
+}
+
+// active compartments whose parent is in the list
+func live_compartments(config common.ConfigurationProvider, region string, tree *ocihelpers.CompartmentTree, cpt_ids []string) ([]drift_resource, error) {
+	parents := make(map[string]bool)
+	for _, cpt_id := range cpt_ids { parents[cpt_id] = true }
+	resources := make([]drift_resource, 0)
+	for _, c := range tree.Compartments {
+		if c.LifecycleState == identity.CompartmentLifecycleStateActive && parents[*c.CompartmentId] {
+			resources = append(resources, drift_resource{ tf_type : "oci_identity_compartment", id : *c.Id, name : *c.Name, compartment_id : *c.CompartmentId })
+		}
+	}
+	return resources, nil
+}
+
+// VCNs (not terminated) of some compartments
+func live_vcns(config common.ConfigurationProvider, region string, tree *ocihelpers.CompartmentTree, cpt_ids []string) ([]drift_resource, error) {
+	client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	resources := make([]drift_resource, 0)
+	for _, cpt_id := range cpt_ids {
+		request := core.ListVcnsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListVcns(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				if v.LifecycleState != core.VcnLifecycleStateTerminated { resources = append(resources, drift_resource{ tf_type : "oci_core_vcn", id : *v.Id, name : *v.DisplayName, compartment_id : cpt_id }) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return resources, nil
+}
+
+// subnets (not terminated) of some compartments
+func live_subnets(config common.ConfigurationProvider, region string, tree *ocihelpers.CompartmentTree, cpt_ids []string) ([]drift_resource, error) {
+	client, err := core.NewVirtualNetworkClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	resources := make([]drift_resource, 0)
+	for _, cpt_id := range cpt_ids {
+		request := core.ListSubnetsRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListSubnets(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, s := range response.Items {
+				if s.LifecycleState != core.SubnetLifecycleStateTerminated { resources = append(resources, drift_resource{ tf_type : "oci_core_subnet", id : *s.Id, name : *s.DisplayName, compartment_id : cpt_id }) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return resources, nil
+}
+
+// compute instances (not terminated) of some compartments
+func live_instances(config common.ConfigurationProvider, region string, tree *ocihelpers.CompartmentTree, cpt_ids []string) ([]drift_resource, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	resources := make([]drift_resource, 0)
+	for _, cpt_id := range cpt_ids {
+		request := core.ListInstancesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListInstances(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, i := range response.Items {
+				if i.LifecycleState != core.InstanceLifecycleStateTerminated { resources = append(resources, drift_resource{ tf_type : "oci_core_instance", id : *i.Id, name : *i.DisplayName, compartment_id : cpt_id }) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return resources, nil
+}
+
+// block volumes (not terminated) of some compartments
+func live_volumes(config common.ConfigurationProvider, region string, tree *ocihelpers.CompartmentTree, cpt_ids []string) ([]drift_resource, error) {
+	client, err := core.NewBlockstorageClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	resources := make([]drift_resource, 0)
+	for _, cpt_id := range cpt_ids {
+		request := core.ListVolumesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListVolumes(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, v := range response.Items {
+				if v.LifecycleState != core.VolumeLifecycleStateTerminated { resources = append(resources, drift_resource{ tf_type : "oci_core_volume", id : *v.Id, name : *v.DisplayName, compartment_id : cpt_id }) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return resources, nil
+}
+
+// Autonomous Databases (not terminated) of some compartments
+func live_adbs(config common.ConfigurationProvider, region string, tree *ocihelpers.CompartmentTree, cpt_ids []string) ([]drift_resource, error) {
+	client, err := database.NewDatabaseClientWithConfigurationProvider(config)
+	if err != nil { return nil, err }
+	client.SetRegion(region)
+	resources := make([]drift_resource, 0)
+	for _, cpt_id := range cpt_ids {
+		request := database.ListAutonomousDatabasesRequest{ CompartmentId : common.String(cpt_id), RequestMetadata : common.RequestMetadata{ RetryPolicy : ocihelpers.RetryPolicy() } }
+		err := ocihelpers.ListAllPages(func(page *string) (*string, error) {
+			request.Page = page
+			response, err := client.ListAutonomousDatabases(context.Background(), request)
+			if err != nil { return nil, err }
+			for _, a := range response.Items {
+				if a.LifecycleState != database.AutonomousDatabaseSummaryLifecycleStateTerminated { resources = append(resources, drift_resource{ tf_type : "oci_database_autonomous_database", id : *a.Id, name : *a.DisplayName, compartment_id : cpt_id }) }
+			}
+			return response.OpcNextPage, nil
+		})
+		if err != nil { return nil, err }
+	}
+	return resources, nil
+}
+
+// ---- terraform drift
+func terraform_drift(args []string) {
+	formats := []string{ "text", "json", "csv", "markdown" }
+	supported := make([]string, 0, len(terraform_types))
+	for t := range terraform_types { supported = append(supported, t) }
+	sort.Strings(supported)
+	fs, opts := new_flag_set("terraform drift", "STATE_FILE", formats)
+	region      := fs.String("region", "", "region of the resources (default: region of the profile)")
+	compartment := fs.String("compartment", "", "check this compartment (full path) and its sub-compartments (default: compartments of the state)")
+	type_list   := fs.String("types", strings.Join(supported, ","), "comma separated list of Terraform resource types to check")
+	fs.Parse(args)
+	if fs.NArg() != 1 { fs.Usage() }
+	types := strings.Split(*type_list, ",")
+	for _, t := range types {
+		if _, ok := terraform_types[t]; !ok { fs.Usage() }
+	}
+	opts.check(fs, formats)
+
+	state, err := read_state(fs.Arg(0))
+	ocihelpers.FatalIfError(err)
+
+	config := opts.config_provider()
+	if *region == "" {
+		*region, err = config.Region()
+		ocihelpers.FatalIfError(err)
+	}
+	tree, err := ocihelpers.GetCompartmentTree(config)
+	ocihelpers.FatalIfError(err)
+	paths := tree.FullPaths()
+	paths[tree.TenancyOCID] = "root"
+
+	// compartments to check: the one given and its sub-compartments, or the (active) compartments of the resources of the state
+	active := make(map[string]bool)
+	for _, cpt_id := range tree.ActiveCompartmentIds() { active[cpt_id] = true }
+	scope := make(map[string]bool)
+	if *compartment != "" {
+		top_id, found := tree.FindByPath(*compartment)
+		if !found { ocihelpers.Fatal("compartment %s not found", *compartment) }
+		for cpt_id := range active {
+			if paths[cpt_id] == paths[top_id] || strings.HasPrefix(paths[cpt_id], paths[top_id] + "/") { scope[cpt_id] = true }
+		}
+	} else {
+		for _, r := range state {
+			if _, ok := terraform_types[r.tf_type]; ok && active[r.compartment_id] { scope[r.compartment_id] = true }
+		}
+	}
+	cpt_ids := make([]string, 0, len(scope))
+	for cpt_id := range scope { cpt_ids = append(cpt_ids, cpt_id) }
+
+	// live resources of the selected types in these compartments
+	in_state, in_tenancy := make(map[string]bool), make(map[string]bool)
+	for _, r := range state { in_state[r.id] = true }
+	results := ocihelpers.Table{ Headers : []string{ "compartment_path", "status", "type", "name", "address", "id" } }
+	nb_live := 0
+	for _, t := range types {
+		live, err := terraform_types[t](config, *region, tree, cpt_ids)
+		ocihelpers.FatalIfError(err)
+		for _, r := range live {
+			nb_live++
+			in_tenancy[r.id] = true
+			if !in_state[r.id] { results.AddRow(paths[r.compartment_id], "NOT_IN_STATE", r.tf_type, r.name, "", r.id) }
+		}
+	}
+
+	// resources of the state (selected types, compartments and region) not found in the tenant
+	// (without --compartment, the resources of deleted compartments are not found either)
+	selected := make(map[string]bool)
+	for _, t := range types { selected[t] = true }
+	nb_checked := 0
+	for _, r := range state {
+		if !selected[r.tf_type] { continue }
+		if !scope[r.compartment_id] && (*compartment != "" || active[r.compartment_id]) { continue }
+		if ocid_region := region_from_ocid(r.id); ocid_region != "" && ocid_region != *region { continue }
+		nb_checked++
+		if !in_tenancy[r.id] { results.AddRow(paths[r.compartment_id], "NOT_IN_TENANCY", r.tf_type, r.name, r.address, r.id) }
+	}
+
+	// Display the results per compartment
+	sort.SliceStable(results.Rows, func(i, j int) bool {
+		for k := 0; k < 4; k++ {
+			if results.Rows[i][k] != results.Rows[j][k] { return results.Rows[i][k] < results.Rows[j][k] }
+		}
+		return false
+	})
+	ocihelpers.FatalIfError(results.Print(opts.output))
+	if opts.output == "text" && !ocihelpers.Quiet {
+		fmt.Println ("")
+		fmt.Printf ("%d compartments, %d resources of the state checked, %d live resources: %d drifts\n", len(cpt_ids), nb_checked, nb_live, len(results.Rows))
+	}
+}